@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/alexflint/go-arg"
@@ -13,6 +14,7 @@ const (
 	// Config Metadata
 	FmanConfigDir      = "/.config/fman/"
 	FmanConfigFileName = "config.toml"
+	FmanSortFileName   = "sort.json" // sort chosen for each directory
 
 	// Config Defaults
 	DefaultTheme            = "dracula"
@@ -24,6 +26,12 @@ const (
 	DefaultDoubleClickDelay = 500
 	DefaultPrintPwdResult   = false
 	DefaultDryRun           = false
+	DefaultPreviewerTimeout = 2000
+	DefaultDirSizes         = false
+	DefaultSort             = "natural"
+	DefaultSortReverse      = false
+	DefaultCommandMode      = "foreground"
+	DefaultHookTimeout      = 2000
 )
 
 // These pointers are a janky way to get Nonetype values so we can know
@@ -40,10 +48,109 @@ type Cfg struct {
 	PreviewDelay     *int   `arg:"--preview-delay" placeholder:"DELAY" help:"delay in milliseconds before opening a file for previewing. This is meant to reduce io. Defaults to 200"`
 	DoubleClickDelay *int   `arg:"--double-click-delay" placeholder:"DELAY" help:"delay in milliseconds to register a second click as a double click. This is included for people with limited mobility. Defaults to 500"`
 	PrintPwdResult   *bool  `arg:"--print-pwd-as-result" help:"print the current working directory to stdout on exit. Defaults to false"`
+	CwdFile          string `arg:"--cwd-file" placeholder:"FILE" help:"write the current working directory to FILE on exit. Used by the shell functions of fman init"`
+	NoRemote         bool   `arg:"--no-remote" help:"do not listen for fman remote. Defaults to false"`
 	DryRun           *bool  `arg:"--dry-run" help:"do not make filesystem changes. Defaults to false"`
+	DirSizes         *bool  `arg:"--dir-sizes" help:"calculate the total size of directories in the background. Defaults to false"`
+	Sort             string `default:"" help:"default sort for directories without a saved sort. Options are: natural, name, size, mtime, ext. Defaults to natural"`
+	SortReverse      *bool  `arg:"--sort-reverse" help:"reverse the default sort. Defaults to false"`
+
+	// The following put fman in chooser mode and can only be set on the cli
+	ChooseFiles    string   `arg:"--choose-files" placeholder:"FILE" help:"choose files for another program. enter writes the paths of the chosen files to FILE, or to stdout if FILE is -, and quits"`
+	ChooseDir      string   `arg:"--choose-dir" placeholder:"FILE" help:"choose directories for another program, like --choose-files"`
+	ChooseMultiple bool     `arg:"--choose-multiple" help:"choose all selected entries instead of only the entry under the cursor"`
+	ChooseNull     bool     `arg:"--choose-null" help:"separate the chosen paths with NUL instead of newlines"`
+	ChooseFilter   []string `arg:"--choose-filter,separate" placeholder:"PATTERN" help:"only list files matching the pattern, such as image/*, .pdf or *.tar.gz. Can be repeated"`
+
+	// The following can only be set in the config file
+	Previewers        []PreviewerCfg `arg:"-"`
+	Openers           []OpenerCfg    `arg:"-"`
+	Commands          []CommandCfg   `arg:"-"`
+	Hooks             []HookCfg      `arg:"-"`
+	Columns           []ColumnCfg    `arg:"-"`
+	SortCaseSensitive bool           `arg:"-"` // do not ignore case when sorting by name
+	SortKeepAccents   bool           `arg:"-"` // do not ignore diacritics when sorting by name
 	// colorScheme theme.Theme // TODO: fetch colorscheme and icon map from theme and pin to config
 }
 
+// PreviewerCfg configures an external command whose output is used as the preview
+// of matching files. For example:
+//
+//	[[previewers]]
+//	match = ["application/pdf", ".pdf"]
+//	command = "pdftotext {path} -"
+//	timeout = 2000
+type PreviewerCfg struct {
+	Match    []string // mime type globs, extensions or file name globs
+	Command  string   // command to run. {path} is replaced by the path of the file
+	Timeout  int      // timeout in milliseconds. Defaults to 2000
+	MaxBytes int      // maximum bytes of output to keep. Defaults to the preview size
+}
+
+// OpenerCfg configures a command used to open matching files. The first matching
+// opener is used when a file is opened and all matching openers are listed in the
+// "open with" menu. For example:
+//
+//	[[openers]]
+//	name = "Edit"
+//	match = ["text/*"]
+//	command = "vim {paths}"
+//	foreground = true
+//	multiple = true
+type OpenerCfg struct {
+	Name       string   // name shown in the "open with" menu. Defaults to the program name
+	Match      []string // mime type globs, extensions or file name globs
+	Command    string   // command to run. {path} is replaced by the path of the file and {paths} by all selected files
+	Foreground bool     // run in the terminal and wait for the command to exit. Otherwise the command is detached
+	Multiple   bool     // open all selected files with one command instead of only the highlighted file
+}
+
+// CommandCfg configures a shell command run with a key. {path} is replaced by the entry
+// under the cursor, {paths} by the selected entries, {dir} by the current directory and
+// {clipboard} by the entries copied or cut. The paths are quoted for the shell. For example:
+//
+//	[[commands]]
+//	name = "Disk usage"
+//	key = "ctrl+u"
+//	command = "du -sh {paths} | sort -h"
+//	mode = "preview"
+//	button = true
+type CommandCfg struct {
+	Name    string // name shown in the help and on the button. Defaults to the program name
+	Key     string // key that runs the command, such as ctrl+u or f5
+	Command string // script run by sh, or by cmd on Windows
+	Mode    string // foreground, background or preview. Defaults to foreground
+	Button  bool   // show a button for the command next to the file buttons
+}
+
+// HookCfg configures a shell command run on an event of fman. The event is written to
+// its stdin as JSON. A pre- hook vetoes the operation by exiting with a non-zero status,
+// and the last line it printed is shown. For example:
+//
+//	[[hooks]]
+//	event = "post-rename"
+//	command = "cat >> ~/renames.jsonl"
+//	timeout = 2000
+type HookCfg struct {
+	Event   string // on-cd, on-select, pre-delete, post-delete, post-rename or post-paste
+	Command string // script run by sh, or by cmd on Windows, in the current directory
+	Timeout int    // timeout in milliseconds. Defaults to 2000
+}
+
+// ColumnCfg configures a column of the list. The columns are shown in the order
+// they are defined. Defaults to name, size and mtime. For example:
+//
+//	[[columns]]
+//	name = "name"
+//	width = 4
+//
+//	[[columns]]
+//	name = "perms"
+type ColumnCfg struct {
+	Name  string // one of name, size, mtime, perms, mode, owner, group, links, inode, btime, atime, mime, target
+	Width int    // width relative to the other columns. Defaults to a width suited to the column
+}
+
 // LoadConfig loads the configuration from the cli and config file (if present).
 //
 // It returns an error if the config file exists but could not be read or parsed
@@ -67,7 +174,7 @@ func loadConfigFile() (Cfg, error) {
 	if err != nil {
 		return fileCfg, err
 	}
-	fileContents, err := os.ReadFile(filepath.Join(home, FmanConfigDir, FmanConfigFileName))
+	fileContents, err := os.ReadFile(ConfigFilePath(home, FmanConfigFileName))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return fileCfg, nil
@@ -82,6 +189,11 @@ func loadConfigFile() (Cfg, error) {
 	return fileCfg, nil
 }
 
+// ConfigFilePath returns the path of a file with the given name in the fman config directory
+func ConfigFilePath(home string, name string) string {
+	return filepath.Join(home, FmanConfigDir, name)
+}
+
 // mergeConfigs takes the values from the cli and the config file and
 // prioritises the values from the cli, if they are not set.
 func mergeConfigs(cmdCfg Cfg, fileCfg Cfg) Cfg {
@@ -112,6 +224,22 @@ func mergeConfigs(cmdCfg Cfg, fileCfg Cfg) Cfg {
 	if cmdCfg.DryRun == nil {
 		cmdCfg.DryRun = fileCfg.DryRun
 	}
+	if cmdCfg.DirSizes == nil {
+		cmdCfg.DirSizes = fileCfg.DirSizes
+	}
+	if cmdCfg.Sort == "" {
+		cmdCfg.Sort = fileCfg.Sort
+	}
+	if cmdCfg.SortReverse == nil {
+		cmdCfg.SortReverse = fileCfg.SortReverse
+	}
+	cmdCfg.Previewers = fileCfg.Previewers
+	cmdCfg.Openers = fileCfg.Openers
+	cmdCfg.Commands = fileCfg.Commands
+	cmdCfg.Hooks = fileCfg.Hooks
+	cmdCfg.Columns = fileCfg.Columns
+	cmdCfg.SortCaseSensitive = fileCfg.SortCaseSensitive
+	cmdCfg.SortKeepAccents = fileCfg.SortKeepAccents
 	return cmdCfg
 }
 
@@ -151,5 +279,39 @@ func setDefaults(cfg Cfg) Cfg {
 		cfg.DryRun = new(bool)
 		*cfg.DryRun = DefaultDryRun
 	}
+	if cfg.DirSizes == nil {
+		cfg.DirSizes = new(bool)
+		*cfg.DirSizes = DefaultDirSizes
+	}
+	if cfg.Sort == "" {
+		cfg.Sort = DefaultSort
+	}
+	if cfg.SortReverse == nil {
+		cfg.SortReverse = new(bool)
+		*cfg.SortReverse = DefaultSortReverse
+	}
+	for i := range cfg.Previewers {
+		if cfg.Previewers[i].Timeout <= 0 {
+			cfg.Previewers[i].Timeout = DefaultPreviewerTimeout
+		}
+	}
+	for i := range cfg.Openers {
+		if cfg.Openers[i].Name == "" {
+			cfg.Openers[i].Name = strings.SplitN(strings.TrimSpace(cfg.Openers[i].Command), " ", 2)[0]
+		}
+	}
+	for i := range cfg.Commands {
+		if cfg.Commands[i].Name == "" {
+			cfg.Commands[i].Name = strings.SplitN(strings.TrimSpace(cfg.Commands[i].Command), " ", 2)[0]
+		}
+		if cfg.Commands[i].Mode == "" {
+			cfg.Commands[i].Mode = DefaultCommandMode
+		}
+	}
+	for i := range cfg.Hooks {
+		if cfg.Hooks[i].Timeout <= 0 {
+			cfg.Hooks[i].Timeout = DefaultHookTimeout
+		}
+	}
 	return cfg
 }
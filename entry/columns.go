@@ -0,0 +1,240 @@
+package entry
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Column describes a piece of information about entries that can be shown in the list
+type Column struct {
+	ID    string // identifies the column in the config
+	Title string // shown in the list header
+	Width int    // width of the column relative to the other columns
+
+	// Value returns the text shown in the column for an entry in the directory dir
+	Value func(dir string, e Entry) string
+
+	method SortMethod                        // sort method used to sort on the column, if less is nil
+	less   func(dir string, a, b Entry) bool // orders entries when sorting on the column
+}
+
+// Sorted reports whether entries sorted with spec are sorted on the column
+func (c Column) Sorted(spec SortSpec) bool {
+	if c.less != nil {
+		return spec.Column == c.ID
+	}
+	if spec.Column != "" {
+		return false
+	}
+	if c.method == NaturalSort {
+		// the name column covers all of the methods that sort on the name
+		return spec.Method == NaturalSort || spec.Method == NameSort || spec.Method == ExtSort
+	}
+	return spec.Method == c.method
+}
+
+// SortBy returns spec changed to sort on the column. If spec is
+// already sorted on the column, the sort is reversed instead.
+func (c Column) SortBy(spec SortSpec) SortSpec {
+	if c.Sorted(spec) {
+		spec.Reverse = !spec.Reverse
+		return spec
+	}
+	spec.Reverse = false
+	spec.Column = ""
+	spec.Method = c.method
+	if c.less != nil {
+		spec.Column = c.ID
+	}
+	return spec
+}
+
+// LookupColumn returns the column with the given id
+func LookupColumn(id string) (Column, bool) {
+	for _, c := range columns {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// ColumnIDs returns the ids of all of the available columns
+func ColumnIDs() []string {
+	ids := make([]string, len(columns))
+	for i, c := range columns {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+// DefaultColumns returns the columns shown when none are configured
+func DefaultColumns() []Column {
+	cols := make([]Column, 0, 3)
+	for _, id := range []string{"name", "size", "mtime"} {
+		c, _ := LookupColumn(id)
+		cols = append(cols, c)
+	}
+	return cols
+}
+
+var columns = []Column{
+	{
+		ID:     "name",
+		Title:  "Name",
+		Width:  5,
+		Value:  func(_ string, e Entry) string { return e.Name() },
+		method: NaturalSort,
+	},
+	{
+		ID:     "size",
+		Title:  "Size",
+		Width:  2,
+		Value:  func(_ string, e Entry) string { return e.SizeStr },
+		method: SizeSort,
+	},
+	{
+		ID:     "mtime",
+		Title:  "Modify Time",
+		Width:  3,
+		Value:  func(_ string, e Entry) string { return e.ModifyTime },
+		method: MtimeSort,
+	},
+	{
+		ID:    "perms",
+		Title: "Permissions",
+		Width: 3,
+		Value: func(_ string, e Entry) string { return e.Mode().String() },
+		less: func(_ string, a, b Entry) bool {
+			return a.Mode().String() < b.Mode().String()
+		},
+	},
+	{
+		ID:    "mode",
+		Title: "Mode",
+		Width: 2,
+		Value: func(_ string, e Entry) string { return fmt.Sprintf("%04o", octalMode(e.Mode())) },
+		less: func(_ string, a, b Entry) bool {
+			return octalMode(a.Mode()) < octalMode(b.Mode())
+		},
+	},
+	{
+		ID:    "owner",
+		Title: "Owner",
+		Width: 2,
+		Value: func(_ string, e Entry) string { return userName(e.FileInfo) },
+		less: func(_ string, a, b Entry) bool {
+			return userName(a.FileInfo) < userName(b.FileInfo)
+		},
+	},
+	{
+		ID:    "group",
+		Title: "Group",
+		Width: 2,
+		Value: func(_ string, e Entry) string { return groupName(e.FileInfo) },
+		less: func(_ string, a, b Entry) bool {
+			return groupName(a.FileInfo) < groupName(b.FileInfo)
+		},
+	},
+	{
+		ID:    "links",
+		Title: "Links",
+		Width: 1,
+		Value: func(_ string, e Entry) string { return formatUint(linkCount(e.FileInfo)) },
+		less: func(_ string, a, b Entry) bool {
+			n1, _ := linkCount(a.FileInfo)
+			n2, _ := linkCount(b.FileInfo)
+			return n1 < n2
+		},
+	},
+	{
+		ID:    "inode",
+		Title: "Inode",
+		Width: 2,
+		Value: func(_ string, e Entry) string { return formatUint(inode(e.FileInfo)) },
+		less: func(_ string, a, b Entry) bool {
+			n1, _ := inode(a.FileInfo)
+			n2, _ := inode(b.FileInfo)
+			return n1 < n2
+		},
+	},
+	{
+		ID:    "btime",
+		Title: "Creation Time",
+		Width: 3,
+		Value: func(_ string, e Entry) string { return formatTime(e.BirthTime(), !e.BirthTime().IsZero()) },
+		less: func(_ string, a, b Entry) bool {
+			return a.BirthTime().Before(b.BirthTime())
+		},
+	},
+	{
+		ID:    "atime",
+		Title: "Access Time",
+		Width: 3,
+		Value: func(_ string, e Entry) string { return formatTime(accessTime(e.FileInfo)) },
+		less: func(_ string, a, b Entry) bool {
+			t1, _ := accessTime(a.FileInfo)
+			t2, _ := accessTime(b.FileInfo)
+			return t1.Before(t2)
+		},
+	},
+	{
+		ID:    "mime",
+		Title: "Type",
+		Width: 3,
+		Value: func(_ string, e Entry) string { return e.MimeType },
+		less: func(_ string, a, b Entry) bool {
+			return a.MimeType < b.MimeType
+		},
+	},
+	{
+		ID:    "target",
+		Title: "Link Target",
+		Width: 4,
+		Value: func(_ string, e Entry) string { return e.SymLinkPath },
+		less: func(_ string, a, b Entry) bool {
+			return a.SymLinkPath < b.SymLinkPath
+		},
+	},
+}
+
+// sortByColumn sorts the entries with the less function of the column
+func sortByColumn(dirPath string, entries []Entry, col Column) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return col.less(dirPath, entries[i], entries[j])
+	})
+}
+
+// octalMode returns the permission bits of the mode as used by chmod
+func octalMode(mode fs.FileMode) uint32 {
+	octal := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		octal |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		octal |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		octal |= 0o1000
+	}
+	return octal
+}
+
+func formatUint(n uint64, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+func formatTime(t time.Time, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return humanize.Time(t)
+}
@@ -0,0 +1,80 @@
+package entry
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestColumnSortBy(t *testing.T) {
+	t.Parallel()
+	name, _ := LookupColumn("name")
+	size, _ := LookupColumn("size")
+	perms, _ := LookupColumn("perms")
+
+	spec := DefaultSortSpec()
+	if !name.Sorted(spec) || size.Sorted(spec) {
+		t.Fatal("expected the default sort to be sorted on the name column only")
+	}
+	spec = size.SortBy(spec)
+	if spec.Method != SizeSort || spec.Reverse || spec.Column != "" {
+		t.Errorf("expected sort by size, got %+v", spec)
+	}
+	spec = size.SortBy(spec)
+	if spec.Method != SizeSort || !spec.Reverse {
+		t.Errorf("expected reversed sort by size, got %+v", spec)
+	}
+	spec = perms.SortBy(spec)
+	if spec.Column != "perms" || spec.Reverse || !perms.Sorted(spec) || size.Sorted(spec) {
+		t.Errorf("expected sort by permissions, got %+v", spec)
+	}
+	spec = name.SortBy(spec)
+	if spec.Column != "" || spec.Method != NaturalSort {
+		t.Errorf("expected natural sort, got %+v", spec)
+	}
+}
+
+func TestSortByColumn(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/dir/a", []byte("a"), 0o644)
+	afero.WriteFile(fsys, "/dir/b", []byte("b"), 0o600)
+	afero.WriteFile(fsys, "/dir/c", []byte("c"), 0o755)
+
+	spec := DefaultSortSpec()
+	spec.Column = "mode"
+	entries, _, err := GetSortedEntries(fsys, "/dir", true, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mode, _ := LookupColumn("mode")
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name()+" "+mode.Value("/dir", e))
+	}
+	want := []string{"b 0600", "a 0644", "c 0755"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestOctalMode(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		mode fs.FileMode
+		want uint32
+	}{
+		{0o644, 0o644},
+		{fs.ModeDir | 0o755, 0o755},
+		{fs.ModeSetuid | 0o755, 0o4755},
+		{fs.ModeDir | fs.ModeSticky | 0o777, 0o1777},
+	}
+	for _, tc := range testcases {
+		if got := octalMode(tc.mode); got != tc.want {
+			t.Errorf("octalMode(%v) = %o; want %o", tc.mode, got, tc.want)
+		}
+	}
+}
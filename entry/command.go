@@ -0,0 +1,97 @@
+package entry
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnterminatedQuote = errors.New("unterminated quote in command")
+
+// ExpandCommand splits a command template into arguments and replaces placeholders
+// of the form {name} with the values in vars. Arguments are split on whitespace and
+// single or double quotes can be used to keep whitespace inside an argument.
+//
+// A placeholder that makes up a whole unquoted argument expands to one argument per
+// value, so "cmd {paths}" with two paths becomes three arguments and the paths never
+// need quoting. A placeholder embedded in a larger argument is replaced by its values
+// joined with a space. Unknown placeholders are left untouched.
+func ExpandCommand(template string, vars map[string][]string) ([]string, error) {
+	words, err := splitCommand(template)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(words))
+	for _, w := range words {
+		if !w.quoted && strings.HasPrefix(w.text, "{") && strings.HasSuffix(w.text, "}") {
+			if values, ok := vars[w.text[1:len(w.text)-1]]; ok {
+				args = append(args, values...)
+				continue
+			}
+		}
+		args = append(args, replacePlaceholders(w.text, vars))
+	}
+	return args, nil
+}
+
+// replacePlaceholders replaces every known {name} in s with the values joined by a space.
+func replacePlaceholders(s string, vars map[string][]string) string {
+	for name, values := range vars {
+		s = strings.ReplaceAll(s, "{"+name+"}", strings.Join(values, " "))
+	}
+	return s
+}
+
+type commandWord struct {
+	text   string
+	quoted bool
+}
+
+// splitCommand splits s on unquoted whitespace. Quotes are removed and a
+// backslash outside of single quotes escapes the next character.
+func splitCommand(s string) ([]commandWord, error) {
+	var (
+		words   []commandWord
+		current strings.Builder
+		inWord  bool
+		quoted  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			quoted = true
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, commandWord{current.String(), quoted})
+				current.Reset()
+				inWord = false
+				quoted = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errUnterminatedQuote
+	}
+	if inWord {
+		words = append(words, commandWord{current.String(), quoted})
+	}
+	return words, nil
+}
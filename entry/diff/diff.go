@@ -0,0 +1,306 @@
+// Package diff computes line based differences between files and
+// entry based differences between directories.
+package diff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxEditDistance bounds the work done by the Myers algorithm. Parts of the inputs that
+// are further apart than this are reported as a full replacement.
+const maxEditDistance = 4096
+
+// Op is the kind of an edit
+type Op uint8
+
+const (
+	Equal  Op = iota // line is in both a and b
+	Delete           // line is only in a
+	Insert           // line is only in b
+)
+
+// Edit is a single line of a diff. A and B are the zero based line numbers
+// in a and b, or -1 if the line is not present on that side.
+type Edit struct {
+	Op   Op
+	A    int
+	B    int
+	Text string
+}
+
+// Lines returns the shortest edit script that turns a into b using the Myers algorithm.
+func Lines(a, b []string) []Edit {
+	edits, _ := LinesContext(context.Background(), a, b)
+	return edits
+}
+
+// LinesContext is like Lines but stops early and returns the error of ctx if it is done.
+// It uses the linear space variant of the Myers algorithm, which splits the inputs at
+// the middle of the shortest edit script and compares both halves.
+func LinesContext(ctx context.Context, a, b []string) ([]Edit, error) {
+	limit := (len(a) + len(b) + 1) / 2
+	if limit > maxEditDistance/2 {
+		limit = maxEditDistance / 2
+	}
+	d := differ{
+		ctx:   ctx,
+		a:     a,
+		b:     b,
+		limit: limit,
+		vf:    make([]int, 2*limit+3),
+		vb:    make([]int, 2*limit+3),
+		edits: make([]Edit, 0, len(a)+len(b)),
+	}
+	if err := d.compare(0, len(a), 0, len(b)); err != nil {
+		return nil, err
+	}
+	return d.edits, nil
+}
+
+// differ holds the state of a comparison. vf and vb are the furthest points reached
+// on each diagonal by the forward and the backward searches and are reused by every
+// part of the inputs, so memory grows with the inputs and not with the edit distance.
+type differ struct {
+	ctx    context.Context
+	a, b   []string
+	limit  int // searches going further than this report a replacement
+	vf, vb []int
+	edits  []Edit
+}
+
+// compare appends the edits turning a[a0:a1] into b[b0:b1]
+func (d *differ) compare(a0, a1, b0, b1 int) error {
+	// common prefixes and suffixes are trimmed first as they are
+	// cheap to find and very common when comparing versions of a file
+	for a0 < a1 && b0 < b1 && d.a[a0] == d.b[b0] {
+		d.equal(a0, b0)
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1-suffix && b0 < b1-suffix && d.a[a1-1-suffix] == d.b[b1-1-suffix] {
+		suffix++
+	}
+	a1, b1 = a1-suffix, b1-suffix
+	defer func() {
+		for i := 0; i < suffix; i++ {
+			d.equal(a1+i, b1+i)
+		}
+	}()
+
+	if a0 == a1 || b0 == b1 {
+		d.replace(a0, a1, b0, b1)
+		return nil
+	}
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
+	x, y, u, v, ok := d.middleSnake(a0, a1, b0, b1)
+	if !ok || (x == a0 && y == b0 && u == a1 && v == b1) {
+		d.replace(a0, a1, b0, b1)
+		return d.ctx.Err()
+	}
+	if err := d.compare(a0, x, b0, y); err != nil {
+		return err
+	}
+	for ; x < u; x, y = x+1, y+1 {
+		d.equal(x, y)
+	}
+	return d.compare(u, a1, v, b1)
+}
+
+// middleSnake runs the forward and the backward searches of "An O(ND) Difference
+// Algorithm and Its Variations" until they meet and returns the snake from (x, y) to
+// (u, v) where they do. ok is false if the searches went further than the limit or the
+// context is done.
+func (d *differ) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int, ok bool) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	if maxD > d.limit {
+		maxD = d.limit
+	}
+	// vf[o+k] is the furthest x reached on the diagonal k = x-y of the forward search and
+	// vb[o+k] the furthest distance from the ends reached on the diagonal k of the
+	// backward search, which runs on the reversed inputs
+	o := maxD + 1
+	d.vf[o+1], d.vb[o+1] = 0, 0
+	for step := 0; step <= maxD; step++ {
+		if d.ctx.Err() != nil {
+			return 0, 0, 0, 0, false
+		}
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && d.vf[o+k-1] < d.vf[o+k+1]) {
+				x = d.vf[o+k+1]
+			} else {
+				x = d.vf[o+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[a0+x] == d.b[b0+y] {
+				x++
+				y++
+			}
+			d.vf[o+k] = x
+			// the backward search took step-1 steps and its diagonals are reversed
+			if kb := delta - k; odd && kb >= -(step-1) && kb <= step-1 && x >= n-d.vb[o+kb] {
+				return a0 + startX, b0 + startY, a0 + x, b0 + y, true
+			}
+		}
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && d.vb[o+k-1] < d.vb[o+k+1]) {
+				x = d.vb[o+k+1]
+			} else {
+				x = d.vb[o+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[a1-1-x] == d.b[b1-1-y] {
+				x++
+				y++
+			}
+			d.vb[o+k] = x
+			if kf := delta - k; !odd && kf >= -step && kf <= step && d.vf[o+kf] >= n-x {
+				return a1 - x, b1 - y, a1 - startX, b1 - startY, true
+			}
+		}
+	}
+	return 0, 0, 0, 0, false
+}
+
+func (d *differ) equal(a, b int) {
+	d.edits = append(d.edits, Edit{Op: Equal, A: a, B: b, Text: d.a[a]})
+}
+
+// replace appends the deletion of a[a0:a1] and the insertion of b[b0:b1]
+func (d *differ) replace(a0, a1, b0, b1 int) {
+	for i := a0; i < a1; i++ {
+		d.edits = append(d.edits, Edit{Op: Delete, A: i, B: -1, Text: d.a[i]})
+	}
+	for i := b0; i < b1; i++ {
+		d.edits = append(d.edits, Edit{Op: Insert, A: -1, B: i, Text: d.b[i]})
+	}
+}
+
+// Hunk is a group of changed lines along with the surrounding context lines.
+// The starts are one based line numbers as used in unified diffs.
+type Hunk struct {
+	AStart int
+	ALines int
+	BStart int
+	BLines int
+	Edits  []Edit
+}
+
+// Header returns the unified diff header of the hunk, e.g. "@@ -1,3 +1,4 @@"
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.AStart, h.ALines, h.BStart, h.BLines)
+}
+
+// Hunks groups the edits into hunks with up to context unchanged lines around each change.
+// Changes closer than 2*context lines are merged into one hunk.
+func Hunks(edits []Edit, context int) []Hunk {
+	var hunks []Hunk
+	i := 0
+	for i < len(edits) {
+		// find the next change
+		for i < len(edits) && edits[i].Op == Equal {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		// extend the hunk while the next change is within reach of the context
+		end := i
+		for {
+			for end < len(edits) && edits[end].Op != Equal {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].Op == Equal {
+				next++
+			}
+			if next == len(edits) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		stop := end + context
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+		hunks = append(hunks, newHunk(edits, start, stop))
+		i = stop
+	}
+	return hunks
+}
+
+func newHunk(edits []Edit, start, stop int) Hunk {
+	h := Hunk{Edits: edits[start:stop]}
+	// the position of the hunk on each side is the position of the first line
+	// on that side, or the line before the hunk if the side has no lines
+	aPos, bPos := 0, 0
+	for j := start - 1; j >= 0; j-- {
+		if edits[j].A >= 0 && aPos == 0 {
+			aPos = edits[j].A + 1
+		}
+		if edits[j].B >= 0 && bPos == 0 {
+			bPos = edits[j].B + 1
+		}
+		if aPos != 0 && bPos != 0 {
+			break
+		}
+	}
+	h.AStart, h.BStart = aPos, bPos
+	aSet, bSet := false, false
+	for _, e := range h.Edits {
+		if e.A >= 0 {
+			if !aSet {
+				h.AStart = e.A + 1
+				aSet = true
+			}
+			h.ALines++
+		}
+		if e.B >= 0 {
+			if !bSet {
+				h.BStart = e.B + 1
+				bSet = true
+			}
+			h.BLines++
+		}
+	}
+	return h
+}
+
+// Unified renders the hunks as a unified diff.
+func Unified(nameA, nameB string, hunks []Hunk) string {
+	var sb strings.Builder
+	sb.WriteString("--- " + nameA + "\n")
+	sb.WriteString("+++ " + nameB + "\n")
+	for _, h := range hunks {
+		sb.WriteString(h.Header())
+		sb.WriteByte('\n')
+		for _, e := range h.Edits {
+			switch e.Op {
+			case Equal:
+				sb.WriteByte(' ')
+			case Delete:
+				sb.WriteByte('-')
+			case Insert:
+				sb.WriteByte('+')
+			}
+			sb.WriteString(e.Text)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,226 @@
+package diff
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// apply rebuilds both sides from the edit script so we can check it is valid
+func apply(edits []Edit) ([]string, []string) {
+	var a, b []string
+	for _, e := range edits {
+		switch e.Op {
+		case Equal:
+			a = append(a, e.Text)
+			b = append(b, e.Text)
+		case Delete:
+			a = append(a, e.Text)
+		case Insert:
+			b = append(b, e.Text)
+		}
+	}
+	return a, b
+}
+
+func countChanges(edits []Edit) int {
+	n := 0
+	for _, e := range edits {
+		if e.Op != Equal {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		name        string
+		a           string
+		b           string
+		wantChanges int
+	}{
+		{"equal", "a b c", "a b c", 0},
+		{"empty a", "", "a b", 2},
+		{"empty b", "a b", "", 2},
+		{"insert middle", "a c", "a b c", 1},
+		{"delete middle", "a b c", "a c", 1},
+		{"replace", "a b c", "a x c", 2},
+		{"myers example", "a b c a b b a", "c b a b a c", 5},
+		{"swap", "a b", "b a", 2},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := strings.Fields(tc.a), strings.Fields(tc.b)
+			edits := Lines(a, b)
+			gotA, gotB := apply(edits)
+			if strings.Join(gotA, " ") != tc.a || strings.Join(gotB, " ") != tc.b {
+				t.Fatalf("edit script does not rebuild inputs: got %v and %v", gotA, gotB)
+			}
+			if got := countChanges(edits); got != tc.wantChanges {
+				t.Errorf("got %d changes; want %d", got, tc.wantChanges)
+			}
+			for _, e := range edits {
+				if e.A >= 0 && a[e.A] != e.Text || e.B >= 0 && b[e.B] != e.Text {
+					t.Errorf("edit %+v has the wrong line numbers", e)
+				}
+			}
+		})
+	}
+}
+
+// lcs returns the length of the longest common subsequence of a and b
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func TestLinesShortest(t *testing.T) {
+	t.Parallel()
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		edits := Lines(a, b)
+		gotA, gotB := apply(edits)
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("edit script of %v and %v does not rebuild inputs", a, b)
+		}
+		if got, want := countChanges(edits), len(a)+len(b)-2*lcs(a, b); got != want {
+			t.Fatalf("%v and %v: got %d changes; want %d", a, b, got, want)
+		}
+	}
+}
+
+func TestLinesContextCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LinesContext(ctx, []string{"a", "b"}, []string{"b", "a"}); err != context.Canceled {
+		t.Errorf("got %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	t.Parallel()
+	a := strings.Fields("1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20")
+	b := strings.Fields("1 2 x 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 y 20")
+	hunks := Hunks(Lines(a, b), 3)
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks; want 2", len(hunks))
+	}
+	if got := hunks[0].Header(); got != "@@ -1,6 +1,6 @@" {
+		t.Errorf("got header %s; want @@ -1,6 +1,6 @@", got)
+	}
+	if got := hunks[1].Header(); got != "@@ -16,5 +16,5 @@" {
+		t.Errorf("got header %s; want @@ -16,5 +16,5 @@", got)
+	}
+
+	// changes within 2*context lines are merged
+	b = strings.Fields("1 2 x 4 5 6 7 y 9 10 11 12 13 14 15 16 17 18 19 20")
+	hunks = Hunks(Lines(a, b), 3)
+	if len(hunks) != 1 {
+		t.Fatalf("got %d hunks; want 1", len(hunks))
+	}
+}
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+	a := []string{"a", "b", "c"}
+	b := []string{"a", "c", "d"}
+	got := Unified("a.txt", "b.txt", Hunks(Lines(a, b), 3))
+	want := "--- a.txt\n+++ b.txt\n@@ -1,3 +1,3 @@\n a\n-b\n c\n+d\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = Unified("a.txt", "b.txt", Hunks(Lines(nil, []string{"new"}), 3))
+	want = "--- a.txt\n+++ b.txt\n@@ -0,0 +1,1 @@\n+new\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/a.txt", []byte("one\ntwo\n"), 0644)
+	afero.WriteFile(fsys, "/b.txt", []byte("one\r\nthree\r\n"), 0644)
+	afero.WriteFile(fsys, "/c.bin", []byte{1, 0, 2}, 0644)
+
+	d, err := Files(context.Background(), fsys, "/a.txt", "/b.txt", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Hunks) != 1 || countChanges(d.Hunks[0].Edits) != 2 {
+		t.Errorf("unexpected hunks %+v", d.Hunks)
+	}
+	if _, err := Files(context.Background(), fsys, "/a.txt", "/c.bin", 1000); err != ErrBinary {
+		t.Errorf("got error %v; want %v", err, ErrBinary)
+	}
+}
+
+func TestDirs(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	now := time.Now()
+	write := func(path, content string, mod time.Time) {
+		afero.WriteFile(fsys, path, []byte(content), 0644)
+		fsys.Chtimes(path, mod, mod)
+	}
+	write("/a/same", "same", now)
+	write("/b/same", "same", now)
+	write("/a/size", "short", now)
+	write("/b/size", "longer", now)
+	write("/a/touched", "abc", now)
+	write("/b/touched", "abc", now.Add(time.Hour))
+	write("/a/changed", "abc", now)
+	write("/b/changed", "xyz", now.Add(time.Hour))
+	write("/a/onlya/child", "x", now)
+	write("/b/onlyb", "x", now)
+
+	got, err := Dirs(context.Background(), fsys, "/a", "/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EntryDiff{
+		{Path: "changed", Status: ContentDiffers},
+		{Path: "onlya", IsDir: true, Status: OnlyInA},
+		{Path: "onlyb", Status: OnlyInB},
+		{Path: "size", Status: SizeDiffers},
+		{Path: "touched", Status: ModTimeDiffers},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v; want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %+v; want %+v", got[i], want[i])
+		}
+	}
+}
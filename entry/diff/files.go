@@ -0,0 +1,227 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+var ErrBinary = errors.New("cannot diff binary files")
+
+// FileDiff is the difference between two files.
+type FileDiff struct {
+	PathA  string
+	PathB  string
+	LinesA []string
+	LinesB []string
+	Hunks  []Hunk
+}
+
+// Unified returns the difference as a unified diff
+func (f FileDiff) Unified() string {
+	return Unified(f.PathA, f.PathB, f.Hunks)
+}
+
+// Files compares the text files at paths a and b. At most maxBytes are read from each file.
+// ErrBinary is returned if either file does not look like text.
+func Files(ctx context.Context, fsys afero.Fs, a, b string, maxBytes int64) (FileDiff, error) {
+	linesA, err := readLines(fsys, a, maxBytes)
+	if err != nil {
+		return FileDiff{}, err
+	}
+	if ctx.Err() != nil {
+		return FileDiff{}, ctx.Err()
+	}
+	linesB, err := readLines(fsys, b, maxBytes)
+	if err != nil {
+		return FileDiff{}, err
+	}
+	edits, err := LinesContext(ctx, linesA, linesB)
+	if err != nil {
+		return FileDiff{}, err
+	}
+	return FileDiff{
+		PathA:  a,
+		PathB:  b,
+		LinesA: linesA,
+		LinesB: linesB,
+		Hunks:  Hunks(edits, DefaultContext),
+	}, nil
+}
+
+func readLines(fsys afero.Fs, path string, maxBytes int64) ([]string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, maxBytes))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(content, 0) != -1 {
+		return nil, ErrBinary
+	}
+	if len(content) == 0 {
+		return []string{}, nil
+	}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), nil
+}
+
+// Status describes how an entry differs between two directories
+type Status uint8
+
+const (
+	OnlyInA Status = iota
+	OnlyInB
+	SizeDiffers
+	ModTimeDiffers // same size and content but a different modification time
+	ContentDiffers // same size but a different checksum
+	TypeDiffers    // a file in one directory and a directory in the other
+)
+
+func (s Status) String() string {
+	return [...]string{
+		"only in A",
+		"only in B",
+		"size",
+		"modified",
+		"checksum",
+		"type",
+	}[s]
+}
+
+// EntryDiff is an entry that differs between two directories.
+type EntryDiff struct {
+	Path   string // path relative to the compared directories
+	IsDir  bool
+	Status Status
+}
+
+// Dirs recursively compares the directories a and b and returns the entries that
+// differ, sorted by path. Files with the same size but different modification times
+// are compared by checksum. Entries that are only in one of the directories are
+// reported but their children are not.
+func Dirs(ctx context.Context, fsys afero.Fs, a, b string) ([]EntryDiff, error) {
+	infosA, err := walkRelative(ctx, fsys, a)
+	if err != nil {
+		return nil, err
+	}
+	infosB, err := walkRelative(ctx, fsys, b)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []EntryDiff
+	for rel, infoA := range infosA {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		infoB, ok := infosB[rel]
+		if !ok {
+			if !parentReported(infosB, rel) {
+				diffs = append(diffs, EntryDiff{Path: rel, IsDir: infoA.IsDir(), Status: OnlyInA})
+			}
+			continue
+		}
+		switch {
+		case infoA.IsDir() != infoB.IsDir():
+			diffs = append(diffs, EntryDiff{Path: rel, Status: TypeDiffers})
+		case infoA.IsDir():
+			// directories are compared through their children
+		case infoA.Size() != infoB.Size():
+			diffs = append(diffs, EntryDiff{Path: rel, Status: SizeDiffers})
+		case !infoA.ModTime().Equal(infoB.ModTime()):
+			same, err := sameContent(fsys, filepath.Join(a, rel), filepath.Join(b, rel))
+			if err != nil {
+				return nil, err
+			}
+			status := ModTimeDiffers
+			if !same {
+				status = ContentDiffers
+			}
+			diffs = append(diffs, EntryDiff{Path: rel, Status: status})
+		}
+	}
+	for rel, infoB := range infosB {
+		if _, ok := infosA[rel]; ok || parentReported(infosA, rel) {
+			continue
+		}
+		diffs = append(diffs, EntryDiff{Path: rel, IsDir: infoB.IsDir(), Status: OnlyInB})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+// parentReported returns true if the parent directory of rel is not a directory
+// in other, in which case only the parent is reported.
+func parentReported(other map[string]fs.FileInfo, rel string) bool {
+	parent := filepath.Dir(rel)
+	if parent == "." {
+		return false
+	}
+	info, ok := other[parent]
+	return !ok || !info.IsDir()
+}
+
+// walkRelative returns the file info of every entry under root keyed by its path relative to root.
+func walkRelative(ctx context.Context, fsys afero.Fs, root string) (map[string]fs.FileInfo, error) {
+	infos := make(map[string]fs.FileInfo)
+	err := afero.Walk(fsys, root, func(path string, info fs.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		infos[rel] = info
+		return nil
+	})
+	return infos, err
+}
+
+func sameContent(fsys afero.Fs, a, b string) (bool, error) {
+	sumA, err := checksum(fsys, a)
+	if err != nil {
+		return false, err
+	}
+	sumB, err := checksum(fsys, b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(sumA, sumB), nil
+}
+
+func checksum(fsys afero.Fs, path string) ([]byte, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
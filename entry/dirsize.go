@@ -0,0 +1,35 @@
+package entry
+
+import (
+	"context"
+
+	"github.com/spf13/afero"
+)
+
+// DirSize returns the total size in bytes of the files under dir. Directories are
+// walked concurrently with at most nRoutines goroutines. Symlinks are not followed
+// and entries that cannot be read are skipped.
+func DirSize(ctx context.Context, fsys afero.Fs, dir string, nRoutines int) (int64, error) {
+	entriesCh, errCh, err := WalkDown(ctx, fsys, dir, -1, nRoutines, true)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for entriesCh != nil || errCh != nil {
+		select {
+		case e, ok := <-entriesCh:
+			if !ok {
+				entriesCh = nil
+				continue
+			}
+			if !e.IsDir() {
+				size += e.Size()
+			}
+		case _, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			}
+		}
+	}
+	return size, ctx.Err()
+}
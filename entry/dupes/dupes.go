@@ -0,0 +1,290 @@
+// Package dupes finds files with the same content. Files are grouped by size, then by a
+// hash of their first and last blocks and finally by a hash of their full content, so
+// most files never have to be read in full.
+package dupes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/spf13/afero"
+	"golang.org/x/sync/errgroup"
+)
+
+// blockSize is the size of the blocks at the start and end of a file that are hashed
+// before the full content
+const blockSize = 4096
+
+// Group is a set of files with the same content
+type Group struct {
+	Size  int64    // size of each file
+	Paths []string // sorted paths of the files
+}
+
+// Reclaimable returns the bytes freed by keeping only one of the files
+func (g Group) Reclaimable() int64 {
+	if len(g.Paths) < 2 {
+		return 0
+	}
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+// Stage is the step a search is at
+type Stage int32
+
+const (
+	StageWalk    Stage = iota // listing the files
+	StagePartial              // hashing the first and last blocks of files of the same size
+	StageFull                 // hashing the full content of the remaining candidates
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StagePartial:
+		return "Comparing"
+	case StageFull:
+		return "Hashing"
+	}
+	return "Listing"
+}
+
+// Progress reports how far a search is. It is safe for concurrent use.
+type Progress struct {
+	stage  atomic.Int32
+	files  atomic.Int64
+	hashed atomic.Int64
+}
+
+// Stage returns the step the search is at
+func (p *Progress) Stage() Stage {
+	return Stage(p.stage.Load())
+}
+
+// Counts returns the number of files listed and the number of bytes hashed so far
+func (p *Progress) Counts() (files int64, hashed int64) {
+	return p.files.Load(), p.hashed.Load()
+}
+
+// Options configures a search
+type Options struct {
+	MinSize  int64 // files smaller than this are ignored. Empty files are always ignored
+	Routines int   // maximum number of directories read or files hashed concurrently
+}
+
+// Find returns the groups of files below root with the same content, sorted by the space
+// that removing the copies would free. Symlinks are not followed and files that are hard
+// links to each other are only counted once. Files that cannot be read are skipped.
+// progress may be nil.
+func Find(ctx context.Context, fsys afero.Fs, root string, opts Options, progress *Progress) ([]Group, error) {
+	if progress == nil {
+		progress = &Progress{}
+	}
+	if opts.Routines <= 0 {
+		opts.Routines = 10
+	}
+	if opts.MinSize < 1 {
+		opts.MinSize = 1
+	}
+
+	bySize, err := listFiles(ctx, fsys, root, opts, progress)
+	if err != nil {
+		return nil, err
+	}
+	var candidates [][]string
+	for _, paths := range bySize {
+		if len(paths) > 1 {
+			candidates = append(candidates, paths)
+		}
+	}
+
+	progress.stage.Store(int32(StagePartial))
+	candidates, err = splitByHash(ctx, fsys, candidates, opts.Routines, progress, partialHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// files that fit in the first and last blocks were already hashed in full
+	var small, large [][]string
+	for _, paths := range candidates {
+		if size(fsys, paths[0]) <= 2*blockSize {
+			small = append(small, paths)
+		} else {
+			large = append(large, paths)
+		}
+	}
+	progress.stage.Store(int32(StageFull))
+	large, err = splitByHash(ctx, fsys, large, opts.Routines, progress, fullHash)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]Group, 0, len(small)+len(large))
+	for _, paths := range append(small, large...) {
+		sort.Strings(paths)
+		groups = append(groups, Group{Size: size(fsys, paths[0]), Paths: paths})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Reclaimable() != groups[j].Reclaimable() {
+			return groups[i].Reclaimable() > groups[j].Reclaimable()
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return groups, nil
+}
+
+// listFiles returns the paths of the regular files below root by their size
+func listFiles(ctx context.Context, fsys afero.Fs, root string, opts Options, progress *Progress) (map[int64][]string, error) {
+	entriesCh, errCh, err := entry.WalkDown(ctx, fsys, root, -1, opts.Routines, true)
+	if err != nil {
+		return nil, err
+	}
+	bySize := make(map[int64][]string)
+	seen := make(map[fileID]struct{})
+	for entriesCh != nil || errCh != nil {
+		select {
+		case e, ok := <-entriesCh:
+			if !ok {
+				entriesCh = nil
+				continue
+			}
+			if !e.Mode().IsRegular() || e.Size() < opts.MinSize {
+				continue
+			}
+			progress.files.Add(1)
+			if id, ok := idOf(e.FileInfo); ok {
+				if _, dup := seen[id]; dup {
+					continue // a hard link to a file that was already listed
+				}
+				seen[id] = struct{}{}
+			}
+			bySize[e.Size()] = append(bySize[e.Size()], e.Path())
+		case _, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return bySize, nil
+}
+
+type hashFunc func(fsys afero.Fs, path string, progress *Progress) ([]byte, error)
+
+// splitByHash hashes the files of each group concurrently and splits the groups into
+// files with the same hash. Groups that end up with a single file are dropped.
+func splitByHash(ctx context.Context, fsys afero.Fs, groups [][]string, routines int, progress *Progress, hash hashFunc) ([][]string, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(routines)
+	var mu sync.Mutex
+	var split [][]string
+	for _, paths := range groups {
+		paths := paths
+		g.Go(func() error {
+			byHash := make(map[string][]string, len(paths))
+			for _, path := range paths {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				sum, err := hash(fsys, path, progress)
+				if err != nil {
+					continue
+				}
+				byHash[string(sum)] = append(byHash[string(sum)], path)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, same := range byHash {
+				if len(same) > 1 {
+					split = append(split, same)
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return split, nil
+}
+
+// partialHash hashes the first and last blocks of the file
+func partialHash(fsys afero.Fs, path string, progress *Progress) ([]byte, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if info.Size() <= 2*blockSize {
+		n, err := io.Copy(h, f)
+		progress.hashed.Add(n)
+		if err != nil {
+			return nil, err
+		}
+		return h.Sum(nil), nil
+	}
+	buf := make([]byte, blockSize)
+	for _, offset := range []int64{0, info.Size() - blockSize} {
+		n, err := f.ReadAt(buf, offset)
+		progress.hashed.Add(int64(n))
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		h.Write(buf[:n])
+	}
+	return h.Sum(nil), nil
+}
+
+// fullHash hashes the whole content of the file
+func fullHash(fsys afero.Fs, path string, progress *Progress) ([]byte, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	progress.hashed.Add(n)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// size returns the size of the file at path
+func size(fsys afero.Fs, path string) int64 {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// Same reports whether the files at the two paths have the same content
+func Same(fsys afero.Fs, a, b string) (bool, error) {
+	if size(fsys, a) != size(fsys, b) {
+		return false, nil
+	}
+	var progress Progress
+	sumA, err := fullHash(fsys, a, &progress)
+	if err != nil {
+		return false, err
+	}
+	sumB, err := fullHash(fsys, b, &progress)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(sumA, sumB), nil
+}
@@ -0,0 +1,117 @@
+package dupes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func testFs() afero.Fs {
+	big := bytes.Repeat([]byte("0123456789"), 1000)
+	// same size, first and last blocks as big, but a different middle
+	bigMiddle := append([]byte{}, big...)
+	bigMiddle[5000] = 'x'
+
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", []byte("hello"), 0644)
+	afero.WriteFile(fsys, "/root/sub/a copy", []byte("hello"), 0644)
+	afero.WriteFile(fsys, "/root/b", []byte("world"), 0644) // same size as a
+	afero.WriteFile(fsys, "/root/big", big, 0644)
+	afero.WriteFile(fsys, "/root/sub/big copy", big, 0644)
+	afero.WriteFile(fsys, "/root/sub/deep/big copy", big, 0644)
+	afero.WriteFile(fsys, "/root/big middle", bigMiddle, 0644)
+	afero.WriteFile(fsys, "/root/empty", nil, 0644)
+	afero.WriteFile(fsys, "/root/empty2", nil, 0644)
+	return fsys
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+	progress := &Progress{}
+	groups, err := Find(context.Background(), testFs(), "/root", Options{Routines: 2}, progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Group{
+		{Size: 10000, Paths: []string{"/root/big", "/root/sub/big copy", "/root/sub/deep/big copy"}},
+		{Size: 5, Paths: []string{"/root/a", "/root/sub/a copy"}},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("expected %v, got %v", expected, groups)
+	}
+	if groups[0].Reclaimable() != 20000 {
+		t.Errorf("expected 20000 reclaimable bytes, got %d", groups[0].Reclaimable())
+	}
+	files, _ := progress.Counts()
+	if files != 7 {
+		t.Errorf("expected 7 files to be listed, got %d", files)
+	}
+	if progress.Stage() != StageFull {
+		t.Errorf("expected the search to end at the full hash stage, got %s", progress.Stage())
+	}
+}
+
+func TestFindMinSize(t *testing.T) {
+	t.Parallel()
+	groups, err := Find(context.Background(), testFs(), "/root", Options{MinSize: 100}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Size != 10000 {
+		t.Errorf("expected only the large files, got %v", groups)
+	}
+}
+
+func TestFindCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Find(ctx, testFs(), "/root", Options{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFindSkipsHardLinks(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a"), []byte("hello"), 0644)
+	if err := os.Link(filepath.Join(dir, "a"), filepath.Join(dir, "b")); err != nil {
+		t.Skip(err)
+	}
+	groups, err := Find(context.Background(), afero.NewOsFs(), dir, Options{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("expected hard links not to be reported as duplicates, got %v", groups)
+	}
+}
+
+func TestSame(t *testing.T) {
+	t.Parallel()
+	fsys := testFs()
+	tests := []struct {
+		a, b string
+		exp  bool
+	}{
+		{"/root/a", "/root/sub/a copy", true},
+		{"/root/a", "/root/b", false},
+		{"/root/big", "/root/big middle", false},
+	}
+	for _, test := range tests {
+		got, err := Same(fsys, test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.exp {
+			t.Errorf("Same(%s, %s) = %v; want %v", test.a, test.b, got, test.exp)
+		}
+	}
+}
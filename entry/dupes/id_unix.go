@@ -0,0 +1,21 @@
+//go:build !windows
+
+package dupes
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileID identifies a file on disk, so that hard links to the same file can be recognised
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func idOf(info fs.FileInfo) (fileID, bool) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+	}
+	return fileID{}, false
+}
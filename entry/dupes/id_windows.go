@@ -0,0 +1,13 @@
+//go:build windows
+
+package dupes
+
+import "io/fs"
+
+// fileID identifies a file on disk. The file index is not part of the stat information
+// on windows, so hard links are listed as separate files.
+type fileID struct{}
+
+func idOf(info fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
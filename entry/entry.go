@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -37,9 +38,38 @@ type Entry struct {
 	MimeType    string // expected mime type from file extension
 	SymlinkName string // name of the symlink
 	SymLinkPath string // path of the symlink
+	BrokenLink  bool   // whether the target of the symlink cannot be read
 	IsHidden    bool   // whether the file is hidden
 	SizeInt     int64  // either size in bytes or count of entries in directory
 	Entries     []Entry
+
+	birth *birthTime // creation time, read when it is first needed
+}
+
+// BirthTime returns the creation time, zero if the filesystem does not record it
+func (e Entry) BirthTime() time.Time {
+	if e.birth == nil {
+		return time.Time{}
+	}
+	return e.birth.get()
+}
+
+// birthTime reads the creation time of a file once. It takes a syscall on linux, which
+// is too slow to make for every entry of every directory that is read, so it is only
+// read for the entries whose creation time is shown or sorted by. The copies of an
+// entry share it.
+type birthTime struct {
+	once sync.Once
+	path string
+	info fs.FileInfo
+	time time.Time
+}
+
+func (b *birthTime) get() time.Time {
+	b.once.Do(func() {
+		b.time, _ = creationTime(b.path, b.info)
+	})
+	return b.time
 }
 
 // func handleSymlink(fsys afero.Fs, fullPath string, file fs.FileInfo) (string, fs.FileInfo, error) {
@@ -83,6 +113,26 @@ func getSize(fsys afero.Fs, file fs.FileInfo, filePath string) (string, int64, e
 	return size, int64(lenEntries), nil
 }
 
+// linkInfo is the file info of the target of a symlink under the name of the link
+type linkInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (l linkInfo) Name() string {
+	return l.name
+}
+
+// Lstat returns the file info of path without following a symlink, if the filesystem
+// supports it
+func Lstat(fsys afero.Fs, path string) (fs.FileInfo, error) {
+	if lstater, ok := fsys.(afero.Lstater); ok {
+		info, _, err := lstater.LstatIfPossible(path)
+		return info, err
+	}
+	return fsys.Stat(path)
+}
+
 func createEntry(fsys afero.Fs, dirPath string, file fs.FileInfo) (Entry, error) {
 	var symLinkName string
 	var symLinkPath string
@@ -102,13 +152,25 @@ func createEntry(fsys afero.Fs, dirPath string, file fs.FileInfo) (Entry, error)
 		if err != nil {
 			return Entry{FileInfo: file}, err
 		}
-		symInfo, err := fsys.Stat(linkedPath)
-		if err != nil {
-			return Entry{FileInfo: file}, err
+		// a relative target is relative to the directory of the link, not to the working directory
+		if !filepath.IsAbs(linkedPath) {
+			linkedPath = filepath.Join(dirPath, linkedPath)
 		}
 		symLinkName = file.Name()
 		symLinkPath = linkedPath
-		file = symInfo
+		symInfo, err := fsys.Stat(linkedPath)
+		if err != nil {
+			return Entry{
+				FileInfo:    file,
+				SizeStr:     "broken link",
+				ModifyTime:  humanize.Time(file.ModTime()),
+				SymlinkName: symLinkName,
+				SymLinkPath: symLinkPath,
+				BrokenLink:  true,
+				IsHidden:    hidden,
+			}, nil
+		}
+		file = linkInfo{FileInfo: symInfo, name: file.Name()}
 		fullPath = linkedPath
 	}
 	sizeStr, sizeInt, err := getSize(fsys, file, fullPath)
@@ -124,11 +186,25 @@ func createEntry(fsys afero.Fs, dirPath string, file fs.FileInfo) (Entry, error)
 		SymlinkName: symLinkName,
 		SymLinkPath: symLinkPath,
 		IsHidden:    hidden,
+		birth:       &birthTime{path: fullPath, info: file},
 	}
 	return entry, err
 }
 
 func GetEntries(fsys afero.Fs, dirPath string, showHidden bool, dirsMixed bool) ([]Entry, map[string]error, error) {
+	spec := DefaultSortSpec()
+	spec.DirsFirst = !dirsMixed
+	return GetSortedEntries(fsys, dirPath, showHidden, spec)
+}
+
+// GetSortedEntries reads the entries of the directory and sorts them according to spec.
+func GetSortedEntries(fsys afero.Fs, dirPath string, showHidden bool, spec SortSpec) ([]Entry, map[string]error, error) {
+	return GetFilteredEntries(fsys, dirPath, showHidden, spec, Filter{})
+}
+
+// GetFilteredEntries reads the entries of the directory, sorts them according to spec and
+// leaves out the entries that do not pass the filter.
+func GetFilteredEntries(fsys afero.Fs, dirPath string, showHidden bool, spec SortSpec, filter Filter) ([]Entry, map[string]error, error) {
 	files, err := afero.ReadDir(fsys, dirPath)
 	if err != nil {
 		return nil, nil, err
@@ -153,17 +229,10 @@ func GetEntries(fsys afero.Fs, dirPath string, showHidden bool, dirsMixed bool)
 	}
 	group.Wait()
 
-	sortT := SortOrder{
-		method:     NaturalSort,
-		dirsFirst:  !dirsMixed,
-		dirsOnly:   false,
-		showHidden: showHidden,
-		reverse:    false,
-		ignoreDiac: true,
-		ignoreCase: true,
-	}
-
-	entries = sortEntries(dirPath, entries, sortT)
+	order := spec.order(showHidden)
+	order.dirsOnly = filter.DirsOnly
+	order.patterns = filter.Patterns
+	entries = sortEntries(dirPath, entries, order)
 	return entries, errMap, nil
 }
 
@@ -191,7 +260,7 @@ func CheckForChanges(fsys afero.Fs, dir Dir) (Dir, map[string]error, error) {
 	errMap := make(map[string]error, len(dir.Entries))
 	for _, entry := range dir.Entries {
 		fullPath := filepath.Join(dir.Path, entry.Name())
-		file, err := fsys.Stat(fullPath)
+		file, err := Lstat(fsys, fullPath)
 		if errors.Is(err, fs.ErrNotExist) {
 			continue
 		}
@@ -199,7 +268,8 @@ func CheckForChanges(fsys afero.Fs, dir Dir) (Dir, map[string]error, error) {
 			errMap[entry.Name()] = err
 			continue
 		}
-		if entry.ModTime() != file.ModTime() {
+		// the target of a symlink can change without the link changing
+		if entry.SymlinkName != "" || entry.ModTime() != file.ModTime() {
 			entry, err := createEntry(fsys, dir.Path, file)
 			if err != nil {
 				errMap[file.Name()] = err
@@ -1,6 +1,9 @@
 package entry
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -68,3 +71,71 @@ func TestWithAfero(t *testing.T) {
 // 	}
 // 	t.Error()
 // }
+
+func TestSymlinkEntries(t *testing.T) {
+	dir := t.TempDir()
+	fsys := afero.NewOsFs()
+	fsys.Mkdir(filepath.Join(dir, "sub"), 0755)
+	afero.WriteFile(fsys, filepath.Join(dir, "sub", "file"), []byte("content"), 0644)
+	// the relative target is relative to the directory of the link, not to the working directory
+	if err := os.Symlink(filepath.Join("sub", "file"), filepath.Join(dir, "relative")); err != nil {
+		t.Skip(err)
+	}
+	os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "broken"))
+
+	entries, _, err := GetEntries(fsys, dir, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		byName[e.Name()] = e
+	}
+
+	relative, ok := byName["relative"]
+	if !ok {
+		t.Fatalf("expected the link to keep its own name, got %v", byName)
+	}
+	if relative.BrokenLink || relative.Size() != int64(len("content")) {
+		t.Errorf("expected the link to show its target, got %+v", relative)
+	}
+	if relative.SymLinkPath != filepath.Join(dir, "sub", "file") {
+		t.Errorf("expected the target to be resolved from the link, got %s", relative.SymLinkPath)
+	}
+
+	broken, ok := byName["broken"]
+	if !ok || !broken.BrokenLink || broken.SymlinkName != "broken" {
+		t.Errorf("expected the broken link to be listed as broken, got %+v", broken)
+	}
+}
+
+func TestFilteredEntries(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	fsys.MkdirAll("src/dir", 0755)
+	for _, name := range []string{"a.PDF", "b.png", "c.txt", "Makefile"} {
+		afero.WriteFile(fsys, filepath.Join("src", name), []byte("x"), 0644)
+	}
+	tests := []struct {
+		filter Filter
+		want   []string
+	}{
+		{Filter{}, []string{"dir", "a.PDF", "b.png", "c.txt", "Makefile"}},
+		{Filter{DirsOnly: true}, []string{"dir"}},
+		{Filter{Patterns: []string{".pdf", "image/*"}}, []string{"dir", "a.PDF", "b.png"}},
+		{Filter{Patterns: []string{"make*"}}, []string{"dir", "Makefile"}},
+		{Filter{DirsOnly: true, Patterns: []string{".pdf"}}, []string{"dir"}},
+	}
+	for _, tt := range tests {
+		entries, _, err := GetFilteredEntries(fsys, "src", true, DefaultSortSpec(), tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(entries))
+		for i, e := range entries {
+			got[i] = e.Name()
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package fileutils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// ErrLinkUnsupported is returned when hard links are requested on a filesystem that
+// does not support them
+var ErrLinkUnsupported = errors.New("hard links are not supported on this filesystem")
+
+// ErrSymlinkUnsupported is returned when symbolic links are requested on a filesystem that
+// does not support them
+var ErrSymlinkUnsupported = errors.New("symbolic links are not supported on this filesystem")
+
+// Link creates newname as a hard link to oldname.
+// Only the os filesystem supports hard links.
+func Link(fsys afero.Fs, oldname, newname string) error {
+	if _, ok := fsys.(*afero.OsFs); !ok {
+		return ErrLinkUnsupported
+	}
+	return os.Link(oldname, newname)
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+// The filesystem must implement afero.Linker.
+func Symlink(fsys afero.Fs, oldname, newname string) error {
+	linker, ok := fsys.(afero.Linker)
+	if !ok {
+		return ErrSymlinkUnsupported
+	}
+	return linker.SymlinkIfPossible(oldname, newname)
+}
+
+// RelativeTarget returns target relative to the directory of a link at linkPath. Both paths
+// must be absolute. A relative target is followed from the real directory of the link, so
+// on the os filesystem symlinks in the directories of both paths are resolved first.
+func RelativeTarget(fsys afero.Fs, target, linkPath string) (string, error) {
+	linkDir := filepath.Dir(filepath.Clean(linkPath))
+	targetDir, targetName := filepath.Split(filepath.Clean(target))
+	if _, ok := fsys.(*afero.OsFs); ok {
+		if real, err := filepath.EvalSymlinks(linkDir); err == nil {
+			linkDir = real
+		}
+		if real, err := filepath.EvalSymlinks(targetDir); err == nil {
+			targetDir = real
+		}
+	}
+	return filepath.Rel(linkDir, filepath.Join(targetDir, targetName))
+}
+
+// ReplaceWithLink replaces the file at path with a hard link to target. The link is
+// created next to path and then renamed over it, so path is never missing.
+func ReplaceWithLink(fsys afero.Fs, target, path string) error {
+	dir, name := filepath.Split(path)
+	for i := 0; i < 100; i++ {
+		tmp := filepath.Join(dir, fmt.Sprintf(".%s.fman-link-%d", name, i))
+		err := Link(fsys, target, tmp)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fsys.Rename(tmp, path); err != nil {
+			fsys.Remove(tmp)
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("could not create a temporary link next to %s", path)
+}
@@ -0,0 +1,98 @@
+package fileutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestReplaceWithLink(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	fsys := afero.NewOsFs()
+	target := filepath.Join(dir, "a")
+	path := filepath.Join(dir, "b")
+	afero.WriteFile(fsys, target, []byte("same"), 0644)
+	afero.WriteFile(fsys, path, []byte("same"), 0644)
+
+	if err := ReplaceWithLink(fsys, target, path); err != nil {
+		t.Fatal(err)
+	}
+	targetInfo, _ := os.Stat(target)
+	pathInfo, _ := os.Stat(path)
+	if !os.SameFile(targetInfo, pathInfo) {
+		t.Error("expected the path to be a hard link to the target")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected the temporary link to be renamed, got %d entries", len(entries))
+	}
+}
+
+func TestLinkUnsupported(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/a", []byte("a"), 0644)
+	if err := Link(fsys, "/a", "/b"); !errors.Is(err, ErrLinkUnsupported) {
+		t.Errorf("expected ErrLinkUnsupported, got %v", err)
+	}
+}
+
+func TestSymlinkUnsupported(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	if err := Symlink(fsys, "/a", "/b"); !errors.Is(err, ErrSymlinkUnsupported) {
+		t.Errorf("expected ErrSymlinkUnsupported, got %v", err)
+	}
+}
+
+func TestRelativeTarget(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	tests := []struct {
+		target, link, exp string
+	}{
+		{"/a/b/file", "/a/b/link", "file"},
+		{"/a/b/file", "/a/c/link", "../b/file"},
+		{"/a/file", "/a/b/c/link", "../../file"},
+		{"/a/b/dir/", "/link", "a/b/dir"},
+	}
+	for _, test := range tests {
+		target, link := filepath.FromSlash(test.target), filepath.FromSlash(test.link)
+		got, err := RelativeTarget(fsys, target, link)
+		if err != nil {
+			t.Errorf("%s from %s: %v", test.target, test.link, err)
+			continue
+		}
+		if got != filepath.FromSlash(test.exp) {
+			t.Errorf("%s from %s: expected %s, got %s", test.target, test.link, test.exp, got)
+		}
+	}
+}
+
+// A relative target is followed from the real directory of the link, which differs from
+// the lexical one when the link is created through a symlinked directory
+func TestRelativeTargetThroughSymlink(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	fsys := afero.NewOsFs()
+	fsys.MkdirAll(filepath.Join(dir, "real", "sub"), 0755)
+	afero.WriteFile(fsys, filepath.Join(dir, "file"), []byte("file"), 0644)
+	if err := Symlink(fsys, filepath.Join(dir, "real", "sub"), filepath.Join(dir, "alias")); err != nil {
+		t.Skip(err)
+	}
+	link := filepath.Join(dir, "alias", "link")
+	target, err := RelativeTarget(fsys, filepath.Join(dir, "file"), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Symlink(fsys, target, link); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := afero.ReadFile(fsys, link); err != nil || string(content) != "file" {
+		t.Errorf("expected the link to reach the file through %s, got %q, %v", target, content, err)
+	}
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Stage stages the changes to the named entries of the directory dir, including
+// untracked and deleted entries
+func Stage(ctx context.Context, dir string, names []string) error {
+	return change(ctx, dir, names, "add", "--all")
+}
+
+// Unstage unstages the changes to the named entries of the directory dir, keeping the
+// changes in the work tree
+func Unstage(ctx context.Context, dir string, names []string) error {
+	return change(ctx, dir, names, "reset", "--quiet")
+}
+
+// Restore discards the changes to the named entries of the directory dir that are not
+// staged. Untracked entries are not touched.
+func Restore(ctx context.Context, dir string, names []string) error {
+	return change(ctx, dir, names, "restore")
+}
+
+// Commit commits the staged changes of the work tree of the directory dir with the message
+// and returns the abbreviated hash of the new commit
+func Commit(ctx context.Context, dir string, message string) (string, error) {
+	out, err := run(ctx, dir, strings.NewReader(message), "commit", "--quiet", "--cleanup=strip", "--file=-")
+	if err != nil {
+		return "", commandError(out, err)
+	}
+	hash, err := run(ctx, dir, nil, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", commandError(hash, err)
+	}
+	return strings.TrimSpace(string(hash)), nil
+}
+
+// change runs a git command on the named entries of the directory dir. The names are
+// taken literally, so names such as :foo or *.go are not read as pathspec magic or globs.
+func change(ctx context.Context, dir string, names []string, args ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	args = append([]string{"--literal-pathspecs"}, args...)
+	args = append(args, "--")
+	args = append(args, names...)
+	out, err := run(ctx, dir, nil, args...)
+	return commandError(out, err)
+}
+
+// commandError returns err with the reason git gave, which is the last line git printed
+// to stderr or, for commands such as commit that explain on stdout, to stdout
+func commandError(stdout []byte, err error) error {
+	var exitErr *exec.ExitError
+	if err == nil || !errors.As(err, &exitErr) {
+		return err
+	}
+	for _, out := range [][]byte{exitErr.Stderr, stdout} {
+		if out = bytes.TrimSpace(out); len(out) > 0 {
+			lines := bytes.Split(out, []byte("\n"))
+			return errors.New(strings.TrimSpace(string(lines[len(lines)-1])))
+		}
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStageUnstageRestore(t *testing.T) {
+	dir := testRepo(t)
+	ctx := context.Background()
+	sub := filepath.Join(dir, "sub")
+	writeFile(t, dir, "sub/tracked", "changed")
+	writeFile(t, dir, "sub/:magic", "new")
+	if err := os.Remove(filepath.Join(dir, "clean")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Stage(ctx, sub, []string{"tracked", ":magic"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Stage(ctx, dir, []string{"clean"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testGit(t, dir, "diff", "--cached", "--name-status"); got != "D\tclean\nA\tsub/:magic\nM\tsub/tracked\n" {
+		t.Errorf("staged:\n%s", got)
+	}
+
+	if err := Unstage(ctx, sub, []string{"tracked"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testGit(t, dir, "diff", "--cached", "--name-only"); got != "clean\nsub/:magic\n" {
+		t.Errorf("staged after unstaging tracked:\n%s", got)
+	}
+
+	if err := Restore(ctx, sub, []string{"tracked"}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(sub, "tracked"))
+	if err != nil || string(content) != "a" {
+		t.Errorf("got %q, %v after restoring, want the committed content", content, err)
+	}
+
+	err = Restore(ctx, sub, []string{"untracked"})
+	if err == nil || !strings.Contains(err.Error(), "did not match") {
+		t.Errorf("got %v restoring an unknown entry, want the reason given by git", err)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	dir := testRepo(t)
+	ctx := context.Background()
+	if _, err := Commit(ctx, dir, "nothing staged"); err == nil || !strings.Contains(err.Error(), "commit") {
+		t.Errorf("got %v committing without staged changes, want the reason given by git", err)
+	}
+
+	writeFile(t, dir, "clean", "changed")
+	if err := Stage(ctx, dir, []string{"clean"}); err != nil {
+		t.Fatal(err)
+	}
+	message := "Change clean\n\nThe body\nspans lines\n"
+	hash, err := Commit(ctx, dir, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(testGit(t, dir, "rev-parse", "--short", "HEAD")); got != hash {
+		t.Errorf("got hash %q, want %q", hash, got)
+	}
+	if got := testGit(t, dir, "log", "-1", "--format=%B"); strings.TrimSpace(got) != strings.TrimSpace(message) {
+		t.Errorf("got message %q, want %q", got, message)
+	}
+}
@@ -0,0 +1,230 @@
+// Package git reads the status of the entries of a directory inside a git work tree and
+// the branch it is on. It runs the git command, so git has to be installed.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotRepository is returned when a directory is not inside a git work tree
+var ErrNotRepository = errors.New("not inside a git work tree")
+
+// Status is a set of states of an entry. The status of a directory combines the states
+// of the entries below it, apart from ignored entries.
+type Status uint8
+
+const (
+	Modified   Status = 1 << iota // changed in the work tree and not staged
+	Staged                        // changes are staged
+	Untracked                     // not tracked and not ignored
+	Ignored                       // ignored by a .gitignore
+	Conflicted                    // unmerged after a merge or rebase
+)
+
+// statusMarkers are the markers of the states in the order they are shown
+var statusMarkers = []struct {
+	status Status
+	marker string
+}{
+	{Conflicted, "U"},
+	{Staged, "+"},
+	{Modified, "M"},
+	{Untracked, "?"},
+	{Ignored, "!"},
+}
+
+// String returns the markers of the states, such as +M for staged and modified
+func (s Status) String() string {
+	var b strings.Builder
+	for _, m := range statusMarkers {
+		if s&m.status != 0 {
+			b.WriteString(m.marker)
+		}
+	}
+	return b.String()
+}
+
+// Primary returns the state that matters most, which decides the color of the entry.
+// Changes that are not staged yet matter more than staged changes.
+func (s Status) Primary() Status {
+	for _, status := range []Status{Conflicted, Modified, Staged, Untracked, Ignored} {
+		if s&status != 0 {
+			return status
+		}
+	}
+	return 0
+}
+
+// Repo is the status of a directory inside a git work tree
+type Repo struct {
+	Branch   string // name of the branch, or the abbreviated commit if Detached
+	Detached bool
+	Upstream bool // the branch has an upstream, so Ahead and Behind are known
+	Ahead    int  // commits on the branch that are not on the upstream
+	Behind   int  // commits on the upstream that are not on the branch
+
+	// Entries holds the status of the entries of the directory by name. Entries
+	// without changes are left out.
+	Entries map[string]Status
+}
+
+// timeout limits how long git may take on very large work trees
+const timeout = 30 * time.Second
+
+// Read returns the status of the entries of the directory dir. ErrNotRepository is
+// returned if dir is not inside a git work tree or git is not installed.
+func Read(ctx context.Context, dir string) (*Repo, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// git prints the paths relative to the top of the work tree
+	prefix, err := showPrefix(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, dir, nil, "status", "--porcelain=v2", "--branch", "-z", "--ignored", "--untracked-files=normal", "--", ".")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && bytes.Contains(exitErr.Stderr, []byte("work tree")) {
+			// inside the .git directory
+			return nil, ErrNotRepository
+		}
+		return nil, err
+	}
+	return parse(out, prefix), nil
+}
+
+// showPrefix returns the path of the directory dir relative to the top of the work tree,
+// ending with a slash unless dir is the top. ErrNotRepository is returned if dir is not
+// inside a git work tree or git is not installed.
+func showPrefix(ctx context.Context, dir string) (string, error) {
+	prefix, err := run(ctx, dir, nil, "rev-parse", "--show-prefix")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.Is(err, exec.ErrNotFound) || errors.As(err, &exitErr) {
+			return "", ErrNotRepository
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(prefix)), nil
+}
+
+// run runs git in dir with stdin as its input and returns its output
+func run(ctx context.Context, dir string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	// a status in the background must not take the index lock from commands of the user
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0", "LC_ALL=C")
+	cmd.WaitDelay = time.Second
+	return cmd.Output()
+}
+
+// parse parses the output of git status --porcelain=v2 --branch -z. prefix is the path
+// of the directory relative to the top of the work tree, ending with a slash.
+func parse(out []byte, prefix string) *Repo {
+	repo := &Repo{Entries: make(map[string]Status)}
+	var oid string
+	records := strings.Split(string(out), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 2 {
+			continue
+		}
+		var path string
+		var status Status
+		switch record[0] {
+		case '#':
+			fields := strings.Fields(record)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.oid":
+				oid = fields[2]
+			case "branch.head":
+				repo.Branch = fields[2]
+			case "branch.ab":
+				if len(fields) == 4 {
+					repo.Upstream = true
+					repo.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					repo.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			}
+			continue
+		case '1':
+			fields := strings.SplitN(record, " ", 9)
+			if len(fields) < 9 {
+				continue
+			}
+			status, path = changeStatus(fields[1]), fields[8]
+		case '2':
+			fields := strings.SplitN(record, " ", 10)
+			if len(fields) < 10 {
+				continue
+			}
+			status, path = changeStatus(fields[1]), fields[9]
+			i++ // the next record is the path the entry was renamed from
+		case 'u':
+			fields := strings.SplitN(record, " ", 11)
+			if len(fields) < 11 {
+				continue
+			}
+			status, path = Conflicted, fields[10]
+		case '?':
+			status, path = Untracked, record[2:]
+		case '!':
+			status, path = Ignored, record[2:]
+		default:
+			continue
+		}
+		repo.add(strings.TrimPrefix(path, prefix), status)
+	}
+	if repo.Branch == "(detached)" {
+		repo.Detached = true
+		repo.Branch = oid
+		if len(oid) > 7 {
+			repo.Branch = oid[:7]
+		}
+	}
+	return repo
+}
+
+// changeStatus returns the status of an entry with the XY code of a changed entry
+func changeStatus(xy string) Status {
+	var status Status
+	if len(xy) != 2 {
+		return status
+	}
+	if xy[0] != '.' {
+		status |= Staged
+	}
+	if xy[1] != '.' {
+		status |= Modified
+	}
+	return status
+}
+
+// add adds the status of the path, relative to the directory, to the entry it is in.
+// Untracked and ignored directories are listed by git with a trailing slash.
+func (repo *Repo) add(path string, status Status) {
+	name, rest, nested := strings.Cut(path, "/")
+	if name == "" || name == ".." {
+		return
+	}
+	if nested && rest != "" {
+		// a directory is not ignored because entries below it are
+		status &^= Ignored
+	}
+	if status != 0 {
+		repo.Entries[name] |= status
+	}
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatusString(t *testing.T) {
+	tests := []struct {
+		status  Status
+		want    string
+		primary Status
+	}{
+		{0, "", 0},
+		{Modified, "M", Modified},
+		{Staged | Modified, "+M", Modified},
+		{Staged | Untracked, "+?", Staged},
+		{Untracked | Modified, "M?", Modified},
+		{Ignored, "!", Ignored},
+		{Conflicted | Staged, "U+", Conflicted},
+	}
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("%08b: String() = %q, want %q", tt.status, got, tt.want)
+		}
+		if got := tt.status.Primary(); got != tt.primary {
+			t.Errorf("%08b: Primary() = %08b, want %08b", tt.status, got, tt.primary)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	records := []string{
+		"# branch.oid 7fc2d05f748db1e7a3392b665a4e5b0ec9d2b9e2",
+		"# branch.head main",
+		"# branch.upstream origin/main",
+		"# branch.ab +2 -1",
+		"1 .M N... 100644 100644 100644 587be6b 587be6b sub/a",
+		"1 A. N... 000000 100644 100644 0000000 b478595 sub/staged",
+		"1 MM N... 100644 100644 100644 587be6b 587be6b sub/both file",
+		"2 R. N... 100644 100644 100644 587be6b 587be6b R100 sub/new name",
+		"sub/old name",
+		"u UU N... 100644 100644 100644 100644 aaaaaaa bbbbbbb ccccccc sub/conflict",
+		"? sub/deep/",
+		"? sub/deep2/untracked",
+		"! sub/x.log",
+		"! sub/cache/",
+		"! sub/build/out.log",
+		"1 .M N... 100644 100644 100644 587be6b 587be6b sub/nested/changed",
+		"",
+	}
+	repo := parse([]byte(strings.Join(records, "\x00")), "sub/")
+	if repo.Branch != "main" || repo.Detached || !repo.Upstream || repo.Ahead != 2 || repo.Behind != 1 {
+		t.Errorf("got branch %q detached %v upstream %v +%d -%d", repo.Branch, repo.Detached, repo.Upstream, repo.Ahead, repo.Behind)
+	}
+	want := map[string]Status{
+		"a":         Modified,
+		"staged":    Staged,
+		"both file": Staged | Modified,
+		"new name":  Staged,
+		"conflict":  Conflicted,
+		"deep":      Untracked,
+		"deep2":     Untracked,
+		"x.log":     Ignored,
+		"cache":     Ignored,
+		"nested":    Modified,
+	}
+	if len(repo.Entries) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(repo.Entries), len(want), repo.Entries)
+	}
+	for name, status := range want {
+		if got := repo.Entries[name]; got != status {
+			t.Errorf("%s: got %q, want %q", name, got, status)
+		}
+	}
+	if _, ok := repo.Entries["old name"]; ok {
+		t.Error("the old name of a renamed entry should be skipped")
+	}
+	if _, ok := repo.Entries["build"]; ok {
+		t.Error("a directory should not be ignored because of an ignored entry below it")
+	}
+}
+
+func TestParseDetached(t *testing.T) {
+	out := "# branch.oid 7fc2d05f748db1e7a3392b665a4e5b0ec9d2b9e2\x00# branch.head (detached)\x00"
+	repo := parse([]byte(out), "")
+	if !repo.Detached || repo.Branch != "7fc2d05" || repo.Upstream {
+		t.Errorf("got branch %q detached %v upstream %v", repo.Branch, repo.Detached, repo.Upstream)
+	}
+}
+
+// testRepo creates a repository with a commit on the branch work. It skips the test if
+// git is not installed.
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	testGit(t, dir, "init", "-q", "-b", "work")
+	testGit(t, dir, "config", "user.email", "fman@example.com")
+	testGit(t, dir, "config", "user.name", "fman")
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	writeFile(t, dir, "sub/tracked", "a")
+	writeFile(t, dir, "clean", "a")
+	testGit(t, dir, "add", ".")
+	testGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func testGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	if _, err := Read(context.Background(), t.TempDir()); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("got %v outside of a repository, want ErrNotRepository", err)
+	}
+
+	dir := testRepo(t)
+	writeFile(t, dir, "sub/tracked", "b")
+	writeFile(t, dir, "sub/new", "c")
+	writeFile(t, dir, "sub/debug.log", "d")
+	writeFile(t, dir, "staged", "e")
+	testGit(t, dir, "add", "staged")
+
+	repo, err := Read(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Branch != "work" || repo.Upstream {
+		t.Errorf("got branch %q upstream %v, want work without an upstream", repo.Branch, repo.Upstream)
+	}
+	want := map[string]Status{"sub": Modified | Untracked, "staged": Staged}
+	if len(repo.Entries) != len(want) {
+		t.Errorf("got %v, want %v", repo.Entries, want)
+	}
+	for name, status := range want {
+		if got := repo.Entries[name]; got != status {
+			t.Errorf("%s: got %q, want %q", name, got, status)
+		}
+	}
+
+	repo, err = Read(context.Background(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]Status{"tracked": Modified, "new": Untracked, "debug.log": Ignored}
+	if len(repo.Entries) != len(want) {
+		t.Errorf("got %v, want %v", repo.Entries, want)
+	}
+	for name, status := range want {
+		if got := repo.Entries[name]; got != status {
+			t.Errorf("sub/%s: got %q, want %q", name, got, status)
+		}
+	}
+
+	if _, err := Read(context.Background(), filepath.Join(dir, ".git")); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("got %v inside the .git directory, want ErrNotRepository", err)
+	}
+}
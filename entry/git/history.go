@@ -0,0 +1,143 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Revision is a commit that changed a file
+type Revision struct {
+	Hash    string
+	Author  string
+	Time    time.Time
+	Subject string
+	Path    string // path of the file in the commit, relative to the top of the work tree
+}
+
+// ShortHash returns the hash abbreviated to 7 characters
+func (r Revision) ShortHash() string {
+	if len(r.Hash) > 7 {
+		return r.Hash[:7]
+	}
+	return r.Hash
+}
+
+// Log returns up to max of the commits that changed the file at path, newest first.
+// Renames of the file are followed.
+func Log(ctx context.Context, path string, max int) ([]Revision, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	dir, name := filepath.Split(path)
+	prefix, err := showPrefix(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, dir, nil,
+		"-c", "core.quotePath=false", "--literal-pathspecs",
+		"log", "--follow", "--name-only", "--no-color", "-n", strconv.Itoa(max),
+		"--format=%x1e%H%x1f%an%x1f%at%x1f%s", "--", name,
+	)
+	if err != nil {
+		return nil, commandError(out, err)
+	}
+	return parseLog(out, prefix+name), nil
+}
+
+// parseLog parses the output of git log --name-only with the format of Log. path is the
+// path of the file relative to the top of the work tree, which is used for commits that
+// do not list the file, such as merges.
+func parseLog(out []byte, path string) []Revision {
+	records := bytes.Split(out, []byte{0x1e})
+	revisions := make([]Revision, 0, len(records))
+	for _, record := range records {
+		header, files, _ := strings.Cut(string(record), "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		r := Revision{Hash: fields[0], Author: fields[1], Subject: fields[3], Path: path}
+		if sec, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			r.Time = time.Unix(sec, 0)
+		}
+		for _, line := range strings.Split(files, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				r.Path = line
+			}
+		}
+		// older revisions name the file as it was before the renames
+		path = r.Path
+		revisions = append(revisions, r)
+	}
+	return revisions
+}
+
+// Show returns up to maxBytes of the content of the file at the revision. dir is any
+// directory of the work tree.
+func Show(ctx context.Context, dir string, r Revision, maxBytes int) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return runLimited(ctx, dir, maxBytes, "cat-file", "blob", r.Hash+":"+r.Path)
+}
+
+// Diff returns up to maxBytes of the changes to the file at path in the work tree
+// against HEAD. It is empty if the file is unchanged or not tracked. ErrNotRepository is
+// returned if the file is not inside a git work tree.
+func Diff(ctx context.Context, path string, maxBytes int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	dir, name := filepath.Split(path)
+	if _, err := showPrefix(ctx, dir); err != nil {
+		return "", err
+	}
+	out, err := runLimited(ctx, dir, maxBytes, "--literal-pathspecs", "diff", "--no-color", "--no-ext-diff", "HEAD", "--", name)
+	return string(out), err
+}
+
+// runLimited runs git in dir and returns up to maxBytes of its output. The rest of the
+// output is discarded.
+func runLimited(ctx context.Context, dir string, maxBytes int, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0", "LC_ALL=C")
+	cmd.WaitDelay = time.Second
+	stdout := &limitedBuffer{limit: maxBytes}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, ErrNotRepository
+		}
+		if errors.As(err, &exitErr) {
+			exitErr.Stderr = stderr.Bytes()
+		}
+		return nil, commandError(stdout.buf.Bytes(), err)
+	}
+	return stdout.buf.Bytes(), nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it. The buffer is not embedded, as
+// its ReadFrom would let io.Copy bypass the limit.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLog(t *testing.T) {
+	out := "\x1eaaaaaaaaaa\x1fann\x1f1700000000\x1frename\n\nsub/new\n" +
+		"\x1ebbbbbbbbbb\x1fbob\x1f1600000000\x1fmerge\n" +
+		"\x1ecccccccccc\x1fcid\x1fbad\x1fcreate\n\nsub/old\n"
+	revisions := parseLog([]byte(out), "sub/new")
+	want := []Revision{
+		{Hash: "aaaaaaaaaa", Author: "ann", Subject: "rename", Path: "sub/new"},
+		{Hash: "bbbbbbbbbb", Author: "bob", Subject: "merge", Path: "sub/new"},
+		{Hash: "cccccccccc", Author: "cid", Subject: "create", Path: "sub/old"},
+	}
+	if len(revisions) != len(want) {
+		t.Fatalf("got %d revisions, want %d: %v", len(revisions), len(want), revisions)
+	}
+	for i, r := range revisions {
+		w := want[i]
+		if r.Hash != w.Hash || r.Author != w.Author || r.Subject != w.Subject || r.Path != w.Path {
+			t.Errorf("%d: got %+v, want %+v", i, r, w)
+		}
+	}
+	if revisions[0].Time.Unix() != 1700000000 || !revisions[2].Time.IsZero() {
+		t.Errorf("got times %v and %v", revisions[0].Time, revisions[2].Time)
+	}
+	if got := revisions[0].ShortHash(); got != "aaaaaaa" {
+		t.Errorf("ShortHash() = %q, want aaaaaaa", got)
+	}
+}
+
+func TestLogShowDiff(t *testing.T) {
+	if _, err := Log(context.Background(), filepath.Join(t.TempDir(), "file"), 10); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("got %v outside of a repository, want ErrNotRepository", err)
+	}
+	if _, err := Diff(context.Background(), filepath.Join(t.TempDir(), "file"), 10); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("got %v outside of a repository, want ErrNotRepository", err)
+	}
+
+	dir := testRepo(t)
+	testGit(t, dir, "mv", "sub/tracked", "sub/moved")
+	testGit(t, dir, "commit", "-q", "-m", "move")
+	writeFile(t, dir, "sub/moved", "a\nb\n")
+	testGit(t, dir, "commit", "-q", "-am", "second line")
+
+	path := filepath.Join(dir, "sub", "moved")
+	revisions, err := Log(context.Background(), path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subjects := make([]string, len(revisions))
+	for i, r := range revisions {
+		subjects[i] = r.Subject
+	}
+	if got := strings.Join(subjects, ","); got != "second line,move,initial" {
+		t.Fatalf("got subjects %s", got)
+	}
+	if revisions[2].Path != "sub/tracked" {
+		t.Errorf("got path %q before the rename, want sub/tracked", revisions[2].Path)
+	}
+
+	content, err := Show(context.Background(), filepath.Join(dir, "sub"), revisions[2], 100)
+	if err != nil || string(content) != "a" {
+		t.Errorf("got %q, %v for the initial revision, want a", content, err)
+	}
+	content, err = Show(context.Background(), dir, revisions[0], 3)
+	if err != nil || string(content) != "a\nb" {
+		t.Errorf("got %q, %v for the last revision limited to 3 bytes", content, err)
+	}
+
+	diff, err := Diff(context.Background(), path, 1000)
+	if err != nil || diff != "" {
+		t.Errorf("got %q, %v for an unchanged file, want no diff", diff, err)
+	}
+	writeFile(t, dir, "sub/moved", "a\nc\n")
+	diff, err = Diff(context.Background(), path, 1000)
+	if err != nil || !strings.Contains(diff, "-b\n+c\n") {
+		t.Errorf("got %q, %v for a changed file", diff, err)
+	}
+}
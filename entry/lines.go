@@ -0,0 +1,198 @@
+package entry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// lineChunkSize is the number of bytes read at a time when indexing a file
+const lineChunkSize = 64 * 1024
+
+// LineFile gives random access to the lines of a file. The file is never
+// read as a whole. Instead, the offsets of the lines are indexed in chunks
+// as lines further into the file are requested. It is safe for concurrent use.
+type LineFile struct {
+	mu      sync.Mutex
+	file    afero.File
+	offsets []int64 // offset of the start of each line found so far
+	indexed int64   // number of bytes of the file that have been indexed
+	eof     bool    // true once the whole file has been indexed
+}
+
+// OpenLineFile opens the file at path for reading lines.
+func OpenLineFile(fsys afero.Fs, path string) (*LineFile, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &LineFile{file: file, offsets: []int64{0}}, nil
+}
+
+// Close closes the underlying file.
+func (f *LineFile) Close() error {
+	return f.file.Close()
+}
+
+// LineCount returns the number of lines indexed so far and
+// whether that is all of the lines in the file.
+func (f *LineFile) LineCount() (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lineCount(), f.eof
+}
+
+// CountLines indexes the whole file and returns the number of lines. The file is only
+// locked while a chunk is indexed, so lines can be read while it counts. It stops early
+// with the error of ctx if ctx is done.
+func (f *LineFile) CountLines(ctx context.Context) (int, error) {
+	return f.IndexLines(ctx, -1)
+}
+
+// IndexLines indexes the file until line n is known to end, or the whole file if n is
+// negative, like CountLines. It returns the number of lines indexed.
+func (f *LineFile) IndexLines(ctx context.Context, n int) (int, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			count, _ := f.LineCount()
+			return count, err
+		}
+		f.mu.Lock()
+		if f.eof || (n >= 0 && len(f.offsets) > n) {
+			count := f.lineCount()
+			f.mu.Unlock()
+			return count, nil
+		}
+		err := f.indexChunk()
+		count := f.lineCount()
+		f.mu.Unlock()
+		if err != nil {
+			return count, err
+		}
+	}
+}
+
+// Lines returns up to n lines starting at the zero based line start.
+// Fewer lines are returned if the end of the file is reached.
+func (f *LineFile) Lines(start, n int) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.indexTo(start + n); err != nil {
+		return nil, err
+	}
+	if n <= 0 || start >= f.lineCount() {
+		return []string{}, nil
+	}
+	end := start + n
+	if end > f.lineCount() {
+		end = f.lineCount()
+	}
+	from, to := f.offsets[start], f.lineEnd(end-1)
+	buf := make([]byte, to-from)
+	if _, err := f.file.ReadAt(buf, from); err != nil && err != io.EOF {
+		return nil, err
+	}
+	text := strings.ReplaceAll(string(buf), "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return lines, nil
+}
+
+// Search returns the first line matching re starting at the zero based line from
+// and moving forward, or backward if forward is false. ok is false if no line matches.
+// It stops early with the error of ctx if ctx is done.
+func (f *LineFile) Search(ctx context.Context, re *regexp.Regexp, from int, forward bool) (line int, ok bool, err error) {
+	const batch = 1000
+	if forward {
+		for start := from; ; start += batch {
+			if err := ctx.Err(); err != nil {
+				return 0, false, err
+			}
+			lines, err := f.Lines(start, batch)
+			if err != nil {
+				return 0, false, err
+			}
+			for i, l := range lines {
+				if re.MatchString(l) {
+					return start + i, true, nil
+				}
+			}
+			if len(lines) < batch {
+				return 0, false, nil
+			}
+		}
+	}
+	for end := from + 1; end > 0; end -= batch {
+		if err := ctx.Err(); err != nil {
+			return 0, false, err
+		}
+		start := end - batch
+		if start < 0 {
+			start = 0
+		}
+		lines, err := f.Lines(start, end-start)
+		if err != nil {
+			return 0, false, err
+		}
+		for i := len(lines) - 1; i >= 0; i-- {
+			if re.MatchString(lines[i]) {
+				return start + i, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
+
+// lineCount returns the number of known lines. A final empty line
+// after a trailing newline is not counted.
+func (f *LineFile) lineCount() int {
+	n := len(f.offsets)
+	if f.eof && f.offsets[n-1] == f.indexed {
+		n--
+	}
+	return n
+}
+
+// lineEnd returns the offset of the end of line i
+func (f *LineFile) lineEnd(i int) int64 {
+	if i+1 < len(f.offsets) {
+		return f.offsets[i+1]
+	}
+	return f.indexed
+}
+
+// indexTo indexes the file until line n is known to end or the file ends
+func (f *LineFile) indexTo(n int) error {
+	for !f.eof && len(f.offsets) <= n {
+		if err := f.indexChunk(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *LineFile) indexChunk() error {
+	buf := make([]byte, lineChunkSize)
+	read, err := f.file.ReadAt(buf, f.indexed)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	buf = buf[:read]
+	for i := bytes.IndexByte(buf, '\n'); i != -1; {
+		f.offsets = append(f.offsets, f.indexed+int64(i)+1)
+		next := bytes.IndexByte(buf[i+1:], '\n')
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	f.indexed += int64(read)
+	if err == io.EOF || read == 0 {
+		f.eof = true
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package entry
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func writeLines(t *testing.T, fsys afero.Fs, path string, n int) {
+	t.Helper()
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	if err := afero.WriteFile(fsys, path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLineFileLines(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	// large enough to need several chunks
+	writeLines(t, fsys, "/big", 20_000)
+	afero.WriteFile(fsys, "/nonl", []byte("a\r\nb"), 0644)
+	afero.WriteFile(fsys, "/empty", nil, 0644)
+
+	f, err := OpenLineFile(fsys, "/big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines, err := f.Lines(10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(lines, ",") != "line 10,line 11,line 12" {
+		t.Errorf("got %q", lines)
+	}
+	if n, complete := f.LineCount(); complete || n >= 20_000 {
+		t.Errorf("whole file was indexed to read the start: %d lines, complete %v", n, complete)
+	}
+
+	lines, _ = f.Lines(19_998, 10)
+	if strings.Join(lines, ",") != "line 19998,line 19999" {
+		t.Errorf("got %q at the end of the file", lines)
+	}
+	if n, err := f.CountLines(context.Background()); err != nil || n != 20_000 {
+		t.Errorf("CountLines() = %d, %v; want 20000", n, err)
+	}
+
+	f2, _ := OpenLineFile(fsys, "/nonl")
+	defer f2.Close()
+	lines, _ = f2.Lines(0, 5)
+	if strings.Join(lines, ",") != "a,b" {
+		t.Errorf("got %q for file without final newline", lines)
+	}
+
+	f3, _ := OpenLineFile(fsys, "/empty")
+	defer f3.Close()
+	if n, _ := f3.CountLines(context.Background()); n != 0 {
+		t.Errorf("got %d lines for empty file", n)
+	}
+}
+
+func TestLineFileCancel(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	writeLines(t, fsys, "/big", 20_000)
+	f, _ := OpenLineFile(fsys, "/big")
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.CountLines(ctx); err != context.Canceled {
+		t.Errorf("CountLines() = %v; want %v", err, context.Canceled)
+	}
+	if _, _, err := f.Search(ctx, regexp.MustCompile("x"), 0, true); err != context.Canceled {
+		t.Errorf("Search() = %v; want %v", err, context.Canceled)
+	}
+	if n, err := f.IndexLines(context.Background(), 10); err != nil || n <= 10 {
+		t.Errorf("IndexLines(10) = %d, %v; want more than 10 lines", n, err)
+	}
+	if _, complete := f.LineCount(); complete {
+		t.Error("whole file was indexed to find line 10")
+	}
+}
+
+func TestLineFileSearch(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	writeLines(t, fsys, "/big", 5000)
+	f, _ := OpenLineFile(fsys, "/big")
+	defer f.Close()
+
+	re := regexp.MustCompile(`^line 4\d{2}$`)
+	testcases := []struct {
+		from    int
+		forward bool
+		want    int
+		ok      bool
+	}{
+		{0, true, 400, true},
+		{401, true, 401, true},
+		{500, true, 0, false},
+		{4999, false, 499, true},
+		{399, false, 0, false},
+	}
+	for _, tc := range testcases {
+		got, ok, err := f.Search(context.Background(), re, tc.from, tc.forward)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Search(from %d, forward %v) = %d, %v; want %d, %v", tc.from, tc.forward, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -153,18 +153,6 @@ func TestHumanizeSize(t *testing.T) {
 	}
 }
 
-func TestMounts(t *testing.T) {
-	t.SkipNow()
-	mounts, err := GetMounts()
-	if err != nil {
-		t.Error(err)
-	}
-	for _, mount := range mounts {
-		t.Log(mount)
-	}
-	t.Error()
-}
-
 func TestIsZipFile(t *testing.T) {
 	path := `fixtures/ziptest.zip`
 	got, err := IsZipFile(path)
@@ -0,0 +1,68 @@
+package entry
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Opener is a rule that opens files matching any of its patterns with a command.
+type Opener struct {
+	Name       string   // name shown in the "open with" menu
+	Match      []string // patterns as accepted by MatchPattern
+	Command    string   // command template. {path} is replaced by the first path and {paths} by all paths
+	Foreground bool     // if true, the command takes over the terminal until it exits
+	Multiple   bool     // if true, all selected files are passed to a single invocation of the command
+}
+
+// Matches reports whether the opener applies to a file with the given name and mime type.
+func (o Opener) Matches(name string, mimeType string) bool {
+	for _, pattern := range o.Match {
+		if MatchPattern(pattern, name, mimeType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cmd returns the command that opens the paths. If the command template does not
+// contain a placeholder, the paths are appended to the command as arguments.
+func (o Opener) Cmd(paths []string) (*exec.Cmd, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no files to open")
+	}
+	template := o.Command
+	if !strings.Contains(template, "{path}") && !strings.Contains(template, "{paths}") {
+		template += " {paths}"
+	}
+	args, err := ExpandCommand(template, map[string][]string{
+		"path":  paths[:1],
+		"paths": paths,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty opener command")
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// MatchOpeners returns the openers that apply to every one of the files
+// with the given names and mime types, in the order they are defined.
+func MatchOpeners(openers []Opener, names []string, mimeTypes []string) []Opener {
+	var matched []Opener
+	for _, o := range openers {
+		ok := len(names) > 0
+		for i := range names {
+			if !o.Matches(names[i], mimeTypes[i]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, o)
+		}
+	}
+	return matched
+}
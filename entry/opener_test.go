@@ -0,0 +1,58 @@
+package entry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOpenerCmd(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		command string
+		paths   []string
+		want    []string
+	}{
+		{"vim", []string{"/a b", "/c"}, []string{"vim", "/a b", "/c"}},
+		{"feh {path}", []string{"/a", "/b"}, []string{"feh", "/a"}},
+		{"mpv --fs {paths}", []string{"/a", "/b"}, []string{"mpv", "--fs", "/a", "/b"}},
+	}
+	for _, tc := range testcases {
+		cmd, err := Opener{Command: tc.command}.Cmd(tc.paths)
+		if err != nil {
+			t.Fatalf("Cmd(%q) returned error: %v", tc.command, err)
+		}
+		if !reflect.DeepEqual(cmd.Args, tc.want) {
+			t.Errorf("Cmd(%q) args = %q; want %q", tc.command, cmd.Args, tc.want)
+		}
+	}
+	if _, err := (Opener{Command: "vim"}).Cmd(nil); err == nil {
+		t.Error("Cmd with no paths should return an error")
+	}
+}
+
+func TestMatchOpeners(t *testing.T) {
+	t.Parallel()
+	openers := []Opener{
+		{Name: "text", Match: []string{"text/*"}},
+		{Name: "markdown", Match: []string{".md"}},
+		{Name: "image", Match: []string{"image/*"}},
+	}
+	names := func(openers []Opener) []string {
+		var n []string
+		for _, o := range openers {
+			n = append(n, o.Name)
+		}
+		return n
+	}
+	got := names(MatchOpeners(openers, []string{"a.md"}, []string{"text/plain; charset=utf-8"}))
+	if !reflect.DeepEqual(got, []string{"text", "markdown"}) {
+		t.Errorf("got %v; want [text markdown]", got)
+	}
+	got = names(MatchOpeners(openers, []string{"a.md", "b.txt"}, []string{"text/plain", "text/plain"}))
+	if !reflect.DeepEqual(got, []string{"text"}) {
+		t.Errorf("got %v; want [text]", got)
+	}
+	if got := MatchOpeners(openers, nil, nil); len(got) != 0 {
+		t.Errorf("got %v for no files; want none", got)
+	}
+}
@@ -3,12 +3,14 @@
 package entry
 
 import (
-	"fmt"
+	"context"
 	"os"
-	// "os/exec"
+	"os/exec"
 	"os/user"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	// "golang.org/x/sys/unix"
 )
@@ -22,27 +24,85 @@ func isHidden(fullPath string) (bool, error) {
 	return hidden, nil
 }
 
+// names caches the names of user and group ids, which are looked up for every row shown
+var names sync.Map // "u<id>" or "g<id>" -> name
+
 func userName(f os.FileInfo) string {
-	if stat, ok := f.Sys().(*syscall.Stat_t); ok {
-		if u, err := user.LookupId(fmt.Sprint(stat.Uid)); err == nil {
-			return fmt.Sprintf("%v ", u.Username)
-		}
+	stat, ok := f.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	key := "u" + strconv.FormatUint(uint64(stat.Uid), 10)
+	if name, ok := names.Load(key); ok {
+		return name.(string)
 	}
-	return ""
+	name := key[1:]
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	names.Store(key, name)
+	return name
 }
 
 func groupName(f os.FileInfo) string {
+	stat, ok := f.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	key := "g" + strconv.FormatUint(uint64(stat.Gid), 10)
+	if name, ok := names.Load(key); ok {
+		return name.(string)
+	}
+	name := key[1:]
+	if g, err := user.LookupGroupId(name); err == nil {
+		name = g.Name
+	}
+	names.Store(key, name)
+	return name
+}
+
+func linkCount(f os.FileInfo) (uint64, bool) {
 	if stat, ok := f.Sys().(*syscall.Stat_t); ok {
-		if g, err := user.LookupGroupId(fmt.Sprint(stat.Gid)); err == nil {
-			return fmt.Sprintf("%v ", g.Name)
-		}
+		return uint64(stat.Nlink), true
 	}
-	return ""
+	return 0, false
 }
 
-// func linkCount(f os.FileInfo) string {
-// 	if stat, ok := f.Sys().(*syscall.Stat_t); ok {
-// 		return fmt.Sprintf("%v ", stat.Nlink)
-// 	}
-// 	return ""
-// }
+func inode(f os.FileInfo) (uint64, bool) {
+	if stat, ok := f.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino), true
+	}
+	return 0, false
+}
+
+// ShellCommand returns the command that runs the script with sh
+func ShellCommand(script string) *exec.Cmd {
+	return ShellCommandContext(context.Background(), script)
+}
+
+// ShellCommandContext is like ShellCommand but the script is killed when ctx is done
+func ShellCommandContext(ctx context.Context, script string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", script)
+}
+
+// Detach makes cmd run in a session of its own, so it does not get the signals meant
+// for fman, such as SIGHUP when the terminal is closed. Its standard streams are the
+// null device so it cannot write over fman.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	// exec connects nil streams to the null device
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = nil, nil, nil
+}
+
+// shellQuote quotes s as a single argument of sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// DefaultShell returns the shell of the user, falling back to sh
+func DefaultShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "sh"
+}
@@ -3,15 +3,16 @@
 package entry
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
 	"golang.org/x/sys/windows"
 )
 
-// func detachedCommand(name string, arg ...string) *exec.Cmd {
-// 	cmd := exec.Command(name, arg...)
-// 	cmd.SysProcAttr = &windows.SysProcAttr{CreationFlags: 8}
-// 	return cmd
-// }
-
 // func shellKill(cmd *exec.Cmd) error {
 // 	return cmd.Process.Kill()
 // }
@@ -27,3 +28,70 @@ func isHidden(filePath string) (bool, error) {
 	}
 	return attrs&windows.FILE_ATTRIBUTE_HIDDEN != 0, nil
 }
+
+// Owners, groups, link counts and inodes are not shown on windows
+
+func userName(f os.FileInfo) string {
+	return ""
+}
+
+func groupName(f os.FileInfo) string {
+	return ""
+}
+
+func linkCount(f os.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+func inode(f os.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+func accessTime(f os.FileInfo) (time.Time, bool) {
+	if attrs, ok := f.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, attrs.LastAccessTime.Nanoseconds()), true
+	}
+	return time.Time{}, false
+}
+
+func creationTime(_ string, f os.FileInfo) (time.Time, bool) {
+	if attrs, ok := f.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, attrs.CreationTime.Nanoseconds()), true
+	}
+	return time.Time{}, false
+}
+
+// ShellCommand returns the command that runs the script with cmd. The command line is
+// set as is, since cmd does not follow the quoting rules of exec.Command.
+func ShellCommand(script string) *exec.Cmd {
+	return ShellCommandContext(context.Background(), script)
+}
+
+// ShellCommandContext is like ShellCommand but the script is killed when ctx is done
+func ShellCommandContext(ctx context.Context, script string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "cmd /C " + script}
+	return cmd
+}
+
+// Detach makes cmd run without a console and in a process group of its own, so it does
+// not get the Ctrl+C meant for fman. Its standard streams are the null device so it
+// cannot write over fman.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP}
+	// exec connects nil streams to the null device
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = nil, nil, nil
+}
+
+// shellQuote quotes s as a single argument of cmd
+func shellQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// DefaultShell returns the command interpreter of the user, falling back to cmd
+func DefaultShell() string {
+	if shell := os.Getenv("COMSPEC"); shell != "" {
+		return shell
+	}
+	return "cmd"
+}
@@ -0,0 +1,76 @@
+package entry
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// MaxCommandOutput is the most output of a command that is kept when it runs in the
+// background
+const MaxCommandOutput = 4 << 20
+
+// tailSize is how much of the end of the output is kept to find its last line
+const tailSize = 4096
+
+// OutputBuffer collects the output of a command up to a limit. Output past the limit is
+// counted but dropped, so a command that writes without end cannot use up the memory.
+// It can be read while the command is writing to it.
+type OutputBuffer struct {
+	mu      sync.Mutex
+	buf     []byte
+	limit   int
+	dropped int64
+	tail    []byte // end of the output, including what was dropped
+}
+
+// NewOutputBuffer returns a buffer that keeps the first limit bytes written to it
+func NewOutputBuffer(limit int) *OutputBuffer {
+	return &OutputBuffer{limit: limit}
+}
+
+// Write never fails, so the command is not stopped when the limit is reached
+func (b *OutputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(p)
+	b.tail = append(b.tail, p...)
+	if len(b.tail) > tailSize {
+		b.tail = append(b.tail[:0], b.tail[len(b.tail)-tailSize:]...)
+	}
+	if room := b.limit - len(b.buf); room < n {
+		if room < 0 {
+			room = 0
+		}
+		b.dropped += int64(n - room)
+		p = p[:room]
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+// Bytes returns a copy of the output. If output was dropped, a line saying how much is
+// appended.
+func (b *OutputBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	output := append([]byte(nil), b.buf...)
+	if b.dropped > 0 {
+		if len(output) > 0 && output[len(output)-1] != '\n' {
+			output = append(output, '\n')
+		}
+		output = append(output, fmt.Sprintf("[%d more bytes of output were dropped]\n", b.dropped)...)
+	}
+	return output
+}
+
+// LastLine returns the last line of the output that is not empty, even if it was dropped
+func (b *OutputBuffer) LastLine() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	output := bytes.TrimRight(b.tail, " \t\r\n")
+	if i := bytes.LastIndexByte(output, '\n'); i >= 0 {
+		output = output[i+1:]
+	}
+	return string(bytes.TrimSpace(output))
+}
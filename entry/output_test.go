@@ -0,0 +1,43 @@
+package entry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputBuffer(t *testing.T) {
+	t.Parallel()
+	b := NewOutputBuffer(8)
+	if n, err := b.Write([]byte("one\ntwo\n")); n != 8 || err != nil {
+		t.Fatalf("Write returned %d, %v", n, err)
+	}
+	if got := b.LastLine(); got != "two" {
+		t.Errorf("LastLine() = %q; want two", got)
+	}
+	if n, err := b.Write([]byte("three")); n != 5 || err != nil {
+		t.Fatalf("Write past the limit returned %d, %v; want 5, nil", n, err)
+	}
+	want := "one\ntwo\n[5 more bytes of output were dropped]\n"
+	if got := string(b.Bytes()); got != want {
+		t.Errorf("Bytes() = %q; want %q", got, want)
+	}
+	if got := b.LastLine(); got != "three" {
+		t.Errorf("LastLine() = %q after the limit; want three", got)
+	}
+
+	b = NewOutputBuffer(6)
+	b.Write([]byte("abc"))
+	b.Write([]byte("defgh"))
+	want = "abcdef\n[2 more bytes of output were dropped]\n"
+	if got := string(b.Bytes()); got != want {
+		t.Errorf("Bytes() = %q; want %q", got, want)
+	}
+
+	// the last line is found when the end of the output is longer than what is kept of it
+	b = NewOutputBuffer(MaxCommandOutput)
+	b.Write([]byte("first\n"))
+	b.Write([]byte(strings.Repeat("x", tailSize) + "\nlast line\n\n"))
+	if got := b.LastLine(); got != "last line" {
+		t.Errorf("LastLine() = %q; want last line", got)
+	}
+}
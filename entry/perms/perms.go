@@ -0,0 +1,236 @@
+// Package perms changes the permissions and owners of entries, optionally recursively with
+// different modes for directories and files. All of the changes go through afero.Fs.
+package perms
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Mode holds the permission bits as written for chmod, including setuid, setgid and sticky
+type Mode uint32
+
+const (
+	Setuid Mode = 0o4000
+	Setgid Mode = 0o2000
+	Sticky Mode = 0o1000
+)
+
+// Class is the set of users a permission applies to
+type Class uint8
+
+const (
+	User Class = iota
+	Group
+	Other
+)
+
+// Perm is a permission of a class
+type Perm uint8
+
+const (
+	Read Perm = iota
+	Write
+	Exec
+)
+
+// FromFileMode returns the permission bits of a file mode
+func FromFileMode(mode fs.FileMode) Mode {
+	m := Mode(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		m |= Setuid
+	}
+	if mode&fs.ModeSetgid != 0 {
+		m |= Setgid
+	}
+	if mode&fs.ModeSticky != 0 {
+		m |= Sticky
+	}
+	return m
+}
+
+// FileMode returns the mode as used by Chmod
+func (m Mode) FileMode() fs.FileMode {
+	mode := fs.FileMode(m) & fs.ModePerm
+	if m&Setuid != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if m&Setgid != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if m&Sticky != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// ParseOctal parses a mode written in octal such as 755 or 4755
+func ParseOctal(s string) (Mode, error) {
+	s = strings.TrimSpace(s)
+	n, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || s == "" || n > 0o7777 {
+		return 0, fmt.Errorf("invalid octal mode %q", s)
+	}
+	return Mode(n), nil
+}
+
+// bit returns the bit of the permission of the class
+func bit(c Class, p Perm) Mode {
+	return 1 << (3*(2-Mode(c)) + 2 - Mode(p))
+}
+
+// Has reports whether the class has the permission
+func (m Mode) Has(c Class, p Perm) bool {
+	return m&bit(c, p) != 0
+}
+
+// Toggle flips the permission of the class
+func (m Mode) Toggle(c Class, p Perm) Mode {
+	return m ^ bit(c, p)
+}
+
+// String returns the mode in octal with four digits
+func (m Mode) String() string {
+	return fmt.Sprintf("%04o", uint32(m))
+}
+
+// Symbolic returns the mode as shown by ls, such as rwsr-xr-x
+func (m Mode) Symbolic() string {
+	b := []byte("---------")
+	for c := User; c <= Other; c++ {
+		for p, r := range []byte("rwx") {
+			if m.Has(c, Perm(p)) {
+				b[3*int(c)+p] = r
+			}
+		}
+	}
+	special := [3]Mode{Setuid, Setgid, Sticky}
+	for c, r := range []byte("sst") {
+		if m&special[c] == 0 {
+			continue
+		}
+		if i := 3*c + 2; b[i] == 'x' {
+			b[i] = r
+		} else {
+			b[i] = r - 'a' + 'A'
+		}
+	}
+	return string(b)
+}
+
+// Change is a change of the mode and owner of entries
+type Change struct {
+	Mode      *Mode // mode of every entry, or of files if Recursive. nil keeps the modes
+	DirMode   *Mode // mode of directories if Recursive. nil keeps the modes
+	Recursive bool  // also change the entries below directories
+	UID       int   // new owner, or -1 to keep it
+	GID       int   // new group, or -1 to keep it
+}
+
+// LookupIDs returns the ids of the user and group given by name or id. An empty name
+// gives -1, which keeps the owner or group as it is.
+func LookupIDs(owner, group string) (uid, gid int, err error) {
+	uid, gid = -1, -1
+	if owner = strings.TrimSpace(owner); owner != "" {
+		id := owner
+		if _, convErr := strconv.Atoi(owner); convErr != nil {
+			u, err := user.Lookup(owner)
+			if err != nil {
+				return -1, -1, err
+			}
+			id = u.Uid
+		}
+		if uid, err = strconv.Atoi(id); err != nil {
+			return -1, -1, fmt.Errorf("user %s has no numeric id", owner)
+		}
+	}
+	if group = strings.TrimSpace(group); group != "" {
+		id := group
+		if _, convErr := strconv.Atoi(group); convErr != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return -1, -1, err
+			}
+			id = g.Gid
+		}
+		if gid, err = strconv.Atoi(id); err != nil {
+			return -1, -1, fmt.Errorf("group %s has no numeric id", group)
+		}
+	}
+	return uid, gid, nil
+}
+
+// Apply changes the paths. Symbolic links below directories are not followed. Directories
+// are changed after the entries below them so a mode without read or search permission
+// does not stop the walk. It returns the errors of the entries that could not be changed.
+func Apply(ctx context.Context, fsys afero.Fs, paths []string, c Change) []error {
+	var errs []error
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return append(errs, err)
+		}
+		info, err := fsys.Stat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !c.Recursive || !info.IsDir() {
+			if err := c.apply(fsys, path, info); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		var dirs []string
+		var dirInfos []fs.FileInfo
+		err = afero.Walk(fsys, path, func(p string, info fs.FileInfo, err error) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			switch {
+			case err != nil:
+				errs = append(errs, err)
+			case info.IsDir():
+				dirs = append(dirs, p)
+				dirInfos = append(dirInfos, info)
+			case info.Mode()&fs.ModeSymlink == 0:
+				if err := c.apply(fsys, p, info); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return append(errs, err)
+		}
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if err := c.apply(fsys, dirs[i], dirInfos[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// apply changes one entry. Ownership is changed first because changing the owner can
+// clear the setuid and setgid bits.
+func (c Change) apply(fsys afero.Fs, path string, info fs.FileInfo) error {
+	if c.UID >= 0 || c.GID >= 0 {
+		if err := fsys.Chown(path, c.UID, c.GID); err != nil {
+			return err
+		}
+	}
+	mode := c.Mode
+	if c.Recursive && info.IsDir() {
+		mode = c.DirMode
+	}
+	if mode == nil {
+		return nil
+	}
+	return fsys.Chmod(path, mode.FileMode())
+}
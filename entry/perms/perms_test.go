@@ -0,0 +1,106 @@
+package perms
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestMode(t *testing.T) {
+	t.Parallel()
+	m, err := ParseOctal("4755")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Symbolic() != "rwsr-xr-x" {
+		t.Errorf("expected rwsr-xr-x, got %s", m.Symbolic())
+	}
+	if !m.Has(User, Write) || m.Has(Group, Write) || !m.Has(Other, Exec) {
+		t.Errorf("unexpected permissions of %s", m)
+	}
+	if m = m.Toggle(Group, Write); m.String() != "4775" {
+		t.Errorf("expected 4775, got %s", m)
+	}
+	if got := FromFileMode(m.FileMode() | fs.ModeDir); got != m {
+		t.Errorf("expected the mode to survive a round trip, got %s", got)
+	}
+	for _, s := range []string{"", "8", "17777", "rwx"} {
+		if _, err := ParseOctal(s); err == nil {
+			t.Errorf("expected %q to be refused", s)
+		}
+	}
+}
+
+func TestLookupIDs(t *testing.T) {
+	t.Parallel()
+	uid, gid, err := LookupIDs("", "")
+	if err != nil || uid != -1 || gid != -1 {
+		t.Errorf("expected empty names to keep the owner, got %d, %d, %v", uid, gid, err)
+	}
+	uid, gid, err = LookupIDs("1000", "100")
+	if err != nil || uid != 1000 || gid != 100 {
+		t.Errorf("expected numeric ids to be used as they are, got %d, %d, %v", uid, gid, err)
+	}
+	if _, _, err := LookupIDs("no-such-user-fman", ""); err == nil {
+		t.Error("expected an unknown user to be refused")
+	}
+}
+
+func modeOf(t *testing.T, fsys afero.Fs, path string) Mode {
+	t.Helper()
+	info, err := fsys.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return FromFileMode(info.Mode())
+}
+
+func TestApplyRecursive(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	fsys.MkdirAll("/root/dir/sub", 0700)
+	afero.WriteFile(fsys, "/root/dir/a", nil, 0600)
+	afero.WriteFile(fsys, "/root/dir/sub/b", nil, 0600)
+	afero.WriteFile(fsys, "/root/c", nil, 0600)
+
+	fileMode, dirMode := Mode(0o644), Mode(0o755)
+	errs := Apply(context.Background(), fsys, []string{"/root/dir", "/root/c"}, Change{
+		Mode: &fileMode, DirMode: &dirMode, Recursive: true, UID: -1, GID: -1,
+	})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	exp := map[string]Mode{
+		"/root/dir": 0o755, "/root/dir/sub": 0o755,
+		"/root/dir/a": 0o644, "/root/dir/sub/b": 0o644, "/root/c": 0o644,
+	}
+	for path, mode := range exp {
+		if got := modeOf(t, fsys, path); got != mode {
+			t.Errorf("%s: expected %s, got %s", path, mode, got)
+		}
+	}
+
+	// without Recursive the mode is applied to the directory itself only
+	mode := Mode(0o700)
+	Apply(context.Background(), fsys, []string{"/root/dir"}, Change{Mode: &mode, UID: -1, GID: -1})
+	if got := modeOf(t, fsys, "/root/dir"); got != mode {
+		t.Errorf("expected %s, got %s", mode, got)
+	}
+	if got := modeOf(t, fsys, "/root/dir/a"); got != fileMode {
+		t.Errorf("expected the entries below to be kept, got %s", got)
+	}
+}
+
+func TestApplyKeepsMode(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/a", nil, 0640)
+	if errs := Apply(context.Background(), fsys, []string{"/a"}, Change{UID: 1000, GID: 1000}); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if got := modeOf(t, fsys, "/a"); got != 0o640 {
+		t.Errorf("expected the mode to be kept, got %s", got)
+	}
+}
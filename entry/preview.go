@@ -6,11 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/formatters"
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/styles"
@@ -29,12 +31,18 @@ type Preview struct {
 	ReadTime time.Time
 }
 
+// CreatePreview generates a preview of the file specified in the Preview struct using the given file system
+// and the default previewers. See PreviewRegistry.CreatePreview.
+func CreatePreview(ctx context.Context, fsys afero.Fs, preview Preview, maxBytes int) Preview {
+	return defaultPreviewRegistry.CreatePreview(ctx, fsys, preview, maxBytes)
+}
+
 // CreatePreview generates a preview of the file specified in the Preview struct using the given file system.
-// The preview is generated based on the file's MIME type and is returned as a Preview struct.
-// If the file is not a text file, an error is returned in the Preview struct's Err field.
+// The previewer is chosen based on the file's name and MIME type and the preview is returned as a Preview struct.
+// If no previewer is registered for the file, an error is returned in the Preview struct's Err field.
 // If the context is cancelled, the function returns the original Preview struct.
-// The maxBytes parameter specifies the maximum number of bytes to read from the file.
-func CreatePreview(ctx context.Context, fsys afero.Fs, preview Preview, maxBytes int) Preview {
+// The maxBytes parameter specifies the maximum number of bytes of the preview.
+func (r *PreviewRegistry) CreatePreview(ctx context.Context, fsys afero.Fs, preview Preview, maxBytes int) Preview {
 	previewChan := make(chan Preview)
 	errc := make(chan error, 1)
 	go func(prev Preview) {
@@ -57,42 +65,29 @@ func CreatePreview(ctx context.Context, fsys afero.Fs, preview Preview, maxBytes
 		// return early if context is cancelled
 		if ctx.Err() != nil {
 			errc <- ctx.Err()
-		}
-
-		file, err := fsys.Open(prev.Path)
-		if err != nil {
-			errc <- err
 			return
 		}
-		defer file.Close()
-
-		// return early if context is cancelled
-		if ctx.Err() != nil {
-			errc <- ctx.Err()
-		}
 
-		mime, err := GetMimeTypeByRead(file)
+		mimeType, err := DetectMimeType(fsys, prev.Path)
 		if err != nil {
 			errc <- err
 			return
 		}
 
-		isText := strings.HasPrefix(mime, "text/")
-		if !isText {
-			errc <- fmt.Errorf("not a text file")
+		previewer := r.Lookup(filepath.Base(prev.Path), mimeType)
+		if previewer == nil {
+			errc <- fmt.Errorf("no previewer for %s", mimeType)
 			return
 		}
 
 		// return early if context is cancelled
 		if ctx.Err() != nil {
 			errc <- ctx.Err()
+			return
 		}
 
-		if stat.Size() < int64(maxBytes) {
-			maxBytes = int(stat.Size())
-		}
-
-		content, err := createPreview(ctx, filepath.Base(prev.Path), file, maxBytes)
+		content, err := previewer.Preview(ctx, fsys, prev.Path, maxBytes)
+		content = ExpandTabs(content)
 		p := Preview{
 			Content:  content,
 			Err:      err,
@@ -144,10 +139,14 @@ func createPreview(ctx context.Context, fileName string, reader io.Reader, maxBy
 		preview, _ = highlightSyntax(fileName, preview)
 	}
 
-	// tabs are rendered with different widths based on terminal and font settings
-	// so we replace the tab with four spaces so we can reliably truncate each line
-	preview = strings.ReplaceAll(preview, "\t", "    ")
-	return preview, nil
+	return ExpandTabs(preview), nil
+}
+
+// ExpandTabs replaces the tabs in s with four spaces. Tabs are rendered with different
+// widths based on terminal and font settings, so lines can only be reliably truncated
+// once their tabs are replaced.
+func ExpandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
 }
 
 func highlightSyntax(name string, preview string) (string, error) {
@@ -155,6 +154,10 @@ func highlightSyntax(name string, preview string) (string, error) {
 	if lexer == nil {
 		lexer = lexers.Fallback
 	}
+	return highlight(lexer, preview)
+}
+
+func highlight(lexer chroma.Lexer, preview string) (string, error) {
 	style := styles.Get("monokai")
 	formatter := formatters.Get("terminal")
 
@@ -172,6 +175,57 @@ func highlightSyntax(name string, preview string) (string, error) {
 	return buffer.String(), nil
 }
 
+// HighlightLines highlights the syntax of the lines based on the file name.
+// The lines are highlighted together so multi-line tokens are styled correctly
+// and the result has the same number of lines as the input. If highlighting fails,
+// the lines are returned unchanged.
+func HighlightLines(name string, lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+	highlighted, err := highlightSyntax(name, strings.Join(lines, "\n"))
+	if err != nil {
+		return lines
+	}
+	out := strings.Split(strings.TrimSuffix(highlighted, "\n"), "\n")
+	if len(out) != len(lines) {
+		return lines
+	}
+	for i := range out {
+		// styles may span lines so reset at the end of each line, as lines
+		// can be displayed out of their original order
+		out[i] = ExpandTabs(out[i]) + "\x1b[0m"
+	}
+	return out
+}
+
+// HighlightDiff highlights a unified diff. If highlighting fails, the diff is returned
+// unchanged.
+func HighlightDiff(diff string) string {
+	lexer := lexers.Get("diff")
+	if lexer == nil {
+		return diff
+	}
+	highlighted, err := highlight(lexer, diff)
+	if err != nil {
+		return diff
+	}
+	return ExpandTabs(highlighted)
+}
+
+// RevisionPreview creates a preview of content, which was read from the file fileName at
+// an earlier revision. Binary content is not shown.
+func RevisionPreview(ctx context.Context, fileName string, content []byte) (string, error) {
+	mimeType, err := GetMimeTypeByRead(bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(mimeType, "text/") {
+		return "", fmt.Errorf("no preview for %s", mimeType)
+	}
+	return createPreview(ctx, fileName, bytes.NewReader(content), len(content))
+}
+
 func renderMarkdown(content string) (string, error) {
 	str, err := glamour.Render(content, "dracula")
 	if err != nil {
@@ -180,6 +234,27 @@ func renderMarkdown(content string) (string, error) {
 	return str, nil
 }
 
+// DetectMimeType returns the mime type of the file at path based on its content.
+// As the content based type is not very specific for binary files, the type
+// is guessed from the extension if the content looks binary.
+func DetectMimeType(fsys afero.Fs, path string) (string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	mimeType, err := GetMimeTypeByRead(file)
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(mimeType, "application/octet-stream") {
+		if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+			mimeType = byExt
+		}
+	}
+	return mimeType, nil
+}
+
 // GetMimeTypeByRead returns the mime type of a file by reading up to 512 bytes of its content.
 func GetMimeTypeByRead(seeker io.ReadSeeker) (string, error) {
 	// At most the first 512 bytes of data are used:
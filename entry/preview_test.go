@@ -115,3 +115,46 @@ func TestGetMimeType(t *testing.T) {
 		})
 	}
 }
+
+func TestHighlightLines(t *testing.T) {
+	t.Parallel()
+	lines := []string{"package main", "", "func main() {", "\tprintln(`multi", "line`)", "}"}
+	got := HighlightLines("main.go", lines)
+	if len(got) != len(lines) {
+		t.Fatalf("got %d lines; want %d", len(got), len(lines))
+	}
+	for i, line := range got {
+		if strings.Contains(line, "\t") {
+			t.Errorf("line %d contains a tab: %q", i, line)
+		}
+		if !strings.HasSuffix(line, "\x1b[0m") {
+			t.Errorf("line %d is not reset: %q", i, line)
+		}
+	}
+	if got := HighlightLines("main.go", nil); len(got) != 0 {
+		t.Errorf("got %d lines for empty input", len(got))
+	}
+}
+
+func TestHighlightDiff(t *testing.T) {
+	t.Parallel()
+	diff := "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-old\n+\tnew\n"
+	got := HighlightDiff(diff)
+	if got == diff || !strings.Contains(got, "old") || strings.Contains(got, "\t") {
+		t.Errorf("diff was not highlighted: %q", got)
+	}
+	if got := HighlightDiff(""); got != "" {
+		t.Errorf("got %q for an empty diff", got)
+	}
+}
+
+func TestRevisionPreview(t *testing.T) {
+	t.Parallel()
+	got, err := RevisionPreview(context.Background(), "main.go", []byte("package main\n"))
+	if err != nil || !strings.Contains(got, "main") {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if _, err := RevisionPreview(context.Background(), "a.bin", []byte{0, 1, 2, 3}); err == nil {
+		t.Error("expected an error for binary content")
+	}
+}
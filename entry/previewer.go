@@ -0,0 +1,213 @@
+package entry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Previewer renders a preview of the file at the given path. maxBytes is the
+// maximum number of bytes of the preview the caller is interested in.
+type Previewer interface {
+	Preview(ctx context.Context, fsys afero.Fs, path string, maxBytes int) (string, error)
+}
+
+// PreviewerFunc is an adapter to allow the use of ordinary functions as Previewers.
+type PreviewerFunc func(ctx context.Context, fsys afero.Fs, path string, maxBytes int) (string, error)
+
+// Preview calls f(ctx, fsys, path, maxBytes).
+func (f PreviewerFunc) Preview(ctx context.Context, fsys afero.Fs, path string, maxBytes int) (string, error) {
+	return f(ctx, fsys, path, maxBytes)
+}
+
+type registeredPreviewer struct {
+	pattern   string
+	previewer Previewer
+}
+
+// PreviewRegistry maps file patterns to the Previewer used to render them.
+// Patterns are matched with MatchPattern and the most recently registered
+// matching Previewer wins, so user defined previewers override the built-in ones.
+type PreviewRegistry struct {
+	mu         sync.RWMutex
+	previewers []registeredPreviewer
+}
+
+// NewPreviewRegistry returns an empty registry.
+func NewPreviewRegistry() *PreviewRegistry {
+	return &PreviewRegistry{}
+}
+
+// DefaultPreviewRegistry returns a registry with the built-in previewers
+// for text files (syntax highlighted) and markdown files (rendered).
+func DefaultPreviewRegistry() *PreviewRegistry {
+	r := NewPreviewRegistry()
+	r.Register("text/*", PreviewerFunc(textPreview))
+	r.Register(".md", PreviewerFunc(textPreview))
+	return r
+}
+
+var defaultPreviewRegistry = DefaultPreviewRegistry()
+
+// Register adds a previewer for files matching the pattern.
+func (r *PreviewRegistry) Register(pattern string, p Previewer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.previewers = append(r.previewers, registeredPreviewer{pattern: pattern, previewer: p})
+}
+
+// Lookup returns the previewer for a file with the given name and mime type,
+// or nil if no previewer matches.
+func (r *PreviewRegistry) Lookup(name string, mimeType string) Previewer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := len(r.previewers) - 1; i >= 0; i-- {
+		if MatchPattern(r.previewers[i].pattern, name, mimeType) {
+			return r.previewers[i].previewer
+		}
+	}
+	return nil
+}
+
+// MatchPattern reports whether a file with the given name and mime type matches the pattern.
+//
+// Patterns containing a slash are mime type globs (e.g., "text/*" or "application/pdf"),
+// patterns like ".go" match the file extension and anything else is a glob
+// matched against the file name (e.g., "*.tar.gz" or "Makefile").
+// Matching is case insensitive and mime type parameters such as charset are ignored.
+func MatchPattern(pattern string, name string, mimeType string) bool {
+	pattern = strings.ToLower(pattern)
+	switch {
+	case strings.Contains(pattern, "/"):
+		if mimeType == "" {
+			return false
+		}
+		mediaType, _, err := mime.ParseMediaType(mimeType)
+		if err != nil {
+			return false
+		}
+		ok, _ := path.Match(pattern, mediaType)
+		return ok
+	case strings.HasPrefix(pattern, ".") && !strings.ContainsAny(pattern, "*?["):
+		return strings.ToLower(filepath.Ext(name)) == pattern
+	default:
+		ok, _ := path.Match(pattern, strings.ToLower(filepath.Base(name)))
+		return ok
+	}
+}
+
+// textPreview reads up to maxBytes of the file and renders it as markdown
+// or highlights the syntax based on the file name.
+func textPreview(ctx context.Context, fsys afero.Fs, path string, maxBytes int) (string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if stat.Size() < int64(maxBytes) {
+		maxBytes = int(stat.Size())
+	}
+	return createPreview(ctx, filepath.Base(path), file, maxBytes)
+}
+
+var errNoExternalPreview = errors.New("external previewers only work on the os filesystem")
+
+// CommandPreviewer previews files with the output of an external command.
+type CommandPreviewer struct {
+	Command   string        // command template. {path} is replaced by the path of the file
+	Timeout   time.Duration // the command is killed after this duration. No timeout if <= 0
+	MaxOutput int           // maximum bytes of output to keep. Defaults to maxBytes if <= 0
+}
+
+// Preview runs the command and returns its stdout. The command is killed when the context
+// is cancelled, the timeout elapses or the output grows larger than the maximum size.
+func (c CommandPreviewer) Preview(ctx context.Context, fsys afero.Fs, path string, maxBytes int) (string, error) {
+	if _, ok := fsys.(*afero.OsFs); !ok {
+		return "", errNoExternalPreview
+	}
+	args, err := ExpandCommand(c.Command, map[string][]string{"path": {path}})
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", errors.New("empty previewer command")
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limit := c.MaxOutput
+	if limit <= 0 {
+		limit = maxBytes
+	}
+	stdout := &cappedBuffer{limit: limit, onFull: cancel}
+	stderr := &cappedBuffer{limit: 1024}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	err = cmd.Run()
+	if stdout.full {
+		// the command was stopped because we have all the output we want
+		return stdout.String(), nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%s: %w", args[0], ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// cappedBuffer is a writer that keeps the first limit bytes written to it
+// and calls onFull once the limit is reached.
+type cappedBuffer struct {
+	buf    bytes.Buffer
+	limit  int
+	full   bool
+	onFull func()
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.full {
+		return len(p), nil
+	}
+	remaining := b.limit - b.buf.Len()
+	if len(p) < remaining {
+		return b.buf.Write(p)
+	}
+	b.buf.Write(p[:remaining])
+	b.full = true
+	if b.onFull != nil {
+		b.onFull()
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,189 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestMatchPattern(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		pattern  string
+		name     string
+		mimeType string
+		want     bool
+	}{
+		{"text/*", "a.txt", "text/plain; charset=utf-8", true},
+		{"text/*", "a.pdf", "application/pdf", false},
+		{"application/pdf", "a.pdf", "application/pdf", true},
+		{"image/*", "a.png", "", false},
+		{".md", "README.MD", "", true},
+		{".md", "a.mdx", "", false},
+		{"*.tar.gz", "archive.tar.gz", "", true},
+		{"Makefile", "makefile", "", true},
+		{"Makefile", "Makefile.am", "", false},
+	}
+	for _, tc := range testcases {
+		got := MatchPattern(tc.pattern, tc.name, tc.mimeType)
+		if got != tc.want {
+			t.Errorf("MatchPattern(%q, %q, %q) = %v; want %v", tc.pattern, tc.name, tc.mimeType, got, tc.want)
+		}
+	}
+}
+
+func TestPreviewRegistryLookup(t *testing.T) {
+	t.Parallel()
+	named := func(name string) Previewer {
+		return PreviewerFunc(func(ctx context.Context, fsys afero.Fs, path string, maxBytes int) (string, error) {
+			return name, nil
+		})
+	}
+	r := NewPreviewRegistry()
+	r.Register("text/*", named("text"))
+	r.Register(".md", named("markdown"))
+	r.Register("text/plain", named("plain"))
+
+	testcases := []struct {
+		name     string
+		mimeType string
+		want     string
+	}{
+		{"a.go", "text/x-go", "text"},
+		{"a.md", "text/markdown", "markdown"},
+		{"a.txt", "text/plain; charset=utf-8", "plain"},
+		{"a.bin", "application/octet-stream", ""},
+	}
+	for _, tc := range testcases {
+		p := r.Lookup(tc.name, tc.mimeType)
+		if p == nil {
+			if tc.want != "" {
+				t.Errorf("Lookup(%q, %q) = nil; want %s", tc.name, tc.mimeType, tc.want)
+			}
+			continue
+		}
+		got, _ := p.Preview(context.Background(), nil, "", 0)
+		if got != tc.want {
+			t.Errorf("Lookup(%q, %q) = %s; want %s", tc.name, tc.mimeType, got, tc.want)
+		}
+	}
+}
+
+func TestExpandCommand(t *testing.T) {
+	t.Parallel()
+	vars := map[string][]string{
+		"path":  {"/a b/c.pdf"},
+		"paths": {"/x", "/y z"},
+	}
+	testcases := []struct {
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{"pdftotext {path} -", []string{"pdftotext", "/a b/c.pdf", "-"}, false},
+		{"cmd {paths}", []string{"cmd", "/x", "/y z"}, false},
+		{"cmd --file={path}", []string{"cmd", "--file=/a b/c.pdf"}, false},
+		{`sh -c "echo {unknown}"`, []string{"sh", "-c", "echo {unknown}"}, false},
+		{`cmd 'single quoted' a\ b`, []string{"cmd", "single quoted", "a b"}, false},
+		{`cmd "{paths}"`, []string{"cmd", "/x /y z"}, false},
+		{`cmd "unterminated`, nil, true},
+		{"", []string{}, false},
+	}
+	for _, tc := range testcases {
+		got, err := ExpandCommand(tc.template, vars)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ExpandCommand(%q) error = %v; wantErr %v", tc.template, err, tc.wantErr)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("ExpandCommand(%q) = %q; want %q", tc.template, got, tc.want)
+		}
+	}
+}
+
+func TestCommandPreviewer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses unix commands")
+	}
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fsys := afero.NewOsFs()
+
+	t.Run("output", func(t *testing.T) {
+		p := CommandPreviewer{Command: "cat {path}"}
+		got, err := p.Preview(context.Background(), fsys, path, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "0123456789" {
+			t.Errorf("got %q; want %q", got, "0123456789")
+		}
+	})
+
+	t.Run("capped", func(t *testing.T) {
+		p := CommandPreviewer{Command: "yes", MaxOutput: 10}
+		got, err := p.Preview(context.Background(), fsys, path, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "y\ny\ny\ny\ny\n" {
+			t.Errorf("got %q; want 10 bytes of yes", got)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		p := CommandPreviewer{Command: "sleep 5", Timeout: 50 * time.Millisecond}
+		start := time.Now()
+		_, err := p.Preview(context.Background(), fsys, path, 100)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v; want %v", err, context.DeadlineExceeded)
+		}
+		if time.Since(start) > 3*time.Second {
+			t.Errorf("command was not killed on timeout")
+		}
+	})
+
+	t.Run("not os filesystem", func(t *testing.T) {
+		p := CommandPreviewer{Command: "cat {path}"}
+		_, err := p.Preview(context.Background(), afero.NewMemMapFs(), path, 100)
+		if !errors.Is(err, errNoExternalPreview) {
+			t.Errorf("got error %v; want %v", err, errNoExternalPreview)
+		}
+	})
+}
+
+func TestRegistryCreatePreview(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/a.txt", []byte("hello"), 0644)
+	afero.WriteFile(fsys, "/b.bin", []byte{0, 1, 2, 3}, 0644)
+
+	r := DefaultPreviewRegistry()
+	p := r.CreatePreview(context.Background(), fsys, Preview{Path: "/a.txt"}, 100)
+	if p.Err != nil || !strings.Contains(p.Content, "hello") {
+		t.Errorf("text preview = %q, %v", p.Content, p.Err)
+	}
+	p = r.CreatePreview(context.Background(), fsys, Preview{Path: "/b.bin"}, 100)
+	if p.Err == nil {
+		t.Errorf("expected error for binary file without previewer")
+	}
+
+	r.Register(".bin", PreviewerFunc(func(ctx context.Context, fsys afero.Fs, path string, maxBytes int) (string, error) {
+		return "binary", nil
+	}))
+	p = r.CreatePreview(context.Background(), fsys, Preview{Path: "/b.bin"}, 100)
+	if p.Err != nil || p.Content != "binary" {
+		t.Errorf("custom preview = %q, %v", p.Content, p.Err)
+	}
+}
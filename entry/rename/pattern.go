@@ -0,0 +1,263 @@
+package rename
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Case is a change of case applied to new names
+type Case uint8
+
+const (
+	CaseKeep Case = iota
+	CaseLower
+	CaseUpper
+	CaseTitle
+)
+
+var caseNames = []string{"keep", "lower", "UPPER", "Title"}
+
+func (c Case) String() string {
+	if int(c) < len(caseNames) {
+		return caseNames[c]
+	}
+	return caseNames[CaseKeep]
+}
+
+// Next returns the case after c, wrapping around
+func (c Case) Next() Case {
+	return (c + 1) % Case(len(caseNames))
+}
+
+// Prev returns the case before c, wrapping around
+func (c Case) Prev() Case {
+	return (c + Case(len(caseNames)) - 1) % Case(len(caseNames))
+}
+
+// Entry is an entry that is renamed by a pattern
+type Entry struct {
+	Name    string
+	ModTime time.Time
+}
+
+// Pattern rewrites names. Find is a regular expression that is replaced in each name by
+// Replace, which can refer to capture groups as $1 or ${name}. If Find is empty, Replace
+// is the new name without the extension. Replace can contain the tokens:
+//
+//	{name}      the old name without the extension
+//	{ext}       the old extension without the dot
+//	{n}         a counter, {n:3} pads it with zeros to 3 digits
+//	{date}      the modification time as 2006-01-02, {date:YYYYMMDD-hhmmss} formats it
+//
+// Ext replaces the extension when it is not empty. Case is applied last.
+type Pattern struct {
+	Find    string
+	Replace string
+	Ext     string
+	Case    Case
+	Start   int // first value of the counter
+}
+
+// tokenRe matches the tokens of a replacement
+var tokenRe = regexp.MustCompile(`\{(name|ext|n|date)(?::([^}]*))?\}`)
+
+// dateTokens are the tokens of a date format with their time layouts, longest first
+var dateTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"DD", "02"},
+	{"hh", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+// formatDate formats t with a date format such as YYYYMMDD. Text that is not a token is
+// copied as it is, so words and digits are never taken for parts of a time layout.
+func formatDate(t time.Time, format string) string {
+	var sb strings.Builder
+	for format != "" {
+		token, layout := format[:1], ""
+		for _, d := range dateTokens {
+			if strings.HasPrefix(format, d.token) {
+				token, layout = d.token, d.layout
+				break
+			}
+		}
+		if layout != "" {
+			sb.WriteString(t.Format(layout))
+		} else {
+			sb.WriteString(token)
+		}
+		format = format[len(token):]
+	}
+	return sb.String()
+}
+
+// Names returns the new name of each entry. The counter counts up from Start in the
+// order of the entries.
+func (p Pattern) Names(entries []Entry) ([]string, error) {
+	var find *regexp.Regexp
+	if p.Find != "" {
+		var err error
+		find, err = regexp.Compile(p.Find)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		ext := filepath.Ext(e.Name)
+		stem := strings.TrimSuffix(e.Name, ext)
+		if stem == "" { // dot files such as .bashrc have no extension
+			stem, ext = e.Name, ""
+		}
+		replace, err := p.expand(e, stem, ext, p.Start+i, find != nil)
+		if err != nil {
+			return nil, err
+		}
+		name := stem + ext
+		switch {
+		case find != nil:
+			name = find.ReplaceAllString(e.Name, replace)
+		case p.Replace != "":
+			name = replace + ext
+		}
+		if p.Ext != "" {
+			ext := filepath.Ext(name)
+			if strings.TrimSuffix(name, ext) == "" {
+				ext = ""
+			}
+			name = strings.TrimSuffix(name, ext) + "." + strings.TrimPrefix(p.Ext, ".")
+		}
+		names[i] = changeCase(name, p.Case)
+	}
+	return names, nil
+}
+
+// expand replaces the tokens of the replacement for one entry. When the replacement is used
+// with a regular expression, dollar signs in the values are escaped so they are not taken
+// for capture groups.
+func (p Pattern) expand(e Entry, stem, ext string, n int, regex bool) (string, error) {
+	var err error
+	replace := tokenRe.ReplaceAllStringFunc(p.Replace, func(token string) string {
+		m := tokenRe.FindStringSubmatch(token)
+		var value string
+		switch m[1] {
+		case "name":
+			value = stem
+		case "ext":
+			value = strings.TrimPrefix(ext, ".")
+		case "n":
+			value = strconv.Itoa(n)
+			if m[2] != "" {
+				width, convErr := strconv.Atoi(m[2])
+				if convErr != nil || width < 0 || width > 20 {
+					err = fmt.Errorf("invalid counter width %q", m[2])
+					return token
+				}
+				value = fmt.Sprintf("%0*d", width, n)
+			}
+		case "date":
+			format := "YYYY-MM-DD"
+			if m[2] != "" {
+				format = m[2]
+			}
+			value = formatDate(e.ModTime, format)
+		}
+		if regex {
+			value = strings.ReplaceAll(value, "$", "$$")
+		}
+		return value
+	})
+	return replace, err
+}
+
+func changeCase(name string, c Case) string {
+	switch c {
+	case CaseLower:
+		return strings.ToLower(name)
+	case CaseUpper:
+		return strings.ToUpper(name)
+	case CaseTitle:
+		// the extension is only lowered
+		ext := filepath.Ext(name)
+		if strings.TrimSuffix(name, ext) == "" {
+			ext = ""
+		}
+		return title(strings.TrimSuffix(name, ext)) + strings.ToLower(ext)
+	}
+	return name
+}
+
+// title capitalises the first letter of each word and lowers the other letters
+func title(s string) string {
+	runes := []rune(strings.ToLower(s))
+	start := true
+	for i, r := range runes {
+		if start && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+		}
+		start = !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}
+	return string(runes)
+}
+
+// Row is a line of a preview
+type Row struct {
+	Rename
+	Err error // why the rename cannot be done, or nil
+}
+
+// Changed reports whether the entry gets a new name
+func (r Row) Changed() bool {
+	return r.From != r.To
+}
+
+// Preview returns the renames of the entries to the new names, flagging invalid names and
+// names that collide with another new name or with an existing entry that is not renamed.
+func Preview(entries []Entry, names []string, existing []string) []Row {
+	rows := make([]Row, len(entries))
+	renamed := make(map[string]struct{}, len(entries))
+	count := make(map[string]int, len(entries))
+	for i, e := range entries {
+		rows[i].From = e.Name
+		rows[i].To = names[i]
+		if rows[i].Changed() {
+			renamed[e.Name] = struct{}{}
+		}
+		count[names[i]]++
+	}
+	taken := make(map[string]struct{}, len(existing))
+	for _, name := range existing {
+		if _, ok := renamed[name]; !ok {
+			taken[name] = struct{}{}
+		}
+	}
+	for i := range rows {
+		r := &rows[i]
+		if !r.Changed() {
+			if count[r.To] > 1 {
+				r.Err = fmt.Errorf("another entry is renamed to %s", r.To)
+			}
+			continue
+		}
+		if err := Validate([]Rename{r.Rename}, nil); err != nil {
+			r.Err = err
+			continue
+		}
+		if count[r.To] > 1 {
+			r.Err = fmt.Errorf("%d entries would be named %s", count[r.To], r.To)
+			continue
+		}
+		if _, ok := taken[r.To]; ok {
+			r.Err = fmt.Errorf("%s already exists", r.To)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,72 @@
+package rename
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPatternNames(t *testing.T) {
+	t.Parallel()
+	modTime := time.Date(2023, 7, 14, 9, 30, 5, 0, time.UTC)
+	entries := []Entry{
+		{Name: "IMG_0012.JPG", ModTime: modTime},
+		{Name: "IMG_0013.JPG", ModTime: modTime},
+		{Name: ".bashrc", ModTime: modTime},
+	}
+	tests := []struct {
+		name    string
+		pattern Pattern
+		exp     []string
+	}{
+		{"unchanged", Pattern{}, []string{"IMG_0012.JPG", "IMG_0013.JPG", ".bashrc"}},
+		{"capture group", Pattern{Find: `IMG_(\d+)`, Replace: "photo-$1"}, []string{"photo-0012.JPG", "photo-0013.JPG", ".bashrc"}},
+		{"counter", Pattern{Replace: "holiday {n:3}", Start: 1}, []string{"holiday 001.JPG", "holiday 002.JPG", "holiday 003"}},
+		{"date", Pattern{Replace: "{date:YYYYMMDD-hhmmss}_{name}"}, []string{"20230714-093005_IMG_0012.JPG", "20230714-093005_IMG_0013.JPG", "20230714-093005_.bashrc"}},
+		{"default date", Pattern{Find: "^IMG", Replace: "{date}"}, []string{"2023-07-14_0012.JPG", "2023-07-14_0013.JPG", ".bashrc"}},
+		{"date with words", Pattern{Find: "^IMG", Replace: "{date:DD Jan Mon PM}"}, []string{"14 Jan Mon PM_0012.JPG", "14 Jan Mon PM_0013.JPG", ".bashrc"}},
+		{"date with digits", Pattern{Find: "^IMG", Replace: "{date:YY 2 1 06 hh:mm}"}, []string{"23 2 1 06 09:30_0012.JPG", "23 2 1 06 09:30_0013.JPG", ".bashrc"}},
+		{"extension", Pattern{Ext: "jpeg"}, []string{"IMG_0012.jpeg", "IMG_0013.jpeg", ".bashrc.jpeg"}},
+		{"lower", Pattern{Case: CaseLower}, []string{"img_0012.jpg", "img_0013.jpg", ".bashrc"}},
+		{"title", Pattern{Find: "_", Replace: " ", Case: CaseTitle}, []string{"Img 0012.jpg", "Img 0013.jpg", ".Bashrc"}},
+		{"tokens in regex", Pattern{Find: `^`, Replace: "{n}-", Start: 7}, []string{"7-IMG_0012.JPG", "8-IMG_0013.JPG", "9-.bashrc"}},
+	}
+	for _, test := range tests {
+		names, err := test.pattern.Names(entries)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(names, test.exp) {
+			t.Errorf("%s: expected %q, got %q", test.name, test.exp, names)
+		}
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	t.Parallel()
+	entries := []Entry{{Name: "a"}}
+	for _, p := range []Pattern{{Find: "("}, {Replace: "{n:x}"}} {
+		if _, err := p.Names(entries); err == nil {
+			t.Errorf("expected an error for %+v", p)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+	entries := []Entry{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	names := []string{"b", "x", "x", "e"}
+	existing := []string{"a", "b", "c", "d", "e", "f"}
+	rows := Preview(entries, names, existing)
+	// a can take the name of b because b is renamed, but e exists and is not renamed
+	failed := []bool{false, true, true, true}
+	for i, row := range rows {
+		if (row.Err != nil) != failed[i] {
+			t.Errorf("%s → %s: expected failed %v, got %v", row.From, row.To, failed[i], row.Err)
+		}
+	}
+	if rows := Preview(entries[:1], []string{"a/b"}, existing); rows[0].Err == nil {
+		t.Error("expected an invalid name to be flagged")
+	}
+}
@@ -0,0 +1,217 @@
+// Package rename renames many files in a directory at once. The renames are ordered so
+// that no file is overwritten, and swaps and cycles such as a→b, b→a go through
+// temporary names.
+package rename
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/spf13/afero"
+)
+
+// Rename renames the entry named From to To. Both are names in the same directory.
+type Rename struct {
+	From string
+	To   string
+}
+
+// step is a single rename on disk. rename is the index of the Rename it is part of.
+type step struct {
+	from   string
+	to     string
+	rename int
+}
+
+// Parse reads the names edited by the user, one per line in the same order as names,
+// and returns the renames for the lines that changed. A trailing empty line is ignored.
+func Parse(names []string, edited string) ([]Rename, error) {
+	edited = strings.ReplaceAll(edited, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(edited, "\n"), "\n")
+	if edited == "" {
+		lines = nil
+	}
+	if len(lines) != len(names) {
+		return nil, fmt.Errorf("expected %d names but got %d lines. Lines must not be added or removed", len(names), len(lines))
+	}
+	renames := make([]Rename, 0, len(names))
+	for i, name := range names {
+		if lines[i] != name {
+			renames = append(renames, Rename{From: name, To: lines[i]})
+		}
+	}
+	return renames, nil
+}
+
+// Validate checks that every new name is a valid file name, that no two entries get the
+// same name and that no entry that is not renamed would be overwritten. existing lists
+// the names of all of the entries in the directory.
+func Validate(renames []Rename, existing []string) error {
+	from := make(map[string]struct{}, len(renames))
+	for _, r := range renames {
+		if _, ok := from[r.From]; ok {
+			return fmt.Errorf("%s is renamed twice", r.From)
+		}
+		from[r.From] = struct{}{}
+	}
+	to := make(map[string]string, len(renames))
+	for _, r := range renames {
+		if err := entry.InvalidFilename(r.To); err != nil {
+			return fmt.Errorf("%q: %w", r.To, err)
+		}
+		if r.To == "." || r.To == ".." {
+			return fmt.Errorf("%q is not a valid name", r.To)
+		}
+		if other, ok := to[r.To]; ok {
+			return fmt.Errorf("%s and %s would both be named %s", other, r.From, r.To)
+		}
+		to[r.To] = r.From
+	}
+	for _, name := range existing {
+		if _, renamed := from[name]; renamed {
+			continue
+		}
+		if source, ok := to[name]; ok {
+			return fmt.Errorf("renaming %s to %s would overwrite an existing entry", source, name)
+		}
+	}
+	return nil
+}
+
+// order returns the steps that perform the renames without overwriting each other.
+// A rename is done once its new name is no longer in use. When only cycles are left,
+// one entry of a cycle is first moved to a temporary name. taken reports whether a
+// name is used by an entry that is not renamed.
+func order(renames []Rename, taken func(string) bool) []step {
+	// current name of each rename that has not been done yet
+	pending := make([]string, len(renames))
+	inUse := make(map[string]int, len(renames)) // pending name to the index of its rename
+	for i, r := range renames {
+		if r.From == r.To {
+			continue
+		}
+		pending[i] = r.From
+		inUse[r.From] = i
+	}
+	steps := make([]step, 0, len(renames))
+	tmp := 0
+	for len(inUse) > 0 {
+		progressed := false
+		for i, r := range renames {
+			if pending[i] == "" {
+				continue
+			}
+			if _, blocked := inUse[r.To]; blocked {
+				continue
+			}
+			steps = append(steps, step{from: pending[i], to: r.To, rename: i})
+			delete(inUse, pending[i])
+			pending[i] = ""
+			progressed = true
+		}
+		if progressed {
+			continue
+		}
+		// every pending rename waits on another, so break a cycle with a temporary name
+		for i := range renames {
+			if pending[i] == "" {
+				continue
+			}
+			var name string
+			for {
+				tmp++
+				name = fmt.Sprintf(".fman-rename-%d-%s", tmp, renames[i].From)
+				if _, used := inUse[name]; !used && !taken(name) {
+					break
+				}
+			}
+			steps = append(steps, step{from: pending[i], to: name, rename: i})
+			delete(inUse, pending[i])
+			pending[i] = name
+			inUse[name] = i
+			break
+		}
+	}
+	return steps
+}
+
+// Apply performs the renames in dir. The renames must have been validated. It returns one
+// error for each rename, which is nil if the rename succeeded. If an entry was moved to a
+// temporary name and could not be given its new name, it is moved back to its old name
+// unless that name was taken in the meantime, in which case it is left at the temporary name.
+func Apply(fsys afero.Fs, dir string, renames []Rename) []error {
+	errs := make([]error, len(renames))
+	taken := func(name string) bool {
+		ok, err := afero.Exists(fsys, filepath.Join(dir, name))
+		return ok || err != nil
+	}
+	steps := order(renames, taken)
+	stuck := make(map[int]string) // renames left at a temporary name
+	for _, s := range steps {
+		if errs[s.rename] != nil {
+			continue
+		}
+		// a rename that failed earlier can leave an entry where another one is moved to.
+		// Names that only differ in case are the same entry on case insensitive filesystems.
+		if !strings.EqualFold(s.from, s.to) && taken(s.to) {
+			errs[s.rename] = fmt.Errorf("%s: %s already exists", renames[s.rename].From, s.to)
+			continue
+		}
+		if err := fsys.Rename(filepath.Join(dir, s.from), filepath.Join(dir, s.to)); err != nil {
+			errs[s.rename] = fmt.Errorf("%s: %w", renames[s.rename].From, err)
+			continue
+		}
+		delete(stuck, s.rename)
+		if s.to != renames[s.rename].To {
+			stuck[s.rename] = s.to
+		}
+	}
+	for i, name := range stuck {
+		if taken(renames[i].From) {
+			errs[i] = fmt.Errorf("%s was left at %s because %s exists: %w", renames[i].From, name, renames[i].From, errs[i])
+			continue
+		}
+		if err := fsys.Rename(filepath.Join(dir, name), filepath.Join(dir, renames[i].From)); err != nil {
+			errs[i] = fmt.Errorf("%s was left at %s: %w, and moving it back failed: %w", renames[i].From, name, errs[i], err)
+		}
+	}
+	return errs
+}
+
+// ApplyAll performs the renames in dir as one batch: if any rename fails, the renames that
+// succeeded are undone so that either all of the entries or none of them are renamed.
+// It returns the renames that were done.
+func ApplyAll(fsys afero.Fs, dir string, renames []Rename) ([]Rename, error) {
+	var done []Rename
+	var failed []error
+	for i, err := range Apply(fsys, dir, renames) {
+		if err != nil {
+			failed = append(failed, err)
+			continue
+		}
+		done = append(done, renames[i])
+	}
+	if len(failed) == 0 {
+		return done, nil
+	}
+	var kept []Rename
+	for i, err := range Apply(fsys, dir, Reverse(done)) {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("could not undo: %w", err))
+			kept = append(kept, done[i])
+		}
+	}
+	return kept, errors.Join(failed...)
+}
+
+// Reverse returns the renames that undo the renames
+func Reverse(renames []Rename) []Rename {
+	reversed := make([]Rename, len(renames))
+	for i, r := range renames {
+		reversed[i] = Rename{From: r.To, To: r.From}
+	}
+	return reversed
+}
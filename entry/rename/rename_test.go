@@ -0,0 +1,224 @@
+package rename
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	names := []string{"a", "b", "c"}
+	renames, err := Parse(names, "a\nB\nd\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Rename{{"b", "B"}, {"c", "d"}}
+	if !reflect.DeepEqual(renames, expected) {
+		t.Errorf("expected %v, got %v", expected, renames)
+	}
+	if _, err := Parse(names, "a\nb\n"); err == nil {
+		t.Error("expected an error when a line is removed")
+	}
+	if _, err := Parse(names, "a\r\nb\r\nc\r\n"); err != nil {
+		t.Errorf("expected windows line endings to be accepted, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	existing := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name    string
+		renames []Rename
+		valid   bool
+	}{
+		{"swap", []Rename{{"a", "b"}, {"b", "a"}}, true},
+		{"duplicate", []Rename{{"a", "x"}, {"b", "x"}}, false},
+		{"overwrite", []Rename{{"a", "c"}}, false},
+		{"empty", []Rename{{"a", ""}}, false},
+		{"separator", []Rename{{"a", "x/y"}}, false},
+		{"dot dot", []Rename{{"a", ".."}}, false},
+		{"chain", []Rename{{"a", "b"}, {"b", "c"}, {"c", "x"}}, true},
+	}
+	for _, test := range tests {
+		err := Validate(test.renames, existing)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func testFs(names ...string) afero.Fs {
+	fsys := afero.NewMemMapFs()
+	for _, name := range names {
+		afero.WriteFile(fsys, filepath.Join("/dir", name), []byte(name), 0644)
+	}
+	return fsys
+}
+
+// contents returns the content of each file in the directory by name
+func contents(t *testing.T, fsys afero.Fs) map[string]string {
+	t.Helper()
+	infos, err := afero.ReadDir(fsys, "/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string, len(infos))
+	for _, info := range infos {
+		content, _ := afero.ReadFile(fsys, filepath.Join("/dir", info.Name()))
+		got[info.Name()] = string(content)
+	}
+	return got
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		files   []string
+		renames []Rename
+		exp     map[string]string
+	}{
+		{
+			name:    "swap",
+			files:   []string{"a", "b"},
+			renames: []Rename{{"a", "b"}, {"b", "a"}},
+			exp:     map[string]string{"a": "b", "b": "a"},
+		},
+		{
+			name:    "cycle",
+			files:   []string{"a", "b", "c"},
+			renames: []Rename{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+			exp:     map[string]string{"a": "c", "b": "a", "c": "b"},
+		},
+		{
+			name:    "chain",
+			files:   []string{"a", "b"},
+			renames: []Rename{{"a", "b"}, {"b", "c"}},
+			exp:     map[string]string{"b": "a", "c": "b"},
+		},
+		{
+			name:    "cycle and chain",
+			files:   []string{"a", "b", "c"},
+			renames: []Rename{{"a", "b"}, {"b", "a"}, {"c", "d"}},
+			exp:     map[string]string{"a": "b", "b": "a", "d": "c"},
+		},
+	}
+	for _, test := range tests {
+		fsys := testFs(test.files...)
+		for i, err := range Apply(fsys, "/dir", test.renames) {
+			if err != nil {
+				t.Errorf("%s: rename %d failed: %v", test.name, i, err)
+			}
+		}
+		if got := contents(t, fsys); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.exp, got)
+		}
+	}
+}
+
+func TestApplyReverse(t *testing.T) {
+	t.Parallel()
+	fsys := testFs("a", "b", "c")
+	renames := []Rename{{"a", "b"}, {"b", "c"}, {"c", "a"}}
+	Apply(fsys, "/dir", renames)
+	Apply(fsys, "/dir", Reverse(renames))
+	exp := map[string]string{"a": "a", "b": "b", "c": "c"}
+	if got := contents(t, fsys); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected the renames to be undone, got %v", got)
+	}
+}
+
+// failFs fails to rename one file. If create is set, a file is created there when the
+// rename fails, as if by another program.
+type failFs struct {
+	afero.Fs
+	fail   string
+	create string
+}
+
+func (f failFs) Rename(oldname, newname string) error {
+	if oldname == f.fail {
+		if f.create != "" {
+			afero.WriteFile(f.Fs, f.create, []byte("new"), 0o644)
+		}
+		return errors.New("permission denied")
+	}
+	return f.Fs.Rename(oldname, newname)
+}
+
+func TestApplyFailure(t *testing.T) {
+	t.Parallel()
+	// b cannot be renamed, so a must not be moved onto it and is put back
+	fsys := failFs{Fs: testFs("a", "b", "x"), fail: "/dir/b"}
+	errs := Apply(fsys, "/dir", []Rename{{"a", "b"}, {"b", "a"}, {"x", "y"}})
+	if errs[0] == nil || errs[1] == nil || errs[2] != nil {
+		t.Fatalf("expected the swap to fail and the other rename to succeed, got %v", errs)
+	}
+	if !strings.Contains(errs[1].Error(), "permission denied") {
+		t.Errorf("unexpected error %v", errs[1])
+	}
+	exp := map[string]string{"a": "a", "b": "b", "y": "x"}
+	if got := contents(t, fsys); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestApplyRollback(t *testing.T) {
+	t.Parallel()
+	// a is moved to a temporary name, then a new a is created, so a is not moved back
+	fsys := failFs{Fs: testFs("a", "b"), fail: "/dir/b", create: "/dir/a"}
+	errs := Apply(fsys, "/dir", []Rename{{"a", "b"}, {"b", "a"}})
+	if errs[0] == nil || !strings.Contains(errs[0].Error(), "because a exists") {
+		t.Fatalf("expected a to be left at its temporary name, got %v", errs[0])
+	}
+	exp := map[string]string{".fman-rename-1-a": "a", "a": "new", "b": "b"}
+	if got := contents(t, fsys); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+
+	// moving a back fails, so both errors are reported
+	fsys = failFs{Fs: testFs("a", "b"), fail: "/dir/b"}
+	errs = Apply(moveBackFs{fsys}, "/dir", []Rename{{"a", "b"}, {"b", "a"}})
+	if errs[0] == nil || !strings.Contains(errs[0].Error(), "already exists") || !strings.Contains(errs[0].Error(), "read only") {
+		t.Errorf("expected the rename and the rollback errors, got %v", errs[0])
+	}
+}
+
+// moveBackFs fails to move entries back from their temporary name
+type moveBackFs struct {
+	afero.Fs
+}
+
+func (f moveBackFs) Rename(oldname, newname string) error {
+	if strings.Contains(oldname, ".fman-rename-") && filepath.Base(newname) == "a" {
+		return errors.New("read only")
+	}
+	return f.Fs.Rename(oldname, newname)
+}
+
+func TestApplyAll(t *testing.T) {
+	t.Parallel()
+	// x is renamed before b fails, so x is renamed back
+	fsys := failFs{Fs: testFs("a", "b", "x"), fail: "/dir/b"}
+	done, err := ApplyAll(fsys, "/dir", []Rename{{"x", "y"}, {"b", "c"}})
+	if err == nil || len(done) != 0 {
+		t.Fatalf("expected the batch to fail and be undone, got %v, %v", done, err)
+	}
+	exp := map[string]string{"a": "a", "b": "b", "x": "x"}
+	if got := contents(t, fsys); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+
+	fsys = failFs{Fs: testFs("a", "b"), fail: "/dir/none"}
+	renames := []Rename{{"a", "b"}, {"b", "a"}}
+	done, err = ApplyAll(fsys, "/dir", renames)
+	if err != nil || !reflect.DeepEqual(done, renames) {
+		t.Errorf("expected all renames to be done, got %v, %v", done, err)
+	}
+}
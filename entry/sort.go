@@ -1,6 +1,7 @@
 package entry
 
 import (
+	"fmt"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -19,10 +20,100 @@ const (
 	ExtSort
 )
 
+var sortMethodNames = [...]string{
+	NaturalSort: "natural",
+	NameSort:    "name",
+	SizeSort:    "size",
+	MtimeSort:   "mtime",
+	ExtSort:     "ext",
+}
+
+// ParseSortMethod returns the sort method with the given name. The names
+// are natural, name, size, mtime and ext.
+func ParseSortMethod(name string) (SortMethod, error) {
+	for i, n := range sortMethodNames {
+		if strings.EqualFold(n, name) {
+			return SortMethod(i), nil
+		}
+	}
+	return NaturalSort, fmt.Errorf("unknown sort method %q", name)
+}
+
+func (m SortMethod) String() string {
+	if int(m) < len(sortMethodNames) {
+		return sortMethodNames[m]
+	}
+	return "unknown"
+}
+
+// Next returns the sort method that follows m, wrapping around to NaturalSort.
+func (m SortMethod) Next() SortMethod {
+	return (m + 1) % SortMethod(len(sortMethodNames))
+}
+
+// MarshalText implements encoding.TextMarshaler so sort methods are stored by name
+func (m SortMethod) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (m *SortMethod) UnmarshalText(text []byte) error {
+	method, err := ParseSortMethod(string(text))
+	if err != nil {
+		return err
+	}
+	*m = method
+	return nil
+}
+
+// SortSpec describes the user facing options for sorting the entries of a directory
+type SortSpec struct {
+	Method           SortMethod `json:"method"`
+	Column           string     `json:"column,omitempty"` // id of a column to sort on instead of the method
+	Reverse          bool       `json:"reverse"`
+	DirsFirst        bool       `json:"dirsFirst"`
+	IgnoreCase       bool       `json:"ignoreCase"`
+	IgnoreDiacritics bool       `json:"ignoreDiacritics"`
+}
+
+// DefaultSortSpec returns the sort used when nothing else is configured
+func DefaultSortSpec() SortSpec {
+	return SortSpec{
+		Method:           NaturalSort,
+		DirsFirst:        true,
+		IgnoreCase:       true,
+		IgnoreDiacritics: true,
+	}
+}
+
+func (s SortSpec) order(showHidden bool) SortOrder {
+	var column *Column
+	if c, ok := LookupColumn(s.Column); ok && c.less != nil {
+		column = &c
+	}
+	return SortOrder{
+		column:     column,
+		method:     s.Method,
+		dirsFirst:  s.DirsFirst,
+		showHidden: showHidden,
+		reverse:    s.Reverse,
+		ignoreDiac: s.IgnoreDiacritics,
+		ignoreCase: s.IgnoreCase,
+	}
+}
+
+// Filter limits the entries of a directory that are listed
+type Filter struct {
+	DirsOnly bool     // list only directories
+	Patterns []string // list only the files matching one of the patterns, see MatchPattern. Directories are always listed
+}
+
 type SortOrder struct {
 	method     SortMethod
+	column     *Column // if set, entries are sorted on the column instead of with the method
 	dirsFirst  bool
 	dirsOnly   bool
+	patterns   []string // if set, files must match one of the patterns
 	showHidden bool
 	reverse    bool
 	ignoreDiac bool
@@ -44,44 +135,10 @@ func normalize(s1, s2 string, ignorecase, ignoredia bool) (string, string) {
 // sortEntries sorts the entries according to the given sortType.
 func sortEntries(dirPath string, entries []Entry, sortT SortOrder) []Entry {
 	ignorecase, ignoredia := sortT.ignoreCase, sortT.ignoreDiac
-	switch sortT.method {
-	case NaturalSort:
-		sort.SliceStable(entries, func(i, j int) bool {
-			s1, s2 := normalize(entries[i].Name(), entries[j].Name(), ignorecase, ignoredia)
-			return naturalLess(s1, s2)
-		})
-	case NameSort:
-		sort.SliceStable(entries, func(i, j int) bool {
-			s1, s2 := normalize(entries[i].Name(), entries[j].Name(), ignorecase, ignoredia)
-			return s1 < s2
-		})
-	case SizeSort:
-		sort.SliceStable(entries, func(i, j int) bool {
-			return entries[i].SizeInt < entries[j].SizeInt
-		})
-	case MtimeSort:
-		sort.SliceStable(entries, func(i, j int) bool {
-			return entries[i].ModTime().Before(entries[j].ModTime())
-		})
-	case ExtSort:
-		sort.SliceStable(entries, func(i, j int) bool {
-			ext1, ext2 := normalize(filepath.Ext(entries[i].Name()), filepath.Ext(entries[j].Name()), ignorecase, ignoredia)
-
-			// if the extension could not be determined (directories, files without)
-			// use a zero byte so that these files can be ranked higher
-			if ext1 == "" {
-				ext1 = "\x00"
-			}
-			if ext2 == "" {
-				ext2 = "\x00"
-			}
-
-			name1, name2 := normalize(entries[i].Name(), entries[j].Name(), ignorecase, ignoredia)
-
-			// in order to also have natural sorting with the filenames
-			// combine the name with the ext but have the ext at the front
-			return ext1 < ext2 || ext1 == ext2 && name1 < name2
-		})
+	if sortT.column != nil {
+		sortByColumn(dirPath, entries, *sortT.column)
+	} else {
+		sortByMethod(entries, sortT.method, ignorecase, ignoredia)
 	}
 
 	if sortT.dirsFirst {
@@ -121,6 +178,18 @@ func sortEntries(dirPath string, entries []Entry, sortT SortOrder) []Entry {
 		}()
 	}
 
+	// when patterns are set, files matching none of them are left out. The mime type
+	// is guessed from the extension, as reading every file would be too slow
+	if len(sortT.patterns) > 0 {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.IsDir() || matchAny(sortT.patterns, e.Name(), e.MimeType) {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+
 	// when hidden option is disabled, we move hidden files to the
 	// beginning of our file list and then set the beginning of displayed
 	// files to the first non-hidden file in the list
@@ -145,6 +214,49 @@ func sortEntries(dirPath string, entries []Entry, sortT SortOrder) []Entry {
 	return entries
 }
 
+// sortByMethod sorts the entries with one of the built-in sort methods
+func sortByMethod(entries []Entry, method SortMethod, ignorecase, ignoredia bool) {
+	switch method {
+	case NaturalSort:
+		sort.SliceStable(entries, func(i, j int) bool {
+			s1, s2 := normalize(entries[i].Name(), entries[j].Name(), ignorecase, ignoredia)
+			return naturalLess(s1, s2)
+		})
+	case NameSort:
+		sort.SliceStable(entries, func(i, j int) bool {
+			s1, s2 := normalize(entries[i].Name(), entries[j].Name(), ignorecase, ignoredia)
+			return s1 < s2
+		})
+	case SizeSort:
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].SizeInt < entries[j].SizeInt
+		})
+	case MtimeSort:
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].ModTime().Before(entries[j].ModTime())
+		})
+	case ExtSort:
+		sort.SliceStable(entries, func(i, j int) bool {
+			ext1, ext2 := normalize(filepath.Ext(entries[i].Name()), filepath.Ext(entries[j].Name()), ignorecase, ignoredia)
+
+			// if the extension could not be determined (directories, files without)
+			// use a zero byte so that these files can be ranked higher
+			if ext1 == "" {
+				ext1 = "\x00"
+			}
+			if ext2 == "" {
+				ext2 = "\x00"
+			}
+
+			name1, name2 := normalize(entries[i].Name(), entries[j].Name(), ignorecase, ignoredia)
+
+			// in order to also have natural sorting with the filenames
+			// combine the name with the ext but have the ext at the front
+			return ext1 < ext2 || ext1 == ext2 && name1 < name2
+		})
+	}
+}
+
 func isDigit(b byte) bool {
 	return '0' <= b && b <= '9'
 }
@@ -189,3 +301,13 @@ func naturalLess(s1, s2 string) bool {
 		return s1[lo1:hi1] < s2[lo2:hi2]
 	}
 }
+
+// matchAny reports whether the file matches one of the patterns
+func matchAny(patterns []string, name string, mimeType string) bool {
+	for _, pattern := range patterns {
+		if MatchPattern(pattern, name, mimeType) {
+			return true
+		}
+	}
+	return false
+}
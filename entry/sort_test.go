@@ -250,3 +250,26 @@ func TestNaturalLess(t *testing.T) {
 		}
 	}
 }
+
+func TestSortMethodText(t *testing.T) {
+	t.Parallel()
+	for m := NaturalSort; m <= ExtSort; m++ {
+		text, err := m.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got SortMethod
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if got != m {
+			t.Errorf("round trip of %v returned %v", m, got)
+		}
+	}
+	if _, err := ParseSortMethod("bingo"); err == nil {
+		t.Error("expected an error for an unknown sort method")
+	}
+	if ExtSort.Next() != NaturalSort {
+		t.Errorf("expected sort methods to wrap around, got %v", ExtSort.Next())
+	}
+}
@@ -0,0 +1,23 @@
+//go:build darwin || freebsd || netbsd
+
+package entry
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func accessTime(f os.FileInfo) (time.Time, bool) {
+	if stat, ok := f.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Atimespec.Unix()), true
+	}
+	return time.Time{}, false
+}
+
+func creationTime(_ string, f os.FileInfo) (time.Time, bool) {
+	if stat, ok := f.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Birthtimespec.Unix()), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,32 @@
+package entry
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func accessTime(f os.FileInfo) (time.Time, bool) {
+	if stat, ok := f.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Atim.Unix()), true
+	}
+	return time.Time{}, false
+}
+
+// creationTime uses statx because the birth time is not part of stat on linux.
+// Not every filesystem records it.
+func creationTime(path string, f os.FileInfo) (time.Time, bool) {
+	if _, ok := f.Sys().(*syscall.Stat_t); !ok {
+		return time.Time{}, false
+	}
+	var stat unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, 0, unix.STATX_BTIME, &stat); err != nil {
+		return time.Time{}, false
+	}
+	if stat.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(stat.Btime.Sec, int64(stat.Btime.Nsec)), true
+}
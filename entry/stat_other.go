@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !windows
+
+package entry
+
+import (
+	"os"
+	"time"
+)
+
+// Access and creation times are not read on the remaining platforms
+// because their stat structs differ.
+
+func accessTime(f os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func creationTime(_ string, f os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Mount is a mounted filesystem and its space
+type Mount struct {
+	Mountpoint string
+	Device     string
+	Fstype     string
+	StorageInfo
+}
+
+// UsedSpace returns the number of bytes in use on the filesystem
+func (m Mount) UsedSpace() uint64 {
+	if m.FreeSpace > m.TotalSpace {
+		return 0
+	}
+	return m.TotalSpace - m.FreeSpace
+}
+
+// pseudoFilesystems are kernel and virtual filesystems that do not store files
+var pseudoFilesystems = map[string]struct{}{
+	"autofs":      {},
+	"binfmt_misc": {},
+	"bpf":         {},
+	"cgroup":      {},
+	"cgroup2":     {},
+	"configfs":    {},
+	"debugfs":     {},
+	"devfs":       {},
+	"devpts":      {},
+	"devtmpfs":    {},
+	"efivarfs":    {},
+	"fdescfs":     {},
+	"fusectl":     {},
+	"hugetlbfs":   {},
+	"mqueue":      {},
+	"nsfs":        {},
+	"nullfs":      {},
+	"proc":        {},
+	"procfs":      {},
+	"pstore":      {},
+	"rpc_pipefs":  {},
+	"securityfs":  {},
+	"selinuxfs":   {},
+	"sysfs":       {},
+	"tracefs":     {},
+}
+
+// IsPseudo reports whether fstype is a kernel or virtual filesystem such as proc or sysfs
+func IsPseudo(fstype string) bool {
+	_, ok := pseudoFilesystems[fstype]
+	return ok
+}
+
+// GetMounts returns the mounted filesystems that store files, in the order they
+// were mounted. Pseudo filesystems and filesystems without any space are left out.
+// When a mountpoint is mounted over, only the last mount is returned.
+func GetMounts() ([]Mount, error) {
+	parts, err := disk.Partitions(true)
+	if err != nil {
+		return nil, err
+	}
+	mounts := make([]Mount, 0, len(parts))
+	index := make(map[string]int, len(parts))
+	for _, part := range parts {
+		if IsPseudo(part.Fstype) {
+			continue
+		}
+		info, err := GetStorageInfo(part.Mountpoint)
+		if err != nil || info.TotalSpace == 0 {
+			continue
+		}
+		mount := Mount{
+			Mountpoint:  part.Mountpoint,
+			Device:      part.Device,
+			Fstype:      part.Fstype,
+			StorageInfo: info,
+		}
+		if i, ok := index[mount.Mountpoint]; ok {
+			mounts[i] = mount
+			continue
+		}
+		index[mount.Mountpoint] = len(mounts)
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
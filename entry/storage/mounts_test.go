@@ -0,0 +1,43 @@
+package storage
+
+import "testing"
+
+func TestIsPseudo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fstype string
+		exp    bool
+	}{
+		{"proc", true},
+		{"sysfs", true},
+		{"cgroup2", true},
+		{"ext4", false},
+		{"tmpfs", false},
+		{"ntfs", false},
+	}
+	for _, test := range tests {
+		if got := IsPseudo(test.fstype); got != test.exp {
+			t.Errorf("IsPseudo(%q) = %v; want %v", test.fstype, got, test.exp)
+		}
+	}
+}
+
+// The mounts depend on the machine, so only check that the returned mounts are sane
+func TestGetMounts(t *testing.T) {
+	t.Parallel()
+	mounts, err := GetMounts()
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, mount := range mounts {
+		if IsPseudo(mount.Fstype) {
+			t.Errorf("pseudo filesystem %s at %s was returned", mount.Fstype, mount.Mountpoint)
+		}
+		if mount.TotalSpace == 0 {
+			t.Errorf("mount %s has no space", mount.Mountpoint)
+		}
+		if mount.UsedSpace()+mount.FreeSpace != mount.TotalSpace {
+			t.Errorf("mount %s: used %d + free %d != total %d", mount.Mountpoint, mount.UsedSpace(), mount.FreeSpace, mount.TotalSpace)
+		}
+	}
+}
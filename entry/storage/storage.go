@@ -4,7 +4,6 @@
 package storage
 
 import (
-	"os"
 	"syscall"
 )
 
@@ -14,16 +13,10 @@ type StorageInfo struct {
 	AvailableSpace uint64
 }
 
-func GetStorageInfo() (info StorageInfo, err error) {
+// GetStorageInfo returns the space of the filesystem that path is on
+func GetStorageInfo(path string) (info StorageInfo, err error) {
 	fs := syscall.Statfs_t{}
-
-	var dir string
-	dir, err = os.Getwd()
-	if err != nil {
-		return
-	}
-
-	if err = syscall.Statfs(dir, &fs); err != nil {
+	if err = syscall.Statfs(path, &fs); err != nil {
 		return
 	}
 
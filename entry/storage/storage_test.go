@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package storage
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+	info, err := GetStorageInfo(os.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FreeSpace > info.TotalSpace || info.AvailableSpace > info.FreeSpace {
+		t.Errorf("unexpected space %+v", info)
+	}
+	if _, err := GetStorageInfo("/does/not/exist"); err == nil {
+		t.Error("expected an error for a path that does not exist")
+	}
+}
@@ -14,11 +14,12 @@ type StorageInfo struct {
 	AvailableSpace uint64
 }
 
-func GetStorageInfo() (StorageInfo, error) {
+// GetStorageInfo returns the space of the filesystem that path is on
+func GetStorageInfo(path string) (StorageInfo, error) {
 	dll := windows.NewLazyDLL("kernel32.dll")
 	proc := dll.NewProc("GetDiskFreeSpaceExW")
 	info := StorageInfo{}
-	_, _, err := proc.Call(uintptr(unsafe.Pointer(windows.StringToUTF16Ptr("."))),
+	_, _, err := proc.Call(uintptr(unsafe.Pointer(windows.StringToUTF16Ptr(path))),
 		uintptr(unsafe.Pointer(&info.AvailableSpace)),
 		uintptr(unsafe.Pointer(&info.TotalSpace)),
 		uintptr(unsafe.Pointer(&info.FreeSpace)))
@@ -8,7 +8,7 @@ import "testing"
 // It's hard to programmatically test disk space
 func TestStorage(t *testing.T) {
 	t.Parallel()
-	_, err := GetStorageInfo()
+	_, err := GetStorageInfo(".")
 	if err != nil {
 		t.Error(err)
 	}
@@ -0,0 +1,111 @@
+package entry
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// TailState records how much of a followed file has been read.
+type TailState struct {
+	Path   string
+	Offset int64       // number of bytes of the file that have been read
+	info   fs.FileInfo // info of the file when it was last read, used to detect rotation
+}
+
+// Tail reads the last maxBytes of the file at path. If the file is larger than maxBytes
+// the first, likely partial, line is dropped. The returned state can be passed to
+// ReadAppended to follow the file.
+func Tail(fsys afero.Fs, path string, maxBytes int64) (string, TailState, error) {
+	state := TailState{Path: path}
+	file, err := fsys.Open(path)
+	if err != nil {
+		return "", state, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", state, err
+	}
+
+	start := info.Size() - maxBytes
+	if start < 0 {
+		start = 0
+	}
+	content, err := readRange(file, start, info.Size())
+	if err != nil {
+		return "", state, err
+	}
+	if start > 0 {
+		if i := strings.IndexByte(content, '\n'); i != -1 {
+			content = content[i+1:]
+		}
+	}
+	state.Offset = info.Size()
+	state.info = info
+	return cleanTail(content), state, nil
+}
+
+// ReadAppended reads the data appended to the file since it was last read. If the file
+// was truncated or replaced by a new file (e.g., by log rotation), the tail of the file
+// is read again and reset is true, in which case the returned data replaces what was
+// read before rather than being appended to it.
+func ReadAppended(fsys afero.Fs, state TailState, maxBytes int64) (data string, next TailState, reset bool, err error) {
+	info, err := fsys.Stat(state.Path)
+	if err != nil {
+		return "", state, false, err
+	}
+	if info.Size() < state.Offset || rotated(state.info, info) {
+		data, next, err = Tail(fsys, state.Path, maxBytes)
+		return data, next, true, err
+	}
+	if info.Size() == state.Offset {
+		state.info = info
+		return "", state, false, nil
+	}
+
+	file, err := fsys.Open(state.Path)
+	if err != nil {
+		return "", state, false, err
+	}
+	defer file.Close()
+
+	start := state.Offset
+	if info.Size()-start > maxBytes {
+		// too much was appended to show it all so start over from the tail
+		data, next, err = Tail(fsys, state.Path, maxBytes)
+		return data, next, true, err
+	}
+	content, err := readRange(file, start, info.Size())
+	if err != nil {
+		return "", state, false, err
+	}
+	state.Offset = start + int64(len(content))
+	state.info = info
+	return cleanTail(content), state, false, nil
+}
+
+// rotated returns true if the file described by cur is not the file described by prev.
+// This can only be detected for files on the os filesystem.
+func rotated(prev, cur fs.FileInfo) bool {
+	if prev == nil || prev.Sys() == nil || cur.Sys() == nil {
+		return false
+	}
+	return !os.SameFile(prev, cur)
+}
+
+func readRange(file afero.File, start, end int64) (string, error) {
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return "", err
+	}
+	buf, err := io.ReadAll(io.LimitReader(file, end-start))
+	return string(buf), err
+}
+
+// cleanTail prepares the text read from a followed file for display
+func cleanTail(s string) string {
+	return strings.ReplaceAll(ExpandTabs(s), "\r\n", "\n")
+}
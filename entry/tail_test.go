@@ -0,0 +1,97 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTail(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/log", []byte("first line\nsecond line\nthird line\n"), 0644)
+
+	got, state, err := Tail(fsys, "/log", 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the partial second line is dropped
+	if got != "third line\n" {
+		t.Errorf("got %q; want %q", got, "third line\n")
+	}
+	if state.Offset != 34 {
+		t.Errorf("got offset %d; want 34", state.Offset)
+	}
+
+	got, _, err = Tail(fsys, "/log", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "first line\nsecond line\nthird line\n" {
+		t.Errorf("got %q for whole file", got)
+	}
+}
+
+func TestReadAppended(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/log", []byte("a\n"), 0644)
+
+	// reading from the zero state reads the file
+	data, state, reset, err := ReadAppended(fsys, TailState{Path: "/log"}, 100)
+	if err != nil || data != "a\n" || reset {
+		t.Fatalf("initial read = %q, %v, %v", data, reset, err)
+	}
+
+	data, state, reset, err = ReadAppended(fsys, state, 100)
+	if err != nil || data != "" || reset {
+		t.Fatalf("read without changes = %q, %v, %v", data, reset, err)
+	}
+
+	file, _ := fsys.OpenFile("/log", os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("b\tc\n")
+	file.Close()
+	data, state, reset, err = ReadAppended(fsys, state, 100)
+	if err != nil || data != "b    c\n" || reset {
+		t.Fatalf("appended read = %q, %v, %v", data, reset, err)
+	}
+
+	// truncation starts over
+	afero.WriteFile(fsys, "/log", []byte("z\n"), 0644)
+	data, _, reset, err = ReadAppended(fsys, state, 100)
+	if err != nil || data != "z\n" || !reset {
+		t.Fatalf("read after truncation = %q, %v, %v", data, reset, err)
+	}
+}
+
+func TestReadAppendedRotation(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fsys := afero.NewOsFs()
+	_, state, err := Tail(fsys, path, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate the log and write more to the new file than the old one had
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new 1\nnew 2\nnew 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, _, reset, err := ReadAppended(fsys, state, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reset || !strings.HasPrefix(data, "new 1") {
+		t.Errorf("read after rotation = %q, reset %v; want the new file", data, reset)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build darwin
+
+package trash
+
+import "path/filepath"
+
+// Home returns the trash in the home directory that the Finder uses
+func Home(home string) (Bin, error) {
+	return Bin{Dir: filepath.Join(home, ".Trash")}, nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !darwin
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Home returns the trash in the home directory, as described by the freedesktop.org
+// trash specification
+func Home(home string) (Bin, error) {
+	data := os.Getenv("XDG_DATA_HOME")
+	if data == "" {
+		data = filepath.Join(home, ".local", "share")
+	}
+	return Bin{Dir: filepath.Join(data, "Trash"), Info: true}, nil
+}
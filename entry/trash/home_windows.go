@@ -0,0 +1,8 @@
+//go:build windows
+
+package trash
+
+// Home returns ErrUnsupported. The recycle bin can only be used through the shell api.
+func Home(home string) (Bin, error) {
+	return Bin{}, ErrUnsupported
+}
@@ -0,0 +1,120 @@
+// Package trash moves files to the trash instead of deleting them, so that they can be
+// restored with the desktop's file manager.
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Philistino/fman/entry/fileutils"
+	"github.com/spf13/afero"
+)
+
+// ErrUnsupported is returned on systems without a trash that files can be moved to
+var ErrUnsupported = errors.New("moving to the trash is not supported on this system")
+
+// Bin is a trash directory
+type Bin struct {
+	Dir  string // directory the trashed files are moved into
+	Info bool   // use the freedesktop.org layout, with files/ and info/ directories and a .trashinfo file for each trashed file
+}
+
+// Put moves the file or directory at path into the trash and returns its path in the trash.
+// If a file with the same name is already in the trash, a number is added to the name.
+func (b Bin) Put(fsys afero.Fs, path string, now time.Time) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fsys.Stat(path); err != nil {
+		return "", err
+	}
+	filesDir, infoDir := b.Dir, ""
+	if b.Info {
+		filesDir = filepath.Join(b.Dir, "files")
+		infoDir = filepath.Join(b.Dir, "info")
+		if err := fsys.MkdirAll(infoDir, 0700); err != nil {
+			return "", err
+		}
+	}
+	if err := fsys.MkdirAll(filesDir, 0700); err != nil {
+		return "", err
+	}
+
+	name, infoFile, err := b.reserve(fsys, filesDir, infoDir, filepath.Base(path), path, now)
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(filesDir, name)
+	if err := move(fsys, path, dst); err != nil {
+		if infoFile != "" {
+			fsys.Remove(infoFile)
+		}
+		return "", err
+	}
+	return dst, nil
+}
+
+// reserve finds a name that is free in the trash. With the freedesktop.org layout the name
+// is claimed by creating its .trashinfo file, which is returned.
+func (b Bin) reserve(fsys afero.Fs, filesDir, infoDir, base, path string, now time.Time) (string, string, error) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		stem, ext = base, ""
+	}
+	for i := 1; i < 10000; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+		if _, err := fsys.Stat(filepath.Join(filesDir, name)); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if !b.Info {
+			return name, "", nil
+		}
+		infoFile := filepath.Join(infoDir, name+".trashinfo")
+		f, err := fsys.OpenFile(infoFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		_, err = f.WriteString(info(path, now))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fsys.Remove(infoFile)
+			return "", "", err
+		}
+		return name, infoFile, nil
+	}
+	return "", "", fmt.Errorf("no free name for %s in the trash", base)
+}
+
+// info returns the contents of the .trashinfo file of a trashed file
+func info(path string, now time.Time) string {
+	escaped := (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+	return fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", escaped, now.Format("2006-01-02T15:04:05"))
+}
+
+// move renames the file into the trash, copying it when the trash is on another filesystem
+func move(fsys afero.Fs, src, dst string) error {
+	info, err := fsys.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fileutils.MoveOrCopy(fsys, src, dst)
+	}
+	return fileutils.RenameOrCopy(fsys, src, dst)
+}
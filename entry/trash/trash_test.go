@@ -0,0 +1,67 @@
+package trash
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestPut(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/home/docs/my file.txt", []byte("a"), 0644)
+	afero.WriteFile(fsys, "/home/other/my file.txt", []byte("b"), 0644)
+	afero.WriteFile(fsys, "/home/dir/c", []byte("c"), 0644)
+	bin := Bin{Dir: "/home/.local/share/Trash", Info: true}
+	now := time.Date(2023, 5, 1, 12, 30, 0, 0, time.Local)
+
+	dst, err := bin.Put(fsys, "/home/docs/my file.txt", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst != filepath.FromSlash("/home/.local/share/Trash/files/my file.txt") {
+		t.Errorf("unexpected path in the trash %s", dst)
+	}
+	if ok, _ := afero.Exists(fsys, "/home/docs/my file.txt"); ok {
+		t.Error("expected the file to be moved")
+	}
+	info, _ := afero.ReadFile(fsys, "/home/.local/share/Trash/info/my file.txt.trashinfo")
+	expected := "[Trash Info]\nPath=/home/docs/my%20file.txt\nDeletionDate=2023-05-01T12:30:00\n"
+	if string(info) != expected {
+		t.Errorf("expected info %q, got %q", expected, info)
+	}
+
+	// a file with the same name gets a number
+	dst, err = bin.Put(fsys, "/home/other/my file.txt", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(dst, "my file.2.txt") {
+		t.Errorf("expected a numbered name, got %s", dst)
+	}
+	if content, _ := afero.ReadFile(fsys, dst); string(content) != "b" {
+		t.Errorf("expected the second file in the trash, got %q", content)
+	}
+
+	if _, err := bin.Put(fsys, "/home/dir", now); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := afero.ReadFile(fsys, "/home/.local/share/Trash/files/dir/c"); string(content) != "c" {
+		t.Error("expected the directory to be moved to the trash")
+	}
+}
+
+func TestPutMissing(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	bin := Bin{Dir: "/trash", Info: true}
+	if _, err := bin.Put(fsys, "/missing", time.Now()); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if ok, _ := afero.DirExists(fsys, "/trash"); ok {
+		t.Error("expected the trash not to be created for a missing file")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package usage
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// deviceID returns the id of the device the file is on
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev), true
+	}
+	return 0, false
+}
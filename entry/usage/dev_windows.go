@@ -0,0 +1,11 @@
+//go:build windows
+
+package usage
+
+import "io/fs"
+
+// deviceID is not available on windows, so scans may cross into other volumes
+// mounted as folders
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
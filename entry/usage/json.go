@@ -0,0 +1,141 @@
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The export format is the one used by ncdu so scans can be shared between the two programs.
+// A file is an object and a directory is an array whose first element is the object
+// describing the directory, followed by its children:
+//
+//	[1, 2, {"progname": "fman", ...}, [{"name": "/root"}, {"name": "file", "asize": 10}, [{"name": "dir"}, ...]]]
+
+const (
+	exportMajorVersion = 1
+	exportMinorVersion = 2
+)
+
+var errInvalidExport = errors.New("not a disk usage export")
+
+// nodeInfo is how a node is described in an export
+type nodeInfo struct {
+	Name      string `json:"name"`
+	Asize     int64  `json:"asize,omitempty"`
+	ReadError bool   `json:"read_error,omitempty"`
+	Excluded  string `json:"excluded,omitempty"`
+}
+
+type exportMeta struct {
+	Progname  string `json:"progname"`
+	Progver   string `json:"progver"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Export writes the tree below root to w
+func Export(w io.Writer, root *Node) error {
+	bw := bufio.NewWriter(w)
+	meta, err := json.Marshal(exportMeta{Progname: "fman", Progver: "0", Timestamp: time.Now().Unix()})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(bw, "[%d,%d,%s,\n", exportMajorVersion, exportMinorVersion, meta)
+	if err := exportNode(bw, root); err != nil {
+		return err
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+func exportNode(w *bufio.Writer, n *Node) error {
+	info := nodeInfo{Name: n.Name, ReadError: n.ReadError}
+	if !n.IsDir {
+		info.Asize = n.Size
+	}
+	if n.Excluded {
+		info.Excluded = "othfs"
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if !n.IsDir || n.Excluded {
+		_, err = w.Write(data)
+		return err
+	}
+	w.WriteByte('[')
+	w.Write(data)
+	for _, c := range n.Children {
+		w.WriteString(",\n")
+		if err := exportNode(w, c); err != nil {
+			return err
+		}
+	}
+	_, err = w.WriteString("]")
+	return err
+}
+
+// Import reads a tree written by Export or by ncdu
+func Import(r io.Reader) (*Node, error) {
+	var doc []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc) < 4 {
+		return nil, errInvalidExport
+	}
+	var major int
+	if err := json.Unmarshal(doc[0], &major); err != nil || major != exportMajorVersion {
+		return nil, errInvalidExport
+	}
+	root, err := importNode(doc[3])
+	if err != nil {
+		return nil, err
+	}
+	if !root.IsDir {
+		return nil, errInvalidExport
+	}
+	root.finalize()
+	return root, nil
+}
+
+func importNode(raw json.RawMessage) (*Node, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, errInvalidExport
+	}
+	if raw[0] != '[' {
+		var info nodeInfo
+		if err := json.Unmarshal(raw, &info); err != nil {
+			return nil, err
+		}
+		excluded := info.Excluded != ""
+		return &Node{Name: info.Name, Size: info.Asize, IsDir: excluded, Excluded: excluded}, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errInvalidExport
+	}
+	var info nodeInfo
+	if err := json.Unmarshal(items[0], &info); err != nil {
+		return nil, err
+	}
+	node := &Node{Name: info.Name, IsDir: true, ReadError: info.ReadError, Excluded: info.Excluded != ""}
+	node.Children = make([]*Node, 0, len(items)-1)
+	for _, item := range items[1:] {
+		child, err := importNode(item)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
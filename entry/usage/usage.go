@@ -0,0 +1,193 @@
+// Package usage scans directory trees to show where disk space is used, similar to ncdu.
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"github.com/spf13/afero"
+	"golang.org/x/sync/errgroup"
+)
+
+// Node is a file or directory in a scanned tree
+type Node struct {
+	Name      string // the root node holds the full path of the scanned directory
+	Size      int64  // apparent size. For directories, the total size of everything below
+	Items     int64  // number of entries below a directory
+	IsDir     bool
+	ReadError bool // the directory could not be read, so its size is incomplete
+	Excluded  bool // the directory is on another filesystem and was not scanned
+	Children  []*Node
+	Parent    *Node
+}
+
+// Path returns the full path of the node
+func (n *Node) Path() string {
+	if n.Parent == nil {
+		return n.Name
+	}
+	return filepath.Join(n.Parent.Path(), n.Name)
+}
+
+// Remove removes the child from the node and subtracts its size from all of the ancestors.
+func (n *Node) Remove(child *Node) {
+	for i, c := range n.Children {
+		if c != child {
+			continue
+		}
+		n.Children = append(n.Children[:i], n.Children[i+1:]...)
+		for p := n; p != nil; p = p.Parent {
+			p.Size -= child.Size
+			p.Items -= child.Items + 1
+		}
+		child.Parent = nil
+		return
+	}
+}
+
+// Copy returns a deep copy of n and the nodes below it, without the parent of n.
+// The copy can be read by another goroutine while the original tree is changed.
+func (n *Node) Copy() *Node {
+	c := *n
+	c.Parent = nil
+	c.Children = make([]*Node, len(n.Children))
+	for i, child := range n.Children {
+		c.Children[i] = child.Copy()
+		c.Children[i].Parent = &c
+	}
+	return &c
+}
+
+// finalize totals the sizes of the directories below n and sorts their children by size
+func (n *Node) finalize() {
+	if !n.IsDir {
+		return
+	}
+	n.Size, n.Items = 0, 0
+	for _, c := range n.Children {
+		c.Parent = n
+		c.finalize()
+		n.Size += c.Size
+		n.Items += c.Items + 1
+	}
+	sortBySize(n.Children)
+}
+
+func sortBySize(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Size != nodes[j].Size {
+			return nodes[i].Size > nodes[j].Size
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+// Progress counts what has been scanned so far. It is safe for concurrent use.
+type Progress struct {
+	items atomic.Int64
+	bytes atomic.Int64
+}
+
+// Counts returns the number of entries and bytes scanned so far
+func (p *Progress) Counts() (items int64, bytes int64) {
+	return p.items.Load(), p.bytes.Load()
+}
+
+// Options configures a scan
+type Options struct {
+	OneFileSystem bool // do not descend into directories on other filesystems
+	Routines      int  // maximum number of directories read concurrently
+}
+
+// Scan reads the tree below root. Directories are read concurrently. Symlinks are not
+// followed. Directories that cannot be read are marked with ReadError. progress may be nil.
+func Scan(ctx context.Context, fsys afero.Fs, root string, opts Options, progress *Progress) (*Node, error) {
+	info, err := fsys.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		progress = &Progress{}
+	}
+	if opts.Routines <= 0 {
+		opts.Routines = 10
+	}
+	dev, hasDev := deviceID(info)
+	s := scanner{
+		fsys:     fsys,
+		progress: progress,
+		dev:      dev,
+		checkDev: opts.OneFileSystem && hasDev,
+	}
+	s.g, s.ctx = errgroup.WithContext(ctx)
+	s.g.SetLimit(opts.Routines)
+
+	node := &Node{Name: root, IsDir: info.IsDir(), Size: info.Size()}
+	if node.IsDir {
+		node.Size = 0
+		s.g.Go(func() error {
+			return s.scanDir(node, root)
+		})
+	}
+	if err := s.g.Wait(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	node.finalize()
+	return node, nil
+}
+
+type scanner struct {
+	fsys     afero.Fs
+	progress *Progress
+	dev      uint64
+	checkDev bool
+	g        *errgroup.Group
+	ctx      context.Context
+}
+
+// scanDir reads the directory into node. Subdirectories are scanned in new goroutines when
+// the limit allows it and in the current goroutine otherwise. Each node is only written to
+// by the goroutine scanning it.
+func (s *scanner) scanDir(node *Node, path string) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	infos, err := afero.ReadDir(s.fsys, path)
+	if err != nil {
+		node.ReadError = true
+		return nil
+	}
+	node.Children = make([]*Node, 0, len(infos))
+	for _, info := range infos {
+		child := &Node{Name: info.Name(), IsDir: info.IsDir()}
+		node.Children = append(node.Children, child)
+		s.progress.items.Add(1)
+		if !child.IsDir {
+			child.Size = info.Size()
+			s.progress.bytes.Add(child.Size)
+			continue
+		}
+		if s.checkDev {
+			if dev, ok := deviceID(info); ok && dev != s.dev {
+				child.Excluded = true
+				continue
+			}
+		}
+		childPath := filepath.Join(path, child.Name)
+		started := s.g.TryGo(func() error {
+			return s.scanDir(child, childPath)
+		})
+		if started {
+			continue
+		}
+		if err := s.scanDir(child, childPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
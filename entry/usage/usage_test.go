@@ -0,0 +1,118 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func testFs() afero.Fs {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", make([]byte, 10), 0644)
+	afero.WriteFile(fsys, "/root/big/b", make([]byte, 1000), 0644)
+	afero.WriteFile(fsys, "/root/big/deep/c", make([]byte, 500), 0644)
+	afero.WriteFile(fsys, "/root/small/d", make([]byte, 100), 0644)
+	return fsys
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	progress := &Progress{}
+	root, err := Scan(context.Background(), testFs(), "/root", Options{Routines: 2}, progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Size != 1610 {
+		t.Errorf("expected total size 1610, got %d", root.Size)
+	}
+	if root.Items != 7 {
+		t.Errorf("expected 7 items, got %d", root.Items)
+	}
+	var names []string
+	for _, c := range root.Children {
+		names = append(names, c.Name)
+	}
+	if len(names) != 3 || names[0] != "big" || names[1] != "small" || names[2] != "a" {
+		t.Errorf("expected children sorted by size, got %v", names)
+	}
+	items, bytes := progress.Counts()
+	if items != 7 || bytes != 1610 {
+		t.Errorf("expected progress of 7 items and 1610 bytes, got %d and %d", items, bytes)
+	}
+	deep := root.Children[0].Children[1]
+	if deep.Path() != "/root/big/deep" {
+		t.Errorf("expected path /root/big/deep, got %s", deep.Path())
+	}
+
+	big := root.Children[0]
+	big.Remove(deep)
+	if big.Size != 1000 || root.Size != 1110 || root.Items != 5 {
+		t.Errorf("expected sizes to be updated after removing, got %d, %d, %d items", big.Size, root.Size, root.Items)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+	root, err := Scan(context.Background(), testFs(), "/root", Options{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := root.Copy()
+	big := root.Children[0]
+	big.Remove(big.Children[1])
+	root.Remove(root.Children[2])
+	if c.Size != 1610 || c.Items != 7 || len(c.Children) != 3 || len(c.Children[0].Children) != 2 {
+		t.Errorf("expected the copy to be unchanged by removing from the original, got %d bytes in %d items", c.Size, c.Items)
+	}
+	if deep := c.Children[0].Children[1]; deep.Path() != "/root/big/deep" {
+		t.Errorf("expected path /root/big/deep in the copy, got %s", deep.Path())
+	}
+}
+
+func TestScanCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Scan(ctx, testFs(), "/root", Options{}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+	root, err := Scan(context.Background(), testFs(), "/root", Options{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Children[1].Excluded = true
+	var buf bytes.Buffer
+	if err := Export(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := Import(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Name != "/root" || imported.Size != 1510 || len(imported.Children) != 3 {
+		t.Fatalf("unexpected imported root %+v", imported)
+	}
+	if imported.Children[0].Name != "big" || imported.Children[0].Size != 1500 {
+		t.Errorf("unexpected first child %+v", imported.Children[0])
+	}
+	var excluded *Node
+	for _, c := range imported.Children {
+		if c.Excluded {
+			excluded = c
+		}
+	}
+	if excluded == nil || excluded.Name != "small" || !excluded.IsDir {
+		t.Errorf("expected small to be imported as an excluded directory, got %+v", excluded)
+	}
+
+	if _, err := Import(bytes.NewBufferString(`{"name": "x"}`)); err == nil {
+		t.Error("expected an error importing an invalid document")
+	}
+}
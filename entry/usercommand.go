@@ -0,0 +1,109 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// CommandMode is how a user command is run
+type CommandMode uint8
+
+const (
+	CommandForeground CommandMode = iota // takes over the terminal until it exits
+	CommandBackground                    // runs in the background, its output is shown in a notification
+	CommandPreview                       // runs in the background, its output is shown in the preview
+)
+
+var commandModeNames = [...]string{
+	CommandForeground: "foreground",
+	CommandBackground: "background",
+	CommandPreview:    "preview",
+}
+
+// ParseCommandMode returns the mode with the given name. The names are foreground,
+// background and preview.
+func ParseCommandMode(name string) (CommandMode, error) {
+	for i, n := range commandModeNames {
+		if strings.EqualFold(n, name) {
+			return CommandMode(i), nil
+		}
+	}
+	return CommandForeground, fmt.Errorf("unknown command mode %q", name)
+}
+
+func (m CommandMode) String() string {
+	if int(m) < len(commandModeNames) {
+		return commandModeNames[m]
+	}
+	return "unknown"
+}
+
+// UserCommand is a shell command defined by the user and run with a key
+type UserCommand struct {
+	Name    string
+	Key     string
+	Command string // shell script template, see CommandVars for the placeholders
+	Mode    CommandMode
+	Button  bool // if true, the command is shown as a button
+}
+
+// CommandVars holds the values of the placeholders of a user command. {path} is replaced
+// by Path, {paths} by Paths, {dir} by Dir and {clipboard} by Clipboard.
+type CommandVars struct {
+	Path      string   // entry under the cursor
+	Paths     []string // selected entries
+	Dir       string   // current directory
+	Clipboard []string // entries copied or cut
+}
+
+// Cmd returns the command that runs the script in the directory of vars. The values of
+// the placeholders are quoted, so they must not be quoted in the template.
+func (c UserCommand) Cmd(vars CommandVars) (*exec.Cmd, error) {
+	return c.CmdContext(context.Background(), vars)
+}
+
+// CmdContext is like Cmd but the script is killed when ctx is done
+func (c UserCommand) CmdContext(ctx context.Context, vars CommandVars) (*exec.Cmd, error) {
+	script := strings.TrimSpace(expandScript(c.Command, map[string][]string{
+		"path":      {vars.Path},
+		"paths":     vars.Paths,
+		"dir":       {vars.Dir},
+		"clipboard": vars.Clipboard,
+	}))
+	if script == "" {
+		return nil, errors.New("empty command")
+	}
+	cmd := ShellCommandContext(ctx, script)
+	cmd.Dir = vars.Dir
+	cmd.Env = append(os.Environ(), vars.Environ()...)
+	return cmd, nil
+}
+
+// Environ returns the values as environment variables: FMAN_PATH, FMAN_PATHS, FMAN_DIR
+// and FMAN_CLIPBOARD. The paths of FMAN_PATHS and FMAN_CLIPBOARD are separated by newlines.
+func (v CommandVars) Environ() []string {
+	return []string{
+		"FMAN_PATH=" + v.Path,
+		"FMAN_PATHS=" + strings.Join(v.Paths, "\n"),
+		"FMAN_DIR=" + v.Dir,
+		"FMAN_CLIPBOARD=" + strings.Join(v.Clipboard, "\n"),
+	}
+}
+
+// expandScript replaces every {name} in script with the quoted values joined by a space.
+// The values are not expanded again, so paths containing a placeholder are kept as is.
+func expandScript(script string, vars map[string][]string) string {
+	pairs := make([]string, 0, 2*len(vars))
+	for name, values := range vars {
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = shellQuote(v)
+		}
+		pairs = append(pairs, "{"+name+"}", strings.Join(quoted, " "))
+	}
+	return strings.NewReplacer(pairs...).Replace(script)
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package entry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUserCommandCmd(t *testing.T) {
+	t.Parallel()
+	vars := CommandVars{
+		Path:      "/d/it's.txt",
+		Paths:     []string{"/d/a b", "/d/{dir}"},
+		Dir:       "/d",
+		Clipboard: nil,
+	}
+	testcases := []struct {
+		command string
+		want    string
+	}{
+		{"du -sh {paths} | sort -h", `du -sh '/d/a b' '/d/{dir}' | sort -h`},
+		{"file {path}", `file '/d/it'\''s.txt'`},
+		{"cp {clipboard} {dir}", `cp  '/d'`},
+		{"echo {unknown}", `echo {unknown}`},
+	}
+	for _, tc := range testcases {
+		cmd, err := UserCommand{Command: tc.command}.Cmd(vars)
+		if err != nil {
+			t.Fatalf("Cmd(%q) returned error: %v", tc.command, err)
+		}
+		if want := []string{"sh", "-c", tc.want}; !reflect.DeepEqual(cmd.Args, want) {
+			t.Errorf("Cmd(%q) args = %q; want %q", tc.command, cmd.Args, want)
+		}
+		if cmd.Dir != "/d" {
+			t.Errorf("Cmd(%q) dir = %q; want /d", tc.command, cmd.Dir)
+		}
+		if env := cmd.Env[len(cmd.Env)-4:]; env[1] != "FMAN_PATHS=/d/a b\n/d/{dir}" {
+			t.Errorf("Cmd(%q) env = %q; want the selected paths in FMAN_PATHS", tc.command, env)
+		}
+	}
+	if _, err := (UserCommand{Command: "  "}).Cmd(vars); err == nil {
+		t.Error("Cmd with an empty command should return an error")
+	}
+}
+
+func TestParseCommandMode(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]CommandMode{
+		"foreground": CommandForeground,
+		"background": CommandBackground,
+		"Preview":    CommandPreview,
+	}
+	for name, want := range testcases {
+		if mode, err := ParseCommandMode(name); err != nil || mode != want {
+			t.Errorf("ParseCommandMode(%q) = %v, %v; want %v", name, mode, err, want)
+		}
+	}
+	if _, err := ParseCommandMode("detached"); err == nil {
+		t.Error("ParseCommandMode should reject unknown modes")
+	}
+}
@@ -2,6 +2,7 @@ package entry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -136,3 +137,26 @@ func TestWalkUp(t *testing.T) {
 		}
 	}
 }
+
+func TestDirSize(t *testing.T) {
+	t.Parallel()
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", make([]byte, 10), 0644)
+	afero.WriteFile(fsys, "/root/b/c", make([]byte, 100), 0644)
+	afero.WriteFile(fsys, "/root/b/d/e", make([]byte, 1000), 0644)
+	afero.WriteFile(fsys, "/root/f/g/h/i", make([]byte, 5), 0644)
+
+	size, err := DirSize(context.Background(), fsys, "/root", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 1115 {
+		t.Errorf("expected size 1115, got %d", size)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DirSize(ctx, fsys, "/root", 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
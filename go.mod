@@ -62,6 +62,6 @@ require (
 	github.com/muesli/reflow v0.3.0 // indirect
 	github.com/rivo/uniseg v0.4.4 // indirect
 	golang.org/x/sync v0.2.0
-	golang.org/x/term v0.8.0 // indirect
+	golang.org/x/term v0.8.0
 	golang.org/x/text v0.9.0 // indirect
 )
@@ -0,0 +1,141 @@
+// Package hook runs the commands that the configuration attaches to the events of fman,
+// such as changing the directory or deleting entries. The data of the event is written
+// to the stdin of the command as JSON. A hook of an event starting with pre- can veto the
+// operation by exiting with a non-zero status and printing the reason.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Philistino/fman/entry"
+)
+
+// Event is the name of an event of fman
+type Event string
+
+const (
+	OnCd       Event = "on-cd"       // the current directory changed
+	OnSelect   Event = "on-select"   // the selected entries changed
+	PreDelete  Event = "pre-delete"  // the selected entries are about to be deleted
+	PostDelete Event = "post-delete" // the selected entries were deleted
+	PostRename Event = "post-rename" // entries were renamed
+	PostPaste  Event = "post-paste"  // the entries of the clipboard were pasted
+)
+
+var events = []Event{OnCd, OnSelect, PreDelete, PostDelete, PostRename, PostPaste}
+
+// DefaultTimeout is the time a hook can run before it is killed
+const DefaultTimeout = 2 * time.Second
+
+// waitDelay is the time given to the children of a killed hook to close its output
+const waitDelay = 500 * time.Millisecond
+
+// ParseEvent returns the event with the given name. The names are on-cd, on-select,
+// pre-delete, post-delete, post-rename and post-paste.
+func ParseEvent(name string) (Event, error) {
+	for _, e := range events {
+		if strings.EqualFold(string(e), name) {
+			return e, nil
+		}
+	}
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = string(e)
+	}
+	return "", fmt.Errorf("unknown event %q. Options are: %s", name, strings.Join(names, ", "))
+}
+
+// Data is the event written to the stdin of the hooks
+type Data struct {
+	Event   Event    `json:"event"`
+	Dir     string   `json:"dir"`               // current directory
+	Paths   []string `json:"paths,omitempty"`   // entries selected, deleted or pasted
+	Sources []string `json:"sources,omitempty"` // entries of the clipboard for post-paste
+	Renames []Rename `json:"renames,omitempty"` // renamed entries for post-rename
+}
+
+// Rename is an entry renamed from the path From to the path To
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Hook is a shell command run on an event
+type Hook struct {
+	Event   Event
+	Command string // script run by sh, or by cmd on Windows
+	Timeout time.Duration
+}
+
+// Hooks holds the hooks of each event in the order they are run
+type Hooks map[Event][]Hook
+
+// New groups the hooks by event
+func New(hooks []Hook) Hooks {
+	grouped := make(Hooks)
+	for _, h := range hooks {
+		grouped[h.Event] = append(grouped[h.Event], h)
+	}
+	return grouped
+}
+
+// Has reports whether there are hooks for the event
+func (hs Hooks) Has(event Event) bool {
+	return len(hs[event]) > 0
+}
+
+// Run runs the hooks of the event one after the other in the directory of the data. It
+// stops at the first hook that fails and returns its error, which holds the last line
+// the hook printed, so the reason of a veto can be shown.
+func (hs Hooks) Run(ctx context.Context, event Event, data Data) error {
+	if !hs.Has(event) {
+		return nil
+	}
+	data.Event = event
+	input, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	// one event per line, so hooks can append the events to a file
+	input = append(input, '\n')
+	for _, h := range hs[event] {
+		if err := h.run(ctx, data.Dir, input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h Hook) run(ctx context.Context, dir string, input []byte) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := entry.ShellCommandContext(ctx, h.Command)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.WaitDelay = waitDelay
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s hook timed out after %s", h.Event, timeout)
+	}
+	if err == nil {
+		return nil
+	}
+	reason := strings.TrimSpace(string(output))
+	if i := strings.LastIndexByte(reason, '\n'); i >= 0 {
+		reason = reason[i+1:]
+	}
+	if reason == "" {
+		reason = err.Error()
+	}
+	return fmt.Errorf("%s hook: %s", h.Event, reason)
+}
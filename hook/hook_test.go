@@ -0,0 +1,82 @@
+//go:build !windows
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEvent(t *testing.T) {
+	for _, e := range events {
+		if got, err := ParseEvent(string(e)); err != nil || got != e {
+			t.Errorf("ParseEvent(%q) = %q, %v", e, got, err)
+		}
+	}
+	if _, err := ParseEvent("on-quit"); err == nil {
+		t.Error("ParseEvent should reject unknown events")
+	}
+}
+
+func TestRunData(t *testing.T) {
+	dir := t.TempDir()
+	hooks := New([]Hook{
+		{Event: PostRename, Command: "cat > event.json"},
+		{Event: PostRename, Command: "pwd > dir.txt"},
+	})
+	data := Data{Dir: dir, Renames: []Rename{{From: filepath.Join(dir, "a"), To: filepath.Join(dir, "b")}}}
+	if err := hooks.Run(context.Background(), PostRename, data); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "event.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Data
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatal(err)
+	}
+	data.Event = PostRename
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("got %+v, want %+v", got, data)
+	}
+	if pwd, _ := os.ReadFile(filepath.Join(dir, "dir.txt")); !strings.Contains(string(pwd), filepath.Base(dir)) {
+		t.Errorf("expected the hooks to run in %s, got %q", dir, pwd)
+	}
+}
+
+func TestRunVeto(t *testing.T) {
+	dir := t.TempDir()
+	hooks := New([]Hook{
+		{Event: PreDelete, Command: "echo checking; echo 'keep the logs' >&2; exit 1"},
+		{Event: PreDelete, Command: "touch ran"},
+	})
+	err := hooks.Run(context.Background(), PreDelete, Data{Dir: dir})
+	if err == nil || err.Error() != "pre-delete hook: keep the logs" {
+		t.Errorf("expected the reason of the veto, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err == nil {
+		t.Error("the hooks after a veto should not run")
+	}
+	if err := hooks.Run(context.Background(), PostDelete, Data{Dir: dir}); err != nil {
+		t.Errorf("an event without hooks should pass, got %v", err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	hooks := New([]Hook{{Event: OnCd, Command: "sleep 10 & sleep 10", Timeout: 100 * time.Millisecond}})
+	start := time.Now()
+	err := hooks.Run(context.Background(), OnCd, Data{Dir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("the hook was killed after %s", elapsed)
+	}
+}
@@ -1,22 +1,42 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/remote"
+	"github.com/Philistino/fman/shell"
 	"github.com/Philistino/fman/ui/app"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
 	"github.com/muesli/termenv"
 	"github.com/spf13/afero"
+	"golang.org/x/term"
 
 	"github.com/Philistino/fman/ui/theme"
 )
 
 func main() {
+	// go-arg does not allow subcommands next to the positional path, so init and remote
+	// are handled before the arguments are parsed
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		os.Exit(printShellInit(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "remote" {
+		os.Exit(runRemote(os.Args[2:]))
+	}
+	os.Exit(run())
+}
 
+// run runs the app and returns the exit code
+func run() int {
 	zone.NewGlobal()
 	defer zone.Close()
 
@@ -24,6 +44,16 @@ func main() {
 	if err != nil {
 		log.Println(err)
 	}
+	if cfg.ChooseFiles != "" && cfg.ChooseDir != "" {
+		fmt.Fprintln(os.Stderr, "--choose-files and --choose-dir cannot be used together")
+		return 2
+	}
+
+	// the app is drawn on stderr if the chosen paths are written to stdout
+	tty := os.Stdout
+	if cfg.ChooseFiles == "-" || cfg.ChooseDir == "-" {
+		tty = os.Stderr
+	}
 
 	// TODO: move theme/icons to config and return them on the config struct
 	selectedTheme := theme.GetActiveTheme(cfg.Theme)
@@ -32,12 +62,28 @@ func main() {
 
 	// Set background color then reset it on quit
 	bg := termenv.BackgroundColor()
-	output := termenv.NewOutput(os.Stdout)
+	output := termenv.NewOutput(tty)
 	output.SetBackgroundColor(termenv.RGBColor(lipgloss.Color(selectedTheme.BackgroundColor)))
 	defer output.SetBackgroundColor(bg)
 
 	a := app.NewApp(cfg, selectedTheme, afero.NewOsFs())
-	p := tea.NewProgram(a, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithoutCatchPanics())
+	if term.IsTerminal(int(tty.Fd())) {
+		a.ReportCwd(tty)
+	}
+	p := tea.NewProgram(a, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithoutCatchPanics(), tea.WithOutput(tty))
+	if !cfg.NoRemote {
+		// the programs run by fman find the socket through the session ID
+		session := remote.NewSessionID()
+		os.Setenv(remote.SessionEnv, session)
+		srv, err := remote.Listen(remote.SocketPath(session), a.RemoteHandler(p.Send))
+		if err != nil {
+			log.Println(err)
+		} else {
+			a.SetRemote(srv)
+			go srv.Serve()
+			defer srv.Close()
+		}
+	}
 	_, err = p.Run()
 	if err != nil {
 		println("An error occured: ", err.Error())
@@ -45,4 +91,145 @@ func main() {
 	if cfg.PrintPwdResult != nil && *cfg.PrintPwdResult {
 		println(a.Navi.CurrentPath())
 	}
+	if cfg.CwdFile != "" {
+		if err := os.WriteFile(cfg.CwdFile, []byte(a.Navi.CurrentPath()), 0o600); err != nil {
+			println("Could not write the current directory: ", err.Error())
+		}
+	}
+	if dest := cfg.ChooseFiles + cfg.ChooseDir; dest != "" {
+		chosen := a.Chosen()
+		if err := writeChosen(dest, chosen, cfg.ChooseNull); err != nil {
+			println("Could not write the chosen paths: ", err.Error())
+			return 1
+		}
+		if chosen == nil {
+			// quit without choosing
+			return 1
+		}
+	}
+	return 0
+}
+
+// writeChosen writes the paths to the file dest, or to stdout if dest is -, each followed
+// by a newline or a NUL. The file is written even if nothing was chosen, so a program
+// waiting for it sees that the chooser was closed.
+func writeChosen(dest string, paths []string, null bool) error {
+	sep := "\n"
+	if null {
+		sep = "\x00"
+	}
+	var b strings.Builder
+	for _, path := range paths {
+		b.WriteString(path)
+		b.WriteString(sep)
+	}
+	if dest == "-" {
+		_, err := os.Stdout.WriteString(b.String())
+		return err
+	}
+	return os.WriteFile(dest, []byte(b.String()), 0o600)
+}
+
+// printShellInit prints the shell function for the shell named in args and returns the
+// exit code
+func printShellInit(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: fman init %s\n", strings.Join(shell.Shells(), "|"))
+		return 2
+	}
+	script, err := shell.Script(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Print(script)
+	return 0
+}
+
+const remoteUsage = `usage: fman remote [--session ID] COMMAND [ARG...]
+
+Commands:
+  navigate PATH    open the directory PATH
+  select NAME...   select the entries of the current directory
+  reload           reload the current directory
+  path             print the current directory
+  selection        print the paths of the selected entries
+  run ACTION       run an action, such as toggle-hidden or quit
+  subscribe        print the events as JSON objects, one per line
+
+The session defaults to $FMAN_SESSION, which fman sets for the programs it runs.
+`
+
+var errRemoteUsage = errors.New("invalid command")
+
+// runRemote sends the command in args to a running fman and returns the exit code
+func runRemote(args []string) int {
+	session := os.Getenv(remote.SessionEnv)
+	if len(args) > 1 && args[0] == "--session" {
+		session, args = args[1], args[2:]
+	}
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, remoteUsage)
+		return 2
+	}
+	if session == "" {
+		fmt.Fprintln(os.Stderr, "fman remote: no session, run it from fman or pass --session")
+		return 2
+	}
+	c, err := remote.Dial(remote.SocketPath(session))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fman remote:", err)
+		return 1
+	}
+	defer c.Close()
+	err = remoteCommand(c, args[0], args[1:])
+	if errors.Is(err, errRemoteUsage) {
+		fmt.Fprint(os.Stderr, remoteUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fman remote:", err)
+		return 1
+	}
+	return 0
+}
+
+// remoteCommand sends the command to fman and prints its result
+func remoteCommand(c *remote.Client, command string, args []string) error {
+	switch {
+	case command == "navigate" && len(args) == 1:
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		return c.Call(remote.MethodNavigate, remote.PathParams{Path: path}, nil)
+	case command == "select" && len(args) > 0:
+		return c.Call(remote.MethodSelect, remote.SelectParams{Names: args}, nil)
+	case command == "reload" && len(args) == 0:
+		return c.Call(remote.MethodReload, nil, nil)
+	case command == "path" && len(args) == 0:
+		var result remote.PathParams
+		if err := c.Call(remote.MethodGetPath, nil, &result); err != nil {
+			return err
+		}
+		fmt.Println(result.Path)
+		return nil
+	case command == "selection" && len(args) == 0:
+		var result remote.Selection
+		if err := c.Call(remote.MethodGetSelection, nil, &result); err != nil {
+			return err
+		}
+		for _, name := range result.Names {
+			fmt.Println(filepath.Join(result.Path, name))
+		}
+		return nil
+	case command == "run" && len(args) == 1:
+		return c.Call(remote.MethodRun, remote.RunParams{Action: args[0]}, nil)
+	case command == "subscribe" && len(args) == 0:
+		enc := json.NewEncoder(os.Stdout)
+		return c.Subscribe(func(n remote.Notification) error {
+			return enc.Encode(n)
+		})
+	}
+	return errRemoteUsage
 }
@@ -1,9 +1,26 @@
 package nav
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"path/filepath"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/fileutils"
+)
+
+// LinkKind is the kind of link created by ClipboardPasteLinks
+type LinkKind uint8
+
+const (
+	LinkAbsolute LinkKind = iota // symbolic link to the absolute path
+	LinkRelative                 // symbolic link to the path relative to the link
+	LinkHard                     // hard link
 )
 
+var errClipboardEmpty = errors.New("nothing to paste")
+
 type clipBoard struct {
 	paths []string
 	cut   bool
@@ -30,6 +47,60 @@ func (n *Nav) ClipboardCopy(selectedNames map[string]struct{}, cut bool) {
 	n.clipboard = clip
 }
 
+// ClipboardPaths returns the paths in the internal clipboard
+func (n *Nav) ClipboardPaths() []string {
+	paths := make([]string, len(n.clipboard.paths))
+	copy(paths, n.clipboard.paths)
+	return paths
+}
+
+// ClipboardPasteLinks creates links in the current directory to the paths in the internal
+// clipboard. Each link gets the name of the entry it points at. If the Nav instance is in
+// dry run mode, no links are created. Returns the errors of the links that failed.
+func (n *Nav) ClipboardPasteLinks(ctx context.Context, kind LinkKind) []error {
+	if n.dryRun {
+		return []error{errDryRunError}
+	}
+	if n.clipboard.Empty() {
+		return []error{errClipboardEmpty}
+	}
+	var errs []error
+	for _, path := range n.clipboard.paths {
+		if err := ctx.Err(); err != nil {
+			return append(errs, err)
+		}
+		if err := n.pasteLink(path, kind); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+		}
+	}
+	return errs
+}
+
+func (n *Nav) pasteLink(path string, kind LinkKind) error {
+	link := filepath.Join(n.currentPath, filepath.Base(path))
+	if _, err := entry.Lstat(n.fsys, link); err == nil {
+		return fmt.Errorf("%s already exists", link)
+	}
+	switch kind {
+	case LinkRelative:
+		target, err := fileutils.RelativeTarget(n.fsys, path, link)
+		if err != nil {
+			return err
+		}
+		return fileutils.Symlink(n.fsys, target, link)
+	case LinkHard:
+		info, err := n.fsys.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return errors.New("directories cannot be hard linked")
+		}
+		return fileutils.Link(n.fsys, path, link)
+	}
+	return fileutils.Symlink(n.fsys, path, link)
+}
+
 // func (n *Nav) ClipboardPaste() []error {
 // 	if n.clipboard.Empty() {
 // 		return []error{errors.New("Nothing to paste")}
@@ -0,0 +1,57 @@
+package nav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Philistino/fman/entry/diff"
+)
+
+// maxCompareBytes is the largest file that will be compared line by line
+const maxCompareBytes = 2_000_000 // 2 MB
+
+var errCompareMixed = errors.New("cannot compare a file with a directory")
+
+// Comparison is the result of comparing two entries in the current directory.
+// File is set when two files are compared and Dirs when two directories are compared.
+type Comparison struct {
+	NameA string
+	NameB string
+	IsDir bool
+	File  diff.FileDiff
+	Dirs  []diff.EntryDiff
+}
+
+// Compare compares the entries with the given names in the current directory.
+// Files are compared line by line and directories are compared recursively.
+func (n *Nav) Compare(ctx context.Context, nameA, nameB string) (Comparison, error) {
+	cmp := Comparison{NameA: nameA, NameB: nameB}
+	pathA := filepath.Join(n.currentPath, nameA)
+	pathB := filepath.Join(n.currentPath, nameB)
+
+	infoA, err := n.fsys.Stat(pathA)
+	if err != nil {
+		return cmp, err
+	}
+	infoB, err := n.fsys.Stat(pathB)
+	if err != nil {
+		return cmp, err
+	}
+	if infoA.IsDir() != infoB.IsDir() {
+		return cmp, errCompareMixed
+	}
+
+	if infoA.IsDir() {
+		cmp.IsDir = true
+		cmp.Dirs, err = diff.Dirs(ctx, n.fsys, pathA, pathB)
+		return cmp, err
+	}
+
+	if infoA.Size() > maxCompareBytes || infoB.Size() > maxCompareBytes {
+		return cmp, fmt.Errorf("files larger than %d MB cannot be compared", maxCompareBytes/1_000_000)
+	}
+	cmp.File, err = diff.Files(ctx, n.fsys, pathA, pathB, maxCompareBytes)
+	return cmp, err
+}
@@ -0,0 +1,62 @@
+package nav
+
+import (
+	"context"
+	"time"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/nav/cache"
+)
+
+// dirSizeRoutines is the number of goroutines used to walk a directory when calculating its size
+const dirSizeRoutines = 8
+
+// dirSizeKey identifies a calculated directory size. A size is stale once the modification time
+// of the directory changes. Changes deeper in the tree are not detected.
+type dirSizeKey struct {
+	path    string
+	modTime int64
+}
+
+type dirSize struct {
+	size     int64
+	calcTime time.Time
+}
+
+func newDirSizeCache(ctx context.Context) *cache.Cache[dirSizeKey, dirSize] {
+	c, _ := cache.NewCache[dirSizeKey, dirSize](
+		ctx,
+		1000,
+		time.Minute,
+		nil,
+		func(i, j dirSize) bool {
+			return i.calcTime.Before(j.calcTime)
+		},
+	)
+	return c
+}
+
+// CachedDirSize returns the size of the directory at path if it has been calculated
+// since the directory was last modified.
+func (n *Nav) CachedDirSize(path string, modTime time.Time) (int64, bool) {
+	size, ok := n.dirSizes.Get(dirSizeKey{path: path, modTime: modTime.UnixNano()})
+	return size.size, ok
+}
+
+// DirSize returns the total size of the files under the directory at path.
+// The result is cached until the modification time of the directory changes.
+func (n *Nav) DirSize(ctx context.Context, path string) (int64, error) {
+	info, err := n.fsys.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if size, ok := n.CachedDirSize(path, info.ModTime()); ok {
+		return size, nil
+	}
+	size, err := entry.DirSize(ctx, n.fsys, path, dirSizeRoutines)
+	if err != nil {
+		return 0, err
+	}
+	n.dirSizes.Set(dirSizeKey{path: path, modTime: info.ModTime().UnixNano()}, dirSize{size: size, calcTime: time.Now()})
+	return size, nil
+}
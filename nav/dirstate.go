@@ -32,6 +32,7 @@ func (n NavState) Cursor() string {
 type DirState struct {
 	NavState
 	entries    []entry.Entry
+	sort       entry.SortSpec
 	backActive bool
 	fwdActive  bool
 	upActive   bool
@@ -48,6 +49,11 @@ func (d DirState) Entries() []entry.Entry {
 	return d.entries
 }
 
+// Sort returns the sort of the directory entries
+func (d DirState) Sort() entry.SortSpec {
+	return d.sort
+}
+
 // BackActive returns true if there is a back history
 func (d DirState) BackActive() bool {
 	return d.backActive
@@ -69,6 +75,7 @@ func (n *Nav) newDirState(entries []entry.Entry, nState NavState, err error) Dir
 	return DirState{
 		NavState:   nState,
 		entries:    entries,
+		sort:       n.sortFor(nState.Path()),
 		backActive: !n.hist.BackEmpty(),
 		fwdActive:  !n.hist.ForewardEmpty(),
 		upActive:   !isRoot(nState.Path()),
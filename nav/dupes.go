@@ -0,0 +1,73 @@
+package nav
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Philistino/fman/entry/dupes"
+	"github.com/Philistino/fman/entry/fileutils"
+	"github.com/Philistino/fman/entry/trash"
+)
+
+// FindDuplicates searches the tree below path for files with the same content
+func (n *Nav) FindDuplicates(ctx context.Context, path string, progress *dupes.Progress) ([]dupes.Group, error) {
+	return dupes.Find(ctx, n.fsys, path, dupes.Options{Routines: dirSizeRoutines}, progress)
+}
+
+// TrashPaths moves the files to the trash in the home directory.
+// If the Nav instance is in dry run mode, nothing is moved.
+// Returns one error for each path, which is nil if the path was trashed.
+func (n *Nav) TrashPaths(ctx context.Context, paths []string) []error {
+	errs := make([]error, len(paths))
+	bin, err := n.trashBin()
+	for i, path := range paths {
+		switch {
+		case n.dryRun:
+			errs[i] = errDryRunError
+		case err != nil:
+			errs[i] = err
+		case ctx.Err() != nil:
+			errs[i] = ctx.Err()
+		default:
+			_, errs[i] = bin.Put(n.fsys, path, time.Now())
+		}
+	}
+	return errs
+}
+
+func (n *Nav) trashBin() (trash.Bin, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return trash.Bin{}, err
+	}
+	return trash.Home(home)
+}
+
+// LinkDuplicates replaces each of the paths with a hard link to target. The content of
+// each path is compared to the target first, so files that changed since they were
+// found are left alone. If the Nav instance is in dry run mode, nothing is replaced.
+// Returns one error for each path, which is nil if the path was replaced.
+func (n *Nav) LinkDuplicates(ctx context.Context, target string, paths []string) []error {
+	errs := make([]error, len(paths))
+	for i, path := range paths {
+		if n.dryRun {
+			errs[i] = errDryRunError
+			continue
+		}
+		if errs[i] = ctx.Err(); errs[i] != nil {
+			continue
+		}
+		same, err := dupes.Same(n.fsys, target, path)
+		switch {
+		case err != nil:
+			errs[i] = err
+		case !same:
+			errs[i] = fmt.Errorf("%s: content differs from %s", path, target)
+		default:
+			errs[i] = fileutils.ReplaceWithLink(n.fsys, target, path)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,87 @@
+package nav
+
+import (
+	"context"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/git"
+	"github.com/spf13/afero"
+)
+
+// onOsFs reports whether the filesystem is the os filesystem, the only one git can see
+func (n *Nav) onOsFs() bool {
+	_, ok := n.fsys.(*afero.OsFs)
+	return ok
+}
+
+// GitStatus returns the git status of the entries of the directory at path. git only
+// sees the os filesystem, so git.ErrNotRepository is returned for other filesystems.
+func (n *Nav) GitStatus(ctx context.Context, path string) (*git.Repo, error) {
+	if !n.onOsFs() {
+		return nil, git.ErrNotRepository
+	}
+	return git.Read(ctx, path)
+}
+
+// GitLog returns up to max of the commits that changed the file at path, newest first
+func (n *Nav) GitLog(ctx context.Context, path string, max int) ([]git.Revision, error) {
+	if !n.onOsFs() {
+		return nil, git.ErrNotRepository
+	}
+	return git.Log(ctx, path, max)
+}
+
+// GitRevisionPreview returns the preview of the file at path as it was at the revision
+func (n *Nav) GitRevisionPreview(ctx context.Context, path string, rev git.Revision) entry.Preview {
+	if !n.onOsFs() {
+		return entry.Preview{Path: path, Err: git.ErrNotRepository}
+	}
+	return n.previewer.GetRevisionPreview(ctx, path, rev)
+}
+
+// GitDiffPreview returns the preview of the changes to the file at path against HEAD
+func (n *Nav) GitDiffPreview(ctx context.Context, path string) entry.Preview {
+	if !n.onOsFs() {
+		return entry.Preview{Path: path, Err: git.ErrNotRepository}
+	}
+	return n.previewer.GetDiffPreview(ctx, path)
+}
+
+// GitStage stages the changes to the named entries of the current directory
+func (n *Nav) GitStage(ctx context.Context, names []string) error {
+	return n.gitChange(ctx, git.Stage, names)
+}
+
+// GitUnstage unstages the changes to the named entries of the current directory
+func (n *Nav) GitUnstage(ctx context.Context, names []string) error {
+	return n.gitChange(ctx, git.Unstage, names)
+}
+
+// GitRestore discards the unstaged changes to the named entries of the current directory
+func (n *Nav) GitRestore(ctx context.Context, names []string) error {
+	return n.gitChange(ctx, git.Restore, names)
+}
+
+// GitCommit commits the staged changes of the work tree of the current directory and
+// returns the abbreviated hash of the commit
+func (n *Nav) GitCommit(ctx context.Context, message string) (string, error) {
+	switch {
+	case n.dryRun:
+		return "", errDryRunError
+	case !n.onOsFs():
+		return "", git.ErrNotRepository
+	}
+	return git.Commit(ctx, n.CurrentPath(), message)
+}
+
+// gitChange runs a git operation on the named entries of the current directory. If the
+// Nav instance is in dry run mode, nothing is changed.
+func (n *Nav) gitChange(ctx context.Context, op func(context.Context, string, []string) error, names []string) error {
+	switch {
+	case n.dryRun:
+		return errDryRunError
+	case !n.onOsFs():
+		return git.ErrNotRepository
+	}
+	return op(ctx, n.CurrentPath(), names)
+}
@@ -0,0 +1,8 @@
+package nav
+
+import "github.com/Philistino/fman/entry/storage"
+
+// Mounts returns the mounted filesystems for the devices panel
+func (n *Nav) Mounts() ([]storage.Mount, error) {
+	return storage.GetMounts()
+}
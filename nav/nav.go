@@ -12,6 +12,7 @@ import (
 
 	"github.com/Philistino/fman/entry"
 	"github.com/Philistino/fman/entry/fileutils"
+	"github.com/Philistino/fman/nav/cache"
 	"github.com/Philistino/fman/nav/history"
 	"github.com/spf13/afero"
 )
@@ -34,35 +35,45 @@ const (
 
 const pathSeparator = string(filepath.Separator)
 
+// tailBytes is the number of bytes read from the end of a followed file
+const tailBytes = 64_000 // 64 kB
+
 type Nav struct {
 	mu             sync.Mutex              // mutex for Nav
 	hist           history.History[string] // history of paths visited. Set to record max. 5000 entries
 	currentPath    string                  // current path
 	entries        []entry.Entry           // current entries
 	showHidden     bool                    // if true, show hidden files and directories
-	dirsMixed      bool                    // if true, directories are mixed in with files
-	cursorHist     map[string]string       // path -> cursor. This can grow unchecked but should not be a problem
-	fsys           afero.Fs                // filesystem
-	previewer      *PreviewHandler         // previewer
+	defaultSort    entry.SortSpec          // sort used for directories without a saved sort
+	filter         entry.Filter            // limits the entries that are listed
+	sorts          *SortStore              // sort chosen for each directory
+	dirSizes       *cache.Cache[dirSizeKey, dirSize]
+	cursorHist     map[string]string // path -> cursor. This can grow unchecked but should not be a problem
+	fsys           afero.Fs          // filesystem
+	previewer      *PreviewHandler   // previewer
 	idleWalkCancel context.CancelFunc
+	cancel         context.CancelFunc
 	dryRun         bool // if true, do not alter the filesystem
 	clipboard      clipBoard
+	renameUndo     []renameBatch // renames that can be undone, the last one on top
 }
 
 // NewNav creates a new Nav struct. The startPath is the path to start the navigation at. The fsys is the filesystem to use.
 // The previewDelay is the delay in milliseconds before previewing a file. If dryRun is true, no changes will be made to the filesystem.
 func NewNav(showHidden bool, dirsMixed bool, startPath string, fsys afero.Fs, previewDelay int, dryRun bool) *Nav {
-
+	ctx, cancel := context.WithCancel(context.Background())
 	navi := &Nav{
 		hist:        history.NewHistory[string](5000),
 		showHidden:  showHidden,
-		dirsMixed:   dirsMixed,
 		currentPath: startPath,
 		cursorHist:  make(map[string]string),
 		fsys:        fsys,
 		dryRun:      dryRun,
+		sorts:       NewSortStore(),
+		dirSizes:    newDirSizeCache(ctx),
+		cancel:      cancel,
 		previewer: NewPreviewHandler(
-			context.Background(),
+			ctx,
 			previewDelay,
 			50_000, // 50 kB
 			100,
@@ -70,9 +81,19 @@ func NewNav(showHidden bool, dirsMixed bool, startPath string, fsys afero.Fs, pr
 		),
 	}
 
+	navi.defaultSort = entry.DefaultSortSpec()
+	navi.defaultSort.DirsFirst = !dirsMixed
 	return navi
 }
 
+// Close stops the work the Nav instance does in the background. It is not used afterwards.
+func (n *Nav) Close() {
+	n.cancel()
+	if n.idleWalkCancel != nil {
+		n.idleWalkCancel()
+	}
+}
+
 // Go changes the current directory to the given path and returns a Dirstate struct. If the path is "~", the home directory is used.
 func (n *Nav) Go(path string, currCursor string, currSelected []string) DirState {
 	currState := NavState{path: n.currentPath, cursor: currCursor, selected: mapStruct(currSelected)}
@@ -217,11 +238,16 @@ func (n *Nav) ShowHidden() bool {
 }
 
 func (n *Nav) SetDirsMixed(dirsMixed bool) {
-	n.dirsMixed = dirsMixed
+	n.defaultSort.DirsFirst = !dirsMixed
+}
+
+// SetFilter limits the entries that are listed from the next read of a directory
+func (n *Nav) SetFilter(filter entry.Filter) {
+	n.filter = filter
 }
 
 func (n *Nav) getEntries(path string) ([]entry.Entry, error) {
-	entries, _, err := entry.GetEntries(n.fsys, path, n.showHidden, n.dirsMixed)
+	entries, _, err := entry.GetFilteredEntries(n.fsys, path, n.showHidden, n.sortFor(path), n.filter)
 	return entries, err
 }
 
@@ -240,6 +266,27 @@ func (n *Nav) GetPreview(ctx context.Context, path string) entry.Preview {
 	return n.previewer.GetPreview(ctx, n.fsys, path)
 }
 
+// MimeType returns the mime type of the entry with the given name in the current directory.
+func (n *Nav) MimeType(name string) (string, error) {
+	return entry.DetectMimeType(n.fsys, filepath.Join(n.currentPath, name))
+}
+
+// OpenLines opens the file with the given name in the current directory for reading lines.
+func (n *Nav) OpenLines(name string) (*entry.LineFile, error) {
+	return entry.OpenLineFile(n.fsys, filepath.Join(n.currentPath, name))
+}
+
+// ReadTail reads the data appended to a followed file since it was last read.
+// See entry.ReadAppended.
+func (n *Nav) ReadTail(state entry.TailState) (string, entry.TailState, bool, error) {
+	return entry.ReadAppended(n.fsys, state, tailBytes)
+}
+
+// RegisterPreviewer adds a previewer for files matching the pattern. See entry.MatchPattern for the pattern syntax.
+func (n *Nav) RegisterPreviewer(pattern string, p entry.Previewer) {
+	n.previewer.RegisterPreviewer(pattern, p)
+}
+
 // Delete removes the files or directories with the given names from the current directory.
 // If the Nav instance is in dry run mode, no files or directories will be removed.
 // Returns a slice of errors encountered during the deletion process.
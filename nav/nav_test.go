@@ -1,7 +1,20 @@
 package nav
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/git"
+	"github.com/Philistino/fman/entry/perms"
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/entry/usage"
+	"github.com/spf13/afero"
 )
 
 func TestHandleCursor(t *testing.T) {
@@ -86,3 +99,267 @@ func TestHandleCursorWithHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a.txt", []byte("one\ntwo\nthree\n"), 0644)
+	afero.WriteFile(fsys, "/root/b.txt", []byte("one\n2\nthree\n"), 0644)
+	afero.WriteFile(fsys, "/root/dirA/x", []byte("x"), 0644)
+	afero.WriteFile(fsys, "/root/dirB/y", []byte("y"), 0644)
+
+	n := NewNav(true, true, "/root", fsys, 0, true)
+
+	cmp, err := n.Compare(context.Background(), "a.txt", "b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmp.IsDir || len(cmp.File.Hunks) != 1 {
+		t.Errorf("got %d hunks for files; want 1", len(cmp.File.Hunks))
+	}
+
+	cmp, err = n.Compare(context.Background(), "dirA", "dirB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.IsDir || len(cmp.Dirs) != 2 {
+		t.Errorf("got %v for directories; want 2 differences", cmp.Dirs)
+	}
+
+	_, err = n.Compare(context.Background(), "a.txt", "dirA")
+	if !errors.Is(err, errCompareMixed) {
+		t.Errorf("got error %v; want %v", err, errCompareMixed)
+	}
+}
+
+func TestSortStore(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/b", []byte("bb"), 0644)
+	afero.WriteFile(fsys, "/root/a", []byte("a"), 0644)
+	afero.WriteFile(fsys, "/root/c", []byte("ccc"), 0644)
+
+	store, err := LoadSortStore(fsys, "/config/sort.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NewNav(true, false, "/root", fsys, 0, true)
+	n.SetSortStore(store)
+	spec := n.Sort()
+	spec.Method = entry.SizeSort
+	spec.Reverse = true
+	n.SetSort(spec)
+	if exists, _ := afero.Exists(fsys, "/config/sort.json"); exists {
+		t.Error("expected the sorts to be written only when they are saved")
+	}
+	if err := n.SaveSorts(); err != nil {
+		t.Fatal(err)
+	}
+	state := n.Reload(nil, "")
+	var names []string
+	for _, e := range state.Entries() {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, "") != "cba" {
+		t.Errorf("expected entries sorted by size descending, got %v", names)
+	}
+	if state.Sort() != spec {
+		t.Errorf("expected the dir state to report the sort %+v, got %+v", spec, state.Sort())
+	}
+
+	reloaded, err := LoadSortStore(fsys, "/config/sort.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := reloaded.Get("/root")
+	if !ok || got != spec {
+		t.Errorf("expected the saved sort %+v, got %+v", spec, got)
+	}
+	if _, ok := reloaded.Get("/other"); ok {
+		t.Error("expected no sort saved for /other")
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/dir/a", make([]byte, 10), 0644)
+	afero.WriteFile(fsys, "/root/dir/sub/b", make([]byte, 20), 0644)
+	n := NewNav(true, false, "/root", fsys, 0, true)
+
+	size, err := n.DirSize(context.Background(), "/root/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 30 {
+		t.Errorf("expected size 30, got %d", size)
+	}
+	info, _ := fsys.Stat("/root/dir")
+	if cached, ok := n.CachedDirSize("/root/dir", info.ModTime()); !ok || cached != 30 {
+		t.Errorf("expected cached size 30, got %d, %v", cached, ok)
+	}
+	if _, ok := n.CachedDirSize("/root/dir", info.ModTime().Add(time.Second)); ok {
+		t.Error("expected no cached size for a different modification time")
+	}
+}
+
+func TestBulkRename(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", []byte("a"), 0644)
+	afero.WriteFile(fsys, "/root/b", []byte("b"), 0644)
+	afero.WriteFile(fsys, "/root/c", []byte("c"), 0644)
+	swap := []rename.Rename{{From: "a", To: "b"}, {From: "b", To: "a"}}
+
+	n := NewNav(true, false, "/root", fsys, 0, true)
+	if errs := n.BulkRename(context.Background(), swap); len(errs) != 1 || !errors.Is(errs[0], errDryRunError) {
+		t.Errorf("expected a dry run error, got %v", errs)
+	}
+
+	n = NewNav(true, false, "/root", fsys, 0, false)
+	if err := n.ValidateRenames([]rename.Rename{{From: "a", To: "c"}}); err == nil {
+		t.Error("expected renaming onto an existing entry to be refused")
+	}
+	if errs := n.BulkRename(context.Background(), swap); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if content, _ := afero.ReadFile(fsys, "/root/a"); string(content) != "b" {
+		t.Errorf("expected a and b to be swapped, a holds %q", content)
+	}
+}
+
+func TestBatchRenameUndo(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", []byte("a"), 0644)
+	afero.WriteFile(fsys, "/root/b", []byte("b"), 0644)
+	n := NewNav(true, false, "/root", fsys, 0, false)
+	if _, _, err := n.UndoRename(context.Background()); !errors.Is(err, errNothingToUndo) {
+		t.Errorf("expected nothing to undo, got %v", err)
+	}
+
+	if err := n.BatchRename(context.Background(), []rename.Rename{{From: "a", To: "x"}, {From: "b", To: "y"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.BulkRename(context.Background(), []rename.Rename{{From: "x", To: "z"}}); len(err) != 0 {
+		t.Fatal(err)
+	}
+	// undo the bulk rename, then the batch rename
+	for _, exp := range []string{"/root/x", "/root/a"} {
+		dir, count, err := n.UndoRename(context.Background())
+		if err != nil || dir != "/root" || count == 0 {
+			t.Fatalf("unexpected undo result %s, %d, %v", dir, count, err)
+		}
+		if content, _ := afero.ReadFile(fsys, exp); string(content) != "a" {
+			t.Errorf("expected %s to hold a, got %q", exp, content)
+		}
+	}
+	if ok, _ := afero.Exists(fsys, "/root/b"); !ok {
+		t.Error("expected y to be renamed back to b")
+	}
+}
+
+func TestChangePermissions(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", []byte("a"), 0644)
+	mode := perms.Mode(0o600)
+	change := perms.Change{Mode: &mode, UID: -1, GID: -1}
+
+	n := NewNav(true, false, "/root", fsys, 0, true)
+	if errs := n.ChangePermissions(context.Background(), []string{"a"}, change); len(errs) != 1 || !errors.Is(errs[0], errDryRunError) {
+		t.Errorf("expected a dry run error, got %v", errs)
+	}
+
+	n = NewNav(true, false, "/root", fsys, 0, false)
+	if errs := n.ChangePermissions(context.Background(), []string{"a"}, change); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if info, _ := fsys.Stat("/root/a"); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode())
+	}
+}
+
+func TestExportUsage(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	root := &usage.Node{Name: "/root", IsDir: true}
+
+	n := NewNav(true, false, "/root", fsys, 0, true)
+	if err := n.ExportUsage("/usage.json", root); !errors.Is(err, errDryRunError) {
+		t.Errorf("expected a dry run error, got %v", err)
+	}
+	if _, err := fsys.Stat("/usage.json"); err == nil {
+		t.Error("expected nothing to be written in dry run mode")
+	}
+
+	n = NewNav(true, false, "/root", fsys, 0, false)
+	if err := n.ExportUsage("/usage.json", root); err != nil {
+		t.Fatal(err)
+	}
+	if imported, err := n.ImportUsage("/usage.json"); err != nil || imported.Name != "/root" {
+		t.Errorf("expected the exported tree to be imported, got %v, %v", imported, err)
+	}
+}
+
+func TestGitOnMemFs(t *testing.T) {
+	ctx := context.Background()
+	n := NewNav(true, false, "/", afero.NewMemMapFs(), 0, true)
+	if err := n.GitStage(ctx, []string{"a"}); !errors.Is(err, errDryRunError) {
+		t.Errorf("expected a dry run error, got %v", err)
+	}
+	n = NewNav(true, false, "/", afero.NewMemMapFs(), 0, false)
+	if _, err := n.GitStatus(ctx, "/"); !errors.Is(err, git.ErrNotRepository) {
+		t.Errorf("expected git.ErrNotRepository, got %v", err)
+	}
+	if _, err := n.GitCommit(ctx, "message"); !errors.Is(err, git.ErrNotRepository) {
+		t.Errorf("expected git.ErrNotRepository, got %v", err)
+	}
+	if _, err := n.GitLog(ctx, "/a", 10); !errors.Is(err, git.ErrNotRepository) {
+		t.Errorf("expected git.ErrNotRepository, got %v", err)
+	}
+	if prv := n.GitDiffPreview(ctx, "/a"); !errors.Is(prv.Err, git.ErrNotRepository) {
+		t.Errorf("expected git.ErrNotRepository, got %v", prv.Err)
+	}
+}
+
+func TestClipboardPasteLinks(t *testing.T) {
+	dir := t.TempDir()
+	fsys := afero.NewOsFs()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	fsys.MkdirAll(src, 0755)
+	fsys.MkdirAll(dst, 0755)
+	afero.WriteFile(fsys, filepath.Join(src, "a"), []byte("a"), 0644)
+
+	n := NewNav(true, false, src, fsys, 0, false)
+	if errs := n.ClipboardPasteLinks(context.Background(), LinkAbsolute); len(errs) != 1 || !errors.Is(errs[0], errClipboardEmpty) {
+		t.Errorf("expected an empty clipboard error, got %v", errs)
+	}
+	n.ClipboardCopy(map[string]struct{}{"a": {}}, false)
+	n.currentPath = dst
+
+	kinds := []struct {
+		kind LinkKind
+		name string
+	}{{LinkAbsolute, "abs"}, {LinkRelative, "rel"}, {LinkHard, "hard"}}
+	for _, k := range kinds {
+		if errs := n.ClipboardPasteLinks(context.Background(), k.kind); len(errs) != 0 {
+			t.Skip(errs)
+		}
+		// move the link out of the way so the next kind can use the name
+		fsys.Rename(filepath.Join(dst, "a"), filepath.Join(dst, k.name))
+	}
+	if target, _ := os.Readlink(filepath.Join(dst, "abs")); target != filepath.Join(src, "a") {
+		t.Errorf("expected an absolute target, got %s", target)
+	}
+	if target, _ := os.Readlink(filepath.Join(dst, "rel")); target != filepath.Join("..", "src", "a") {
+		t.Errorf("expected a relative target, got %s", target)
+	}
+	if info, err := os.Lstat(filepath.Join(dst, "hard")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("expected a hard link, got %v, %v", info, err)
+	}
+
+	// links are not overwritten and filesystems without links fail gracefully
+	afero.WriteFile(fsys, filepath.Join(dst, "a"), []byte("taken"), 0644)
+	if errs := n.ClipboardPasteLinks(context.Background(), LinkAbsolute); len(errs) != 1 {
+		t.Errorf("expected the existing entry to be kept, got %v", errs)
+	}
+	mem := NewNav(true, false, "/", afero.NewMemMapFs(), 0, false)
+	mem.clipboard = clipBoard{paths: []string{"/x"}}
+	if errs := mem.ClipboardPasteLinks(context.Background(), LinkAbsolute); len(errs) != 1 {
+		t.Errorf("expected an error on a filesystem without symlinks, got %v", errs)
+	}
+}
@@ -0,0 +1,22 @@
+package nav
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/Philistino/fman/entry/perms"
+)
+
+// ChangePermissions changes the mode and owner of the named entries of the current
+// directory. If the Nav instance is in dry run mode, nothing is changed. Returns the
+// errors of the entries that could not be changed.
+func (n *Nav) ChangePermissions(ctx context.Context, names []string, change perms.Change) []error {
+	if n.dryRun {
+		return []error{errDryRunError}
+	}
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(n.currentPath, name)
+	}
+	return perms.Apply(ctx, n.fsys, paths, change)
+}
@@ -2,17 +2,20 @@ package nav
 
 import (
 	"context"
+	"path/filepath"
 	"time"
 
 	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/git"
 	"github.com/Philistino/fman/nav/cache"
 	"github.com/spf13/afero"
 )
 
 type PreviewHandler struct {
-	readDelay int
-	maxBytes  int
-	cache     *cache.Cache[string, entry.Preview]
+	readDelay  int
+	maxBytes   int
+	cache      *cache.Cache[string, entry.Preview]
+	previewers *entry.PreviewRegistry
 }
 
 // NewPreviewHandler creates a new PreviewHandler
@@ -32,16 +35,23 @@ func NewPreviewHandler(ctx context.Context, previewDelay int, maxBytes int, cach
 		},
 	)
 	return &PreviewHandler{
-		readDelay: previewDelay,
-		maxBytes:  maxBytes,
-		cache:     prevCache,
+		readDelay:  previewDelay,
+		maxBytes:   maxBytes,
+		cache:      prevCache,
+		previewers: entry.DefaultPreviewRegistry(),
 	}
 }
 
+// RegisterPreviewer adds a previewer for files matching the pattern.
+// Previewers registered later take priority over earlier ones and the built-in previewers.
+func (ph *PreviewHandler) RegisterPreviewer(pattern string, p entry.Previewer) {
+	ph.previewers.Register(pattern, p)
+}
+
 func (ph *PreviewHandler) GetPreview(ctx context.Context, fsys afero.Fs, path string) entry.Preview {
 	preview, ok := ph.cache.Get(path)
 	if ok {
-		preview = entry.CreatePreview(ctx, fsys, preview, ph.maxBytes)
+		preview = ph.previewers.CreatePreview(ctx, fsys, preview, ph.maxBytes)
 		ph.cache.Set(path, preview)
 		return preview
 	}
@@ -56,7 +66,56 @@ func (ph *PreviewHandler) GetPreview(ctx context.Context, fsys afero.Fs, path st
 	}
 
 	preview.Path = path
-	preview = entry.CreatePreview(ctx, fsys, preview, ph.maxBytes)
+	preview = ph.previewers.CreatePreview(ctx, fsys, preview, ph.maxBytes)
 	ph.cache.Set(path, preview)
 	return preview
 }
+
+// GetRevisionPreview returns the preview of the file at path as it was at the revision.
+// The preview is cached with the hash of the revision in its key. As a revision does not
+// change, the cached preview is used until it is pruned.
+func (ph *PreviewHandler) GetRevisionPreview(ctx context.Context, path string, rev git.Revision) entry.Preview {
+	key := path + "@" + rev.Hash
+	preview, ok := ph.cache.Get(key)
+	if ok {
+		return preview
+	}
+
+	if ph.readDelay >= 0 {
+		time.Sleep(time.Millisecond * time.Duration(ph.readDelay))
+	}
+
+	preview.Path = path
+	if ctx.Err() != nil {
+		return preview
+	}
+	content, err := git.Show(ctx, filepath.Dir(path), rev, ph.maxBytes)
+	if err == nil {
+		preview.Content, err = entry.RevisionPreview(ctx, filepath.Base(rev.Path), content)
+	}
+	// a cancelled read is not cached as it would hide the revision until it is pruned
+	if ctx.Err() != nil {
+		return preview
+	}
+	preview.Err = err
+	preview.ReadTime = time.Now()
+	ph.cache.Set(key, preview)
+	return preview
+}
+
+// GetDiffPreview returns the highlighted changes to the file at path against HEAD. As
+// the work tree and the index change, the diff is not cached.
+func (ph *PreviewHandler) GetDiffPreview(ctx context.Context, path string) entry.Preview {
+	if ph.readDelay >= 0 {
+		time.Sleep(time.Millisecond * time.Duration(ph.readDelay))
+	}
+	preview := entry.Preview{Path: path}
+	if ctx.Err() != nil {
+		return preview
+	}
+	diff, err := git.Diff(ctx, path, ph.maxBytes)
+	preview.Content = entry.HighlightDiff(diff)
+	preview.Err = err
+	preview.ReadTime = time.Now()
+	return preview
+}
@@ -0,0 +1,125 @@
+package nav
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/spf13/afero"
+)
+
+// maxRenameUndo is the number of rename batches that can be undone
+const maxRenameUndo = 20
+
+// renameBatch is a set of renames done in one directory at once
+type renameBatch struct {
+	dir     string
+	renames []rename.Rename
+}
+
+var errNothingToUndo = errors.New("no renames to undo")
+
+// DirNames returns the names of all of the entries in the current directory, including
+// hidden ones
+func (n *Nav) DirNames() ([]string, error) {
+	infos, err := afero.ReadDir(n.fsys, n.currentPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, nil
+}
+
+// ValidateRenames checks that the renames can be done in the current directory
+func (n *Nav) ValidateRenames(renames []rename.Rename) error {
+	names, err := n.DirNames()
+	if err != nil {
+		return err
+	}
+	return rename.Validate(renames, names)
+}
+
+// BulkRename renames many entries of the current directory at once. Swaps and cycles
+// are done through temporary names. If the Nav instance is in dry run mode, nothing
+// is renamed. Returns the errors of the renames that failed.
+func (n *Nav) BulkRename(ctx context.Context, renames []rename.Rename) []error {
+	if n.dryRun {
+		return []error{errDryRunError}
+	}
+	if err := n.ValidateRenames(renames); err != nil {
+		return []error{err}
+	}
+	if err := ctx.Err(); err != nil {
+		return []error{err}
+	}
+	var errs []error
+	done := make([]rename.Rename, 0, len(renames))
+	for i, err := range rename.Apply(n.fsys, n.currentPath, renames) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		done = append(done, renames[i])
+	}
+	n.pushRenames(n.currentPath, done)
+	return errs
+}
+
+// BatchRename renames many entries of the current directory as one batch: if a rename
+// fails, the others are undone. If the Nav instance is in dry run mode, nothing is renamed.
+func (n *Nav) BatchRename(ctx context.Context, renames []rename.Rename) error {
+	if n.dryRun {
+		return errDryRunError
+	}
+	if err := n.ValidateRenames(renames); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done, err := rename.ApplyAll(n.fsys, n.currentPath, renames)
+	n.pushRenames(n.currentPath, done)
+	return err
+}
+
+// pushRenames records the renames done in dir so they can be undone
+func (n *Nav) pushRenames(dir string, renames []rename.Rename) {
+	if len(renames) == 0 {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.renameUndo = append(n.renameUndo, renameBatch{dir: dir, renames: renames})
+	if len(n.renameUndo) > maxRenameUndo {
+		n.renameUndo = n.renameUndo[len(n.renameUndo)-maxRenameUndo:]
+	}
+}
+
+// UndoRename undoes the last bulk or batch rename, which may have been done in another
+// directory. It returns the directory and the number of entries that were renamed back.
+func (n *Nav) UndoRename(ctx context.Context) (string, int, error) {
+	if n.dryRun {
+		return "", 0, errDryRunError
+	}
+	n.mu.Lock()
+	if len(n.renameUndo) == 0 {
+		n.mu.Unlock()
+		return "", 0, errNothingToUndo
+	}
+	last := n.renameUndo[len(n.renameUndo)-1]
+	n.renameUndo = n.renameUndo[:len(n.renameUndo)-1]
+	n.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return last.dir, 0, err
+	}
+	done, err := rename.ApplyAll(n.fsys, last.dir, rename.Reverse(last.renames))
+	if err != nil && len(done) == 0 {
+		// nothing was renamed back, so the undo can be tried again
+		n.pushRenames(last.dir, last.renames)
+	}
+	return last.dir, len(done), err
+}
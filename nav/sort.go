@@ -0,0 +1,117 @@
+package nav
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/spf13/afero"
+)
+
+// SortStore remembers the sort chosen for each directory. If it was loaded from
+// a file, the sorts can be saved back to that file so they persist across sessions.
+type SortStore struct {
+	mu    sync.Mutex
+	fsys  afero.Fs
+	path  string
+	sorts map[string]entry.SortSpec // directory path -> sort
+
+	// saving is held while the file is written, so an older copy of the
+	// sorts can not be written over a newer one
+	saving sync.Mutex
+}
+
+// NewSortStore returns a SortStore that is only kept in memory
+func NewSortStore() *SortStore {
+	return &SortStore{sorts: make(map[string]entry.SortSpec)}
+}
+
+// LoadSortStore reads the sorts saved at path. A missing file results in an empty store.
+func LoadSortStore(fsys afero.Fs, path string) (*SortStore, error) {
+	store := NewSortStore()
+	store.fsys = fsys
+	store.path = path
+	data, err := afero.ReadFile(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, err
+	}
+	if err := json.Unmarshal(data, &store.sorts); err != nil {
+		store.sorts = make(map[string]entry.SortSpec)
+		return store, err
+	}
+	return store, nil
+}
+
+// Get returns the sort saved for the directory
+func (s *SortStore) Get(dir string) (entry.SortSpec, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spec, ok := s.sorts[dir]
+	return spec, ok
+}
+
+// Set remembers the sort for the directory. It is written to the file of the store by Save.
+func (s *SortStore) Set(dir string, spec entry.SortSpec) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sorts[dir] = spec
+}
+
+// Save writes the store to its file, if it has one. It can be called from a goroutine
+// while the sorts are read and changed.
+func (s *SortStore) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.saving.Lock()
+	defer s.saving.Unlock()
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.sorts, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := s.fsys.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return afero.WriteFile(s.fsys, s.path, data, 0o644)
+}
+
+// SetSortStore replaces the store used to remember the sort of each directory
+func (n *Nav) SetSortStore(store *SortStore) {
+	n.sorts = store
+}
+
+// SetDefaultSort sets the sort used for directories that do not have a sort saved
+func (n *Nav) SetDefaultSort(spec entry.SortSpec) {
+	n.defaultSort = spec
+}
+
+// Sort returns the sort of the current directory
+func (n *Nav) Sort() entry.SortSpec {
+	return n.sortFor(n.currentPath)
+}
+
+// SetSort changes the sort of the current directory. The entries
+// are sorted accordingly the next time the directory is read.
+func (n *Nav) SetSort(spec entry.SortSpec) {
+	n.sorts.Set(n.currentPath, spec)
+}
+
+// SaveSorts writes the sort of each directory to the file of the sort store, if it has one
+func (n *Nav) SaveSorts() error {
+	return n.sorts.Save()
+}
+
+func (n *Nav) sortFor(path string) entry.SortSpec {
+	if spec, ok := n.sorts.Get(path); ok {
+		return spec
+	}
+	return n.defaultSort
+}
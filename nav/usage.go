@@ -0,0 +1,50 @@
+package nav
+
+import (
+	"context"
+
+	"github.com/Philistino/fman/entry/fileutils"
+	"github.com/Philistino/fman/entry/usage"
+)
+
+// ScanUsage scans the tree below path for the disk usage view. The scan does not
+// descend into other filesystems.
+func (n *Nav) ScanUsage(ctx context.Context, path string, progress *usage.Progress) (*usage.Node, error) {
+	return usage.Scan(ctx, n.fsys, path, usage.Options{OneFileSystem: true, Routines: dirSizeRoutines}, progress)
+}
+
+// DeletePath removes the file or directory at path.
+// If the Nav instance is in dry run mode, nothing is removed.
+func (n *Nav) DeletePath(ctx context.Context, path string) error {
+	if n.dryRun {
+		return errDryRunError
+	}
+	return fileutils.Remove(ctx, n.fsys, path)
+}
+
+// ExportUsage writes the scanned tree to the file at path.
+// If the Nav instance is in dry run mode, nothing is written.
+func (n *Nav) ExportUsage(path string, root *usage.Node) error {
+	if n.dryRun {
+		return errDryRunError
+	}
+	f, err := n.fsys.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := usage.Export(f, root); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ImportUsage reads a tree written by ExportUsage or ncdu from the file at path
+func (n *Nav) ImportUsage(path string) (*usage.Node, error) {
+	f, err := n.fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return usage.Import(f)
+}
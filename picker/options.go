@@ -0,0 +1,127 @@
+package picker
+
+import (
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/key"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/spf13/afero"
+)
+
+// Option is used to set options in New
+type Option func(*options)
+
+type options struct {
+	path       string
+	fsys       afero.Fs
+	filter     entry.Filter
+	multiple   bool
+	showHidden bool
+	keyMap     *keys.KeyMap
+	scheme     colors.Theme
+	icons      string
+	zones      *zone.Manager
+}
+
+// WithPath sets the directory the picker starts in. It defaults to the working
+// directory.
+func WithPath(path string) Option {
+	return func(o *options) {
+		o.path = path
+	}
+}
+
+// WithFs sets the filesystem the picker browses. It defaults to the filesystem of the
+// operating system.
+func WithFs(fsys afero.Fs) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithFilter sets the entries that are listed. With DirsOnly, directories are picked
+// instead of files.
+func WithFilter(filter entry.Filter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// WithMultiple picks the selected entries instead of the entry under the cursor
+func WithMultiple(multiple bool) Option {
+	return func(o *options) {
+		o.multiple = multiple
+	}
+}
+
+// WithShowHidden sets whether hidden entries are listed. They are listed by default.
+func WithShowHidden(showHidden bool) Option {
+	return func(o *options) {
+		o.showHidden = showHidden
+	}
+}
+
+// WithKeyMap sets the key bindings of the picker. It defaults to DefaultKeyMap.
+func WithKeyMap(keyMap keys.KeyMap) Option {
+	return func(o *options) {
+		o.keyMap = &keyMap
+	}
+}
+
+// WithTheme sets the colors of the picker. It defaults to the dracula theme.
+func WithTheme(scheme colors.Theme) Option {
+	return func(o *options) {
+		o.scheme = scheme
+	}
+}
+
+// WithIcons sets the icon set of the picker: nerdfont, emoji or none. It defaults to
+// emoji.
+func WithIcons(icons string) Option {
+	return func(o *options) {
+		o.icons = icons
+	}
+}
+
+// WithZoneManager sets the zone manager that locates the clicks on the picker. The
+// program scans its whole view with the manager. Without it, the picker scans its own
+// view, so clicks are only located when the picker is drawn at the top left corner of
+// the terminal.
+func WithZoneManager(zones *zone.Manager) Option {
+	return func(o *options) {
+		o.zones = zones
+	}
+}
+
+// DefaultKeyMap returns the key bindings of fman without the bindings of the file
+// operations and views the picker does not have
+func DefaultKeyMap() keys.KeyMap {
+	keyMap := keys.Map
+	for _, b := range []*key.Binding{
+		&keyMap.OpenWith,
+		&keyMap.OpenPager,
+		&keyMap.Compare,
+		&keyMap.BulkRename,
+		&keyMap.BatchRename,
+		&keyMap.UndoRename,
+		&keyMap.ChangePermissions,
+		&keyMap.InternalCopy,
+		&keyMap.PasteLinks,
+		&keyMap.GitStage,
+		&keyMap.GitUnstage,
+		&keyMap.GitRestore,
+		&keyMap.GitCommit,
+		&keyMap.GitPreview,
+		&keyMap.CalcDirSizes,
+		&keyMap.OpenUsage,
+		&keyMap.OpenDevices,
+		&keyMap.FindDupes,
+		&keyMap.ShellPrompt,
+		&keyMap.Subshell,
+		&keyMap.CancelCommand,
+	} {
+		b.SetEnabled(false)
+	}
+	return keyMap
+}
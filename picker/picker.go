@@ -0,0 +1,330 @@
+// Package picker provides a file picker that can be embedded in other Bubble Tea
+// programs. It is made of the list, breadcrumb and preview of fman and emits a
+// FileSelectedMsg when entries are picked with enter.
+//
+// Each picker has its own key bindings, styles and zones, so several pickers can be
+// shown at the same time. The messages of the components of a picker are wrapped, so
+// only the picker that sent them handles them.
+package picker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/breadcrumb"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/list"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/preview"
+	"github.com/Philistino/fman/ui/theme"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/spf13/afero"
+)
+
+var lastID int64 // protected by atomic operations
+
+// FileSelectedMsg is sent when entries are picked. Paths holds their absolute paths,
+// sorted by name.
+type FileSelectedMsg struct {
+	ID    int // ID of the picker the entries were picked in
+	Paths []string
+}
+
+// wrappedMsg is a message of a component of the picker with the given ID
+type wrappedMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+// Model is a file picker. It implements tea.Model.
+type Model struct {
+	id       int
+	navi     *nav.Nav
+	list     list.List
+	preview  *preview.FilePreview
+	crumb    *breadcrumb.BreadCrumb
+	keys     keys.KeyMap
+	dirs     bool // pick directories instead of files
+	multiple bool // pick the selected entries instead of the entry under the cursor
+	focused  bool
+
+	zones    *zone.Manager
+	ownZones bool // the zones are scanned by the picker and the manager is closed by Close
+}
+
+// New creates a picker. It lists the working directory of the operating system unless
+// it is set with WithPath.
+func New(opts ...Option) *Model {
+	o := options{
+		fsys:       afero.NewOsFs(),
+		showHidden: true,
+		scheme:     theme.GetActiveTheme("dracula"),
+		icons:      "emoji",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.path == "" {
+		o.path, _ = os.Getwd()
+	}
+	if path, err := filepath.Abs(o.path); err == nil {
+		o.path = path
+	}
+	keyMap := DefaultKeyMap()
+	if o.keyMap != nil {
+		keyMap = *o.keyMap
+	}
+	if !o.multiple {
+		for _, b := range []*key.Binding{
+			&keyMap.MultiSelectUp,
+			&keyMap.MultiSelectDown,
+			&keyMap.MultiSelectToTop,
+			&keyMap.MultiSelectToBottom,
+			&keyMap.MultiSelectAll,
+		} {
+			b.SetEnabled(false)
+		}
+	}
+	m := &Model{
+		id:       int(atomic.AddInt64(&lastID, 1)),
+		keys:     keyMap,
+		dirs:     o.filter.DirsOnly,
+		multiple: o.multiple,
+		focused:  true,
+		zones:    o.zones,
+	}
+	if m.zones == nil {
+		m.zones = zone.New()
+		m.ownZones = true
+	}
+	styles := theme.NewStyleSet(o.scheme, o.icons)
+	m.navi = nav.NewNav(o.showHidden, false, o.path, o.fsys, cfg.DefaultPreviewDelay, false)
+	m.navi.SetFilter(o.filter)
+	m.list = list.New(o.scheme, styles, keyMap, m.zones, cfg.DefaultDoubleClickDelay, nil)
+	m.preview = preview.NewFilePreviewer(o.scheme, styles, keyMap, cfg.DefaultPreviewDelay)
+	m.crumb = breadcrumb.NewBreadCrumb(styles, m.zones)
+	return m
+}
+
+// ID returns the ID of the picker, which is set in the messages it sends
+func (m *Model) ID() int {
+	return m.id
+}
+
+// Path returns the directory the picker lists
+func (m *Model) Path() string {
+	return m.navi.CurrentPath()
+}
+
+// Focused returns whether the picker handles key presses
+func (m *Model) Focused() bool {
+	return m.focused
+}
+
+// Focus makes the picker handle key presses
+func (m *Model) Focus() {
+	m.focused = true
+}
+
+// Blur makes the picker ignore key presses
+func (m *Model) Blur() {
+	m.focused = false
+}
+
+// SetSize sets the size of the picker. The list takes two thirds of the width and the
+// preview the rest.
+func (m *Model) SetSize(width, height int) {
+	listWidth := width * 2 / 3
+	m.crumb.SetWidth(width)
+	m.list.SetWidth(listWidth)
+	m.list.SetHeight(height - 3) // the breadcrumb and the margins of the list
+	m.preview.SetWidth(width - listWidth)
+	m.preview.SetHeight(height - 1)
+}
+
+// Close stops the work the picker does in the background and the zone manager the picker
+// created. The zone manager is left running if it was set with WithZoneManager.
+func (m *Model) Close() {
+	m.navi.Close()
+	if m.ownZones {
+		m.zones.Close()
+	}
+}
+
+// wrap makes the messages of cmd reach only this picker
+func (m *Model) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case FileSelectedMsg:
+			return msg // for the program
+		case tea.BatchMsg:
+			cmds := make([]tea.Cmd, len(msg))
+			for i, c := range msg {
+				cmds[i] = m.wrap(c)
+			}
+			return tea.BatchMsg(cmds)
+		default:
+			return wrappedMsg{id: m.id, msg: msg}
+		}
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	load := message.HandleReloadCmd(m.navi, []string{""}, "")
+	return m.wrap(tea.Batch(load, m.preview.Init()))
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case wrappedMsg:
+		if msg.id != m.id {
+			return m, nil
+		}
+		return m, m.wrap(m.handle(msg.msg))
+	case tea.KeyMsg:
+		if !m.focused {
+			return m, nil
+		}
+		if key.Matches(msg, m.keys.OpenFile) {
+			return m, m.wrap(m.handlePick())
+		}
+		return m, m.wrap(m.update(msg))
+	case tea.MouseMsg:
+		return m, m.wrap(m.update(msg))
+	}
+	return m, nil
+}
+
+// handle handles a message of a component of the picker
+func (m *Model) handle(msg tea.Msg) tea.Cmd {
+	name := m.list.SelectedEntryName()
+	cursor := m.list.CursorName()
+	switch msg := msg.(type) {
+	case message.NavBackMsg:
+		return message.HandleBackCmd(m.navi, []string{name}, cursor)
+	case message.NavFwdMsg:
+		return message.HandleFwdCmd(m.navi, []string{name}, cursor)
+	case message.NavUpMsg:
+		return message.HandleNavCmd(m.navi, []string{name}, filepath.Dir(m.navi.CurrentPath()), cursor)
+	case message.NavHomeMsg:
+		return message.HandleNavCmd(m.navi, []string{name}, "~", cursor)
+	case message.NavDownMsg:
+		return message.HandleNavCmd(m.navi, []string{name}, filepath.Join(m.navi.CurrentPath(), name), cursor)
+	case message.NavOtherMsg:
+		return message.HandleNavCmd(m.navi, []string{name}, msg.Path, cursor)
+	case message.ToggleShowHiddenMsg:
+		m.navi.SetShowHidden(!m.navi.ShowHidden())
+		return message.HandleReloadCmd(m.navi, []string{name}, cursor)
+	case message.SetSortMsg:
+		m.navi.SetSort(msg.Sort) // the sorts are not saved by the picker
+		return message.HandleReloadCmd(m.navi, []string{name}, cursor)
+	case message.GetPreviewMsg:
+		return m.getPreviewCmd(msg.Ctx, msg.Path)
+	case message.ReadTailMsg:
+		return m.readTailCmd(msg.ID, msg.State)
+	}
+	return m.update(msg)
+}
+
+// update passes the message to the components
+func (m *Model) update(msg tea.Msg) tea.Cmd {
+	var listCmd, previewCmd, crumbCmd tea.Cmd
+	m.list, listCmd = m.list.Update(msg)
+	m.preview, previewCmd = m.preview.Update(msg)
+	m.crumb, crumbCmd = m.crumb.Update(msg)
+	return tea.Batch(listCmd, previewCmd, crumbCmd)
+}
+
+func (m *Model) getPreviewCmd(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		prv := m.navi.GetPreview(ctx, path)
+		return preview.PreviewReadyMsg{
+			Path:    path,
+			Preview: prv.Content,
+			Err:     prv.Err,
+		}
+	}
+}
+
+func (m *Model) readTailCmd(id int, state entry.TailState) tea.Cmd {
+	return func() tea.Msg {
+		data, next, reset, err := m.navi.ReadTail(state)
+		return preview.TailReadyMsg{
+			ID:    id,
+			State: next,
+			Data:  data,
+			Reset: reset,
+			Err:   err,
+		}
+	}
+}
+
+// handlePick picks the entries. In a directory without entries, picking directories
+// picks the current directory. Picking files enters a directory under the cursor.
+func (m *Model) handlePick() tea.Cmd {
+	if m.list.IsEmpty() {
+		if !m.dirs {
+			return nil
+		}
+		return m.selectedCmd([]string{m.navi.CurrentPath()})
+	}
+	cursor := m.list.SelectedEntry()
+	if !m.dirs && cursor.IsDir() {
+		return message.NavDownCmd(cursor.Name())
+	}
+
+	var names []string
+	if m.multiple {
+		selected := m.list.SelectedEntries()
+		for _, e := range m.list.Entries() {
+			if _, ok := selected[e.Name()]; ok && e.IsDir() == m.dirs {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+	} else if cursor.IsDir() == m.dirs {
+		names = []string{cursor.Name()}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(m.navi.CurrentPath(), name)
+	}
+	return m.selectedCmd(paths)
+}
+
+// selectedCmd sends the FileSelectedMsg, which is not wrapped so it reaches the program
+func (m *Model) selectedCmd(paths []string) tea.Cmd {
+	msg := FileSelectedMsg{ID: m.id, Paths: paths}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+func (m *Model) View() string {
+	view := lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.crumb.View(),
+		m.list.Mark(lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), m.preview.View())),
+	)
+	if m.ownZones {
+		return m.zones.Scan(view)
+	}
+	return view
+}
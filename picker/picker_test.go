@@ -0,0 +1,182 @@
+package picker
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+)
+
+func testFs(t *testing.T) afero.Fs {
+	fsys := afero.NewMemMapFs()
+	for _, path := range []string{"/dir/a.txt", "/dir/b.txt", "/dir/sub/c.txt", "/other/d.txt"} {
+		if err := afero.WriteFile(fsys, path, []byte(path), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fsys
+}
+
+// drive runs the command and passes its messages to the pickers until no message
+// arrives for a while. It returns the messages for the program.
+func drive(cmd tea.Cmd, pickers ...*Model) []FileSelectedMsg {
+	msgs := make(chan tea.Msg, 100)
+	done := make(chan struct{})
+	defer close(done)
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		go func() {
+			msg := cmd()
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				for _, c := range batch {
+					run(c)
+				}
+				return
+			}
+			select {
+			case msgs <- msg:
+			case <-done:
+			}
+		}()
+	}
+	run(cmd)
+
+	var selected []FileSelectedMsg
+	for {
+		select {
+		case msg := <-msgs:
+			if msg, ok := msg.(FileSelectedMsg); ok {
+				selected = append(selected, msg)
+				continue
+			}
+			if w, ok := msg.(wrappedMsg); ok {
+				if _, ok := w.msg.(spinner.TickMsg); ok {
+					continue // the spinners would keep the pickers busy
+				}
+			}
+			for _, p := range pickers {
+				_, cmd := p.Update(msg)
+				run(cmd)
+			}
+		case <-time.After(200 * time.Millisecond):
+			return selected
+		}
+	}
+}
+
+func press(p *Model, key tea.KeyMsg, pickers ...*Model) []FileSelectedMsg {
+	_, cmd := p.Update(key)
+	return drive(cmd, pickers...)
+}
+
+var enter = tea.KeyMsg{Type: tea.KeyEnter}
+
+func TestPickFile(t *testing.T) {
+	p := New(WithFs(testFs(t)), WithPath("/dir"))
+	defer p.Close()
+	p.SetSize(80, 20)
+	drive(p.Init(), p)
+
+	// the directory is listed first and enter goes into it
+	if got := press(p, enter, p); len(got) != 0 || p.Path() != "/dir/sub" {
+		t.Fatalf("expected to enter /dir/sub, got %v in %s", got, p.Path())
+	}
+	got := press(p, enter, p)
+	want := []FileSelectedMsg{{ID: p.ID(), Paths: []string{"/dir/sub/c.txt"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPickDirs(t *testing.T) {
+	p := New(WithFs(testFs(t)), WithPath("/dir"), WithFilter(entry.Filter{DirsOnly: true}))
+	defer p.Close()
+	p.SetSize(80, 20)
+	drive(p.Init(), p)
+
+	if names := p.list.EntryNames(); !reflect.DeepEqual(names, []string{"sub"}) {
+		t.Fatalf("expected only the directory to be listed, got %v", names)
+	}
+	got := press(p, enter, p)
+	want := []FileSelectedMsg{{ID: p.ID(), Paths: []string{"/dir/sub"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPickMultiple(t *testing.T) {
+	p := New(WithFs(testFs(t)), WithPath("/dir"), WithMultiple(true))
+	defer p.Close()
+	p.SetSize(80, 20)
+	drive(p.Init(), p)
+
+	press(p, tea.KeyMsg{Type: tea.KeyDown}, p)
+	press(p, tea.KeyMsg{Type: tea.KeyShiftDown}, p)
+	got := press(p, enter, p)
+	want := []FileSelectedMsg{{ID: p.ID(), Paths: []string{"/dir/a.txt", "/dir/b.txt"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestTwoPickers tests that the messages of a picker do not reach another picker
+func TestTwoPickers(t *testing.T) {
+	fsys := testFs(t)
+	first := New(WithFs(fsys), WithPath("/dir"))
+	defer first.Close()
+	second := New(WithFs(fsys), WithPath("/other"), WithIcons("none"))
+	defer second.Close()
+	first.SetSize(80, 20)
+	second.SetSize(60, 10)
+	drive(tea.Batch(first.Init(), second.Init()), first, second)
+
+	second.Blur()
+	press(first, enter, first, second)
+	if first.Path() != "/dir/sub" || second.Path() != "/other" {
+		t.Fatalf("expected only the first picker to change its directory, got %s and %s", first.Path(), second.Path())
+	}
+	if names := second.list.EntryNames(); !reflect.DeepEqual(names, []string{"d.txt"}) {
+		t.Errorf("expected the second picker to list /other, got %v", names)
+	}
+	if view := second.View(); !strings.Contains(view, "d.txt") {
+		t.Errorf("expected d.txt in the view of the second picker, got %q", view)
+	}
+
+	first.Blur()
+	second.Focus()
+	_, cmd := first.Update(enter)
+	if cmd != nil {
+		t.Error("a blurred picker should ignore keys")
+	}
+	got := press(second, enter, first, second)
+	want := []FileSelectedMsg{{ID: second.ID(), Paths: []string{"/other/d.txt"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCloseStopsGoroutines(t *testing.T) {
+	fsys := testFs(t)
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		p := New(WithFs(fsys), WithPath("/dir"))
+		p.Close()
+	}
+	// the goroutines see that they were stopped a moment after Close returns
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines are left running after closing the pickers", after-before)
+	}
+}
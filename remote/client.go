@@ -0,0 +1,100 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+)
+
+// Client sends requests to a running fman
+type Client struct {
+	conn   net.Conn
+	dec    *json.Decoder
+	lastID int
+}
+
+// incoming is a response or a notification
+type incoming struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Dial connects to the socket at path
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, dec: json.NewDecoder(conn)}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call sends a request and decodes its result into result, unless result is nil. The
+// events received while waiting for the response are skipped.
+func (c *Client) Call(method string, params any, result any) error {
+	c.lastID++
+	id := strconv.Itoa(c.lastID)
+	req := Request{JSONRPC: version, ID: json.RawMessage(id), Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	for {
+		var msg incoming
+		if err := c.dec.Decode(&msg); err != nil {
+			return err
+		}
+		if msg.Method != "" || string(msg.ID) != id {
+			continue
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	}
+}
+
+// Subscribe subscribes to the events and calls fn with each of them until fn returns an
+// error or fman closes the connection
+func (c *Client) Subscribe(fn func(Notification) error) error {
+	if err := c.Call(MethodSubscribe, nil, nil); err != nil {
+		return err
+	}
+	for {
+		var msg incoming
+		if err := c.dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if msg.Method == "" {
+			continue
+		}
+		if err := fn(Notification{JSONRPC: version, Method: msg.Method, Params: msg.Params}); err != nil {
+			return err
+		}
+	}
+}
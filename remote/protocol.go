@@ -0,0 +1,104 @@
+// Package remote lets other programs drive a running fman. fman listens on a Unix socket
+// named after its session ID, which is exported to the programs it runs in FMAN_SESSION.
+// Clients send JSON-RPC 2.0 requests, one JSON object per line, and can subscribe to the
+// events of fman, which are sent as notifications.
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The methods of the requests
+const (
+	MethodNavigate     = "navigate"      // params: PathParams, result: PathParams
+	MethodSelect       = "select"        // params: SelectParams, result: Selection
+	MethodReload       = "reload"        // result: PathParams
+	MethodGetPath      = "get_path"      // result: PathParams
+	MethodGetSelection = "get_selection" // result: Selection
+	MethodRun          = "run"           // params: RunParams, result: true
+	MethodSubscribe    = "subscribe"     // result: true, then the events are sent as notifications
+)
+
+// The methods of the notifications sent to the subscribers
+const (
+	EventDirChanged       = "dir_changed"       // params: PathParams
+	EventSelectionChanged = "selection_changed" // params: Selection
+)
+
+// PathParams holds the path of a directory
+type PathParams struct {
+	Path string `json:"path"`
+}
+
+// SelectParams holds the names of the entries of the current directory to select
+type SelectParams struct {
+	Names []string `json:"names"`
+}
+
+// RunParams holds the name of the action to run, such as toggle-hidden
+type RunParams struct {
+	Action string `json:"action"`
+}
+
+// Selection holds the names of the selected entries of a directory. The entry under
+// the cursor is selected if no other entry is.
+type Selection struct {
+	Path  string   `json:"path"`
+	Names []string `json:"names"`
+}
+
+const version = "2.0"
+
+// Request is a JSON-RPC request. It is a notification, which is not answered, if it has
+// no ID.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is the answer to a request. It has either a result or an error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is an event sent to the subscribers
+type Notification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// The error codes defined by JSON-RPC
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is the error of a response
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// MethodNotFound returns the error answering a request with an unknown method
+func MethodNotFound(method string) *Error {
+	return &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", method)}
+}
+
+// InvalidParams returns the error answering a request with invalid parameters
+func InvalidParams(err error) *Error {
+	return &Error{Code: CodeInvalidParams, Message: err.Error()}
+}
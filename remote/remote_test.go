@@ -0,0 +1,153 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func startServer(t *testing.T, handler Handler) (*Server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fman.sock")
+	s, err := Listen(path, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+func dial(t *testing.T, path string) *Client {
+	t.Helper()
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCall(t *testing.T) {
+	_, path := startServer(t, func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		switch method {
+		case MethodNavigate:
+			var p PathParams
+			if err := json.Unmarshal(params, &p); err != nil || p.Path == "" {
+				return nil, InvalidParams(errors.New("a path is required"))
+			}
+			return p, nil
+		case MethodReload:
+			return nil, errors.New("failed")
+		}
+		return nil, MethodNotFound(method)
+	})
+	c := dial(t, path)
+
+	var got PathParams
+	if err := c.Call(MethodNavigate, PathParams{Path: "/tmp"}, &got); err != nil || got.Path != "/tmp" {
+		t.Errorf("navigate: got %v, %v", got, err)
+	}
+	tests := []struct {
+		method string
+		params any
+		code   int
+	}{
+		{MethodNavigate, nil, CodeInvalidParams},
+		{MethodReload, nil, CodeInternalError},
+		{"unknown", nil, CodeMethodNotFound},
+	}
+	for _, tt := range tests {
+		err := c.Call(tt.method, tt.params, nil)
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) || rpcErr.Code != tt.code {
+			t.Errorf("%s: got %v, want the code %d", tt.method, err, tt.code)
+		}
+	}
+}
+
+func TestInvalidRequest(t *testing.T) {
+	_, path := startServer(t, func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		return true, nil
+	})
+	c := dial(t, path)
+	for line, code := range map[string]int{
+		"not json\n":                        CodeParseError,
+		`{"id":1,"method":"reload"}` + "\n": CodeInvalidRequest,
+	} {
+		if _, err := c.conn.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		var resp Response
+		if err := c.dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Error == nil || resp.Error.Code != code {
+			t.Errorf("%q: got %+v, want the code %d", line, resp, code)
+		}
+	}
+}
+
+// subscribed reports whether a client of the server subscribed to the events
+func subscribed(s *Server) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		if c.events != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSubscribe(t *testing.T) {
+	s, path := startServer(t, func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		return true, nil
+	})
+	c := dial(t, path)
+	other := dial(t, path)
+
+	events := make(chan Notification, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Subscribe(func(n Notification) error {
+			events <- n
+			return nil
+		})
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !subscribed(s) {
+		if time.Now().After(deadline) {
+			t.Fatal("the client did not subscribe")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.Publish(EventDirChanged, PathParams{Path: "/tmp"})
+	s.Publish(EventSelectionChanged, Selection{Path: "/tmp", Names: []string{"a", "b"}})
+
+	// the events do not reach the clients that did not subscribe
+	if err := other.Call(MethodReload, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := <-events; n.Method != EventDirChanged {
+		t.Errorf("expected the directory change first, got %v", n)
+	}
+	n := <-events
+	var selection Selection
+	if err := json.Unmarshal(n.Params, &selection); err != nil || n.Method != EventSelectionChanged {
+		t.Fatalf("expected the selection, got %v, %v", n, err)
+	}
+	if want := (Selection{Path: "/tmp", Names: []string{"a", "b"}}); !reflect.DeepEqual(selection, want) {
+		t.Errorf("got %v, want %v", selection, want)
+	}
+
+	s.Close()
+	if err := <-done; err != nil {
+		t.Errorf("expected the subscription to end when the server is closed, got %v", err)
+	}
+}
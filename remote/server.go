@@ -0,0 +1,263 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// SessionEnv is the environment variable holding the session ID of the fman that started
+// the program
+const SessionEnv = "FMAN_SESSION"
+
+const (
+	maxRequestSize = 1 << 20 // 1 MB
+	writeTimeout   = 5 * time.Second
+
+	// subscriberBuffer is the number of events kept for a subscriber that reads slowly.
+	// A subscriber that falls further behind is disconnected, so publishing never blocks.
+	subscriberBuffer = 256
+)
+
+// NewSessionID returns a session ID that is unique to this process
+func NewSessionID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%d-%x", os.Getpid(), b)
+}
+
+// SocketPath returns the path of the socket of the session. It is in $XDG_RUNTIME_DIR, or
+// in the temporary directory if it is not set.
+func SocketPath(session string) string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "fman-"+session+".sock")
+}
+
+// Handler answers a request with the result, which is encoded to JSON. An *Error is sent
+// to the client as is and other errors as internal errors.
+type Handler func(ctx context.Context, method string, params json.RawMessage) (any, error)
+
+// Server answers the requests of the clients on a Unix socket and sends the events to
+// the clients that subscribed to them
+type Server struct {
+	ln      net.Listener
+	handler Handler
+	ctx     context.Context // cancelled when the server is closed
+	cancel  context.CancelFunc
+
+	mu    sync.Mutex
+	conns map[*conn]struct{}
+}
+
+// conn is the connection of a client
+type conn struct {
+	net.Conn
+	writeMu sync.Mutex
+	events  chan []byte // set once the client subscribed, closed when it is dropped
+}
+
+// Listen creates the socket at path. Only the user can connect to it.
+func Listen(path string, handler Handler) (*Server, error) {
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, 0o600); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		ln:      ln,
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+		conns:   make(map[*conn]struct{}),
+	}, nil
+}
+
+// Serve accepts the clients until the server is closed
+func (s *Server) Serve() error {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		cn := &conn{Conn: c}
+		s.mu.Lock()
+		s.conns[cn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(cn)
+	}
+}
+
+// Close stops accepting clients, disconnects the clients and removes the socket
+func (s *Server) Close() error {
+	s.cancel()
+	err := s.ln.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	return err
+}
+
+// Publish sends the event to the subscribers. It does not wait for them to read it.
+func (s *Server) Publish(event string, params any) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	line, err := json.Marshal(Notification{JSONRPC: version, Method: event, Params: data})
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		if c.events == nil {
+			continue
+		}
+		select {
+		case c.events <- line:
+		default:
+			// the reads of serveConn fail and the subscriber is dropped
+			c.Close()
+		}
+	}
+}
+
+// serveConn answers the requests of the client, one JSON object per line
+func (s *Server) serveConn(c *conn) {
+	defer s.drop(c)
+	scanner := bufio.NewScanner(c)
+	scanner.Buffer(make([]byte, 0, 4096), maxRequestSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resp, events := s.answer(c, line)
+		if resp != nil {
+			data, err := json.Marshal(resp)
+			if err != nil {
+				continue
+			}
+			if err := c.write(data); err != nil {
+				return
+			}
+		}
+		if events != nil {
+			// the events are written after the answer to subscribe, so the client
+			// does not receive them before it knows it subscribed
+			go c.writeEvents(events)
+		}
+	}
+}
+
+// answer handles a request and returns its response, or nil if the request is a
+// notification. It returns the queue of the events if the client subscribed.
+func (s *Server) answer(c *conn, line []byte) (*Response, <-chan []byte) {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: err.Error()}), nil
+	}
+	if req.JSONRPC != version || req.Method == "" {
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: "not a JSON-RPC 2.0 request"}), nil
+	}
+
+	var result any
+	var err error
+	var events <-chan []byte
+	if req.Method == MethodSubscribe {
+		events = s.subscribe(c)
+		result = true
+	} else {
+		result, err = s.handler(s.ctx, req.Method, req.Params)
+	}
+	if req.ID == nil {
+		return nil, events
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr), events
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, &Error{Code: CodeInternalError, Message: err.Error()}), events
+	}
+	return &Response{JSONRPC: version, ID: req.ID, Result: data}, events
+}
+
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: version, ID: id, Error: err}
+}
+
+// subscribe queues the events for the client from now on. It returns the queue, or nil
+// if the client already subscribed.
+func (s *Server) subscribe(c *conn) <-chan []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.events != nil {
+		return nil
+	}
+	c.events = make(chan []byte, subscriberBuffer)
+	return c.events
+}
+
+// drop disconnects the client
+func (s *Server) drop(c *conn) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	if c.events != nil {
+		close(c.events)
+		c.events = nil
+	}
+	s.mu.Unlock()
+	c.Close()
+}
+
+// writeEvents writes the queued events until the client is dropped
+func (c *conn) writeEvents(events <-chan []byte) {
+	for line := range events {
+		if err := c.write(line); err != nil {
+			c.Close()
+		}
+	}
+}
+
+// write writes a JSON object and a newline. The responses and the events are written by
+// different goroutines, so the writes are serialized.
+func (c *conn) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.SetWriteDeadline(time.Now().Add(writeTimeout))
+	// the events are shared by the subscribers, so the newline is appended to a copy
+	_, err := c.Write(append(data[:len(data):len(data)], '\n'))
+	return err
+}
@@ -0,0 +1,91 @@
+// Package shell integrates fman with the shell and the terminal. It provides the shell
+// functions printed by fman init, which change the directory of the shell to the last
+// directory of fman, and the OSC 7 sequence that tells the terminal the current directory.
+package shell
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The functions run fman with --cwd-file, so the final directory is read from a temporary
+// file and stdout stays free for the programs fman opens. fman init is passed through, so
+// the configuration of the shell can be read again after the function is defined.
+
+const posixScript = `fman() {
+    if [ "$1" = init ]; then
+        command fman "$@"
+        return
+    fi
+    local cwd_file dir ret
+    cwd_file="$(mktemp "${TMPDIR:-/tmp}/fman-cwd.XXXXXX")" || return
+    command fman --cwd-file "$cwd_file" "$@"
+    ret=$?
+    dir="$(cat -- "$cwd_file")"
+    rm -f -- "$cwd_file"
+    if [ -n "$dir" ] && [ "$dir" != "$PWD" ] && [ -d "$dir" ]; then
+        cd -- "$dir" || return
+    fi
+    return $ret
+}
+`
+
+const fishScript = `function fman --description 'fman, changing to its last directory on exit'
+    if test "$argv[1]" = init
+        command fman $argv
+        return
+    end
+    set -l tmp /tmp
+    set -q TMPDIR; and set tmp $TMPDIR
+    set -l cwd_file (mktemp $tmp/fman-cwd.XXXXXX); or return
+    command fman --cwd-file $cwd_file $argv
+    set -l ret $status
+    set -l dir (cat -- $cwd_file | string collect)
+    rm -f -- $cwd_file
+    if test -n "$dir" -a "$dir" != "$PWD" -a -d "$dir"
+        cd -- $dir
+    end
+    return $ret
+end
+`
+
+var scripts = map[string]string{
+	"bash": posixScript,
+	"zsh":  posixScript,
+	"fish": fishScript,
+}
+
+// Shells returns the names of the shells there is a function for
+func Shells() []string {
+	names := make([]string, 0, len(scripts))
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Script returns the function for the shell, which runs fman and changes the directory of
+// the shell to the directory fman was in when it quit
+func Script(name string) (string, error) {
+	script, ok := scripts[name]
+	if !ok {
+		return "", fmt.Errorf("unknown shell %q, expected one of %s", name, strings.Join(Shells(), ", "))
+	}
+	return script, nil
+}
+
+// OSC7 returns the escape sequence that tells the terminal that the current directory is
+// dir on the host
+func OSC7(host string, dir string) string {
+	path := filepath.ToSlash(dir)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths start with the volume name
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Host: host, Path: path}
+	return "\x1b]7;" + u.String() + "\x1b\\"
+}
@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	for _, name := range Shells() {
+		script, err := Script(name)
+		if err != nil || !strings.Contains(script, "--cwd-file") {
+			t.Errorf("%s: got %q, %v", name, script, err)
+		}
+	}
+	if _, err := Script("tcsh"); err == nil {
+		t.Error("expected an error for an unknown shell")
+	}
+}
+
+func TestOSC7(t *testing.T) {
+	tests := []struct {
+		host, dir, want string
+	}{
+		{"box", "/home/me/my dir", "\x1b]7;file://box/home/me/my%20dir\x1b\\"},
+		{"", "/", "\x1b]7;file:///\x1b\\"},
+	}
+	for _, tt := range tests {
+		if got := OSC7(tt.host, tt.dir); got != tt.want {
+			t.Errorf("OSC7(%q, %q) = %q, want %q", tt.host, tt.dir, got, tt.want)
+		}
+	}
+}
+
+// TestScriptChangesDirectory runs the functions with a fake fman that writes a directory
+// to the file given with --cwd-file
+func TestScriptChangesDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the functions are for unix shells")
+	}
+	bin := t.TempDir()
+	target := t.TempDir()
+	fake := "#!/bin/sh\n[ \"$1\" = --cwd-file ] && printf '%s' \"$FMAN_TARGET\" > \"$2\"\nexit 3\n"
+	if err := os.WriteFile(filepath.Join(bin, "fman"), []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"bash": `eval "$SCRIPT"; fman some/path; echo "$? $PWD"`,
+		"zsh":  `eval "$SCRIPT"; fman some/path; echo "$? $PWD"`,
+		"fish": `eval "$SCRIPT"; fman some/path; echo "$status $PWD"`,
+	}
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := exec.LookPath(name); err != nil {
+				t.Skipf("%s is not installed", name)
+			}
+			script, _ := Script(name)
+			cmd := exec.Command(name, "-c", run)
+			cmd.Dir = bin
+			cmd.Env = append(os.Environ(),
+				"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+				"SCRIPT="+script,
+				"FMAN_TARGET="+target,
+			)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			if got, want := strings.TrimSpace(string(out)), "3 "+target; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package app
 
 import (
+	"context"
 	"path/filepath"
 
 	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/hook"
 	"github.com/Philistino/fman/nav"
 	"github.com/Philistino/fman/ui/breadcrumb"
 	"github.com/Philistino/fman/ui/dialog"
@@ -37,6 +41,8 @@ type App struct {
 	dialog     *dialog.Dialog
 	breadcrumb *breadcrumb.BreadCrumb
 
+	screen screen // set while a full screen view such as the pager is shown
+
 	width  int
 	height int
 
@@ -46,6 +52,28 @@ type App struct {
 
 	Navi  *nav.Nav
 	theme colors.Theme
+
+	openers     []entry.Opener
+	commands    []userCommand
+	openRequest openRequest     // files and openers offered in the "open with" dialog
+	bulkRenames []rename.Rename // renames waiting for the user to confirm them
+	gitRestore  []string        // entries waiting for the user to confirm discarding their changes
+	sizer       dirSizer
+	gitCancel   context.CancelFunc // cancels reading the git status of the previous directory
+	diffCancel  context.CancelFunc // cancels the comparison of the selected entries
+	cwd         *cwdReporter       // set if the current directory is reported to the terminal
+	chooser     *chooser           // set if fman is used to choose entries for another program
+	remote      *remoteState       // set if remote clients can drive fman
+
+	hooks        hook.Hooks // commands run on the events of fman
+	hookDir      string     // directory the on-cd hooks last ran for
+	selectionKey string     // selection last sent to the remote subscribers and the on-select hooks
+	hookQueues   map[hook.Event]*hookQueue
+
+	running       map[int]*runningCommand // commands running in the background by id
+	lastCommandID int
+
+	shellOutputs []shellOutput // output of shell commands that finished while a full screen view was shown
 }
 
 func (app *App) Init() tea.Cmd {
@@ -69,25 +97,53 @@ func NewApp(cfg cfg.Cfg, selectedTheme colors.Theme, fsys afero.Fs) *App {
 	if err != nil {
 		panic(err)
 	}
+	commands := newCommands(cfg.Commands)
 	app := App{
-		fileBtns:   filebtns.NewFileBtns(),
-		list:       list.New(selectedTheme, *cfg.DoubleClickDelay),
-		preview:    preview.NewFilePreviewer(selectedTheme, *cfg.PreviewDelay),
+		fileBtns:   filebtns.NewFileBtns(commandButtons(commands)),
+		list:       list.New(selectedTheme, theme.GlobalStyleSet(), keys.Map, zone.DefaultManager, *cfg.DoubleClickDelay, newColumns(cfg.Columns)),
+		preview:    preview.NewFilePreviewer(selectedTheme, theme.GlobalStyleSet(), keys.Map, *cfg.PreviewDelay),
 		navBtns:    navbtns.NewNavBtns(),
 		infobar:    infobar.New(),
 		dialog:     dialog.NewDialog(theme.ButtonStyle, theme.EntryInfoStyle),
 		Navi:       nav.NewNav(!*cfg.NoHidden, *cfg.DirsMixed, absPath, fsys, *cfg.PreviewDelay, *cfg.DryRun),
-		breadcrumb: breadcrumb.NewBreadCrumb(),
+		breadcrumb: breadcrumb.NewBreadCrumb(theme.GlobalStyleSet(), zone.DefaultManager),
 		theme:      selectedTheme,
 		config:     cfg,
 		help:       help.New(selectedTheme, keys.Map, theme.EmptyFolderStyle),
+		openers:    newOpeners(cfg.Openers),
+		commands:   commands,
+		chooser:    newChooser(cfg),
+		hooks:      newHooks(cfg.Hooks),
+	}
+	if app.chooser != nil {
+		app.Navi.SetFilter(chooserFilter(cfg))
+	}
+	if len(commands) > 0 {
+		app.help.AddGroup(commandBindings(commands))
 	}
+	app.registerPreviewers(cfg.Previewers)
+	app.setupSort(cfg, fsys)
 	return &app
 }
 
 func (app *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
+	// the remote clients are answered whatever view is shown
+	switch msg := msg.(type) {
+	case remoteRequestMsg:
+		return app, app.handleRemoteRequest(msg)
+	case remoteDoneMsg:
+		return app.handleRemoteDone(msg)
+	}
+	if app.screen != nil {
+		var handled bool
+		cmd, handled = app.updateScreen(msg)
+		if handled {
+			return app, cmd
+		}
+		cmds = append(cmds, cmd)
+	}
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		app.manageSizes(msg.Height, msg.Width)
@@ -113,6 +169,9 @@ func (app *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case message.InternalCopyMsg, message.CutMsg:
 		cmd = app.handleCopy(msg)
 		cmds = append(cmds, cmd)
+	case message.PasteLinksMsg:
+		cmd = app.handlePasteLinks()
+		cmds = append(cmds, cmd)
 	case message.InternalPasteMsg:
 		// cmd = app.handlePaste()
 		// cmds = append(cmds, cmd)
@@ -120,16 +179,132 @@ func (app *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		app.Navi.SetShowHidden(!app.Navi.ShowHidden())
 		cmd = message.HandleReloadCmd(app.Navi, []string{app.list.SelectedEntryName()}, app.list.CursorName())
 		cmds = append(cmds, cmd)
+	case message.NewEntryMsg:
+		app.cancelCompare()
+	case message.DirChangedMsg:
+		app.cancelCompare()
+		cmd = app.handleDirChangedSizes(msg)
+		cmds = append(cmds, cmd, app.handleDirChangedGit(msg))
+		app.handleDirChangedCwd(msg)
+		app.handleDirChangedRemote(msg)
+		cmds = append(cmds, app.handleDirChangedHooks(msg))
+	case hookPassedMsg:
+		cmd = msg.proceed()
+		cmds = append(cmds, cmd)
+	case hookTickMsg:
+		cmd = app.handleHookTick(msg)
+		cmds = append(cmds, cmd)
+	case hookDoneMsg:
+		cmd = app.handleHookDone(msg)
+		cmds = append(cmds, cmd)
+	case hookVetoMsg:
+		cmd = app.handleErrorsAndReload([]error{msg.err})
+		cmds = append(cmds, cmd)
+	case message.GitStatusMsg:
+		if msg.Err != nil {
+			cmds = append(cmds, message.NewNotificationCmd("git status: "+msg.Err.Error()))
+		}
+	case message.CalcDirSizesMsg:
+		cmd = app.handleCalcDirSizes()
+		cmds = append(cmds, cmd)
+	case message.DirSizeMsg:
+		cmd = app.handleDirSize(msg)
+		cmds = append(cmds, cmd)
+	case message.RunCommandMsg:
+		cmd = app.handleRunCommand(msg.Index)
+		cmds = append(cmds, cmd)
+	case commandDoneMsg:
+		cmd = app.handleCommandDone(msg)
+		cmds = append(cmds, cmd)
+	case commandExitMsg:
+		cmd = app.handleCommandExit(msg)
+		cmds = append(cmds, cmd)
+	case commandTickMsg:
+		cmd = app.handleCommandTick(msg)
+		cmds = append(cmds, cmd)
+	case message.SubshellMsg:
+		cmd = app.openSubshell()
+		cmds = append(cmds, cmd)
+	case subshellDoneMsg:
+		cmd = app.handleErrorsAndReload([]error{msg.err})
+		cmds = append(cmds, cmd)
+	case message.SetSortMsg:
+		cmd = app.handleSetSort(msg.Sort)
+		cmds = append(cmds, cmd)
 	case message.GetPreviewMsg:
 		cmd = app.getPreviewCmd(msg.Ctx, msg.Path)
 		cmds = append(cmds, cmd)
+	case message.GetGitLogMsg:
+		cmd = app.getGitLogCmd(msg.Ctx, msg.Path)
+		cmds = append(cmds, cmd)
+	case message.GetRevisionPreviewMsg:
+		cmd = app.getRevisionPreviewCmd(msg.Ctx, msg.Path, msg.Revision)
+		cmds = append(cmds, cmd)
+	case message.GetGitDiffMsg:
+		cmd = app.getGitDiffCmd(msg.Ctx, msg.Path)
+		cmds = append(cmds, cmd)
+	case message.ReadTailMsg:
+		cmd = app.readTailCmd(msg.ID, msg.State)
+		cmds = append(cmds, cmd)
+	case message.OpenFileMsg:
+		cmd = app.handleOpen()
+		cmds = append(cmds, cmd)
+	case message.OpenWithMsg:
+		cmd = app.handleOpenWith()
+		cmds = append(cmds, cmd)
+	case message.OpenPagerMsg:
+		cmd = app.openPager()
+		cmds = append(cmds, cmd)
+	case message.OpenUsageMsg:
+		cmd = app.openUsage()
+		cmds = append(cmds, cmd)
+	case message.OpenDevicesMsg:
+		cmd = app.openDevices()
+		cmds = append(cmds, cmd)
+	case message.FindDupesMsg:
+		cmd = app.openDupes()
+		cmds = append(cmds, cmd)
+	case message.CompareMsg:
+		cmd = app.handleCompare()
+		cmds = append(cmds, cmd)
+	case preview.DiffReadyMsg:
+		if msg.Err != nil {
+			cmds = append(cmds, message.NewNotificationCmd(msg.Err.Error()))
+		}
 	case message.DeleteMsg:
 		cmd = app.handleDeleteCmd()
 		cmds = append(cmds, cmd)
 	case dialog.AnswerMsg:
 		cmd = app.handleDialogAnswer(msg)
 		cmds = append(cmds, cmd)
-	case message.NewFileMsg, message.MkDirMsg, message.RenameMsg:
+	case message.BulkRenameMsg:
+		cmd = app.handleBulkRename()
+		cmds = append(cmds, cmd)
+	case bulkRenameEditedMsg:
+		cmd = app.handleBulkRenameEdited(msg)
+		cmds = append(cmds, cmd)
+	case message.BatchRenameMsg:
+		cmd = app.openBatchRename()
+		cmds = append(cmds, cmd)
+	case message.UndoRenameMsg:
+		cmd = app.handleUndoRename()
+		cmds = append(cmds, cmd)
+	case message.ChangePermissionsMsg:
+		cmd = app.openPermissions()
+		cmds = append(cmds, cmd)
+	case message.GitStageMsg:
+		cmd = app.handleGitStage()
+		cmds = append(cmds, cmd)
+	case message.GitUnstageMsg:
+		cmd = app.handleGitUnstage()
+		cmds = append(cmds, cmd)
+	case message.GitRestoreMsg:
+		cmd = app.handleGitRestore()
+		cmds = append(cmds, cmd)
+	case gitDoneMsg:
+		cmd = app.handleGitDone(msg)
+		cmds = append(cmds, cmd)
+	case message.NewFileMsg, message.MkDirMsg, message.RenameMsg, message.GitCommitMsg, message.ShellPromptMsg:
 		cmd = app.promptInput(msg)
 		cmds = append(cmds, cmd)
 	case infobar.PromptAnswerMsg:
@@ -137,7 +312,7 @@ func (app *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, keys.Map.ToggleHelp):
+		case key.Matches(msg, keys.Map.ToggleHelp) && !app.infobar.PromptFocused(): // ? can be typed into the prompt
 			// TODO Freeze components if showing help
 			if app.showHelp {
 				cmd = func() tea.Msg {
@@ -149,6 +324,16 @@ func (app *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			app.showHelp = !app.showHelp
 		case key.Matches(msg, keys.Map.Quit):
 			return app, tea.Quit
+		case app.chooser != nil && key.Matches(msg, keys.Map.OpenFile) && app.list.Focused():
+			return app, app.handleChoose()
+		case key.Matches(msg, keys.Map.CancelCommand) && len(app.running) > 0 && app.list.Focused():
+			app.cancelCommands()
+			return app, nil
+		case app.list.Focused():
+			// the key of a command is not also handled by the list
+			if cmd, ok := app.handleCommandKey(msg); ok {
+				return app, cmd
+			}
 		}
 	}
 
@@ -164,12 +349,17 @@ func (app *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	app.help, helpCmd = app.help.Update(msg)
 
 	cmds = append(cmds, listCmd, toolbarCmd, entryCmd, infobarCmd, buttonBarCmd, breadCrmbCmd, dialogCmd, helpCmd)
+	cmds = append(cmds, app.handleSelectionChanged())
 
 	return app, tea.Batch(cmds...)
 }
 
 func (app *App) View() string {
 
+	if app.screen != nil {
+		return app.screen.View()
+	}
+
 	var view string
 	switch {
 	case app.dialog.Focused():
@@ -186,17 +376,23 @@ func (app *App) View() string {
 			app.list.View(),
 			app.preview.View(),
 		)
-		view = zone.Mark("list", view)
+		view = app.list.Mark(view)
 	}
 
 	secondRow := lipgloss.JoinHorizontal(lipgloss.Top, app.navBtns.View(), app.breadcrumb.View())
 
+	// a prompt that spans several lines takes rows from the bottom of the list
+	infobarView := app.infobar.View()
+	if extra := lipgloss.Height(infobarView) - 1; extra > 0 {
+		view = lipgloss.NewStyle().MaxHeight(lipgloss.Height(view) - extra).Render(view)
+	}
+
 	return zone.Scan(lipgloss.JoinVertical(
 		lipgloss.Top,
 		app.fileBtns.View(),
 		secondRow,
 		view,
-		app.infobar.View(),
+		infobarView,
 	))
 }
 
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/hook"
+	"github.com/Philistino/fman/ui/batchrename"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openBatchRename shows the pattern rename view for the selected entries
+func (app *App) openBatchRename() tea.Cmd {
+	selected := app.list.SelectedEntries()
+	entries := make([]rename.Entry, 0, len(selected))
+	for _, e := range app.list.Entries() {
+		if _, ok := selected[e.Name()]; !ok {
+			continue
+		}
+		entries = append(entries, rename.Entry{Name: e.Name(), ModTime: e.ModTime()})
+	}
+	if len(entries) == 0 {
+		return message.NewNotificationCmd("No entries selected")
+	}
+	view, cmd := batchrename.New(app.Navi, entries, app.theme, app.width, app.height)
+	app.openScreen(newScreen(view, app.closeBatchRename))
+	return cmd
+}
+
+func (app *App) closeBatchRename(msg batchrename.ClosedMsg) tea.Cmd {
+	if msg.Renamed == 0 {
+		return nil
+	}
+	return tea.Batch(
+		message.NewNotificationCmd(fmt.Sprintf("Renamed %d entries", msg.Renamed)),
+		app.handleErrorsAndReload(nil),
+		app.runHooks(hook.PostRename, app.renameHookData(msg.Renames)),
+	)
+}
+
+// handleUndoRename renames the entries of the last bulk or batch rename back
+func (app *App) handleUndoRename() tea.Cmd {
+	dir, count, err := app.Navi.UndoRename(context.Background())
+	if err != nil {
+		return app.handleErrorsAndReload([]error{err})
+	}
+	notice := fmt.Sprintf("Renamed %d entries back", count)
+	if dir != app.Navi.CurrentPath() {
+		notice += " in " + dir
+	}
+	return tea.Batch(message.NewNotificationCmd(notice), app.handleErrorsAndReload(nil))
+}
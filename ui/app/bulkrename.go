@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/hook"
+	"github.com/Philistino/fman/ui/dialog"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const bulkRenameDialogID = "BulkRename"
+
+// maxRenamesShown is the number of renames listed in the confirmation dialog
+const maxRenamesShown = 10
+
+// bulkRenameEditedMsg is sent when the editor with the names of the selected entries exits
+type bulkRenameEditedMsg struct {
+	file  string   // temporary file holding the names
+	names []string // names before they were edited
+	err   error
+}
+
+// handleBulkRename writes the names of the selected entries to a temporary file, one
+// per line, and opens it in $EDITOR
+func (app *App) handleBulkRename() tea.Cmd {
+	selected := app.list.SelectedEntries()
+	names := make([]string, 0, len(selected))
+	for _, e := range app.list.Entries() {
+		if _, ok := selected[e.Name()]; !ok {
+			continue
+		}
+		if strings.ContainsAny(e.Name(), "\r\n") {
+			return message.NewNotificationCmd(fmt.Sprintf("%q contains a line break and cannot be renamed in the editor", e.Name()))
+		}
+		names = append(names, e.Name())
+	}
+	if len(names) == 0 {
+		return message.NewNotificationCmd("No entries selected")
+	}
+
+	f, err := os.CreateTemp("", "fman-rename-*.txt")
+	if err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	_, err = f.WriteString(strings.Join(names, "\n") + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return message.NewNotificationCmd(err.Error())
+	}
+	file := f.Name()
+	return message.ExecProcess(message.EditorCommand(file), func(err error) tea.Msg {
+		return bulkRenameEditedMsg{file: file, names: names, err: err}
+	})
+}
+
+// handleBulkRenameEdited reads the edited names and asks the user to confirm the renames
+func (app *App) handleBulkRenameEdited(msg bulkRenameEditedMsg) tea.Cmd {
+	defer os.Remove(msg.file)
+	if msg.err != nil {
+		return message.NewNotificationCmd(msg.err.Error())
+	}
+	content, err := os.ReadFile(msg.file)
+	if err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	renames, err := rename.Parse(msg.names, string(content))
+	if err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	if len(renames) == 0 {
+		return message.NewNotificationCmd("No names were changed")
+	}
+	if err := app.Navi.ValidateRenames(renames); err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	app.bulkRenames = renames
+	app.list.Blur()
+	return message.AskDialogCmd(bulkRenameDialogID, renameDiff(renames), []string{"Cancel", "Confirm"})
+}
+
+func (app *App) handleBulkRenameAnswer(msg dialog.AnswerMsg) tea.Cmd {
+	renames := app.bulkRenames
+	app.bulkRenames = nil
+	if msg.Answer() != "Confirm" {
+		app.list.Focus()
+		return nil
+	}
+	errs := app.Navi.BulkRename(context.Background(), renames)
+	if len(errs) == 0 {
+		return tea.Batch(
+			message.NewNotificationCmd(fmt.Sprintf("Renamed %d entries", len(renames))),
+			app.handleErrorsAndReload(nil),
+			app.runHooks(hook.PostRename, app.renameHookData(renames)),
+		)
+	}
+	return app.handleErrorsAndReload(errs)
+}
+
+// renameDiff lists the renames for the confirmation dialog
+func renameDiff(renames []rename.Rename) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Rename %d entries?\n", len(renames))
+	for i, r := range renames {
+		if i == maxRenamesShown {
+			fmt.Fprintf(&b, "\n…and %d more", len(renames)-maxRenamesShown)
+			break
+		}
+		fmt.Fprintf(&b, "\n- %s\n+ %s", r.From, r.To)
+	}
+	return b.String()
+}
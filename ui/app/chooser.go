@@ -0,0 +1,76 @@
+package app
+
+import (
+	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// chooser is set when fman is used to choose files or directories for another program.
+// enter chooses the entries and quits instead of opening them.
+type chooser struct {
+	dirs     bool     // choose directories instead of files
+	multiple bool     // choose the selected entries instead of the entry under the cursor
+	chosen   []string // absolute paths of the chosen entries, nil until enter is pressed
+}
+
+// newChooser returns the chooser configured on the cli, or nil if fman is not used as a
+// chooser
+func newChooser(config cfg.Cfg) *chooser {
+	if config.ChooseFiles == "" && config.ChooseDir == "" {
+		return nil
+	}
+	return &chooser{dirs: config.ChooseDir != "", multiple: config.ChooseMultiple}
+}
+
+// chooserFilter returns the filter of the entries that are listed while choosing
+func chooserFilter(config cfg.Cfg) entry.Filter {
+	return entry.Filter{DirsOnly: config.ChooseDir != "", Patterns: config.ChooseFilter}
+}
+
+// Chosen returns the absolute paths of the entries chosen in chooser mode. It is nil if
+// fman quit without choosing.
+func (app *App) Chosen() []string {
+	if app.chooser == nil {
+		return nil
+	}
+	return app.chooser.chosen
+}
+
+// handleChoose chooses the entries and quits. In a directory without entries, choosing
+// directories chooses the current directory. Choosing files enters a directory under the
+// cursor, as when opening it.
+func (app *App) handleChoose() tea.Cmd {
+	if len(app.list.Entries()) == 0 {
+		if !app.chooser.dirs {
+			return nil
+		}
+		app.chooser.chosen = []string{app.Navi.CurrentPath()}
+		return tea.Quit
+	}
+	cursor := app.list.SelectedEntry()
+	if !app.chooser.dirs && cursor.IsDir() {
+		return message.NavDownCmd(cursor.Name())
+	}
+
+	var names []string
+	if app.chooser.multiple {
+		selected := app.list.SelectedEntries()
+		for _, e := range app.list.Entries() {
+			if _, ok := selected[e.Name()]; ok && e.IsDir() == app.chooser.dirs {
+				names = append(names, e.Name())
+			}
+		}
+	} else if cursor.IsDir() == app.chooser.dirs {
+		names = []string{cursor.Name()}
+	}
+	if len(names) == 0 {
+		return message.NewNotificationCmd("None of the selected entries can be chosen")
+	}
+	app.chooser.chosen = make([]string, len(names))
+	for i, name := range names {
+		app.chooser.chosen[i] = app.fullPath(name)
+	}
+	return tea.Quit
+}
@@ -1,10 +1,23 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Philistino/fman/hook"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/dialog"
 	"github.com/Philistino/fman/ui/message"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+const pasteLinksDialogID = "PasteLinks"
+
+// pasteLinkOptions are the answers of the paste links dialog
+var pasteLinkOptions = []string{"Symlink", "Relative symlink", "Hard link", "Cancel"}
+
 // clipboardCopy sets the internal clipboard to the selected entries
 func (app *App) handleCopy(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
@@ -21,6 +34,39 @@ func (app *App) handleCopy(msg tea.Msg) tea.Cmd {
 	return cmd
 }
 
+// handlePasteLinks asks which kind of links to create to the entries in the clipboard
+func (app *App) handlePasteLinks() tea.Cmd {
+	paths := app.Navi.ClipboardPaths()
+	if len(paths) == 0 {
+		return message.NewNotificationCmd("Nothing to paste")
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	app.list.Blur()
+	return message.AskDialogCmd(
+		pasteLinksDialogID,
+		fmt.Sprintf("Link here to\n%s", strings.Join(names, ", ")),
+		pasteLinkOptions,
+	)
+}
+
+func (app *App) handlePasteLinksAnswer(msg dialog.AnswerMsg) tea.Cmd {
+	kinds := []nav.LinkKind{nav.LinkAbsolute, nav.LinkRelative, nav.LinkHard}
+	if msg.AnswerIdx() >= len(kinds) {
+		app.list.Focus()
+		return nil
+	}
+	sources := app.Navi.ClipboardPaths()
+	data := hook.Data{Dir: app.Navi.CurrentPath(), Sources: sources, Paths: make([]string, len(sources))}
+	for i, source := range sources {
+		data.Paths[i] = filepath.Join(data.Dir, filepath.Base(source))
+	}
+	errs := app.Navi.ClipboardPasteLinks(context.Background(), kinds[msg.AnswerIdx()])
+	return tea.Batch(app.handleErrorsAndReload(errs), app.runPostHooks(hook.PostPaste, data, errs))
+}
+
 // // TODO: make this real
 // func (app *App) handlePaste() tea.Cmd {
 // 	if app.clipboard.Empty() {
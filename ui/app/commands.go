@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/filebtns"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/preview"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// userCommand is a command of the config and the key that runs it
+type userCommand struct {
+	entry.UserCommand
+	binding key.Binding
+}
+
+// commandDoneMsg is sent when a user command run in the foreground or the background exits
+type commandDoneMsg struct {
+	name   string
+	output []byte
+	err    error
+}
+
+// newCommands converts the commands from the config. Commands with an unknown mode are
+// skipped, and a key that is already bound by fman is not bound to the command.
+func newCommands(commands []cfg.CommandCfg) []userCommand {
+	converted := make([]userCommand, 0, len(commands))
+	for _, c := range commands {
+		mode, err := entry.ParseCommandMode(c.Mode)
+		if err != nil {
+			log.Printf("command %s: %s. Options are: foreground, background, preview", c.Name, err)
+			continue
+		}
+		// a binding without keys is disabled, so commands without a key are only
+		// run with their button
+		binding := key.NewBinding(key.WithHelp(c.Key, c.Name))
+		if used, ok := boundKey(c.Key); ok {
+			log.Printf("command %s: key %s is already bound to %q, so the command has no key", c.Name, c.Key, used.Help().Desc)
+		} else if c.Key != "" {
+			binding.SetKeys(c.Key)
+		}
+		converted = append(converted, userCommand{
+			UserCommand: entry.UserCommand{
+				Name:    c.Name,
+				Key:     c.Key,
+				Command: c.Command,
+				Mode:    mode,
+				Button:  c.Button,
+			},
+			binding: binding,
+		})
+	}
+	return converted
+}
+
+// boundKey returns the enabled binding of the file list that uses the key, if any. The
+// commands only run from the file list, so the keys of the full screen views are free.
+func boundKey(k string) (key.Binding, bool) {
+	if k == "" {
+		return key.Binding{}, false
+	}
+	for _, binding := range keys.Map.ListBindings() {
+		if !binding.Enabled() {
+			continue
+		}
+		for _, bound := range binding.Keys() {
+			if bound == k {
+				return binding, true
+			}
+		}
+	}
+	return key.Binding{}, false
+}
+
+// commandButtons returns the buttons of the commands that are shown as buttons
+func commandButtons(commands []userCommand) []filebtns.CommandBtn {
+	var buttons []filebtns.CommandBtn
+	for i, c := range commands {
+		if c.Button {
+			buttons = append(buttons, filebtns.CommandBtn{Name: c.Name, Index: i})
+		}
+	}
+	return buttons
+}
+
+// commandBindings returns the keys of the commands for the help
+func commandBindings(commands []userCommand) []key.Binding {
+	bindings := make([]key.Binding, 0, len(commands))
+	for _, c := range commands {
+		bindings = append(bindings, c.binding)
+	}
+	return bindings
+}
+
+// handleCommandKey runs the command bound to the key, if any. It returns false if no
+// command is bound to the key.
+func (app *App) handleCommandKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	for i, c := range app.commands {
+		if key.Matches(msg, c.binding) {
+			return message.RunCommandCmd(i), true
+		}
+	}
+	return nil, false
+}
+
+// handleRunCommand runs the user command with the given index on the selected entries
+func (app *App) handleRunCommand(index int) tea.Cmd {
+	if index < 0 || index >= len(app.commands) {
+		return nil
+	}
+	c := app.commands[index].UserCommand
+	vars := app.commandVars()
+	newCmd := func(ctx context.Context) (*exec.Cmd, error) {
+		return c.CmdContext(ctx, vars)
+	}
+	switch c.Mode {
+	case entry.CommandBackground:
+		return app.startCommand(c.Name, newCmd, func(output []byte, err error) tea.Cmd {
+			return app.handleCommandDone(commandDoneMsg{name: c.Name, output: output, err: err})
+		})
+	case entry.CommandPreview:
+		return app.startCommand(c.Name, newCmd, func(output []byte, err error) tea.Cmd {
+			msg := preview.CommandOutputMsg{Name: c.Name, Output: string(output), Err: err}
+			return func() tea.Msg { return msg }
+		})
+	}
+	cmd, err := c.Cmd(vars)
+	if err != nil {
+		return message.NewNotificationCmd(fmt.Sprintf("%s: %s", c.Name, err))
+	}
+	return message.ExecProcess(cmd, func(err error) tea.Msg {
+		return commandDoneMsg{name: c.Name, err: err}
+	})
+}
+
+// commandVars returns the values of the placeholders of the user commands
+func (app *App) commandVars() entry.CommandVars {
+	vars := entry.CommandVars{
+		Dir:       app.Navi.CurrentPath(),
+		Clipboard: app.Navi.ClipboardPaths(),
+	}
+	if len(app.list.Entries()) == 0 {
+		return vars
+	}
+	vars.Path = app.fullPath(app.list.SelectedEntryName())
+	for name := range app.list.SelectedEntries() {
+		vars.Paths = append(vars.Paths, app.fullPath(name))
+	}
+	sort.Strings(vars.Paths)
+	return vars
+}
+
+// handleCommandDone shows the last line of the output of the command, or its error, and
+// reloads the directory since the command may have changed it
+func (app *App) handleCommandDone(msg commandDoneMsg) tea.Cmd {
+	output := strings.TrimSpace(string(msg.output))
+	if i := strings.LastIndexByte(output, '\n'); i >= 0 {
+		output = output[i+1:]
+	}
+	var notification string
+	switch {
+	case msg.err != nil && output != "":
+		notification = fmt.Sprintf("%s: %s: %s", msg.name, msg.err, output)
+	case msg.err != nil:
+		notification = fmt.Sprintf("%s: %s", msg.name, msg.err)
+	case output != "":
+		notification = fmt.Sprintf("%s: %s", msg.name, output)
+	}
+	reload := message.HandleReloadCmd(app.Navi, []string{app.list.SelectedEntryName()}, app.list.CursorName())
+	if notification == "" {
+		return reload
+	}
+	return tea.Batch(message.NewNotificationCmd(notification), reload)
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"io"
+	"os"
+
+	"github.com/Philistino/fman/shell"
+	"github.com/Philistino/fman/ui/message"
+)
+
+// cwdReporter tells the terminal the current directory with OSC 7, so new tabs and
+// windows of the terminal open in it
+type cwdReporter struct {
+	w    io.Writer
+	host string
+}
+
+// ReportCwd makes the app write OSC 7 to w, which should be the terminal, each time the
+// current directory changes
+func (app *App) ReportCwd(w io.Writer) {
+	host, _ := os.Hostname()
+	app.cwd = &cwdReporter{w: w, host: host}
+}
+
+// handleDirChangedCwd reports the new directory to the terminal. The sequence is written
+// from Update rather than from a command, so the reports reach the terminal in the order
+// of the directory changes. The renderer writes each frame to the same file with a single
+// write, which the file does not interleave with this one.
+func (app *App) handleDirChangedCwd(msg message.DirChangedMsg) {
+	if app.cwd == nil || msg.Error() != nil {
+		return
+	}
+	io.WriteString(app.cwd.w, shell.OSC7(app.cwd.host, msg.Path()))
+}
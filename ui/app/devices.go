@@ -0,0 +1,22 @@
+package app
+
+import (
+	"github.com/Philistino/fman/ui/devices"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openDevices shows the mounted filesystems
+func (app *App) openDevices() tea.Cmd {
+	view, cmd := devices.New(app.Navi, app.Navi.CurrentPath(), app.theme, app.width, app.height)
+	app.openScreen(newScreen(view, closeDevices))
+	return cmd
+}
+
+// closeDevices goes to the mountpoint chosen in the panel, if any
+func closeDevices(msg devices.ClosedMsg) tea.Cmd {
+	if msg.Path == "" {
+		return nil
+	}
+	return message.NavOtherCmd(msg.Path)
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dirSizer calculates the sizes of the directories in the current directory one at a time
+type dirSizer struct {
+	id     int // incremented for each run so that results of cancelled runs can be ignored
+	path   string
+	queue  []string
+	name   string // directory being sized
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// sizeable reports whether the total size of the entry can be calculated
+func sizeable(e entry.Entry) bool {
+	return e.IsDir() && e.SymlinkName == "" && e.SizeStr != "Access Denied"
+}
+
+// handleDirChangedSizes restarts the calculation of directory sizes after the directory
+// is read. All directories are sized if sizes are calculated automatically. Otherwise, only
+// the directories with a cached size are sized so that their sizes are shown again, along
+// with the directories that were still being sized when the same directory was read again.
+func (app *App) handleDirChangedSizes(msg message.DirChangedMsg) tea.Cmd {
+	path := msg.Path()
+	unfinished := make(map[string]struct{})
+	if path == app.sizer.path {
+		for _, name := range app.sizer.queue {
+			unfinished[name] = struct{}{}
+		}
+		if app.sizer.name != "" {
+			unfinished[app.sizer.name] = struct{}{}
+		}
+	}
+	names := make([]string, 0)
+	for _, e := range msg.Entries() {
+		if !sizeable(e) {
+			continue
+		}
+		_, ok := unfinished[e.Name()]
+		if !ok {
+			_, ok = app.Navi.CachedDirSize(filepath.Join(path, e.Name()), e.ModTime())
+		}
+		if ok || *app.config.DirSizes {
+			names = append(names, e.Name())
+		}
+	}
+	app.stopDirSizes()
+	app.sizer.path = path
+	return app.sizeDirs(names)
+}
+
+// handleCalcDirSizes sizes the selected directories
+func (app *App) handleCalcDirSizes() tea.Cmd {
+	selected := app.list.SelectedEntries()
+	names := make([]string, 0, len(selected))
+	for _, e := range app.list.Entries() {
+		if _, ok := selected[e.Name()]; ok && sizeable(e) {
+			names = append(names, e.Name())
+		}
+	}
+	if app.sizer.path != app.Navi.CurrentPath() {
+		app.stopDirSizes()
+		app.sizer.path = app.Navi.CurrentPath()
+	}
+	return app.sizeDirs(names)
+}
+
+// sizeDirs adds the directories to the queue and starts sizing them if nothing is being sized
+func (app *App) sizeDirs(names []string) tea.Cmd {
+	if len(names) == 0 {
+		return nil
+	}
+	idle := len(app.sizer.queue) == 0 && app.sizer.ctx == nil
+	app.sizer.queue = append(app.sizer.queue, names...)
+	path := app.sizer.path
+	pending := func() tea.Msg {
+		return message.DirSizesPendingMsg{Path: path, Names: names}
+	}
+	if !idle {
+		return pending
+	}
+	app.sizer.ctx, app.sizer.cancel = context.WithCancel(context.Background())
+	return tea.Batch(pending, app.nextDirSizeCmd())
+}
+
+// nextDirSizeCmd returns a command that sizes the next directory in the queue
+func (app *App) nextDirSizeCmd() tea.Cmd {
+	if len(app.sizer.queue) == 0 {
+		app.stopDirSizes()
+		return nil
+	}
+	name := app.sizer.queue[0]
+	app.sizer.queue = app.sizer.queue[1:]
+	app.sizer.name = name
+	id, path, ctx := app.sizer.id, app.sizer.path, app.sizer.ctx
+	return func() tea.Msg {
+		size, err := app.Navi.DirSize(ctx, filepath.Join(path, name))
+		return message.DirSizeMsg{ID: id, Path: path, Name: name, Size: size, Err: err}
+	}
+}
+
+// handleDirSize sizes the next directory once a size has been calculated
+func (app *App) handleDirSize(msg message.DirSizeMsg) tea.Cmd {
+	if msg.ID != app.sizer.id {
+		return nil
+	}
+	return app.nextDirSizeCmd()
+}
+
+// stopDirSizes cancels the calculation of directory sizes
+func (app *App) stopDirSizes() {
+	if app.sizer.cancel != nil {
+		app.sizer.cancel()
+	}
+	app.sizer = dirSizer{id: app.sizer.id + 1, path: app.sizer.path}
+}
@@ -0,0 +1,18 @@
+package app
+
+import (
+	"github.com/Philistino/fman/ui/dupes"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openDupes searches for duplicate files below the current directory
+func (app *App) openDupes() tea.Cmd {
+	view, cmd := dupes.New(app.Navi, app.Navi.CurrentPath(), app.theme, app.width, app.height)
+	app.openScreen(newScreen(view, app.closeDupes))
+	return cmd
+}
+
+func (app *App) closeDupes(dupes.ClosedMsg) tea.Cmd {
+	// files may have been trashed or linked in the view
+	return app.handleErrorsAndReload(nil)
+}
@@ -2,10 +2,15 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/hook"
 	"github.com/Philistino/fman/ui/dialog"
 	"github.com/Philistino/fman/ui/message"
 	"github.com/Philistino/fman/ui/preview"
@@ -16,6 +21,18 @@ func (app *App) handleDialogAnswer(msg dialog.AnswerMsg) tea.Cmd {
 	if msg.ID() == "Delete" && msg.Answer() == "Confirm" {
 		return app.deleteEntries()
 	}
+	if msg.ID() == openWithDialogID {
+		return app.handleOpenWithAnswer(msg)
+	}
+	if msg.ID() == bulkRenameDialogID {
+		return app.handleBulkRenameAnswer(msg)
+	}
+	if msg.ID() == pasteLinksDialogID {
+		return app.handlePasteLinksAnswer(msg)
+	}
+	if msg.ID() == gitRestoreDialogID {
+		return app.handleGitRestoreAnswer(msg)
+	}
 	return nil
 }
 
@@ -45,8 +62,16 @@ func (app *App) deleteEntries() tea.Cmd {
 		entryNames = append(entryNames, k)
 	}
 	sort.Strings(entryNames)
-	errs := app.Navi.Delete(context.Background(), entryNames)
-	return app.handleErrorsAndReload(errs)
+	data := app.hookData(entryNames)
+	return app.runPreHooks(hook.PreDelete, data, func() tea.Cmd {
+		// the list stays blurred while the hooks run, but a remote client can still
+		// change the directory
+		if app.Navi.CurrentPath() != data.Dir {
+			return app.handleErrorsAndReload([]error{errors.New("the directory changed, nothing was deleted")})
+		}
+		errs := app.Navi.Delete(context.Background(), entryNames)
+		return tea.Batch(app.handleErrorsAndReload(errs), app.runPostHooks(hook.PostDelete, data, errs))
+	})
 }
 
 func (app *App) handleErrorsAndReload(errs []error) tea.Cmd {
@@ -68,6 +93,52 @@ func (app *App) handleErrorsAndReload(errs []error) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// handleCompare compares the two selected entries and shows the result in the preview
+func (app *App) handleCompare() tea.Cmd {
+	entries := app.list.SelectedEntries()
+	if len(entries) != 2 {
+		return message.NewNotificationCmd("Select exactly two entries to compare")
+	}
+	names := make([]string, 0, len(entries))
+	for k := range entries {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	app.cancelCompare()
+	var ctx context.Context
+	ctx, app.diffCancel = context.WithCancel(context.Background())
+	return func() tea.Msg {
+		cmp, err := app.Navi.Compare(ctx, names[0], names[1])
+		if ctx.Err() != nil {
+			// the selection changed while comparing
+			return nil
+		}
+		return preview.NewDiffReadyMsg(cmp, err)
+	}
+}
+
+// cancelCompare stops the comparison that is running, if any
+func (app *App) cancelCompare() {
+	if app.diffCancel != nil {
+		app.diffCancel()
+		app.diffCancel = nil
+	}
+}
+
+// registerPreviewers adds the external previewers from the config to the navigator
+func (app *App) registerPreviewers(previewers []cfg.PreviewerCfg) {
+	for _, p := range previewers {
+		previewer := entry.CommandPreviewer{
+			Command:   p.Command,
+			Timeout:   time.Duration(p.Timeout) * time.Millisecond,
+			MaxOutput: p.MaxBytes,
+		}
+		for _, pattern := range p.Match {
+			app.Navi.RegisterPreviewer(pattern, previewer)
+		}
+	}
+}
+
 func (app *App) getPreviewCmd(ctx context.Context, path string) tea.Cmd {
 	return func() tea.Msg {
 		prv := app.Navi.GetPreview(ctx, path)
@@ -78,3 +149,16 @@ func (app *App) getPreviewCmd(ctx context.Context, path string) tea.Cmd {
 		}
 	}
 }
+
+func (app *App) readTailCmd(id int, state entry.TailState) tea.Cmd {
+	return func() tea.Msg {
+		data, next, reset, err := app.Navi.ReadTail(state)
+		return preview.TailReadyMsg{
+			ID:    id,
+			State: next,
+			Data:  data,
+			Reset: reset,
+			Err:   err,
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Philistino/fman/entry/git"
+	"github.com/Philistino/fman/ui/dialog"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/preview"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleDirChangedGit reads the git status of the directory in the background each time
+// it is read, so the status is refreshed after file operations too. A status that is
+// still being read for a previous directory is cancelled.
+func (app *App) handleDirChangedGit(msg message.DirChangedMsg) tea.Cmd {
+	if app.gitCancel != nil {
+		app.gitCancel()
+	}
+	var ctx context.Context
+	ctx, app.gitCancel = context.WithCancel(context.Background())
+	path := msg.Path()
+	return func() tea.Msg {
+		repo, err := app.Navi.GitStatus(ctx, path)
+		if ctx.Err() != nil {
+			// a newer status replaces this one
+			return nil
+		}
+		if errors.Is(err, git.ErrNotRepository) {
+			err = nil
+		}
+		return message.GitStatusMsg{Path: path, Repo: repo, Err: err}
+	}
+}
+
+const (
+	gitRestoreDialogID = "GitRestore"
+	promptGitCommit    = "Git commit"
+)
+
+// gitCommitLines is the number of rows of the commit message editor
+const gitCommitLines = 6
+
+// gitDoneMsg is sent when a git operation on the current directory finishes
+type gitDoneMsg struct {
+	done string // notification shown if the operation succeeded
+	err  error
+}
+
+// gitCmd returns a command that runs the git operation in the background
+func gitCmd(op func(context.Context) error, done string) tea.Cmd {
+	return func() tea.Msg {
+		return gitDoneMsg{done: done, err: op(context.Background())}
+	}
+}
+
+// handleGitDone reports the result of a git operation and reloads the directory, which
+// refreshes the git status
+func (app *App) handleGitDone(msg gitDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		return app.handleErrorsAndReload([]error{msg.err})
+	}
+	return tea.Batch(message.NewNotificationCmd(msg.done), app.handleErrorsAndReload(nil))
+}
+
+// gitSelected returns the names of the selected entries, in the order of the list, whose
+// git status has one of the states
+func (app *App) gitSelected(states git.Status) []string {
+	selected := app.list.SelectedEntries()
+	names := make([]string, 0, len(selected))
+	for _, e := range app.list.Entries() {
+		if _, ok := selected[e.Name()]; ok && app.list.GitStatus(e.Name())&states != 0 {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// handleGitStage stages the changes to the selected entries. Ignored entries are skipped.
+func (app *App) handleGitStage() tea.Cmd {
+	names := app.gitSelected(git.Modified | git.Untracked | git.Conflicted)
+	if len(names) == 0 {
+		return message.NewNotificationCmd("No changes to stage")
+	}
+	return gitCmd(func(ctx context.Context) error {
+		return app.Navi.GitStage(ctx, names)
+	}, fmt.Sprintf("Staged %d entries", len(names)))
+}
+
+// handleGitUnstage unstages the changes to the selected entries
+func (app *App) handleGitUnstage() tea.Cmd {
+	names := app.gitSelected(git.Staged)
+	if len(names) == 0 {
+		return message.NewNotificationCmd("No staged changes")
+	}
+	return gitCmd(func(ctx context.Context) error {
+		return app.Navi.GitUnstage(ctx, names)
+	}, fmt.Sprintf("Unstaged %d entries", len(names)))
+}
+
+// handleGitRestore asks the user to confirm discarding the unstaged changes to the
+// selected entries
+func (app *App) handleGitRestore() tea.Cmd {
+	names := app.gitSelected(git.Modified)
+	if len(names) == 0 {
+		return message.NewNotificationCmd("No unstaged changes to discard")
+	}
+	app.gitRestore = names
+	app.list.Blur()
+	return message.AskDialogCmd(
+		gitRestoreDialogID,
+		fmt.Sprintf("Discard the unstaged changes to\n%s\n\nThis cannot be undone.", strings.Join(names, ", ")),
+		[]string{"Cancel", "Discard"},
+	)
+}
+
+func (app *App) handleGitRestoreAnswer(msg dialog.AnswerMsg) tea.Cmd {
+	names := app.gitRestore
+	app.gitRestore = nil
+	if msg.Answer() != "Discard" {
+		app.list.Focus()
+		return nil
+	}
+	return gitCmd(func(ctx context.Context) error {
+		return app.Navi.GitRestore(ctx, names)
+	}, fmt.Sprintf("Discarded the changes to %d entries", len(names)))
+}
+
+// commitMessageValidator refuses empty commit messages
+func commitMessageValidator(msg string) error {
+	if strings.TrimSpace(msg) == "" {
+		return errors.New("The commit message is empty")
+	}
+	return nil
+}
+
+// handleGitCommit commits the staged changes with the message entered in the prompt
+func (app *App) handleGitCommit(msg string) tea.Cmd {
+	return func() tea.Msg {
+		hash, err := app.Navi.GitCommit(context.Background(), msg)
+		return gitDoneMsg{done: "Committed " + hash, err: err}
+	}
+}
+
+// gitLogLength is the number of commits shown in the history of a file
+const gitLogLength = 100
+
+func (app *App) getGitLogCmd(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		revisions, err := app.Navi.GitLog(ctx, path, gitLogLength)
+		if ctx.Err() != nil {
+			// a newer request replaces this one
+			return nil
+		}
+		return preview.GitLogReadyMsg{
+			Path:      path,
+			Revisions: revisions,
+			Err:       err,
+		}
+	}
+}
+
+func (app *App) getRevisionPreviewCmd(ctx context.Context, path string, rev git.Revision) tea.Cmd {
+	return func() tea.Msg {
+		prv := app.Navi.GitRevisionPreview(ctx, path, rev)
+		if ctx.Err() != nil {
+			// a newer request replaces this one
+			return nil
+		}
+		return preview.RevisionReadyMsg{
+			Path:    path,
+			Hash:    rev.Hash,
+			Preview: prv.Content,
+			Err:     prv.Err,
+		}
+	}
+}
+
+func (app *App) getGitDiffCmd(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		prv := app.Navi.GitDiffPreview(ctx, path)
+		if ctx.Err() != nil {
+			// a newer request replaces this one
+			return nil
+		}
+		return preview.GitDiffReadyMsg{
+			Path:    path,
+			Preview: prv.Content,
+			Err:     prv.Err,
+		}
+	}
+}
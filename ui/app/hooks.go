@@ -0,0 +1,220 @@
+package app
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/hook"
+	"github.com/Philistino/fman/remote"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hookPassedMsg is sent when the pre- hooks of an operation let it run
+type hookPassedMsg struct {
+	proceed func() tea.Cmd
+}
+
+// hookVetoMsg is sent when a pre- hook vetoed an operation
+type hookVetoMsg struct {
+	err error
+}
+
+// hookDelay is how long the directory or the selection must stay the same before their
+// hooks run
+const hookDelay = 250 * time.Millisecond
+
+// hookQueue runs the hooks of an event one event at a time. The on-cd and on-select
+// events come many times a second while a key is held, so they are coalesced: their
+// hooks only run for the last event once no other one came for hookDelay.
+type hookQueue struct {
+	pending []hook.Data // data of the events whose hooks did not run yet
+	seq     int         // counts the coalesced events, so only the tick of the last one runs the hooks
+	due     bool        // set if hookDelay passed while the hooks of an earlier event were running
+	running bool
+}
+
+// hookTickMsg is sent hookDelay after a coalesced event
+type hookTickMsg struct {
+	event hook.Event
+	seq   int
+}
+
+// hookDoneMsg is sent when the hooks of an event exited
+type hookDoneMsg struct {
+	event hook.Event
+	err   error
+}
+
+func coalesced(event hook.Event) bool {
+	return event == hook.OnCd || event == hook.OnSelect
+}
+
+// newHooks returns the hooks of the configuration. Hooks of unknown events are skipped.
+func newHooks(hooks []cfg.HookCfg) hook.Hooks {
+	parsed := make([]hook.Hook, 0, len(hooks))
+	for _, h := range hooks {
+		event, err := hook.ParseEvent(h.Event)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		parsed = append(parsed, hook.Hook{
+			Event:   event,
+			Command: h.Command,
+			Timeout: time.Duration(h.Timeout) * time.Millisecond,
+		})
+	}
+	return hook.New(parsed)
+}
+
+// runHooks queues the hooks of the event, which run in the background after the hooks
+// of the earlier events. A failing hook is reported in a notification.
+func (app *App) runHooks(event hook.Event, data hook.Data) tea.Cmd {
+	if !app.hooks.Has(event) {
+		return nil
+	}
+	if app.hookQueues == nil {
+		app.hookQueues = make(map[hook.Event]*hookQueue)
+	}
+	q, ok := app.hookQueues[event]
+	if !ok {
+		q = &hookQueue{}
+		app.hookQueues[event] = q
+	}
+	if !coalesced(event) {
+		q.pending = append(q.pending, data)
+		if q.running {
+			return nil
+		}
+		return app.runNextHooks(event, q)
+	}
+	q.pending = []hook.Data{data}
+	q.seq++
+	q.due = false
+	seq := q.seq
+	return tea.Tick(hookDelay, func(time.Time) tea.Msg {
+		return hookTickMsg{event: event, seq: seq}
+	})
+}
+
+// runNextHooks runs the hooks of the first pending event of the queue
+func (app *App) runNextHooks(event hook.Event, q *hookQueue) tea.Cmd {
+	data := q.pending[0]
+	q.pending = q.pending[1:]
+	q.running = true
+	q.due = false
+	hooks := app.hooks
+	return func() tea.Msg {
+		return hookDoneMsg{event: event, err: hooks.Run(context.Background(), event, data)}
+	}
+}
+
+// handleHookTick runs the hooks of a coalesced event if no other event came since it
+func (app *App) handleHookTick(msg hookTickMsg) tea.Cmd {
+	q := app.hookQueues[msg.event]
+	if q == nil || msg.seq != q.seq || len(q.pending) == 0 {
+		return nil
+	}
+	if q.running {
+		q.due = true
+		return nil
+	}
+	return app.runNextHooks(msg.event, q)
+}
+
+// handleHookDone reports a failing hook and runs the hooks of the next event
+func (app *App) handleHookDone(msg hookDoneMsg) tea.Cmd {
+	var notification tea.Cmd
+	if msg.err != nil {
+		notification = message.NewNotificationCmd(msg.err.Error())
+	}
+	q := app.hookQueues[msg.event]
+	if q == nil {
+		return notification
+	}
+	q.running = false
+	if len(q.pending) == 0 || (coalesced(msg.event) && !q.due) {
+		return notification
+	}
+	return tea.Batch(notification, app.runNextHooks(msg.event, q))
+}
+
+// runPreHooks runs the hooks of the event in the background and then the operation,
+// unless a hook vetoed it. Without hooks the operation runs right away.
+func (app *App) runPreHooks(event hook.Event, data hook.Data, proceed func() tea.Cmd) tea.Cmd {
+	if !app.hooks.Has(event) {
+		return proceed()
+	}
+	hooks := app.hooks
+	return func() tea.Msg {
+		if err := hooks.Run(context.Background(), event, data); err != nil {
+			return hookVetoMsg{err: err}
+		}
+		return hookPassedMsg{proceed: proceed}
+	}
+}
+
+// hookData returns the data of an event on the entries of the current directory
+func (app *App) hookData(names []string) hook.Data {
+	dir := app.Navi.CurrentPath()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+	}
+	return hook.Data{Dir: dir, Paths: paths}
+}
+
+// renameHookData returns the data of the post-rename event of renames in the current
+// directory
+func (app *App) renameHookData(renames []rename.Rename) hook.Data {
+	dir := app.Navi.CurrentPath()
+	data := hook.Data{Dir: dir, Renames: make([]hook.Rename, len(renames))}
+	for i, r := range renames {
+		data.Renames[i] = hook.Rename{From: filepath.Join(dir, r.From), To: filepath.Join(dir, r.To)}
+	}
+	return data
+}
+
+// runPostHooks runs the hooks of the event if the operation did not fail
+func (app *App) runPostHooks(event hook.Event, data hook.Data, errs []error) tea.Cmd {
+	for _, err := range errs {
+		if err != nil {
+			return nil
+		}
+	}
+	return app.runHooks(event, data)
+}
+
+// handleDirChangedHooks runs the on-cd hooks when the directory is not the one shown
+// before, so reloading the directory does not run them
+func (app *App) handleDirChangedHooks(msg message.DirChangedMsg) tea.Cmd {
+	if msg.Error() != nil || msg.Path() == app.hookDir {
+		return nil
+	}
+	app.hookDir = msg.Path()
+	return app.runHooks(hook.OnCd, hook.Data{Dir: msg.Path()})
+}
+
+// handleSelectionChanged sends the selection to the remote subscribers and runs the
+// on-select hooks if it changed since it was last handled
+func (app *App) handleSelectionChanged() tea.Cmd {
+	if app.remote == nil && !app.hooks.Has(hook.OnSelect) {
+		return nil
+	}
+	selection := app.selection()
+	key := selection.Path + "\x00" + strings.Join(selection.Names, "\x00")
+	if key == app.selectionKey {
+		return nil
+	}
+	app.selectionKey = key
+	if app.remote != nil {
+		app.remote.server.Publish(remote.EventSelectionChanged, selection)
+	}
+	return app.runHooks(hook.OnSelect, app.hookData(selection.Names))
+}
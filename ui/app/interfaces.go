@@ -1,9 +1,15 @@
 package app
 
 import (
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/batchrename"
+	"github.com/Philistino/fman/ui/devices"
 	"github.com/Philistino/fman/ui/dialog"
+	"github.com/Philistino/fman/ui/dupes"
 	"github.com/Philistino/fman/ui/message"
 	"github.com/Philistino/fman/ui/navbtns"
+	"github.com/Philistino/fman/ui/permissions"
+	"github.com/Philistino/fman/ui/usage"
 )
 
 // checking that concrete types implement the relevant interfaces
@@ -11,3 +17,13 @@ import (
 var _ dialog.AskMsg = new(message.AskDialogGeneric)
 
 var _ navbtns.ActiveNavBtns = new(message.DirChangedMsg)
+
+var _ usage.Backend = new(nav.Nav)
+
+var _ devices.Backend = new(nav.Nav)
+
+var _ dupes.Backend = new(nav.Nav)
+
+var _ batchrename.Backend = new(nav.Nav)
+
+var _ permissions.Backend = new(nav.Nav)
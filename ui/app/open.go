@@ -0,0 +1,142 @@
+package app
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/dialog"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const openWithDialogID = "OpenWith"
+
+// openRequest holds the openers offered in the "open with" dialog
+// and the files they will open
+type openRequest struct {
+	openers  []entry.Opener
+	cursor   string
+	selected []string
+}
+
+// newOpeners converts the openers from the config
+func newOpeners(openers []cfg.OpenerCfg) []entry.Opener {
+	converted := make([]entry.Opener, 0, len(openers))
+	for _, o := range openers {
+		converted = append(converted, entry.Opener{
+			Name:       o.Name,
+			Match:      o.Match,
+			Command:    o.Command,
+			Foreground: o.Foreground,
+			Multiple:   o.Multiple,
+		})
+	}
+	return converted
+}
+
+// selectedFiles returns the names of the selected files, excluding directories
+func (app *App) selectedFiles() []string {
+	selected := app.list.SelectedEntries()
+	names := make([]string, 0, len(selected))
+	for _, e := range app.list.Entries() {
+		if _, ok := selected[e.Name()]; ok && !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchingOpeners returns the openers that apply to the file with the given name
+func (app *App) matchingOpeners(name string) ([]entry.Opener, error) {
+	if len(app.openers) == 0 {
+		return nil, nil
+	}
+	mimeType, err := app.Navi.MimeType(name)
+	if err != nil {
+		return nil, err
+	}
+	return entry.MatchOpeners(app.openers, []string{name}, []string{mimeType}), nil
+}
+
+// handleOpen opens the selected files with the first matching opener. If several files
+// are selected, openers that accept multiple files are preferred. $EDITOR is used
+// if no opener matches.
+func (app *App) handleOpen() tea.Cmd {
+	cursor := app.list.SelectedEntryName()
+	openers, err := app.matchingOpeners(cursor)
+	if err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	if len(openers) == 0 {
+		return message.OpenEditorCmd(app.fullPath(cursor))
+	}
+	selected := app.selectedFiles()
+	opener := openers[0]
+	if len(selected) > 1 {
+		for _, o := range openers {
+			if o.Multiple {
+				opener = o
+				break
+			}
+		}
+	}
+	return app.runOpener(opener, cursor, selected)
+}
+
+// handleOpenWith asks which of the matching openers should open the selected files
+func (app *App) handleOpenWith() tea.Cmd {
+	cursor := app.list.SelectedEntryName()
+	openers, err := app.matchingOpeners(cursor)
+	if err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	if len(openers) == 0 {
+		return message.NewNotificationCmd("No opener matches " + cursor)
+	}
+	app.openRequest = openRequest{
+		openers:  openers,
+		cursor:   cursor,
+		selected: app.selectedFiles(),
+	}
+	options := make([]string, 0, len(openers)+1)
+	for _, o := range openers {
+		options = append(options, o.Name)
+	}
+	options = append(options, "Cancel")
+	app.list.Blur()
+	return message.AskDialogCmd(openWithDialogID, "Open "+cursor+" with", options)
+}
+
+func (app *App) handleOpenWithAnswer(msg dialog.AnswerMsg) tea.Cmd {
+	app.list.Focus()
+	req := app.openRequest
+	app.openRequest = openRequest{}
+	if msg.AnswerIdx() >= len(req.openers) {
+		return nil
+	}
+	return app.runOpener(req.openers[msg.AnswerIdx()], req.cursor, req.selected)
+}
+
+// runOpener opens the highlighted file with the opener, or all of the selected
+// files that the opener matches if it accepts multiple files.
+func (app *App) runOpener(opener entry.Opener, cursor string, selected []string) tea.Cmd {
+	paths := []string{app.fullPath(cursor)}
+	if opener.Multiple && len(selected) > 1 {
+		paths = paths[:0]
+		for _, name := range selected {
+			mimeType, err := app.Navi.MimeType(name)
+			if err != nil || !opener.Matches(name, mimeType) {
+				continue
+			}
+			paths = append(paths, app.fullPath(name))
+		}
+	}
+	return message.OpenCmd(opener, paths)
+}
+
+func (app *App) fullPath(name string) string {
+	return filepath.Join(app.Navi.CurrentPath(), name)
+}
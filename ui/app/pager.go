@@ -0,0 +1,25 @@
+package app
+
+import (
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/pager"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openPager shows the selected file in the full screen pager
+func (app *App) openPager() tea.Cmd {
+	selected := app.list.SelectedEntry()
+	if selected.IsDir() {
+		return nil
+	}
+	file, err := app.Navi.OpenLines(selected.Name())
+	if err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	app.openScreen(newScreen(pager.New(file, selected.Name(), app.theme, app.width, app.height), closePager))
+	return nil
+}
+
+func closePager(pager.ClosedMsg) tea.Cmd {
+	return nil
+}
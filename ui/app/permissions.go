@@ -0,0 +1,33 @@
+package app
+
+import (
+	"github.com/Philistino/fman/entry/perms"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/permissions"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openPermissions shows the permissions dialog for the selected entries
+func (app *App) openPermissions() tea.Cmd {
+	selected := app.list.SelectedEntries()
+	targets := make([]permissions.Target, 0, len(selected))
+	for _, e := range app.list.Entries() {
+		if _, ok := selected[e.Name()]; !ok {
+			continue
+		}
+		targets = append(targets, permissions.Target{Name: e.Name(), Mode: perms.FromFileMode(e.Mode()), IsDir: e.IsDir()})
+	}
+	if len(targets) == 0 {
+		return message.NewNotificationCmd("No entries selected")
+	}
+	view, cmd := permissions.New(app.Navi, targets, app.theme, app.width, app.height)
+	app.openScreen(newScreen(view, app.closePermissions))
+	return cmd
+}
+
+func (app *App) closePermissions(msg permissions.ClosedMsg) tea.Cmd {
+	if !msg.Changed {
+		return nil
+	}
+	return app.handleErrorsAndReload(msg.Errs)
+}
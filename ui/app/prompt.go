@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/hook"
 	"github.com/Philistino/fman/ui/infobar"
 	"github.com/Philistino/fman/ui/message"
 	tea "github.com/charmbracelet/bubbletea"
@@ -51,6 +53,10 @@ func (app *App) promptInput(msg tea.Msg) tea.Cmd {
 		return infobar.PromptAskCmd(promptNewDir, "New folder", app.fileNameValidator())
 	case message.RenameMsg:
 		return infobar.PromptAskCmd(promptRename, "New name", app.fileNameValidator())
+	case message.GitCommitMsg:
+		return infobar.PromptAskMultilineCmd(promptGitCommit, "Commit message", gitCommitLines, commitMessageValidator)
+	case message.ShellPromptMsg:
+		return infobar.PromptAskCmd(promptShell, "Shell command", shellCommandValidator)
 	}
 	return nil
 }
@@ -70,12 +76,23 @@ func (app *App) handleInput(msg infobar.PromptAnswerMsg) tea.Cmd {
 	// show spinner while running?
 	var errs []error
 	switch msg.ID {
+	case promptGitCommit:
+		// the list is focused again once the commit is done
+		return app.handleGitCommit(msg.Message)
+	case promptShell:
+		app.list.Focus()
+		app.fileBtns.Focus()
+		app.navBtns.Focus()
+		app.breadcrumb.Focus()
+		return app.runShellCommand(msg.Message)
 	case promptNewFile:
 		errs = app.Navi.MkFile(context.Background(), msg.Message)
 	case promptNewDir:
 		errs = app.Navi.MkDir(context.Background(), msg.Message)
 	case promptRename:
-		errs = app.Navi.Rename(context.Background(), app.list.SelectedEntryName(), msg.Message)
+		renames := []rename.Rename{{From: app.list.SelectedEntryName(), To: msg.Message}}
+		errs = app.Navi.Rename(context.Background(), renames[0].From, renames[0].To)
+		return tea.Batch(app.handleErrorsAndReload(errs), app.runPostHooks(hook.PostRename, app.renameHookData(renames), errs))
 	}
 	return app.handleErrorsAndReload(errs)
 }
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Philistino/fman/remote"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// remoteActions are the actions remote clients can run, by name
+var remoteActions = map[string]func() tea.Cmd{
+	"back":          message.NavBackCmd,
+	"forward":       message.NavFwdCmd,
+	"up":            message.NavUpCmd,
+	"home":          message.NavHomeCmd,
+	"open":          message.OpenFileCmd,
+	"open-with":     message.OpenWithCmd,
+	"pager":         message.OpenPagerCmd,
+	"follow":        message.ToggleFollowCmd,
+	"toggle-hidden": message.ToggleShowHiddenCmd,
+	"compare":       message.CompareCmd,
+	"copy":          message.InternalCopyCmd,
+	"cut":           message.CutCmd,
+	"paste-links":   message.PasteLinksCmd,
+	"new-file":      message.NewFileCmd,
+	"mkdir":         message.MkDirCmd,
+	"rename":        message.RenameCmd,
+	"delete":        message.DeleteCmd,
+	"bulk-rename":   message.BulkRenameCmd,
+	"batch-rename":  message.BatchRenameCmd,
+	"undo-rename":   message.UndoRenameCmd,
+	"permissions":   message.ChangePermissionsCmd,
+	"dir-sizes":     message.CalcDirSizesCmd,
+	"usage":         message.OpenUsageCmd,
+	"devices":       message.OpenDevicesCmd,
+	"dupes":         message.FindDupesCmd,
+	"git-stage":     message.GitStageCmd,
+	"git-unstage":   message.GitUnstageCmd,
+	"git-restore":   message.GitRestoreCmd,
+	"git-commit":    message.GitCommitCmd,
+	"git-preview":   message.ToggleGitPreviewCmd,
+	"quit":          func() tea.Cmd { return tea.Quit },
+}
+
+// RemoteActions returns the names of the actions remote clients can run
+func RemoteActions() []string {
+	names := make([]string, 0, len(remoteActions))
+	for name := range remoteActions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// remoteState is set while remote clients can drive the app
+type remoteState struct {
+	server *remote.Server
+}
+
+// remoteRequestMsg is a request of a remote client. It is answered on reply.
+type remoteRequestMsg struct {
+	method string
+	params json.RawMessage
+	reply  chan<- remoteReply
+}
+
+type remoteReply struct {
+	result any
+	err    error
+}
+
+// remoteDoneMsg holds the message of a command run for a remote client. The client is
+// answered once the message is handled.
+type remoteDoneMsg struct {
+	msg   tea.Msg
+	reply chan<- remoteReply
+}
+
+// SetRemote makes the app send its events to the subscribers of the server
+func (app *App) SetRemote(server *remote.Server) {
+	app.remote = &remoteState{server: server}
+}
+
+// RemoteHandler returns the handler of the requests of remote clients. The requests are
+// sent to the program with send and answered by Update, so the state of the app is only
+// used by the program.
+func (app *App) RemoteHandler(send func(tea.Msg)) remote.Handler {
+	return func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		reply := make(chan remoteReply, 1)
+		send(remoteRequestMsg{method: method, params: params, reply: reply})
+		select {
+		case r := <-reply:
+			return r.result, r.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// handleRemoteRequest answers a request of a remote client
+func (app *App) handleRemoteRequest(msg remoteRequestMsg) tea.Cmd {
+	answer := func(result any, err error) tea.Cmd {
+		msg.reply <- remoteReply{result: result, err: err}
+		return nil
+	}
+	switch msg.method {
+	case remote.MethodNavigate:
+		var params remote.PathParams
+		if err := json.Unmarshal(msg.params, &params); err != nil || !filepath.IsAbs(params.Path) {
+			return answer(nil, remote.InvalidParams(errors.New("navigate needs an absolute path")))
+		}
+		nav := message.HandleNavCmd(app.Navi, []string{app.list.SelectedEntryName()}, params.Path, app.list.CursorName())
+		return remoteDoneCmd(nav, msg.reply)
+	case remote.MethodReload:
+		reload := message.HandleReloadCmd(app.Navi, []string{app.list.SelectedEntryName()}, app.list.CursorName())
+		return remoteDoneCmd(reload, msg.reply)
+	case remote.MethodSelect:
+		var params remote.SelectParams
+		if err := json.Unmarshal(msg.params, &params); err != nil || len(params.Names) == 0 {
+			return answer(nil, remote.InvalidParams(errors.New("select needs the names of entries")))
+		}
+		if err := app.list.Select(params.Names); err != nil {
+			return answer(nil, remote.InvalidParams(err))
+		}
+		answer(app.selection(), nil)
+		return tea.Batch(app.handleSelectionChanged(), message.NewEntryCmd(app.list.SelectedEntry()))
+	case remote.MethodGetPath:
+		return answer(remote.PathParams{Path: app.Navi.CurrentPath()}, nil)
+	case remote.MethodGetSelection:
+		return answer(app.selection(), nil)
+	case remote.MethodRun:
+		var params remote.RunParams
+		if err := json.Unmarshal(msg.params, &params); err != nil {
+			return answer(nil, remote.InvalidParams(err))
+		}
+		action, ok := remoteActions[params.Action]
+		if !ok {
+			err := fmt.Errorf("unknown action %q, expected one of %s", params.Action, strings.Join(RemoteActions(), ", "))
+			return answer(nil, remote.InvalidParams(err))
+		}
+		answer(true, nil)
+		return action()
+	}
+	return answer(nil, remote.MethodNotFound(msg.method))
+}
+
+// remoteDoneCmd runs the navigation and answers the client once the directory is shown
+func remoteDoneCmd(nav tea.Cmd, reply chan<- remoteReply) tea.Cmd {
+	return func() tea.Msg {
+		return remoteDoneMsg{msg: nav(), reply: reply}
+	}
+}
+
+// handleRemoteDone handles the message of the navigation and answers the client with
+// the new directory
+func (app *App) handleRemoteDone(msg remoteDoneMsg) (tea.Model, tea.Cmd) {
+	model, cmd := app.Update(msg.msg)
+	if changed, ok := msg.msg.(message.DirChangedMsg); ok && changed.Error() != nil {
+		msg.reply <- remoteReply{err: changed.Error()}
+	} else {
+		msg.reply <- remoteReply{result: remote.PathParams{Path: app.Navi.CurrentPath()}}
+	}
+	return model, cmd
+}
+
+// selection returns the names of the selected entries
+func (app *App) selection() remote.Selection {
+	names := make([]string, 0, len(app.list.SelectedEntries()))
+	for name := range app.list.SelectedEntries() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return remote.Selection{Path: app.Navi.CurrentPath(), Names: names}
+}
+
+// handleDirChangedRemote sends the new directory to the subscribers
+func (app *App) handleDirChangedRemote(msg message.DirChangedMsg) {
+	if app.remote == nil || msg.Error() != nil {
+		return
+	}
+	app.remote.server.Publish(remote.EventDirChanged, remote.PathParams{Path: msg.Path()})
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandTick is how often the last line of the output of a running command is shown
+const commandTick = time.Second
+
+// commandWaitDelay is the time given to the children of a cancelled command to close its
+// output
+const commandWaitDelay = 500 * time.Millisecond
+
+var errCommandCancelled = errors.New("cancelled")
+
+// runningCommand is a command that runs in the background until it exits or is cancelled
+type runningCommand struct {
+	name   string
+	output *entry.OutputBuffer
+	cancel context.CancelFunc
+	shown  string                                 // last line of the output shown so far
+	done   func(output []byte, err error) tea.Cmd // handles the output when the command exits
+}
+
+// commandExitMsg is sent when a command run in the background exits
+type commandExitMsg struct {
+	id  int
+	err error
+}
+
+// commandTickMsg is sent while a command runs in the background to show its progress
+type commandTickMsg struct {
+	id int
+}
+
+func commandTickCmd(id int) tea.Cmd {
+	return tea.Tick(commandTick, func(time.Time) tea.Msg {
+		return commandTickMsg{id: id}
+	})
+}
+
+// startCommand runs the command made by newCmd in the background. At most
+// entry.MaxCommandOutput bytes of its output are kept and passed to done when it exits.
+// The last line of the output is shown while it runs and the user can cancel it.
+func (app *App) startCommand(name string, newCmd func(ctx context.Context) (*exec.Cmd, error), done func(output []byte, err error) tea.Cmd) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, err := newCmd(ctx)
+	if err != nil {
+		cancel()
+		return message.NewNotificationCmd(fmt.Sprintf("%s: %s", name, err))
+	}
+	output := entry.NewOutputBuffer(entry.MaxCommandOutput)
+	cmd.Stdout, cmd.Stderr = output, output
+	cmd.WaitDelay = commandWaitDelay
+
+	app.lastCommandID++
+	id := app.lastCommandID
+	if app.running == nil {
+		app.running = make(map[int]*runningCommand)
+	}
+	app.running[id] = &runningCommand{name: name, output: output, cancel: cancel, done: done}
+	return tea.Batch(
+		message.NewNotificationCmd(fmt.Sprintf("Running %s. Press %s to cancel", name, keys.Map.CancelCommand.Help().Key)),
+		func() tea.Msg {
+			err := cmd.Run()
+			if ctx.Err() != nil {
+				err = errCommandCancelled
+			}
+			return commandExitMsg{id: id, err: err}
+		},
+		commandTickCmd(id),
+	)
+}
+
+// handleCommandTick shows the last line of the output of the command if it changed
+func (app *App) handleCommandTick(msg commandTickMsg) tea.Cmd {
+	c, ok := app.running[msg.id]
+	if !ok {
+		return nil
+	}
+	line := c.output.LastLine()
+	if line == "" || line == c.shown {
+		return commandTickCmd(msg.id)
+	}
+	c.shown = line
+	return tea.Batch(message.NewNotificationCmd(fmt.Sprintf("%s: %s", c.name, line)), commandTickCmd(msg.id))
+}
+
+func (app *App) handleCommandExit(msg commandExitMsg) tea.Cmd {
+	c, ok := app.running[msg.id]
+	if !ok {
+		return nil
+	}
+	delete(app.running, msg.id)
+	c.cancel()
+	return c.done(c.output.Bytes(), msg.err)
+}
+
+// cancelCommands kills the commands running in the background. Their output so far is
+// still handled when they exit.
+func (app *App) cancelCommands() {
+	for _, c := range app.running {
+		c.cancel()
+	}
+}
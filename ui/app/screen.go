@@ -0,0 +1,92 @@
+package app
+
+import (
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// screen is a view that takes over the whole screen, such as the pager, until it sends
+// its closed message
+type screen interface {
+	update(msg tea.Msg) tea.Cmd
+	View() string
+	// closed returns the command to run if msg is the closed message of the view
+	closed(msg tea.Msg) (tea.Cmd, bool)
+	close()
+}
+
+// screenModel is the model of a full screen view
+type screenModel[T any] interface {
+	Update(msg tea.Msg) (T, tea.Cmd)
+	View() string
+}
+
+// fullScreen makes a model a screen. C is the type of its closed message, which is
+// handled by onClose.
+type fullScreen[T screenModel[T], C tea.Msg] struct {
+	model   T
+	onClose func(msg C) tea.Cmd
+}
+
+func newScreen[T screenModel[T], C tea.Msg](model T, onClose func(msg C) tea.Cmd) *fullScreen[T, C] {
+	return &fullScreen[T, C]{model: model, onClose: onClose}
+}
+
+func (s *fullScreen[T, C]) update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	s.model, cmd = s.model.Update(msg)
+	return cmd
+}
+
+func (s *fullScreen[T, C]) View() string {
+	return s.model.View()
+}
+
+func (s *fullScreen[T, C]) closed(msg tea.Msg) (tea.Cmd, bool) {
+	closed, ok := msg.(C)
+	if !ok {
+		return nil, false
+	}
+	return s.onClose(closed), true
+}
+
+// close stops the work of the view, if it has any
+func (s *fullScreen[T, C]) close() {
+	switch model := any(s.model).(type) {
+	case interface{ Close() }:
+		model.Close()
+	case interface{ Close() error }:
+		model.Close()
+	}
+}
+
+// openScreen shows the view in place of the file manager. A view that was shown is
+// closed.
+func (app *App) openScreen(s screen) {
+	if app.screen != nil {
+		app.screen.close()
+	}
+	app.screen = s
+}
+
+// updateScreen sends input to the full screen view while it is open. Other messages are
+// also sent to it but are not marked as handled so the rest of the app keeps up to date
+// in the background.
+func (app *App) updateScreen(msg tea.Msg) (tea.Cmd, bool) {
+	if cmd, ok := app.screen.closed(msg); ok {
+		app.screen.close()
+		app.screen = nil
+		return tea.Batch(tea.ClearScreen, cmd, app.showNextShellOutput()), true
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Map.Quit) {
+			return tea.Quit, true
+		}
+		return app.screen.update(msg), true
+	case tea.MouseMsg:
+		return nil, true
+	}
+	return app.screen.update(msg), false
+}
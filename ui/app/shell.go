@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/pager"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+)
+
+const promptShell = "Shell"
+
+// shellOutput is the output of a shell command that waits for the full screen view to close
+type shellOutput struct {
+	command string
+	output  []byte
+}
+
+// subshellDoneMsg is sent when the user leaves the shell opened with the subshell key
+type subshellDoneMsg struct {
+	err error
+}
+
+func shellCommandValidator(command string) error {
+	if strings.TrimSpace(command) == "" {
+		return errors.New("enter a command")
+	}
+	return nil
+}
+
+// runShellCommand runs the command of the shell prompt in the current directory in the
+// background. The selected entries are passed in the environment variables of
+// entry.CommandVars.
+func (app *App) runShellCommand(command string) tea.Cmd {
+	vars := app.commandVars()
+	newCmd := func(ctx context.Context) (*exec.Cmd, error) {
+		cmd := entry.ShellCommandContext(ctx, command)
+		cmd.Dir = vars.Dir
+		cmd.Env = append(os.Environ(), vars.Environ()...)
+		return cmd, nil
+	}
+	return app.startCommand(command, newCmd, func(output []byte, err error) tea.Cmd {
+		return app.handleShellOutput(command, output, err)
+	})
+}
+
+// handleShellOutput shows the output of the command in the pager and reloads the
+// directory since the command may have changed it. A full screen view that is shown is not
+// replaced, the output is shown once it is closed.
+func (app *App) handleShellOutput(command string, output []byte, err error) tea.Cmd {
+	reload := message.HandleReloadCmd(app.Navi, []string{app.list.SelectedEntryName()}, app.list.CursorName())
+	if err != nil && len(output) > 0 {
+		output = append(output, fmt.Sprintf("\n%s\n", err)...)
+	}
+	if len(output) == 0 {
+		notification := command + ": no output"
+		if err != nil {
+			notification = fmt.Sprintf("%s: %s", command, err)
+		}
+		return tea.Batch(message.NewNotificationCmd(notification), reload)
+	}
+	if app.screen != nil {
+		app.shellOutputs = append(app.shellOutputs, shellOutput{command: command, output: output})
+		return reload
+	}
+	return tea.Batch(app.showShellOutput(command, output), reload)
+}
+
+// showShellOutput opens the output of the command in the pager
+func (app *App) showShellOutput(command string, output []byte) tea.Cmd {
+	// the pager reads lines from a file, so the output is kept in a file in memory
+	const name = "output"
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, name, output, 0o600); err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	file, err := entry.OpenLineFile(fsys, name)
+	if err != nil {
+		return message.NewNotificationCmd(err.Error())
+	}
+	app.openScreen(newScreen(pager.New(file, "$ "+command, app.theme, app.width, app.height), closePager))
+	return nil
+}
+
+// showNextShellOutput opens the output of the shell command that waited the longest for
+// the full screen view to close
+func (app *App) showNextShellOutput() tea.Cmd {
+	if len(app.shellOutputs) == 0 {
+		return nil
+	}
+	next := app.shellOutputs[0]
+	app.shellOutputs = app.shellOutputs[1:]
+	return app.showShellOutput(next.command, next.output)
+}
+
+// openSubshell runs the shell of the user in the current directory until the user exits it
+func (app *App) openSubshell() tea.Cmd {
+	vars := app.commandVars()
+	cmd := exec.Command(entry.DefaultShell())
+	cmd.Dir = vars.Dir
+	cmd.Env = append(os.Environ(), vars.Environ()...)
+	return message.ExecProcess(cmd, func(err error) tea.Msg {
+		return subshellDoneMsg{err: err}
+	})
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Philistino/fman/cfg"
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+)
+
+// setupSort sets the default sort from the config and loads the
+// sorts saved for each directory in previous sessions
+func (app *App) setupSort(config cfg.Cfg, fsys afero.Fs) {
+	spec := app.Navi.Sort()
+	method, err := entry.ParseSortMethod(config.Sort)
+	if err != nil {
+		log.Println(err)
+	}
+	spec.Method = method
+	spec.Reverse = *config.SortReverse
+	spec.IgnoreCase = !config.SortCaseSensitive
+	spec.IgnoreDiacritics = !config.SortKeepAccents
+	app.Navi.SetDefaultSort(spec)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	store, err := nav.LoadSortStore(fsys, cfg.ConfigFilePath(home, cfg.FmanSortFileName))
+	if err != nil {
+		log.Println("could not load saved sorts: ", err)
+	}
+	app.Navi.SetSortStore(store)
+}
+
+// newColumns looks up the columns from the config. Unknown columns are skipped.
+func newColumns(configured []cfg.ColumnCfg) []entry.Column {
+	columns := make([]entry.Column, 0, len(configured))
+	for _, c := range configured {
+		column, ok := entry.LookupColumn(c.Name)
+		if !ok {
+			log.Printf("unknown column %q. Options are: %s", c.Name, strings.Join(entry.ColumnIDs(), ", "))
+			continue
+		}
+		if c.Width > 0 {
+			column.Width = c.Width
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// handleSetSort changes the sort of the current directory and reloads it
+func (app *App) handleSetSort(sort entry.SortSpec) tea.Cmd {
+	app.Navi.SetSort(sort)
+	return tea.Batch(app.handleErrorsAndReload(nil), saveSortsCmd(app.Navi))
+}
+
+// saveSortsCmd writes the sorts to their file in the background
+func saveSortsCmd(navi *nav.Nav) tea.Cmd {
+	return func() tea.Msg {
+		if err := navi.SaveSorts(); err != nil {
+			return message.NewNotificationMsg{Message: "could not save the sort: " + err.Error()}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,18 @@
+package app
+
+import (
+	"github.com/Philistino/fman/ui/usage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openUsage shows the disk usage of the current directory
+func (app *App) openUsage() tea.Cmd {
+	view, cmd := usage.New(app.Navi, app.Navi.CurrentPath(), app.theme, app.width, app.height)
+	app.openScreen(newScreen(view, app.closeUsage))
+	return cmd
+}
+
+func (app *App) closeUsage(usage.ClosedMsg) tea.Cmd {
+	// entries may have been deleted in the view
+	return app.handleErrorsAndReload(nil)
+}
@@ -0,0 +1,387 @@
+// Package batchrename implements a full screen view that renames the selected entries with
+// a pattern. The new names are previewed as the pattern is typed and names that collide
+// are flagged. The entries are renamed as one batch, so a failure leaves them unchanged.
+package batchrename
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// labelWidth is the width of the labels of the fields
+const labelWidth = 10
+
+// Backend performs the renames
+type Backend interface {
+	DirNames() ([]string, error)
+	BatchRename(ctx context.Context, renames []rename.Rename) error
+}
+
+// ClosedMsg is sent when the view is closed. Renamed is the number of entries that
+// were renamed and Renames are their renames.
+type ClosedMsg struct {
+	Renamed int
+	Renames []rename.Rename
+}
+
+func closedCmd(renames []rename.Rename) tea.Cmd {
+	return func() tea.Msg {
+		return ClosedMsg{Renamed: len(renames), Renames: renames}
+	}
+}
+
+// namesMsg is sent when the names of the directory have been read
+type namesMsg struct {
+	names []string
+	err   error
+}
+
+func namesCmd(backend Backend) tea.Cmd {
+	return func() tea.Msg {
+		names, err := backend.DirNames()
+		return namesMsg{names: names, err: err}
+	}
+}
+
+// renamedMsg is sent when the renames are done
+type renamedMsg struct {
+	renames []rename.Rename
+	err     error
+}
+
+func renameCmd(backend Backend, renames []rename.Rename) tea.Cmd {
+	return func() tea.Msg {
+		err := backend.BatchRename(context.Background(), renames)
+		return renamedMsg{renames: renames, err: err}
+	}
+}
+
+// field is an input of the form
+type field uint8
+
+const (
+	fieldFind field = iota
+	fieldReplace
+	fieldExt
+	fieldStart
+	fieldCase
+	fieldCount
+)
+
+var fieldLabels = [fieldCount]string{"Find", "Replace", "Extension", "Counter", "Case"}
+
+// BatchRename renames entries with a pattern
+type BatchRename struct {
+	backend  Backend
+	entries  []rename.Entry
+	existing []string // names of all of the entries in the directory
+
+	inputs  [fieldCase]textinput.Model
+	caseOpt rename.Case
+	focus   field
+
+	rows      []rename.Row
+	conflicts int
+	changed   int
+	err       error // error of the pattern
+	top       int   // first row of the preview that is shown
+	renaming  bool
+	status    string
+
+	theme  colors.Theme
+	width  int
+	height int
+}
+
+// New creates the view for the entries and returns the command that reads the names of
+// the directory
+func New(backend Backend, entries []rename.Entry, theme colors.Theme, width, height int) (*BatchRename, tea.Cmd) {
+	b := &BatchRename{
+		backend: backend,
+		entries: entries,
+		theme:   theme,
+		width:   width,
+		height:  height,
+	}
+	placeholders := [fieldCase]string{
+		"regular expression, empty to replace the whole name",
+		"$1, {name}, {ext}, {n:3}, {date:YYYYMMDD}",
+		"new extension",
+		"1",
+	}
+	for i := range b.inputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 1024
+		ti.Placeholder = placeholders[i]
+		b.inputs[i] = ti
+	}
+	b.inputs[fieldStart].CharLimit = 9
+	b.setFocus(fieldFind)
+	b.preview()
+	return b, tea.Batch(textinput.Blink, namesCmd(backend))
+}
+
+// SetSize sets the size of the view
+func (b *BatchRename) SetSize(width, height int) {
+	b.width = width
+	b.height = height
+	b.clampTop()
+}
+
+// bodyHeight returns the number of rows available for the preview
+func (b *BatchRename) bodyHeight() int {
+	h := b.height - 3 - int(fieldCount) // header, fields, separator and footer
+	if h < 1 {
+		return 1
+	}
+	return h
+}
+
+func (b *BatchRename) Update(msg tea.Msg) (*BatchRename, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.SetSize(msg.Width, msg.Height)
+	case namesMsg:
+		if msg.err != nil {
+			b.status = msg.err.Error()
+		}
+		b.existing = msg.names
+		b.preview()
+	case renamedMsg:
+		b.renaming = false
+		if msg.err != nil {
+			b.status = msg.err.Error()
+			// the directory may have changed since it was read
+			return b, namesCmd(b.backend)
+		}
+		return b, closedCmd(msg.renames)
+	case tea.KeyMsg:
+		if b.renaming {
+			return b, nil
+		}
+		return b, b.handleKey(msg)
+	default:
+		var cmd tea.Cmd
+		if b.focus < fieldCase {
+			b.inputs[b.focus], cmd = b.inputs[b.focus].Update(msg)
+		}
+		return b, cmd
+	}
+	return b, nil
+}
+
+func (b *BatchRename) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		return closedCmd(nil)
+	case tea.KeyEnter:
+		return b.apply()
+	case tea.KeyTab, tea.KeyDown:
+		b.setFocus((b.focus + 1) % fieldCount)
+		return nil
+	case tea.KeyShiftTab, tea.KeyUp:
+		b.setFocus((b.focus + fieldCount - 1) % fieldCount)
+		return nil
+	case tea.KeyPgUp:
+		b.top -= b.bodyHeight()
+		b.clampTop()
+		return nil
+	case tea.KeyPgDown:
+		b.top += b.bodyHeight()
+		b.clampTop()
+		return nil
+	}
+	if b.focus == fieldCase {
+		switch msg.Type {
+		case tea.KeyLeft:
+			b.caseOpt = b.caseOpt.Prev()
+		case tea.KeyRight, tea.KeySpace:
+			b.caseOpt = b.caseOpt.Next()
+		}
+		b.preview()
+		return nil
+	}
+	b.status = ""
+	var cmd tea.Cmd
+	b.inputs[b.focus], cmd = b.inputs[b.focus].Update(msg)
+	b.preview()
+	return cmd
+}
+
+func (b *BatchRename) setFocus(f field) {
+	b.focus = f
+	for i := range b.inputs {
+		if field(i) == f {
+			b.inputs[i].Focus()
+		} else {
+			b.inputs[i].Blur()
+		}
+	}
+}
+
+// pattern returns the pattern of the fields
+func (b *BatchRename) pattern() (rename.Pattern, error) {
+	p := rename.Pattern{
+		Find:    b.inputs[fieldFind].Value(),
+		Replace: b.inputs[fieldReplace].Value(),
+		Ext:     strings.TrimSpace(b.inputs[fieldExt].Value()),
+		Case:    b.caseOpt,
+		Start:   1,
+	}
+	if start := strings.TrimSpace(b.inputs[fieldStart].Value()); start != "" {
+		n, err := strconv.Atoi(start)
+		if err != nil {
+			return p, fmt.Errorf("invalid counter start %q", start)
+		}
+		p.Start = n
+	}
+	return p, nil
+}
+
+// preview computes the new names with the current pattern
+func (b *BatchRename) preview() {
+	b.changed, b.conflicts = 0, 0
+	p, err := b.pattern()
+	var names []string
+	if err == nil {
+		names, err = p.Names(b.entries)
+	}
+	b.err = err
+	if err != nil {
+		// keep showing the last preview while the pattern is being typed
+		return
+	}
+	b.rows = rename.Preview(b.entries, names, b.existing)
+	for _, row := range b.rows {
+		if row.Err != nil {
+			b.conflicts++
+		}
+		if row.Changed() {
+			b.changed++
+		}
+	}
+	b.clampTop()
+}
+
+// apply starts renaming the entries if the preview has no conflicts
+func (b *BatchRename) apply() tea.Cmd {
+	switch {
+	case b.err != nil:
+		b.status = b.err.Error()
+		return nil
+	case b.conflicts > 0:
+		b.status = fmt.Sprintf("Resolve the %d conflicts first", b.conflicts)
+		return nil
+	case b.changed == 0:
+		b.status = "No names are changed"
+		return nil
+	}
+	renames := make([]rename.Rename, 0, b.changed)
+	for _, row := range b.rows {
+		if row.Changed() {
+			renames = append(renames, row.Rename)
+		}
+	}
+	b.renaming = true
+	b.status = fmt.Sprintf("Renaming %d entries...", len(renames))
+	return renameCmd(b.backend, renames)
+}
+
+func (b *BatchRename) clampTop() {
+	if b.top > len(b.rows)-b.bodyHeight() {
+		b.top = len(b.rows) - b.bodyHeight()
+	}
+	if b.top < 0 {
+		b.top = 0
+	}
+}
+
+func (b *BatchRename) View() string {
+	fields := make([]string, 0, fieldCount)
+	for f := field(0); f < fieldCount; f++ {
+		fields = append(fields, b.fieldView(f))
+	}
+	rows := make([]string, 0, b.bodyHeight())
+	for i := b.top; i < len(b.rows) && len(rows) < b.bodyHeight(); i++ {
+		rows = append(rows, b.rowView(b.rows[i]))
+	}
+	for len(rows) < b.bodyHeight() {
+		rows = append(rows, strings.Repeat(" ", b.width))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		b.headerView(),
+		strings.Join(fields, "\n"),
+		strings.Repeat(" ", b.width),
+		strings.Join(rows, "\n"),
+		b.footerView(),
+	)
+}
+
+func (b *BatchRename) fieldView(f field) string {
+	label := layout.FitWidth(" "+fieldLabels[f], labelWidth) + " "
+	labelStyle := lipgloss.NewStyle().Foreground(b.theme.TextColor)
+	if f == b.focus {
+		labelStyle = labelStyle.Bold(true).Foreground(b.theme.FolderColor)
+	}
+	var value string
+	if f == fieldCase {
+		value = "< " + b.caseOpt.String() + " >"
+		if f != b.focus {
+			value = "  " + b.caseOpt.String()
+		}
+	} else {
+		b.inputs[f].Width = b.width - labelWidth - 2
+		value = b.inputs[f].View()
+	}
+	return layout.FitWidth(labelStyle.Render(label)+value, b.width)
+}
+
+func (b *BatchRename) rowView(row rename.Row) string {
+	old := row.From
+	if !row.Changed() && row.Err == nil {
+		return layout.FitWidth(termenv.String(" "+old).Faint().String(), b.width)
+	}
+	line := " " + old + " → " + row.To
+	if row.Err != nil {
+		return layout.FitWidth(termenv.String(line+"  "+row.Err.Error()).Foreground(termenv.ANSIRed).String(), b.width)
+	}
+	return layout.FitWidth(lipgloss.NewStyle().Foreground(b.theme.TextColor).Render(line), b.width)
+}
+
+func (b *BatchRename) headerView() string {
+	title := lipgloss.NewStyle().Bold(true).Render("Batch rename")
+	summary := fmt.Sprintf("%d entries, %d renamed", len(b.entries), b.changed)
+	if b.conflicts > 0 {
+		summary += fmt.Sprintf(", %d conflicts", b.conflicts)
+	}
+	gap := b.width - lipgloss.Width(title) - lipgloss.Width(summary) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return lipgloss.NewStyle().
+		Background(b.theme.InfobarBgColor).
+		Foreground(b.theme.InfobarFgColor).
+		Inline(true).
+		Render(layout.FitWidth(" "+title+strings.Repeat(" ", gap)+summary+" ", b.width))
+}
+
+func (b *BatchRename) footerView() string {
+	switch {
+	case b.status != "":
+		return layout.FitWidth(b.status, b.width)
+	case b.err != nil:
+		return layout.FitWidth(termenv.String(b.err.Error()).Foreground(termenv.ANSIRed).String(), b.width)
+	}
+	return layout.FitWidth(termenv.String("tab next field  ←/→ change case  pgup/pgdown scroll  enter rename  esc close").Faint().String(), b.width)
+}
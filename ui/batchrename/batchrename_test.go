@@ -0,0 +1,133 @@
+package batchrename
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry/rename"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/theme/colors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+)
+
+func renameFs() afero.Fs {
+	fsys := afero.NewMemMapFs()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		afero.WriteFile(fsys, "/root/"+name, []byte(name), 0644)
+	}
+	return fsys
+}
+
+// opened returns the view for a.txt and b.txt in /root on fsys, once it has read the
+// names of the directory
+func opened(t *testing.T, fsys afero.Fs, dryRun bool) *BatchRename {
+	t.Helper()
+	n := nav.NewNav(true, false, "/root", fsys, 0, dryRun)
+	entries := []rename.Entry{{Name: "a.txt"}, {Name: "b.txt"}}
+	b, _ := New(n, entries, colors.Theme{}, 80, 20)
+	b.Update(namesCmd(n)())
+	if len(b.existing) != 4 {
+		t.Fatalf("expected the 4 names of /root to be read, got %v", b.existing)
+	}
+	return b
+}
+
+// typeText sends the runes of s to the view
+func typeText(b *BatchRename, s string) {
+	for _, r := range s {
+		b.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// names returns the names of the entries of /root
+func names(t *testing.T, fsys afero.Fs) string {
+	t.Helper()
+	infos, err := afero.ReadDir(fsys, "/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return strings.Join(names, " ")
+}
+
+func TestBatchRenamePreviewCollisions(t *testing.T) {
+	fsys := renameFs()
+	b := opened(t, fsys, false)
+	typeText(b, "^a")
+	b.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeText(b, "c")
+	if b.conflicts != 1 || b.rows[0].Err == nil || b.rows[0].Err.Error() != "c.txt already exists" {
+		t.Errorf("expected a.txt to collide with the existing c.txt, got %v", b.rows)
+	}
+	if b.rows[1].Changed() || b.rows[1].Err != nil {
+		t.Errorf("expected b.txt to be left alone, got %v", b.rows[1])
+	}
+
+	// both entries are renamed to the same new name, which is not in the directory
+	b.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	b.inputs[fieldFind].SetValue("^[ab]")
+	b.Update(tea.KeyMsg{Type: tea.KeyTab})
+	b.inputs[fieldReplace].SetValue("")
+	typeText(b, "e")
+	if b.conflicts != 2 || !strings.Contains(b.View(), "a.txt → e.txt  2 entries would be named e.txt") {
+		t.Errorf("expected both entries to collide with each other, got\n%s", b.View())
+	}
+
+	if _, cmd := b.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("expected the renames to be refused while there are conflicts")
+	}
+	if got := names(t, fsys); got != "a.txt b.txt c.txt d.txt" {
+		t.Errorf("expected nothing to be renamed, got %s", got)
+	}
+}
+
+func TestBatchRenameApply(t *testing.T) {
+	fsys := renameFs()
+	b := opened(t, fsys, false)
+	b.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeText(b, "file-{n}")
+	if !strings.Contains(b.View(), "a.txt → file-1.txt") {
+		t.Errorf("expected the preview to show the new names, got\n%s", b.View())
+	}
+
+	_, cmd := b.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command to rename the entries")
+	}
+	_, cmd = b.Update(cmd())
+	if msg, ok := cmd().(ClosedMsg); !ok || msg.Renamed != 2 {
+		t.Errorf("expected the view to close after renaming 2 entries, got %#v", msg)
+	}
+	if got := names(t, fsys); got != "c.txt d.txt file-1.txt file-2.txt" {
+		t.Errorf("expected a.txt and b.txt to be renamed, got %s", got)
+	}
+	if data, _ := afero.ReadFile(fsys, "/root/file-2.txt"); string(data) != "b.txt" {
+		t.Errorf("expected file-2.txt to hold the content of b.txt, got %q", data)
+	}
+}
+
+func TestBatchRenameFailure(t *testing.T) {
+	fsys := renameFs()
+	b := opened(t, fsys, true)
+	b.Update(tea.KeyMsg{Type: tea.KeyShiftTab}) // wraps around to the case field
+	b.Update(tea.KeyMsg{Type: tea.KeyRight})
+	b.Update(tea.KeyMsg{Type: tea.KeyRight})
+	_, cmd := b.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command to rename the entries")
+	}
+	_, cmd = b.Update(cmd())
+	if _, ok := cmd().(namesMsg); !ok {
+		t.Error("expected the view to stay open and read the names again after a failure")
+	}
+	if !strings.Contains(b.View(), "dry run") {
+		t.Errorf("expected the error to be shown, got\n%s", b.View())
+	}
+	if got := names(t, fsys); got != "a.txt b.txt c.txt d.txt" {
+		t.Errorf("expected nothing to be renamed, got %s", got)
+	}
+}
@@ -1,11 +1,13 @@
 package breadcrumb
 
 import (
+	"fmt"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/Philistino/fman/entry/git"
 	"github.com/Philistino/fman/ui/message"
 	"github.com/Philistino/fman/ui/theme"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,14 +30,18 @@ type BreadCrumb struct {
 	path      string
 	width     int
 	viewParts []string
+	branch    string // rendered git branch of the path, empty outside of a git work tree
 	focused   bool
+	styles    theme.StyleSet
+	zones     *zone.Manager
 	zPrefix   string
 }
 
-// NewBreadCrumb creates a new breadcrumb.
+// NewBreadCrumb creates a new breadcrumb drawn with the styles. The clicks on the
+// directories are located with the zone manager.
 // It is focused by default
-func NewBreadCrumb() *BreadCrumb {
-	return &BreadCrumb{focused: true, zPrefix: zone.NewPrefix()}
+func NewBreadCrumb(styles theme.StyleSet, zones *zone.Manager) *BreadCrumb {
+	return &BreadCrumb{focused: true, styles: styles, zones: zones, zPrefix: zones.NewPrefix()}
 }
 
 // Init initializes the model
@@ -45,6 +51,14 @@ func (breadcrumb *BreadCrumb) Init() tea.Cmd {
 
 // Update updates the model
 func (breadcrumb *BreadCrumb) Update(msg tea.Msg) (*BreadCrumb, tea.Cmd) {
+	// the git status is read in the background so it is handled even if the breadcrumb is not focused
+	if msg, ok := msg.(message.GitStatusMsg); ok {
+		if msg.Path == breadcrumb.path {
+			breadcrumb.branch = breadcrumb.branchView(msg.Repo)
+			breadcrumb.updateView(breadcrumb.path)
+		}
+		return breadcrumb, nil
+	}
 	if !breadcrumb.focused {
 		return breadcrumb, nil
 	}
@@ -55,6 +69,9 @@ func (breadcrumb *BreadCrumb) Update(msg tea.Msg) (*BreadCrumb, tea.Cmd) {
 		if msg.Error() != nil {
 			return breadcrumb, nil
 		}
+		if msg.Path() != breadcrumb.path {
+			breadcrumb.branch = ""
+		}
 		breadcrumb.path = msg.Path()
 		breadcrumb.updateView(msg.Path())
 		return breadcrumb, nil
@@ -72,9 +89,30 @@ func (breadcrumb *BreadCrumb) Update(msg tea.Msg) (*BreadCrumb, tea.Cmd) {
 func (breadcrumb *BreadCrumb) View() string {
 	parts := make([]string, 0, len(breadcrumb.viewParts))
 	for i, part := range breadcrumb.viewParts {
-		parts = append(parts, zone.Mark(breadcrumb.zPrefix+strconv.Itoa(i), part))
+		parts = append(parts, breadcrumb.zones.Mark(breadcrumb.zPrefix+strconv.Itoa(i), part))
+	}
+	return lipgloss.NewStyle().MarginLeft(2).Render(strings.Join(parts, "") + breadcrumb.branch)
+}
+
+// branchView renders the branch of the git work tree with the number of commits it is
+// ahead of and behind its upstream
+func (breadcrumb *BreadCrumb) branchView(repo *git.Repo) string {
+	if repo == nil || repo.Branch == "" {
+		return ""
+	}
+	var b strings.Builder
+	if icon := breadcrumb.styles.Icons.BranchIcon; icon != 0 {
+		b.WriteRune(icon)
+		b.WriteRune(' ')
+	}
+	b.WriteString(repo.Branch)
+	if repo.Ahead > 0 {
+		fmt.Fprintf(&b, " ↑%d", repo.Ahead)
+	}
+	if repo.Behind > 0 {
+		fmt.Fprintf(&b, " ↓%d", repo.Behind)
 	}
-	return lipgloss.NewStyle().MarginLeft(2).Render(strings.Join(parts, ""))
+	return breadcrumb.styles.BranchStyle.Render(b.String())
 }
 
 // handleMouseMsg handles mouse clicks on the breadcrumb
@@ -86,7 +124,7 @@ func (breadcrumb *BreadCrumb) handleMouseMsg(msg tea.MouseMsg) tea.Cmd {
 	clicked := false
 	var viewPartClicked int
 	for i := 0; i < len(breadcrumb.viewParts); i++ {
-		if !zone.Get(breadcrumb.zPrefix + strconv.Itoa(i)).InBounds(msg) {
+		if !breadcrumb.zones.Get(breadcrumb.zPrefix + strconv.Itoa(i)).InBounds(msg) {
 			continue
 		}
 		viewPartClicked = i
@@ -111,11 +149,11 @@ func (breadcrumb *BreadCrumb) updateView(path string) {
 
 	// if the path is a root path, just return the root rendered
 	if winRootRgx.MatchString(path) {
-		breadcrumb.viewParts = []string{theme.PathStyle.Render(strings.Replace(path, pathSeparator, "", 1))}
+		breadcrumb.viewParts = []string{breadcrumb.styles.PathStyle.Render(strings.Replace(path, pathSeparator, "", 1))}
 		return
 	}
 	if path == pathSeparator {
-		breadcrumb.viewParts = []string{theme.PathStyle.Render(path)}
+		breadcrumb.viewParts = []string{breadcrumb.styles.PathStyle.Render(path)}
 		return
 	}
 
@@ -125,7 +163,7 @@ func (breadcrumb *BreadCrumb) updateView(path string) {
 		pathParts = append([]string{pathSeparator}, pathParts...)
 	}
 
-	separator := theme.ArrowStyle.Render(string(theme.GetActiveIconTheme().BreadcrumbArrowIcon))
+	separator := breadcrumb.styles.ArrowStyle.Render(string(breadcrumb.styles.Icons.BreadcrumbArrowIcon))
 
 	// reverse the parts so we prioritize directories closer to the current
 	// directory over ones closer to root
@@ -139,14 +177,14 @@ func (breadcrumb *BreadCrumb) updateView(path string) {
 			continue
 		}
 
-		partRendered := theme.PathStyle.Render(part)
+		partRendered := breadcrumb.styles.PathStyle.Render(part)
 		if i != 0 {
 			partRendered = partRendered + separator
 		}
 
 		partWidth := lipgloss.Width(partRendered)
 		totalLength += partWidth
-		if totalLength+12 > breadcrumb.width { // +12 seems to be a magic number
+		if totalLength+lipgloss.Width(breadcrumb.branch)+12 > breadcrumb.width { // +12 seems to be a magic number
 			break
 		}
 		parts = append(parts, partRendered)
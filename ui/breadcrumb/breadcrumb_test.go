@@ -6,6 +6,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Philistino/fman/entry/git"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/theme"
 	zone "github.com/lrstanley/bubblezone"
 )
 
@@ -78,7 +81,7 @@ func TestUpdateView(t *testing.T) {
 	defer zone.Close()
 	for _, tc := range testcases {
 		t.Run(tc.desc, func(t *testing.T) {
-			b := NewBreadCrumb()
+			b := NewBreadCrumb(theme.GlobalStyleSet(), zone.DefaultManager)
 			b.Init()
 			b.SetWidth(1000)
 			b.Update(pathError{path: tc.path, err: nil})
@@ -100,7 +103,7 @@ func TestUpdateView(t *testing.T) {
 // TestError tests that a msg with an error is ignored
 func TestError(t *testing.T) {
 	zone.NewGlobal()
-	b := NewBreadCrumb()
+	b := NewBreadCrumb(theme.GlobalStyleSet(), zone.DefaultManager)
 	b.SetWidth(1000)
 	want := "start"
 	ignore := "ignore"
@@ -143,7 +146,7 @@ func TestUpdateViewTruncate(t *testing.T) {
 	defer zone.Close()
 	for _, tc := range testcases {
 		t.Run(tc.desc, func(t *testing.T) {
-			b := NewBreadCrumb()
+			b := NewBreadCrumb(theme.GlobalStyleSet(), zone.DefaultManager)
 			b.SetWidth(tc.width)
 			b.updateView(tc.path)
 			if len(b.viewParts) != tc.wantLen {
@@ -160,3 +163,35 @@ func TestUpdateViewTruncate(t *testing.T) {
 		})
 	}
 }
+
+// TestGitBranch tests that the branch is shown for the current path only
+func TestGitBranch(t *testing.T) {
+	zone.NewGlobal()
+	defer zone.Close()
+	b := NewBreadCrumb(theme.GlobalStyleSet(), zone.DefaultManager)
+	b.SetWidth(1000)
+	path := filepath.Join(pathSeparator, "repo")
+	b.Update(pathError{path: path})
+
+	b.Update(message.GitStatusMsg{Path: filepath.Join(pathSeparator, "other"), Repo: &git.Repo{Branch: "stale"}})
+	if strings.Contains(b.View(), "stale") {
+		t.Error("the branch of another path is shown")
+	}
+
+	b.Update(message.GitStatusMsg{Path: path, Repo: &git.Repo{Branch: "main", Upstream: true, Ahead: 2, Behind: 1}})
+	for _, want := range []string{"main", "↑2", "↓1"} {
+		if !strings.Contains(b.View(), want) {
+			t.Errorf("%q not found in View() %q", want, b.View())
+		}
+	}
+
+	b.Update(message.GitStatusMsg{Path: path, Repo: &git.Repo{Branch: "main", Upstream: true}})
+	if strings.Contains(b.View(), "↑") || strings.Contains(b.View(), "↓") {
+		t.Errorf("counts shown in View() %q when the branch is up to date", b.View())
+	}
+
+	b.Update(pathError{path: filepath.Join(pathSeparator, "elsewhere")})
+	if strings.Contains(b.View(), "main") {
+		t.Error("the branch is still shown after the path changed")
+	}
+}
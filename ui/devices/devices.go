@@ -0,0 +1,285 @@
+// Package devices implements a full screen panel that lists the mounted filesystems
+// with their free space. A mount can be chosen to navigate to it.
+package devices
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Philistino/fman/entry/storage"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/muesli/termenv"
+)
+
+// barWidth is the width of the used space bars
+const barWidth = 20
+
+// fstypeWidth is the width of the filesystem type column
+const fstypeWidth = 10
+
+// Backend lists the mounted filesystems
+type Backend interface {
+	Mounts() ([]storage.Mount, error)
+}
+
+// ClosedMsg is sent when the user closes the panel. Path is the mountpoint that
+// was chosen, or empty if none was.
+type ClosedMsg struct {
+	Path string
+}
+
+func closedCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		return ClosedMsg{Path: path}
+	}
+}
+
+// mountsMsg is sent when the mounts have been listed
+type mountsMsg struct {
+	mounts []storage.Mount
+	err    error
+}
+
+func mountsCmd(backend Backend) tea.Cmd {
+	return func() tea.Msg {
+		mounts, err := backend.Mounts()
+		return mountsMsg{mounts: mounts, err: err}
+	}
+}
+
+// Devices lists the mounted filesystems
+type Devices struct {
+	backend Backend
+	path    string // current directory, used to put the cursor on its mount
+
+	mounts  []storage.Mount
+	cursor  int
+	top     int
+	loading bool
+	status  string
+
+	theme  colors.Theme
+	width  int
+	height int
+}
+
+// New creates the panel and returns the command that lists the mounts
+func New(backend Backend, path string, theme colors.Theme, width, height int) (*Devices, tea.Cmd) {
+	d := &Devices{
+		backend: backend,
+		path:    path,
+		theme:   theme,
+		width:   width,
+		height:  height,
+	}
+	return d, d.refresh()
+}
+
+// SetSize sets the size of the panel
+func (d *Devices) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.clampCursor()
+}
+
+// bodyHeight returns the number of rows available for mounts
+func (d *Devices) bodyHeight() int {
+	h := d.height - 2 // header and footer
+	if h < 1 {
+		return 1
+	}
+	return h
+}
+
+func (d *Devices) Update(msg tea.Msg) (*Devices, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.SetSize(msg.Width, msg.Height)
+	case mountsMsg:
+		d.handleMounts(msg)
+	case tea.KeyMsg:
+		return d, d.handleKey(msg)
+	}
+	return d, nil
+}
+
+func (d *Devices) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, keys.Map.ClosePager):
+		return closedCmd("")
+	case key.Matches(msg, keys.Map.DevicesRefresh):
+		return d.refresh()
+	case key.Matches(msg, keys.Map.MoveCursorUp):
+		d.moveCursor(-1)
+	case key.Matches(msg, keys.Map.MoveCursorDown):
+		d.moveCursor(1)
+	case key.Matches(msg, keys.Map.PageUp):
+		d.moveCursor(-d.bodyHeight())
+	case key.Matches(msg, keys.Map.PageDown):
+		d.moveCursor(d.bodyHeight())
+	case key.Matches(msg, keys.Map.MoveCursorToTop):
+		d.moveCursor(-len(d.mounts))
+	case key.Matches(msg, keys.Map.MoveCursorToBottom):
+		d.moveCursor(len(d.mounts))
+	case key.Matches(msg, keys.Map.OpenFile), key.Matches(msg, keys.Map.GoToSelectedDirectory):
+		if selected := d.selected(); selected != nil {
+			return closedCmd(selected.Mountpoint)
+		}
+	}
+	return nil
+}
+
+func (d *Devices) refresh() tea.Cmd {
+	d.loading = true
+	d.status = ""
+	return mountsCmd(d.backend)
+}
+
+func (d *Devices) handleMounts(msg mountsMsg) {
+	d.loading = false
+	if msg.err != nil {
+		d.status = msg.err.Error()
+		return
+	}
+	var previous string
+	if selected := d.selected(); selected != nil {
+		previous = selected.Mountpoint
+	}
+	d.mounts = msg.mounts
+	d.cursor = 0
+	d.top = 0
+	// keep the cursor on the same mount after a refresh, or start on the mount of the current directory
+	if previous != "" {
+		d.cursor = d.find(previous)
+	} else {
+		d.cursor = d.mountOf(d.path)
+	}
+	d.clampCursor()
+}
+
+// find returns the index of the mount at mountpoint, or 0 if there is none
+func (d *Devices) find(mountpoint string) int {
+	for i, m := range d.mounts {
+		if m.Mountpoint == mountpoint {
+			return i
+		}
+	}
+	return 0
+}
+
+// mountOf returns the index of the mount with the longest mountpoint that contains path
+func (d *Devices) mountOf(path string) int {
+	best, bestLen := 0, -1
+	for i, m := range d.mounts {
+		if !contains(m.Mountpoint, path) || len(m.Mountpoint) <= bestLen {
+			continue
+		}
+		best, bestLen = i, len(m.Mountpoint)
+	}
+	return best
+}
+
+// contains reports whether path is mountpoint or below it
+func contains(mountpoint, path string) bool {
+	if !strings.HasPrefix(path, mountpoint) {
+		return false
+	}
+	rest := path[len(mountpoint):]
+	return rest == "" || strings.HasSuffix(mountpoint, "/") || strings.HasSuffix(mountpoint, `\`) ||
+		rest[0] == '/' || rest[0] == '\\'
+}
+
+// selected returns the mount under the cursor
+func (d *Devices) selected() *storage.Mount {
+	if d.cursor < 0 || d.cursor >= len(d.mounts) {
+		return nil
+	}
+	return &d.mounts[d.cursor]
+}
+
+func (d *Devices) moveCursor(n int) {
+	d.cursor += n
+	d.clampCursor()
+}
+
+// clampCursor keeps the cursor on a mount and the cursor in view
+func (d *Devices) clampCursor() {
+	if d.cursor >= len(d.mounts) {
+		d.cursor = len(d.mounts) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+	if d.cursor < d.top {
+		d.top = d.cursor
+	}
+	if d.cursor >= d.top+d.bodyHeight() {
+		d.top = d.cursor - d.bodyHeight() + 1
+	}
+}
+
+func (d *Devices) View() string {
+	rows := make([]string, 0, d.bodyHeight())
+	for i := d.top; i < len(d.mounts) && len(rows) < d.bodyHeight(); i++ {
+		rows = append(rows, d.rowView(d.mounts[i], i == d.cursor))
+	}
+	for len(rows) < d.bodyHeight() {
+		rows = append(rows, strings.Repeat(" ", d.width))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, d.headerView(), strings.Join(rows, "\n"), d.footerView())
+}
+
+func (d *Devices) rowView(m storage.Mount, selected bool) string {
+	var share float64
+	if m.TotalSpace > 0 {
+		share = float64(m.UsedSpace()) / float64(m.TotalSpace)
+	}
+	filled := int(share*barWidth + 0.5)
+	bar := lipgloss.NewStyle().Background(d.theme.ProgressBarFgColor).Render(strings.Repeat(" ", filled)) +
+		lipgloss.NewStyle().Background(d.theme.ProgressBarBgColor).Render(strings.Repeat(" ", barWidth-filled))
+
+	fstype := " " + layout.FitWidth(m.Fstype, fstypeWidth) + " "
+	space := fmt.Sprintf(" %9s / %-9s %9s free ", humanize.Bytes(m.UsedSpace()), humanize.Bytes(m.TotalSpace), humanize.Bytes(m.AvailableSpace))
+	name := m.Mountpoint
+	if m.Device != "" && m.Device != m.Mountpoint {
+		name += " (" + m.Device + ")"
+	}
+	nameWidth := d.width - lipgloss.Width(fstype) - barWidth - lipgloss.Width(space)
+	nameStyle := lipgloss.NewStyle().Foreground(d.theme.FolderColor)
+	style := lipgloss.NewStyle().Foreground(d.theme.TextColor)
+	if selected {
+		style = lipgloss.NewStyle().Background(d.theme.SelectedItemBgColor).Foreground(d.theme.SelectedItemFgColor)
+		nameStyle = style
+	}
+	return style.Render(fstype) + bar + style.Render(space) + nameStyle.Render(layout.FitWidth(name, nameWidth))
+}
+
+func (d *Devices) headerView() string {
+	title := lipgloss.NewStyle().Bold(true).Render("Devices")
+	summary := fmt.Sprintf("%d mounts", len(d.mounts))
+	gap := d.width - lipgloss.Width(title) - lipgloss.Width(summary) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return lipgloss.NewStyle().
+		Background(d.theme.InfobarBgColor).
+		Foreground(d.theme.InfobarFgColor).
+		Inline(true).
+		Render(layout.FitWidth(" "+title+strings.Repeat(" ", gap)+summary+" ", d.width))
+}
+
+func (d *Devices) footerView() string {
+	switch {
+	case d.loading:
+		return layout.FitWidth("Listing mounts...", d.width)
+	case d.status != "":
+		return layout.FitWidth(d.status, d.width)
+	}
+	return layout.FitWidth(termenv.String("enter/right go to mount  r refresh  q close").Faint().String(), d.width)
+}
@@ -0,0 +1,101 @@
+package devices
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry/storage"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/theme/colors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+)
+
+var testMounts = []storage.Mount{
+	{Mountpoint: "/", Device: "/dev/sda1", Fstype: "ext4", StorageInfo: storage.StorageInfo{TotalSpace: 100, FreeSpace: 25, AvailableSpace: 20}},
+	{Mountpoint: "/home", Device: "/dev/sda2", Fstype: "ext4", StorageInfo: storage.StorageInfo{TotalSpace: 200, FreeSpace: 200, AvailableSpace: 200}},
+	{Mountpoint: "/home/user/usb", Device: "/dev/sdb1", Fstype: "vfat", StorageInfo: storage.StorageInfo{TotalSpace: 10, FreeSpace: 5, AvailableSpace: 5}},
+}
+
+// loaded returns a panel opened in path that has listed testMounts. The mounts of the
+// system differ from machine to machine, so they are given to the panel directly.
+func loaded(path string) *Devices {
+	n := nav.NewNav(true, false, path, afero.NewMemMapFs(), 0, false)
+	d, _ := New(n, path, colors.Theme{}, 100, 10)
+	d.Update(mountsMsg{mounts: testMounts})
+	return d
+}
+
+func TestDevicesCursorStartsOnCurrentMount(t *testing.T) {
+	tests := []struct {
+		path string
+		exp  string
+	}{
+		{"/etc", "/"},
+		{"/home", "/home"},
+		{"/home/user", "/home"},
+		{"/home/user/usb/photos", "/home/user/usb"},
+		{"/homework", "/"},
+	}
+	for _, test := range tests {
+		d := loaded(test.path)
+		if got := d.selected().Mountpoint; got != test.exp {
+			t.Errorf("path %s: expected the cursor on %s, got %s", test.path, test.exp, got)
+		}
+	}
+}
+
+func TestDevicesRefreshKeepsSelection(t *testing.T) {
+	d := loaded("/")
+	d.Update(tea.KeyMsg{Type: tea.KeyDown})
+	d.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if cmd := d.refresh(); cmd == nil || !d.loading {
+		t.Fatal("expected a command listing the mounts")
+	}
+
+	// the usb stick moved up the list and the home partition was unmounted
+	d.Update(mountsMsg{mounts: []storage.Mount{testMounts[2], testMounts[0]}})
+	if d.loading || d.selected().Mountpoint != "/home/user/usb" {
+		t.Errorf("expected the cursor to stay on /home/user/usb, got %s", d.selected().Mountpoint)
+	}
+	d.Update(mountsMsg{mounts: []storage.Mount{testMounts[0]}})
+	if d.selected().Mountpoint != "/" {
+		t.Errorf("expected the cursor to move to the first mount, got %s", d.selected().Mountpoint)
+	}
+
+	d.Update(mountsMsg{err: errors.New("no mounts")})
+	if d.status != "no mounts" || len(d.mounts) != 1 {
+		t.Errorf("expected the mounts to be kept when listing fails, got status %q", d.status)
+	}
+}
+
+func TestDevicesChooseMount(t *testing.T) {
+	d := loaded("/")
+	d.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command when a mount is chosen")
+	}
+	msg, ok := cmd().(ClosedMsg)
+	if !ok || msg.Path != "/home" {
+		t.Errorf("expected the panel to close with /home, got %#v", msg)
+	}
+}
+
+func TestDevicesView(t *testing.T) {
+	d := loaded("/")
+	lines := strings.Split(d.View(), "\n")
+	if len(lines) != d.height {
+		t.Fatalf("expected %d lines, got %d", d.height, len(lines))
+	}
+	for i, line := range lines {
+		if w := lipgloss.Width(line); w != d.width {
+			t.Errorf("line %d: expected width %d, got %d", i, d.width, w)
+		}
+	}
+	if !strings.Contains(lines[1], "ext4") || !strings.Contains(lines[1], "/dev/sda1") || !strings.Contains(lines[1], "20 B free") {
+		t.Errorf("unexpected row %q", lines[1])
+	}
+}
@@ -0,0 +1,61 @@
+package dupes
+
+import (
+	"context"
+	"time"
+
+	"github.com/Philistino/fman/entry/dupes"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchDoneMsg is sent when a search finishes
+type searchDoneMsg struct {
+	id     int
+	groups []dupes.Group
+	err    error
+}
+
+func searchCmd(ctx context.Context, backend Backend, id int, path string, progress *dupes.Progress) tea.Cmd {
+	return func() tea.Msg {
+		groups, err := backend.FindDuplicates(ctx, path, progress)
+		return searchDoneMsg{id: id, groups: groups, err: err}
+	}
+}
+
+// progressTickMsg redraws the progress of a search
+type progressTickMsg struct {
+	id int
+}
+
+func progressTick(id int) tea.Cmd {
+	return tea.Tick(progressInterval, func(time.Time) tea.Msg {
+		return progressTickMsg{id: id}
+	})
+}
+
+// actionDoneMsg is sent once the selected copies have been trashed or replaced with links
+type actionDoneMsg struct {
+	action action
+	paths  []string
+	errs   []error // one for each path
+}
+
+func trashCmd(backend Backend, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		errs := backend.TrashPaths(context.Background(), paths)
+		return actionDoneMsg{action: actionTrash, paths: paths, errs: errs}
+	}
+}
+
+// linkCmd replaces the selected copies of each group with hard links to the copy that is kept
+func linkCmd(backend Backend, plans []linkPlan) tea.Cmd {
+	return func() tea.Msg {
+		var paths []string
+		var errs []error
+		for _, p := range plans {
+			paths = append(paths, p.paths...)
+			errs = append(errs, backend.LinkDuplicates(context.Background(), p.target, p.paths)...)
+		}
+		return actionDoneMsg{action: actionLink, paths: paths, errs: errs}
+	}
+}
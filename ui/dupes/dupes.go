@@ -0,0 +1,454 @@
+// Package dupes implements a full screen view of the files below a directory that have the
+// same content. Copies are selected like entries of the list and can be moved to the trash
+// or replaced with hard links to the copy that is kept.
+package dupes
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Philistino/fman/entry/dupes"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/Philistino/fman/ui/list"
+	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/muesli/termenv"
+)
+
+// progressInterval is how often the progress of a search is shown
+const progressInterval = 100 * time.Millisecond
+
+// groupWidth is the width of the column that shows the size and count of each group
+const groupWidth = 16
+
+// Backend performs the filesystem operations of the view
+type Backend interface {
+	FindDuplicates(ctx context.Context, path string, progress *dupes.Progress) ([]dupes.Group, error)
+	TrashPaths(ctx context.Context, paths []string) []error
+	LinkDuplicates(ctx context.Context, target string, paths []string) []error
+}
+
+// ClosedMsg is sent when the user closes the view
+type ClosedMsg struct{}
+
+func closedCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ClosedMsg{}
+	}
+}
+
+// action is what is done with the selected copies
+type action uint8
+
+const (
+	actionNone action = iota
+	actionTrash
+	actionLink
+)
+
+// row is a file in the table. The files of a group are in consecutive rows.
+type row struct {
+	group int
+	path  string
+}
+
+// Dupes shows groups of files with the same content
+type Dupes struct {
+	backend Backend
+	path    string // path that is searched
+
+	groups []dupes.Group
+	rows   []row
+	table  list.Table
+
+	searchID  int // identifies the current search so that results of cancelled searches are ignored
+	searching bool
+	progress  *dupes.Progress
+	cancel    context.CancelFunc
+	spinner   spinner.Model
+
+	confirm action // action waiting for the user to confirm it
+	status  string
+
+	theme  colors.Theme
+	width  int
+	height int
+}
+
+// New creates the view and returns the command that starts searching path
+func New(backend Backend, path string, theme colors.Theme, width, height int) (*Dupes, tea.Cmd) {
+	d := &Dupes{
+		backend: backend,
+		path:    path,
+		table:   list.NewTable(),
+		spinner: spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+		theme:   theme,
+		width:   width,
+		height:  height,
+	}
+	d.table.SetHeight(d.bodyHeight())
+	return d, d.search()
+}
+
+// Close cancels a running search
+func (d *Dupes) Close() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// SetSize sets the size of the view
+func (d *Dupes) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.table.SetHeight(d.bodyHeight())
+}
+
+// bodyHeight returns the number of rows available for files
+func (d *Dupes) bodyHeight() int {
+	h := d.height - 2 // header and footer
+	if h < 1 {
+		return 1
+	}
+	return h
+}
+
+func (d *Dupes) Update(msg tea.Msg) (*Dupes, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.SetSize(msg.Width, msg.Height)
+	case searchDoneMsg:
+		d.handleSearchDone(msg)
+	case progressTickMsg:
+		if msg.id == d.searchID && d.searching {
+			return d, progressTick(d.searchID)
+		}
+	case spinner.TickMsg:
+		if d.searching {
+			var cmd tea.Cmd
+			d.spinner, cmd = d.spinner.Update(msg)
+			return d, cmd
+		}
+	case actionDoneMsg:
+		d.handleActionDone(msg)
+	case tea.KeyMsg:
+		if d.confirm != actionNone {
+			return d, d.handleConfirmKey(msg)
+		}
+		return d, d.handleKey(msg)
+	}
+	return d, nil
+}
+
+func (d *Dupes) handleKey(msg tea.KeyMsg) tea.Cmd {
+	d.status = ""
+	switch {
+	case key.Matches(msg, keys.Map.ClosePager):
+		return closedCmd()
+	case key.Matches(msg, keys.Map.DupesRescan):
+		return d.search()
+	}
+	if len(d.rows) == 0 {
+		return nil
+	}
+	switch {
+	case key.Matches(msg, keys.Map.MoveCursorUp):
+		d.table.MoveUp(1, false)
+	case key.Matches(msg, keys.Map.MoveCursorDown):
+		d.table.MoveDown(1, false)
+	case key.Matches(msg, keys.Map.PageUp):
+		d.table.MoveUp(d.bodyHeight(), false)
+	case key.Matches(msg, keys.Map.PageDown):
+		d.table.MoveDown(d.bodyHeight(), false)
+	case key.Matches(msg, keys.Map.MoveCursorToTop):
+		d.table.GoToTop()
+	case key.Matches(msg, keys.Map.MoveCursorToBottom):
+		d.table.GoToBottom()
+	case key.Matches(msg, keys.Map.MultiSelectUp):
+		d.table.MoveUp(1, true)
+	case key.Matches(msg, keys.Map.MultiSelectDown):
+		d.table.MoveDown(1, true)
+	case key.Matches(msg, keys.Map.MultiSelectToTop):
+		d.table.MultiSelectToTop()
+	case key.Matches(msg, keys.Map.MultiSelectToBottom):
+		d.table.MultiSelectToBottom()
+	case key.Matches(msg, keys.Map.MultiSelectAll):
+		d.table.SelectAll()
+	case key.Matches(msg, keys.Map.DupesSelectCopies):
+		d.selectCopies()
+	case key.Matches(msg, keys.Map.DupesTrash):
+		return d.ask(actionTrash)
+	case key.Matches(msg, keys.Map.DupesLink):
+		return d.ask(actionLink)
+	}
+	return nil
+}
+
+// ask checks that the selection keeps a copy of every group and asks the user to confirm the action
+func (d *Dupes) ask(a action) tea.Cmd {
+	if _, err := d.plan(); err != nil {
+		d.status = err.Error()
+		return nil
+	}
+	d.confirm = a
+	return nil
+}
+
+func (d *Dupes) handleConfirmKey(msg tea.KeyMsg) tea.Cmd {
+	a := d.confirm
+	d.confirm = actionNone
+	if msg.String() != "y" && msg.String() != "Y" {
+		return nil
+	}
+	plan, err := d.plan()
+	if err != nil {
+		d.status = err.Error()
+		return nil
+	}
+	var paths []string
+	for _, p := range plan {
+		paths = append(paths, p.paths...)
+	}
+	if a == actionTrash {
+		d.status = fmt.Sprintf("Moving %d files to the trash...", len(paths))
+		return trashCmd(d.backend, paths)
+	}
+	d.status = fmt.Sprintf("Replacing %d files with hard links...", len(paths))
+	return linkCmd(d.backend, plan)
+}
+
+// search starts searching the path, cancelling a running search
+func (d *Dupes) search() tea.Cmd {
+	d.Close()
+	var ctx context.Context
+	ctx, d.cancel = context.WithCancel(context.Background())
+	d.searchID++
+	d.searching = true
+	d.progress = &dupes.Progress{}
+	return tea.Batch(
+		searchCmd(ctx, d.backend, d.searchID, d.path, d.progress),
+		progressTick(d.searchID),
+		d.spinner.Tick,
+	)
+}
+
+func (d *Dupes) handleSearchDone(msg searchDoneMsg) {
+	if msg.id != d.searchID {
+		return
+	}
+	d.searching = false
+	d.cancel = nil
+	if msg.err != nil {
+		d.status = msg.err.Error()
+		return
+	}
+	d.setGroups(msg.groups)
+	d.table.SetCursor(0)
+	d.table.SetSelected([]int{0})
+}
+
+// setGroups shows the groups and keeps the cursor in place
+func (d *Dupes) setGroups(groups []dupes.Group) {
+	d.groups = groups
+	d.rows = d.rows[:0]
+	for i, g := range groups {
+		for _, path := range g.Paths {
+			d.rows = append(d.rows, row{group: i, path: path})
+		}
+	}
+	cursor := d.table.Cursor()
+	d.table.SetNRows(len(d.rows))
+	d.table.SetCursor(cursor)
+	d.table.SetSelected([]int{d.table.Cursor()})
+}
+
+// selectCopies selects every file but the first of each group
+func (d *Dupes) selectCopies() {
+	var idxs []int
+	for i, r := range d.rows {
+		if i > 0 && d.rows[i-1].group == r.group {
+			idxs = append(idxs, i)
+		}
+	}
+	d.table.SetSelected(idxs)
+}
+
+// linkPlan is a group with the copy that is kept and the selected copies of it
+type linkPlan struct {
+	target string
+	paths  []string
+}
+
+// plan returns the selected files of each group together with a file of the group that is not
+// selected. It fails if every file of a group is selected, so that no content is ever lost.
+func (d *Dupes) plan() ([]linkPlan, error) {
+	selected := make(map[int]bool, len(d.rows))
+	for _, i := range d.table.SelectedRows() {
+		if i < len(d.rows) {
+			selected[i] = true
+		}
+	}
+	var plans []linkPlan
+	for i := 0; i < len(d.rows); {
+		group := d.rows[i].group
+		var p linkPlan
+		for ; i < len(d.rows) && d.rows[i].group == group; i++ {
+			switch {
+			case selected[i]:
+				p.paths = append(p.paths, d.rows[i].path)
+			case p.target == "":
+				p.target = d.rows[i].path
+			}
+		}
+		if len(p.paths) == 0 {
+			continue
+		}
+		if p.target == "" {
+			return nil, fmt.Errorf("keep at least one copy of %s", d.relative(d.groups[group].Paths[0]))
+		}
+		plans = append(plans, p)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("no files are selected")
+	}
+	return plans, nil
+}
+
+func (d *Dupes) handleActionDone(msg actionDoneMsg) {
+	done := make(map[string]bool, len(msg.paths))
+	var failed []error
+	for i, err := range msg.errs {
+		if err != nil {
+			failed = append(failed, err)
+			continue
+		}
+		done[msg.paths[i]] = true
+	}
+	var freed int64
+	groups := make([]dupes.Group, 0, len(d.groups))
+	for _, g := range d.groups {
+		kept := make([]string, 0, len(g.Paths))
+		for _, path := range g.Paths {
+			if done[path] {
+				freed += g.Size
+				continue
+			}
+			kept = append(kept, path)
+		}
+		// linked files share the content of the kept copy, so they are no longer duplicates
+		if len(kept) > 1 {
+			groups = append(groups, dupes.Group{Size: g.Size, Paths: kept})
+		}
+	}
+	d.setGroups(groups)
+
+	verb := "Moved %d files to the trash, freeing %s"
+	if msg.action == actionLink {
+		verb = "Replaced %d files with hard links, freeing %s"
+	}
+	d.status = fmt.Sprintf(verb, len(done), humanize.Bytes(uint64(freed)))
+	if len(failed) > 0 {
+		d.status = fmt.Sprintf("%s. %d failed: %s", d.status, len(failed), failed[0])
+	}
+}
+
+// relative returns path relative to the searched directory
+func (d *Dupes) relative(path string) string {
+	if rel, err := filepath.Rel(d.path, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// reclaimable returns the bytes freed by removing all copies and by removing the selected files
+func (d *Dupes) reclaimable() (total int64, selected int64) {
+	for _, g := range d.groups {
+		total += g.Reclaimable()
+	}
+	for _, i := range d.table.SelectedRows() {
+		if i < len(d.rows) {
+			selected += d.groups[d.rows[i].group].Size
+		}
+	}
+	return total, selected
+}
+
+func (d *Dupes) View() string {
+	rows := make([]string, 0, d.bodyHeight())
+	if len(d.rows) > 0 {
+		start, end := d.table.Viewport()
+		for i := start; i <= end && i < len(d.rows) && len(rows) < d.bodyHeight(); i++ {
+			rows = append(rows, d.rowView(i))
+		}
+	}
+	if len(d.rows) == 0 && !d.searching && d.status == "" {
+		rows = append(rows, layout.FitWidth(" No duplicate files found", d.width))
+	}
+	for len(rows) < d.bodyHeight() {
+		rows = append(rows, strings.Repeat(" ", d.width))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, d.headerView(), strings.Join(rows, "\n"), d.footerView())
+}
+
+func (d *Dupes) rowView(i int) string {
+	r := d.rows[i]
+	group := strings.Repeat(" ", groupWidth)
+	if i == 0 || d.rows[i-1].group != r.group {
+		g := d.groups[r.group]
+		group = layout.FitWidth(fmt.Sprintf(" %9s ×%d", humanize.Bytes(uint64(g.Size)), len(g.Paths)), groupWidth)
+	}
+	group = lipgloss.NewStyle().Foreground(d.theme.TextColor).Render(group)
+	name := " " + d.relative(r.path)
+	if d.table.IsSelected(i) {
+		style := lipgloss.NewStyle().Background(d.theme.SelectedItemBgColor).Foreground(d.theme.SelectedItemFgColor)
+		return group + style.Render(layout.FitWidth(name, d.width-groupWidth))
+	}
+	return group + lipgloss.NewStyle().Foreground(d.theme.TextColor).Render(layout.FitWidth(name, d.width-groupWidth))
+}
+
+func (d *Dupes) headerView() string {
+	name := lipgloss.NewStyle().Bold(true).Render("Duplicates in " + d.path)
+	var summary string
+	if !d.searching {
+		total, _ := d.reclaimable()
+		summary = fmt.Sprintf("%d groups, %s reclaimable", len(d.groups), humanize.Bytes(uint64(total)))
+	}
+	gap := d.width - lipgloss.Width(name) - lipgloss.Width(summary) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return lipgloss.NewStyle().
+		Background(d.theme.InfobarBgColor).
+		Foreground(d.theme.InfobarFgColor).
+		Inline(true).
+		Render(layout.FitWidth(" "+name+strings.Repeat(" ", gap)+summary+" ", d.width))
+}
+
+func (d *Dupes) footerView() string {
+	_, selected := d.reclaimable()
+	n := len(d.table.SelectedRows())
+	switch {
+	case d.confirm == actionTrash:
+		return layout.FitWidth(fmt.Sprintf("Move %d files (%s) to the trash? y/N", n, humanize.Bytes(uint64(selected))), d.width)
+	case d.confirm == actionLink:
+		return layout.FitWidth(fmt.Sprintf("Replace %d files (%s) with hard links? y/N", n, humanize.Bytes(uint64(selected))), d.width)
+	case d.searching:
+		files, hashed := d.progress.Counts()
+		return layout.FitWidth(fmt.Sprintf("%s %s... %d files, %s read", d.spinner.View(), d.progress.Stage(), files, humanize.Bytes(uint64(hashed))), d.width)
+	case d.status != "":
+		return layout.FitWidth(d.status, d.width)
+	}
+	help := termenv.String("shift+up/down select  a select copies  d trash  l hard link  r rescan  q close").Faint().String()
+	if len(d.rows) == 0 {
+		return layout.FitWidth(help, d.width)
+	}
+	return layout.FitWidth(fmt.Sprintf("%d selected, %s  ", n, humanize.Bytes(uint64(selected)))+help, d.width)
+}
@@ -0,0 +1,171 @@
+package dupes
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry/fileutils"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/theme/colors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+)
+
+func dupesFs() afero.Fs {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", []byte("hello"), 0644)
+	afero.WriteFile(fsys, "/root/sub/a", []byte("hello"), 0644)
+	afero.WriteFile(fsys, "/root/big", []byte("hello world"), 0644)
+	afero.WriteFile(fsys, "/root/big2", []byte("hello world"), 0644)
+	afero.WriteFile(fsys, "/root/big3", []byte("hello world"), 0644)
+	afero.WriteFile(fsys, "/root/unique", []byte("unique"), 0644)
+	return fsys
+}
+
+// searched returns a view that has finished searching /root on fsys
+func searched(t *testing.T, fsys afero.Fs) *Dupes {
+	t.Helper()
+	n := nav.NewNav(true, false, "/root", fsys, 0, false)
+	d, cmd := New(n, "/root", colors.Theme{}, 80, 10)
+	// the search is the first command of the batch, the others redraw the progress
+	d.Update(cmd().(tea.BatchMsg)[0]())
+	if d.searching || len(d.groups) != 2 {
+		t.Fatalf("expected the search to find 2 groups, got %v, status %q", d.groups, d.status)
+	}
+	return d
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// selectedPaths returns the paths of the selected rows
+func selectedPaths(d *Dupes) []string {
+	var paths []string
+	for _, i := range d.table.SelectedRows() {
+		paths = append(paths, d.rows[i].path)
+	}
+	return paths
+}
+
+func TestDupesSelectCopies(t *testing.T) {
+	d := searched(t, dupesFs())
+	if d.rows[0].path != "/root/big" || d.rows[3].path != "/root/a" {
+		t.Fatalf("expected the group with the most reclaimable space first, got %v", d.rows)
+	}
+	d.Update(keyMsg("a"))
+	expected := "/root/big2,/root/big3,/root/sub/a"
+	if got := strings.Join(selectedPaths(d), ","); got != expected {
+		t.Errorf("expected every copy but the first of each group to be selected, got %s", got)
+	}
+	if total, selected := d.reclaimable(); total != 27 || selected != 27 {
+		t.Errorf("expected 27 of 27 bytes to be reclaimed, got %d of %d", selected, total)
+	}
+	plan, err := d.plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 2 || plan[0].target != "/root/big" || plan[1].target != "/root/a" {
+		t.Errorf("expected the first copy of each group to be kept, got %v", plan)
+	}
+}
+
+func TestDupesKeepsOneCopy(t *testing.T) {
+	fsys := dupesFs()
+	d := searched(t, fsys)
+	d.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	d.Update(keyMsg("d"))
+	if d.confirm != actionNone || !strings.Contains(d.status, "keep at least one copy of big") {
+		t.Errorf("expected trashing every copy to be refused, got status %q", d.status)
+	}
+	d.Update(keyMsg("l"))
+	if d.confirm != actionNone {
+		t.Error("expected linking every copy to be refused")
+	}
+}
+
+func TestDupesTrashCopies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("there is no trash in the home directory on windows")
+	}
+	t.Setenv("HOME", "/home")
+	t.Setenv("XDG_DATA_HOME", "")
+	fsys := dupesFs()
+	d := searched(t, fsys)
+	d.Update(keyMsg("a"))
+	d.Update(keyMsg("d"))
+	if d.confirm != actionTrash {
+		t.Fatal("expected to be asked to confirm")
+	}
+	_, cmd := d.Update(keyMsg("y"))
+	d.Update(cmd())
+
+	for _, path := range []string{"/root/big2", "/root/big3", "/root/sub/a"} {
+		if exists, _ := afero.Exists(fsys, path); exists {
+			t.Errorf("expected %s to be moved to the trash", path)
+		}
+	}
+	for _, path := range []string{"/root/big", "/root/a", "/root/unique"} {
+		if exists, _ := afero.Exists(fsys, path); !exists {
+			t.Errorf("expected %s to be kept", path)
+		}
+	}
+	if len(d.groups) != 0 || len(d.rows) != 0 {
+		t.Errorf("expected no duplicates to be left, got %v", d.groups)
+	}
+	if d.status != "Moved 3 files to the trash, freeing 27 B" {
+		t.Errorf("unexpected status %q", d.status)
+	}
+}
+
+func TestDupesLinkFailureKeepsGroups(t *testing.T) {
+	fsys := dupesFs()
+	d := searched(t, fsys)
+	// select the first two copies of the largest group, keeping the third
+	d.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	d.Update(keyMsg("l"))
+	if d.confirm != actionLink {
+		t.Fatal("expected to be asked to confirm")
+	}
+	if _, cmd := d.Update(keyMsg("n")); cmd != nil || d.confirm != actionNone {
+		t.Fatal("expected nothing to be linked when the user declines")
+	}
+
+	// the in memory filesystem has no hard links, so every copy fails and is kept
+	d.Update(keyMsg("l"))
+	_, cmd := d.Update(keyMsg("y"))
+	d.Update(cmd())
+	if len(d.groups) != 2 || len(d.groups[0].Paths) != 3 {
+		t.Errorf("expected the groups to be unchanged, got %v", d.groups)
+	}
+	if !strings.Contains(d.status, "Replaced 0 files") || !strings.Contains(d.status, "2 failed: "+fileutils.ErrLinkUnsupported.Error()) {
+		t.Errorf("unexpected status %q", d.status)
+	}
+	if data, _ := afero.ReadFile(fsys, "/root/big"); string(data) != "hello world" {
+		t.Errorf("expected /root/big to be unchanged, got %q", data)
+	}
+}
+
+func TestDupesView(t *testing.T) {
+	d := searched(t, dupesFs())
+	lines := strings.Split(d.View(), "\n")
+	if len(lines) != d.height {
+		t.Fatalf("expected %d lines, got %d", d.height, len(lines))
+	}
+	for i, line := range lines {
+		if w := lipgloss.Width(line); w != d.width {
+			t.Errorf("line %d: expected width %d, got %d", i, d.width, w)
+		}
+	}
+	if !strings.Contains(lines[0], "2 groups, 27 B reclaimable") {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "11 B ×3") || !strings.Contains(lines[1], "big") {
+		t.Errorf("unexpected first row %q", lines[1])
+	}
+	if strings.Contains(lines[2], "×") {
+		t.Errorf("expected the group to be shown only on its first row, got %q", lines[2])
+	}
+}
@@ -1,6 +1,8 @@
 package filebtns
 
 import (
+	"strconv"
+
 	"github.com/Philistino/fman/ui/message"
 	"github.com/Philistino/fman/ui/theme"
 	tea "github.com/charmbracelet/bubbletea"
@@ -27,15 +29,23 @@ type FileBtns struct {
 	fileSelected  bool
 	clipBoardFull bool
 	focused       bool
+	commands      []CommandBtn
 	// selectedIsArchive bool
 }
 
-func NewFileBtns() FileBtns {
+// CommandBtn is a button that runs the user command with the index Index
+type CommandBtn struct {
+	Name  string
+	Index int
+}
+
+func NewFileBtns(commands []CommandBtn) FileBtns {
 	return FileBtns{
 		zPrefix:       zone.NewPrefix(),
 		fileSelected:  false,
 		clipBoardFull: false,
 		focused:       true,
+		commands:      commands,
 	}
 }
 
@@ -79,6 +89,13 @@ func (m FileBtns) Update(msg tea.Msg) (FileBtns, tea.Cmd) {
 			cmd = message.DeleteCmd()
 			// case zone.Get(m.id + "compress").InBounds(msg):
 			// case zone.Get(m.id + "extract").InBounds(msg):
+		default:
+			for i, c := range m.commands {
+				if zone.Get(m.commandZone(i)).InBounds(msg) {
+					cmd = message.RunCommandCmd(c.Index)
+					break
+				}
+			}
 		}
 	}
 	return m, cmd
@@ -142,6 +159,7 @@ func (m FileBtns) View() string {
 				zone.Mark(m.zPrefix+"delete", delete),
 			),
 		),
+		m.commandsView(sectionWrapper),
 		// sectionWrapper.Copy().BorderLeft(false).BorderRight(false).PaddingRight(0).Render(
 		// 	lipgloss.JoinHorizontal(
 		// 		lipgloss.Top,
@@ -153,6 +171,24 @@ func (m FileBtns) View() string {
 	return lipgloss.JoinHorizontal(lipgloss.Left, buttons)
 }
 
+// commandsView returns the buttons of the user commands
+func (m FileBtns) commandsView(sectionWrapper lipgloss.Style) string {
+	if len(m.commands) == 0 {
+		return ""
+	}
+	buttons := make([]string, 0, len(m.commands))
+	for i, c := range m.commands {
+		buttons = append(buttons, zone.Mark(m.commandZone(i), theme.ButtonStyle.Render(c.Name)))
+	}
+	return sectionWrapper.Copy().BorderLeft(false).Render(
+		lipgloss.JoinHorizontal(lipgloss.Top, buttons...),
+	)
+}
+
+func (m FileBtns) commandZone(i int) string {
+	return m.zPrefix + "command" + strconv.Itoa(i)
+}
+
 func (m *FileBtns) Blur() {
 	m.focused = false
 }
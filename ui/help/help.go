@@ -17,6 +17,7 @@ type Help struct {
 	focus.FocusField
 	theme    colors.Theme
 	keys     keys.KeyMap
+	extra    [][]key.Binding // groups shown after the key bindings of fman
 	viewport viewport.Model
 }
 
@@ -71,7 +72,7 @@ func (h *Help) setViewPortContent() {
 // ViewHelp returns the Help view with the key bindings and descriptions.
 func (h Help) createView() string {
 
-	groups := h.keys.FullHelp()
+	groups := append(h.keys.FullHelp(), h.extra...)
 
 	// Create a slice of text boxes, one for each group of key bindings
 	boxes := make([]string, 0, len(groups))
@@ -160,6 +161,13 @@ func arrangeGroups(boxes []string, width int) string {
 	}
 }
 
+// AddGroup shows the key bindings after the key bindings of fman, such as the keys of the
+// user commands
+func (h *Help) AddGroup(group []key.Binding) {
+	h.extra = append(h.extra, group)
+	h.setViewPortContent()
+}
+
 // SetSize sets the height and width of the Help view and updates the viewport.
 func (h *Help) SetSize(height, width int) {
 	h.viewport.Height = height
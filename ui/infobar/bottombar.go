@@ -13,6 +13,7 @@ type Infobar struct {
 	notis     notifications
 	logo      string
 	selected  itemTracker
+	free      freeSpace
 	logoWidth int
 }
 
@@ -43,23 +44,33 @@ func (m Infobar) Update(msg tea.Msg) (Infobar, tea.Cmd) {
 		m.prompt.width = m.width - m.logoWidth
 		m.prompt.textInput.Width = m.prompt.width - 4 // 4 is the width of the prompt prefix and cursor
 		m.prompt.textInput.CharLimit = m.prompt.textInput.Width
+		m.prompt.textArea.SetWidth(m.prompt.width - 1) // 1 is the width of the margin
 	}
-	var promptCmd, notiCmd, itemCmd tea.Cmd
+	var promptCmd, notiCmd, itemCmd, freeCmd tea.Cmd
 	m.prompt, promptCmd = m.prompt.Update(msg)
 	m.notis, notiCmd = m.notis.Update(msg)
 	m.selected, itemCmd = m.selected.Update(msg)
-	return m, tea.Batch(promptCmd, notiCmd, itemCmd)
+	m.free, freeCmd = m.free.Update(msg)
+	return m, tea.Batch(promptCmd, notiCmd, itemCmd, freeCmd)
+}
+
+// PromptFocused reports whether the prompt is waiting for an answer
+func (m Infobar) PromptFocused() bool {
+	return m.prompt.Focused()
 }
 
 func (m Infobar) View() string {
 	style := theme.InfobarStyle.Copy()
 	var mainContent string
 	switch {
+	case m.prompt.textArea.Focused():
+		mainContent = style.Width(m.width - m.logoWidth).PaddingLeft(1).Render(m.prompt.View())
+		return lipgloss.JoinHorizontal(lipgloss.Top, m.logo, mainContent)
 	case m.prompt.textInput.Focused():
 		mainContent = style.Width(m.width - m.logoWidth).Render(" " + m.prompt.View())
 	default:
 		noti := " " + m.notis.View()
-		selected := style.Render(m.selected.View())
+		selected := m.free.View() + style.Render(m.selected.View())
 		width := m.width - m.logoWidth - lipgloss.Width(selected)
 		noti = runewidth.Truncate(noti, width, "...")
 		mainContent = style.Width(width).Render(noti) + selected
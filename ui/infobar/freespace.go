@@ -0,0 +1,60 @@
+package infobar
+
+import (
+	"fmt"
+
+	"github.com/Philistino/fman/entry/storage"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+)
+
+// freeSpaceMsg carries the free space of the filesystem of a directory
+type freeSpaceMsg struct {
+	path string
+	info storage.StorageInfo
+	err  error
+}
+
+func freeSpaceCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		info, err := storage.GetStorageInfo(path)
+		return freeSpaceMsg{path: path, info: info, err: err}
+	}
+}
+
+// freeSpace shows the space available on the filesystem of the current directory
+type freeSpace struct {
+	path  string // the current directory
+	known bool
+	free  uint64
+	total uint64
+}
+
+func (m freeSpace) Update(msg tea.Msg) (freeSpace, tea.Cmd) {
+	switch msg := msg.(type) {
+	case message.DirChangedMsg:
+		if msg.Path() == "" {
+			return m, nil
+		}
+		m.path = msg.Path()
+		return m, freeSpaceCmd(m.path)
+	case freeSpaceMsg:
+		if msg.path != m.path {
+			return m, nil // the directory changed again before the space was read
+		}
+		m.known = msg.err == nil
+		m.free = msg.info.AvailableSpace
+		m.total = msg.info.TotalSpace
+	}
+	return m, nil
+}
+
+func (m freeSpace) View() string {
+	if !m.known {
+		return ""
+	}
+	style := theme.InfobarStyle.Copy().UnsetWidth().PaddingLeft(1)
+	return style.Render(fmt.Sprintf("%s free of %s │", humanize.Bytes(m.free), humanize.Bytes(m.total)))
+}
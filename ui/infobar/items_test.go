@@ -1,6 +1,10 @@
 package infobar
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/Philistino/fman/entry/storage"
+)
 
 func TestView(t *testing.T) {
 	t.Run("should return the correct view", func(t *testing.T) {
@@ -16,3 +20,19 @@ func TestView(t *testing.T) {
 		}
 	})
 }
+
+func TestFreeSpaceView(t *testing.T) {
+	m := freeSpace{path: "/home"}
+	if m.View() != "" {
+		t.Errorf("expected no view before the space is known, got %q", m.View())
+	}
+	m, _ = m.Update(freeSpaceMsg{path: "/other", info: storage.StorageInfo{AvailableSpace: 1}})
+	if m.known {
+		t.Error("expected the space of another directory to be ignored")
+	}
+	m, _ = m.Update(freeSpaceMsg{path: "/home", info: storage.StorageInfo{AvailableSpace: 2_000_000_000, TotalSpace: 10_000_000_000}})
+	expected := " 2.0 GB free of 10 GB │"
+	if actual := m.View(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Philistino/fman/ui/theme"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -13,6 +14,7 @@ type PromptAskMsg struct {
 	ID          string
 	Placeholder string
 	Validator   func(string) error
+	Lines       int // rows of the editor if the answer can span several lines
 }
 
 func PromptAskCmd(id string, placeholder string, validator func(string) error) tea.Cmd {
@@ -21,6 +23,15 @@ func PromptAskCmd(id string, placeholder string, validator func(string) error) t
 	}
 }
 
+// PromptAskMultilineCmd asks for an answer that can span several lines, such as a commit
+// message, in an editor with the given number of rows. Enter starts a new line and ctrl+s
+// or alt+enter sends the answer.
+func PromptAskMultilineCmd(id string, placeholder string, lines int, validator func(string) error) tea.Cmd {
+	return func() tea.Msg {
+		return PromptAskMsg{ID: id, Placeholder: placeholder, Validator: validator, Lines: lines}
+	}
+}
+
 // PromptAnswerMsg is sent when the user answers the prompt
 type PromptAnswerMsg struct {
 	ID        string
@@ -43,6 +54,8 @@ func PromptAnswerCmd(id string, value string, cancelled bool) tea.Cmd {
 type prompt struct {
 	width     int
 	textInput textinput.Model
+	textArea  textarea.Model // editor of answers that span several lines
+	errMsg    string         // why the answer in the editor was refused
 	askMsg    PromptAskMsg
 }
 
@@ -58,11 +71,26 @@ func newPrompt() prompt {
 	ti.TextStyle = theme.InfobarStyle.Copy()
 	ti.PlaceholderStyle = theme.InfobarStyle.Copy()
 	ti.Cursor.Style = theme.SelectedItemStyle.Copy().Foreground(theme.GetActiveTheme("dracula").SelectedItemBgColor).Background(theme.GetActiveTheme("dracula").SelectedItemFgColor)
+	ta := textarea.New()
+	ta.Prompt = ""
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.FocusedStyle.Base = theme.InfobarStyle.Copy()
+	ta.FocusedStyle.CursorLine = theme.InfobarStyle.Copy()
+	ta.FocusedStyle.Text = theme.InfobarStyle.Copy()
+	ta.FocusedStyle.EndOfBuffer = theme.InfobarStyle.Copy()
+	ta.FocusedStyle.Placeholder = theme.InfobarStyle.Copy().Faint(true)
 	return prompt{
 		textInput: ti,
+		textArea:  ta,
 	}
 }
 
+// Focused reports whether the prompt is waiting for an answer
+func (m prompt) Focused() bool {
+	return m.textInput.Focused() || m.textArea.Focused()
+}
+
 type resetPlaceholderMsg struct{}
 
 func resetPlaceholderCmd() tea.Cmd {
@@ -78,6 +106,13 @@ func (m prompt) Update(msg tea.Msg) (prompt, tea.Cmd) {
 	case PromptAskMsg:
 		m.textInput.Reset()
 		m.askMsg = msg
+		if msg.Lines > 1 {
+			m.errMsg = ""
+			m.textArea.Reset()
+			m.textArea.SetHeight(msg.Lines)
+			m.textArea.Placeholder = msg.Placeholder
+			return m, m.textArea.Focus()
+		}
 		m.textInput.Placeholder = msg.Placeholder + " (press ESC to cancel)"
 		m.textInput.Focus()
 	case PromptAnswerMsg:
@@ -85,6 +120,9 @@ func (m prompt) Update(msg tea.Msg) (prompt, tea.Cmd) {
 	case resetPlaceholderMsg:
 		m.textInput.Placeholder = m.askMsg.Placeholder + " (press ESC to cancel)"
 	case tea.KeyMsg:
+		if m.textArea.Focused() {
+			return m.handleMultilineKey(msg)
+		}
 		switch msg.Type {
 		case tea.KeyEnter:
 			if m.askMsg.Validator != nil {
@@ -106,10 +144,48 @@ func (m prompt) Update(msg tea.Msg) (prompt, tea.Cmd) {
 			return m, cmd
 		}
 	}
+	if m.textArea.Focused() {
+		m.textArea, cmd = m.textArea.Update(msg)
+		return m, cmd
+	}
 	m.textInput, cmd = m.textInput.Update(msg)
 	return m, cmd
 }
 
+// handleMultilineKey handles the keys of the editor. A refused answer is kept so it
+// can be corrected.
+func (m prompt) handleMultilineKey(msg tea.KeyMsg) (prompt, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.textArea.Blur()
+		cmd := PromptAnswerCmd(m.askMsg.ID, m.textArea.Value(), true)
+		m.textArea.Reset()
+		return m, cmd
+	case msg.Type == tea.KeyCtrlS, msg.Type == tea.KeyEnter && msg.Alt:
+		if m.askMsg.Validator != nil {
+			if err := m.askMsg.Validator(m.textArea.Value()); err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+		}
+		m.textArea.Blur()
+		cmd := PromptAnswerCmd(m.askMsg.ID, m.textArea.Value(), false)
+		m.textArea.Reset()
+		return m, cmd
+	}
+	m.errMsg = ""
+	var cmd tea.Cmd
+	m.textArea, cmd = m.textArea.Update(msg)
+	return m, cmd
+}
+
 func (m prompt) View() string {
+	if m.textArea.Focused() {
+		title := m.askMsg.Placeholder + " (ctrl+s to send, ESC to cancel)"
+		if m.errMsg != "" {
+			title = m.errMsg
+		}
+		return title + "\n" + m.textArea.View()
+	}
 	return m.textInput.View()
 }
@@ -0,0 +1,86 @@
+package infobar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// answer returns the answer sent by the command, if it sends one
+func answer(cmd tea.Cmd) (PromptAnswerMsg, bool) {
+	if cmd == nil {
+		return PromptAnswerMsg{}, false
+	}
+	msg, ok := cmd().(PromptAnswerMsg)
+	return msg, ok
+}
+
+func typeString(m prompt, s string) prompt {
+	for _, r := range s {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestPromptMultiline(t *testing.T) {
+	m := newPrompt()
+	m.textArea.SetWidth(40)
+	validator := func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return errors.New("empty")
+		}
+		return nil
+	}
+	m, _ = m.Update(PromptAskMultilineCmd("commit", "Commit message", 4, validator)())
+	if !m.Focused() || m.textInput.Focused() {
+		t.Fatal("the editor should be focused")
+	}
+
+	var cmd tea.Cmd
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if _, ok := answer(cmd); ok || !m.Focused() {
+		t.Fatal("an empty message should be refused")
+	}
+	if !strings.Contains(m.View(), "empty") {
+		t.Errorf("the reason is not shown in %q", m.View())
+	}
+
+	m = typeString(m, "Subject")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Focused() {
+		t.Fatal("enter should start a new line")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = typeString(m, "Body?")
+	if strings.Contains(m.View(), "empty") {
+		t.Error("the reason is still shown after typing")
+	}
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	got, ok := answer(cmd)
+	if !ok || got.Cancelled || got.ID != "commit" || got.Message != "Subject\n\nBody?" {
+		t.Errorf("got %+v, want the message with its lines", got)
+	}
+	if m.Focused() {
+		t.Error("the prompt is still focused after the answer")
+	}
+}
+
+func TestPromptMultilineCancel(t *testing.T) {
+	m := newPrompt()
+	m, _ = m.Update(PromptAskMultilineCmd("commit", "Commit message", 4, nil)())
+	m = typeString(m, "draft")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if got, ok := answer(cmd); !ok || !got.Cancelled {
+		t.Errorf("got %+v, want a cancelled answer", got)
+	}
+	if m.Focused() {
+		t.Error("the prompt is still focused after cancelling")
+	}
+	m, _ = m.Update(PromptAskMultilineCmd("commit", "Commit message", 4, nil)())
+	if m.textArea.Value() != "" {
+		t.Errorf("the editor starts with %q, want it empty", m.textArea.Value())
+	}
+}
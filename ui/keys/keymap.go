@@ -13,6 +13,24 @@ type KeyMap struct {
 	ToggleHelp        key.Binding
 	ShowHiddenEntries key.Binding
 	OpenFile          key.Binding
+	OpenWith          key.Binding
+	BulkRename        key.Binding
+	BatchRename       key.Binding
+	UndoRename        key.Binding
+	ChangePermissions key.Binding
+	InternalCopy      key.Binding
+	PasteLinks        key.Binding
+	ShellPrompt       key.Binding
+	Subshell          key.Binding
+	CancelCommand     key.Binding
+
+	GitStage     key.Binding
+	GitUnstage   key.Binding
+	GitRestore   key.Binding
+	GitCommit    key.Binding
+	GitPreview   key.Binding
+	NextRevision key.Binding
+	PrevRevision key.Binding
 
 	MoveCursorUp       key.Binding
 	MoveCursorDown     key.Binding
@@ -33,8 +51,47 @@ type KeyMap struct {
 
 	ScrollPreviewDown key.Binding
 	ScrollPreviewUp   key.Binding
+	FollowFile        key.Binding
+
+	Compare          key.Binding
+	NextHunk         key.Binding
+	PrevHunk         key.Binding
+	ToggleDiffLayout key.Binding
 
 	CopyToClipboard key.Binding
+	CalcDirSizes    key.Binding
+
+	OpenUsage   key.Binding
+	UsageDelete key.Binding
+	UsageExport key.Binding
+	UsageImport key.Binding
+	UsageRescan key.Binding
+
+	OpenDevices    key.Binding
+	DevicesRefresh key.Binding
+
+	FindDupes         key.Binding
+	DupesSelectCopies key.Binding
+	DupesTrash        key.Binding
+	DupesLink         key.Binding
+	DupesRescan       key.Binding
+
+	CycleSort         key.Binding
+	ReverseSort       key.Binding
+	ToggleDirsFirst   key.Binding
+	ToggleSortCase    key.Binding
+	ToggleSortAccents key.Binding
+
+	OpenPager         key.Binding
+	ClosePager        key.Binding
+	PageUp            key.Binding
+	PageDown          key.Binding
+	GoToLine          key.Binding
+	Search            key.Binding
+	NextMatch         key.Binding
+	PrevMatch         key.Binding
+	ToggleLineNumbers key.Binding
+	ToggleWrap        key.Binding
 
 	width  int
 	height int
@@ -89,6 +146,10 @@ var Map = KeyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "Open file"),
 	),
+	OpenWith: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "Open with..."),
+	),
 	ShowHiddenEntries: key.NewBinding(
 		key.WithKeys("."),
 		key.WithHelp(".", "Toggle show hidden"),
@@ -101,6 +162,50 @@ var Map = KeyMap{
 		key.WithKeys("shift+up"),
 		key.WithHelp("shift+↑", "Scroll preview up"),
 	),
+	FollowFile: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "Follow end of file"),
+	),
+	OpenPager: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "View file in pager"),
+	),
+	ClosePager: key.NewBinding(
+		key.WithKeys("q", "esc"),
+		key.WithHelp("q", "Close pager"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup", "b"),
+		key.WithHelp("pgup", "Page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown", " "),
+		key.WithHelp("pgdown", "Page down"),
+	),
+	GoToLine: key.NewBinding(
+		key.WithKeys(":"),
+		key.WithHelp(":", "Go to line"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "Search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "Next match"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "Previous match"),
+	),
+	ToggleLineNumbers: key.NewBinding(
+		key.WithKeys("#"),
+		key.WithHelp("#", "Toggle line numbers"),
+	),
+	ToggleWrap: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "Toggle line wrap"),
+	),
 	ToggleHelp: key.NewBinding(
 		key.WithKeys("?"),
 		key.WithHelp("?", "Toggle help"),
@@ -125,6 +230,158 @@ var Map = KeyMap{
 		key.WithKeys("ctrl+a"),
 		key.WithHelp("ctrl+a", "Select all"),
 	),
+	Compare: key.NewBinding(
+		key.WithKeys("="),
+		key.WithHelp("=", "Compare two selected entries"),
+	),
+	NextHunk: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "Next diff hunk"),
+	),
+	PrevHunk: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "Previous diff hunk"),
+	),
+	ToggleDiffLayout: key.NewBinding(
+		key.WithKeys("|"),
+		key.WithHelp("|", "Toggle side-by-side diff"),
+	),
+	BulkRename: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "Rename selected in $EDITOR"),
+	),
+	BatchRename: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "Rename selected with a pattern"),
+	),
+	UndoRename: key.NewBinding(
+		key.WithKeys("ctrl+z"),
+		key.WithHelp("ctrl+z", "Undo the last rename"),
+	),
+	ChangePermissions: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "Change permissions and owner"),
+	),
+	InternalCopy: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "Copy selected for pasting"),
+	),
+	PasteLinks: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "Paste as symlinks or hard links"),
+	),
+	GitStage: key.NewBinding(
+		key.WithKeys("+"),
+		key.WithHelp("+", "Git stage selected"),
+	),
+	GitUnstage: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "Git unstage selected"),
+	),
+	GitRestore: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "Git discard changes to selected"),
+	),
+	GitCommit: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "Git commit staged changes"),
+	),
+	GitPreview: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "Cycle preview: contents, git log, git diff"),
+	),
+	NextRevision: key.NewBinding(
+		key.WithKeys("}"),
+		key.WithHelp("}", "Next commit of the git log"),
+	),
+	PrevRevision: key.NewBinding(
+		key.WithKeys("{"),
+		key.WithHelp("{", "Previous commit of the git log"),
+	),
+	CalcDirSizes: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "Calculate directory sizes"),
+	),
+	OpenUsage: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "Disk usage of the current dir"),
+	),
+	UsageDelete: key.NewBinding(
+		key.WithKeys("d", "delete"),
+		key.WithHelp("d", "Delete in disk usage view"),
+	),
+	UsageExport: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "Export disk usage scan"),
+	),
+	UsageImport: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "Import disk usage scan"),
+	),
+	UsageRescan: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "Rescan disk usage"),
+	),
+	OpenDevices: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "Mounted devices"),
+	),
+	DevicesRefresh: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "Refresh devices"),
+	),
+	FindDupes: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "Find duplicate files"),
+	),
+	DupesSelectCopies: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "Select all but one copy"),
+	),
+	DupesTrash: key.NewBinding(
+		key.WithKeys("d", "delete"),
+		key.WithHelp("d", "Trash selected duplicates"),
+	),
+	DupesLink: key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "Hard link selected duplicates"),
+	),
+	DupesRescan: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "Search for duplicates again"),
+	),
+	CycleSort: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "Cycle sort method"),
+	),
+	ShellPrompt: key.NewBinding(
+		key.WithKeys(":", "!"),
+		key.WithHelp(":", "Run a shell command"),
+	),
+	Subshell: key.NewBinding(
+		key.WithKeys("$"),
+		key.WithHelp("$", "Open a shell in the current dir"),
+	),
+	CancelCommand: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "Cancel background commands"),
+	),
+	ReverseSort: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "Reverse sort"),
+	),
+	ToggleDirsFirst: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "Toggle directories first"),
+	),
+	ToggleSortCase: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "Toggle case sensitive sort"),
+	),
+	ToggleSortAccents: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "Toggle ignoring accents in sort"),
+	),
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
@@ -133,11 +390,36 @@ func (k KeyMap) ShortHelp() []key.Binding {
 
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Quit, k.ToggleHelp, k.ShowHiddenEntries, k.OpenFile},
+		{k.Quit, k.ToggleHelp, k.ShowHiddenEntries, k.OpenFile, k.OpenWith, k.BulkRename, k.BatchRename, k.UndoRename, k.ChangePermissions, k.InternalCopy, k.PasteLinks, k.CalcDirSizes, k.ShellPrompt, k.Subshell, k.CancelCommand},
 		{k.MoveCursorUp, k.MoveCursorDown, k.MoveCursorToTop, k.MoveCursorToBottom},
 		{k.GoToParentDirectory, k.GoToSelectedDirectory, k.GoToHomeDirectory, k.GoBack, k.GoForward},
 		{k.MultiSelectAll, k.MultiSelectUp, k.MultiSelectDown, k.MultiSelectToTop, k.MultiSelectToBottom},
-		{k.ScrollPreviewUp, k.ScrollPreviewDown},
+		{k.ScrollPreviewUp, k.ScrollPreviewDown, k.FollowFile},
+		{k.Compare, k.NextHunk, k.PrevHunk, k.ToggleDiffLayout},
+		{k.GitStage, k.GitUnstage, k.GitRestore, k.GitCommit},
+		{k.GitPreview, k.NextRevision, k.PrevRevision},
+		{k.CycleSort, k.ReverseSort, k.ToggleDirsFirst, k.ToggleSortCase, k.ToggleSortAccents},
+		{k.OpenUsage, k.UsageDelete, k.UsageExport, k.UsageImport, k.UsageRescan},
+		{k.OpenDevices, k.DevicesRefresh},
+		{k.FindDupes, k.DupesSelectCopies, k.DupesTrash, k.DupesLink, k.DupesRescan},
+		{k.OpenPager, k.ClosePager, k.PageUp, k.PageDown, k.GoToLine, k.Search, k.NextMatch, k.PrevMatch, k.ToggleLineNumbers, k.ToggleWrap},
+	}
+}
+
+// ListBindings returns the bindings that work while the file list is shown. The other
+// bindings of FullHelp only work in a full screen view, such as the pager.
+func (k KeyMap) ListBindings() []key.Binding {
+	return []key.Binding{
+		k.Quit, k.ToggleHelp, k.ShowHiddenEntries, k.OpenFile, k.OpenWith, k.BulkRename, k.BatchRename, k.UndoRename, k.ChangePermissions, k.InternalCopy, k.PasteLinks, k.CalcDirSizes, k.ShellPrompt, k.Subshell, k.CancelCommand,
+		k.MoveCursorUp, k.MoveCursorDown, k.MoveCursorToTop, k.MoveCursorToBottom,
+		k.GoToParentDirectory, k.GoToSelectedDirectory, k.GoToHomeDirectory, k.GoBack, k.GoForward,
+		k.MultiSelectAll, k.MultiSelectUp, k.MultiSelectDown, k.MultiSelectToTop, k.MultiSelectToBottom,
+		k.ScrollPreviewUp, k.ScrollPreviewDown, k.FollowFile,
+		k.Compare, k.NextHunk, k.PrevHunk, k.ToggleDiffLayout,
+		k.GitStage, k.GitUnstage, k.GitRestore, k.GitCommit,
+		k.GitPreview, k.NextRevision, k.PrevRevision,
+		k.CycleSort, k.ReverseSort, k.ToggleDirsFirst, k.ToggleSortCase, k.ToggleSortAccents,
+		k.OpenUsage, k.OpenDevices, k.FindDupes, k.OpenPager,
 	}
 }
 
@@ -236,11 +518,19 @@ func (k KeyMap) renderGroup(group []key.Binding) string {
 func (k KeyMap) ViewHelp(colors colors.Theme) string {
 
 	groups := [][]key.Binding{
-		{k.Quit, k.ToggleHelp, k.ShowHiddenEntries, k.OpenFile},
+		{k.Quit, k.ToggleHelp, k.ShowHiddenEntries, k.OpenFile, k.OpenWith, k.BulkRename, k.BatchRename, k.UndoRename, k.ChangePermissions, k.InternalCopy, k.PasteLinks, k.CalcDirSizes},
 		{k.MoveCursorUp, k.MoveCursorDown, k.MoveCursorToTop, k.MoveCursorToBottom},
 		{k.GoToParentDirectory, k.GoToSelectedDirectory, k.GoToHomeDirectory, k.GoBack, k.GoForward},
 		{k.MultiSelectAll, k.MultiSelectUp, k.MultiSelectDown, k.MultiSelectToTop, k.MultiSelectToBottom},
-		{k.ScrollPreviewUp, k.ScrollPreviewDown},
+		{k.ScrollPreviewUp, k.ScrollPreviewDown, k.FollowFile},
+		{k.Compare, k.NextHunk, k.PrevHunk, k.ToggleDiffLayout},
+		{k.GitStage, k.GitUnstage, k.GitRestore, k.GitCommit},
+		{k.GitPreview, k.NextRevision, k.PrevRevision},
+		{k.CycleSort, k.ReverseSort, k.ToggleDirsFirst, k.ToggleSortCase, k.ToggleSortAccents},
+		{k.OpenUsage, k.UsageDelete, k.UsageExport, k.UsageImport, k.UsageRescan},
+		{k.OpenDevices, k.DevicesRefresh},
+		{k.FindDupes, k.DupesSelectCopies, k.DupesTrash, k.DupesLink, k.DupesRescan},
+		{k.OpenPager, k.ClosePager, k.PageUp, k.PageDown, k.GoToLine, k.Search, k.NextMatch, k.PrevMatch, k.ToggleLineNumbers, k.ToggleWrap},
 	}
 
 	// Create a slice of text boxes, one for each chunk
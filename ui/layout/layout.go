@@ -0,0 +1,21 @@
+// Package layout holds helpers shared by the views to lay out their content.
+package layout
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FitWidth truncates or pads the possibly styled string s to exactly width cells
+func FitWidth(s string, width int) string {
+	if width <= 0 {
+		// a MaxWidth of 0 does not limit the width
+		return ""
+	}
+	s = lipgloss.NewStyle().Inline(true).MaxWidth(width).Render(s)
+	if w := lipgloss.Width(s); w < width {
+		s += strings.Repeat(" ", width-w)
+	}
+	return s
+}
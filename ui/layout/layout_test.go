@@ -0,0 +1,30 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFitWidth(t *testing.T) {
+	testcases := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 3, "abc"},
+		{"abc", 0, ""},
+		{"abc", -1, ""},
+		{"日本語", 4, "日本"},
+	}
+	for _, tc := range testcases {
+		if got := FitWidth(tc.in, tc.width); got != tc.want {
+			t.Errorf("FitWidth(%q, %d) = %q; want %q", tc.in, tc.width, got, tc.want)
+		}
+	}
+	styled := lipgloss.NewStyle().Bold(true).Render("abcdef")
+	if got := lipgloss.Width(FitWidth(styled, 4)); got != 4 {
+		t.Errorf("got a styled string of width %d; want 4", got)
+	}
+}
@@ -1,16 +1,31 @@
 package list
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/76creates/stickers"
 	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/git"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/theme"
 	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
 )
 
 type List struct {
 	entries []entry.Entry
+	path    string         // path of the directory of the entries
+	sort    entry.SortSpec // sort of the entries, shown in the header
+	columns []entry.Column // columns shown for each entry
+
+	sizing  map[string]struct{} // names of the directories whose sizes are being calculated
+	spinner spinner.Model
+
+	git *git.Repo // git status of the entries, nil outside of a git work tree
 
 	width  int
 	height int
@@ -22,19 +37,29 @@ type List struct {
 	table   Table
 
 	maxEntryToShow int
-	truncateLimit  int
 
 	lastClickedTime time.Time
 	lastClickedIdx  int // list index of the last clicked item. Must be reset to -1 when the list is updated
 	clickDelay      time.Duration
 
-	theme colors.Theme
+	theme  colors.Theme
+	styles theme.StyleSet
+	keys   keys.KeyMap
+
+	zones  *zone.Manager
+	zoneID string // id of the zone of the list, which is marked with Mark
 
 	lastKeyCharacter byte
 	focused          bool
 }
 
-func New(theme colors.Theme, doubleClickDelay int) List {
+// New creates a list showing the given columns. The default columns
+// are shown if columns is empty. The clicks on the list are located with the zone
+// manager.
+func New(scheme colors.Theme, styles theme.StyleSet, keyMap keys.KeyMap, zones *zone.Manager, doubleClickDelay int, columns []entry.Column) List {
+	if len(columns) == 0 {
+		columns = entry.DefaultColumns()
+	}
 
 	list := List{
 		entries:          []entry.Entry{},
@@ -44,24 +69,28 @@ func New(theme colors.Theme, doubleClickDelay int) List {
 		selected:         map[int]struct{}{},
 		flexBox:          stickers.NewFlexBox(0, 0),
 		maxEntryToShow:   0,
-		truncateLimit:    100,
 		lastClickedTime:  time.Time{},
 		lastClickedIdx:   -1,
 		clickDelay:       time.Duration(time.Millisecond * time.Duration(doubleClickDelay)),
-		theme:            theme,
+		theme:            scheme,
+		styles:           styles,
+		keys:             keyMap,
+		zones:            zones,
+		zoneID:           zones.NewPrefix() + "list",
 		lastKeyCharacter: 0,
 		focused:          true,
 		table:            NewTable(),
+		columns:          columns,
+		sizing:           map[string]struct{}{},
+		spinner:          spinner.New(spinner.WithSpinner(spinner.MiniDot)),
 	}
 
+	cells := make([]*stickers.FlexBoxCell, len(columns))
+	for i, column := range columns {
+		cells[i] = stickers.NewFlexBoxCell(column.Width, 1)
+	}
 	rows := []*stickers.FlexBoxRow{
-		list.flexBox.NewRow().AddCells(
-			[]*stickers.FlexBoxCell{
-				stickers.NewFlexBoxCell(5, 1),
-				stickers.NewFlexBoxCell(2, 1),
-				stickers.NewFlexBoxCell(3, 1),
-			},
-		),
+		list.flexBox.NewRow().AddCells(cells),
 	}
 
 	list.flexBox.AddRows(rows)
@@ -69,6 +98,11 @@ func New(theme colors.Theme, doubleClickDelay int) List {
 	return list
 }
 
+// Mark marks the area of the list in a view, so clicks on it are handled by the list
+func (list *List) Mark(view string) string {
+	return list.zones.Mark(list.zoneID, view)
+}
+
 func (list *List) Init() tea.Cmd {
 	return nil
 }
@@ -89,20 +123,65 @@ func (list *List) SelectedEntryName() string {
 	return list.entries[list.table.Cursor()].Name()
 }
 
-// TODO: Change this when reimplementing the list
+// SelectedEntries returns the names of the selected entries
 func (list *List) SelectedEntries() map[string]struct{} {
 	if len(list.entries) == 0 {
 		return nil
 	}
-	return map[string]struct{}{
-		list.SelectedEntry().Name(): {},
+	selected := make(map[string]struct{}, len(list.table.selected))
+	for _, idx := range list.table.SelectedRows() {
+		if idx < len(list.entries) {
+			selected[list.entries[idx].Name()] = struct{}{}
+		}
+	}
+	if len(selected) == 0 {
+		selected[list.SelectedEntry().Name()] = struct{}{}
+	}
+	return selected
+}
+
+// Select selects the entries with the given names and moves the cursor to the first of
+// them. Nothing is selected if one of the names is not in the list.
+func (list *List) Select(names []string) error {
+	idx := make(map[string]int, len(list.entries))
+	for i, e := range list.entries {
+		idx[e.Name()] = i
+	}
+	var rows []int
+	var missing []string
+	for _, name := range names {
+		i, ok := idx[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		rows = append(rows, i)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no entries named %s", strings.Join(missing, ", "))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	list.selected = make(map[int]struct{}, len(rows))
+	list.table.ClearSelected()
+	for _, i := range rows {
+		list.selected[i] = struct{}{}
+		list.table.selected[i] = struct{}{}
 	}
+	list.table.SetCursor(rows[0])
+	return nil
 }
 
 func (list *List) CursorName() string {
 	return list.SelectedEntryName()
 }
 
+// Entries returns the entries in the list
+func (list *List) Entries() []entry.Entry {
+	return list.entries
+}
+
 // EntryNames returns a slice of the names of the entries in the list
 func (list *List) EntryNames() []string {
 	names := make([]string, len(list.entries))
@@ -112,6 +191,14 @@ func (list *List) EntryNames() []string {
 	return names
 }
 
+// GitStatus returns the git status of the entry with the given name
+func (list *List) GitStatus(name string) git.Status {
+	if list.git == nil {
+		return 0
+	}
+	return list.git.Entries[name]
+}
+
 func (list *List) SetWidth(width int) {
 	list.width = width
 	list.flexBox.SetWidth(width)
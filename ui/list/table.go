@@ -89,7 +89,7 @@ func (m *Table) setHeight(grow int) {
 func (m *Table) SetSelected(idxs []int) error {
 	m.ClearSelected()
 	for _, idx := range idxs {
-		if idx < 0 || idx >= m.nRows-1 {
+		if idx < 0 || idx >= m.nRows {
 			return fmt.Errorf("index out of bounds: %d", idx)
 		}
 		m.selected[idx] = struct{}{}
@@ -97,6 +97,11 @@ func (m *Table) SetSelected(idxs []int) error {
 	return nil
 }
 
+// Viewport returns the indexes of the first and last rows that are displayed.
+func (m Table) Viewport() (start int, end int) {
+	return m.start, m.end
+}
+
 // Height returns the viewport height of the table.
 func (m Table) Height() int {
 	return m.height
@@ -214,6 +219,19 @@ func (m *Table) MultiSelectToBottom() {
 	m.updateViewport()
 }
 
+// SelectAll selects every row without moving the cursor.
+func (m *Table) SelectAll() {
+	for i := 0; i < m.nRows; i++ {
+		m.selected[i] = struct{}{}
+	}
+}
+
+// IsSelected returns true if the row at the given index is selected.
+func (m Table) IsSelected(idx int) bool {
+	_, ok := m.selected[idx]
+	return ok
+}
+
 // GoToTop moves the cursor to the first row.
 func (m *Table) GoToTop() {
 	m.MoveUp(m.cursor, false)
@@ -1,13 +1,15 @@
 package list
 
 import (
+	"context"
+	"errors"
 	"time"
 
-	"github.com/Philistino/fman/ui/keys"
 	"github.com/Philistino/fman/ui/message"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
-	zone "github.com/lrstanley/bubblezone"
+	"github.com/dustin/go-humanize"
 )
 
 func (list *List) clearLastKey() tea.Cmd {
@@ -20,7 +22,21 @@ func (list *List) handlePathChange(newDir message.DirChangedMsg) tea.Cmd {
 	list.selected = make(map[int]struct{})
 	list.table.selected = make(map[int]struct{})
 
+	if newDir.Path() != list.path {
+		list.sizing = make(map[string]struct{})
+		list.git = nil
+	}
 	list.entries = newDir.Entries()
+	// directories that are still there are sized again, so only the others stop spinning
+	sizing := make(map[string]struct{}, len(list.sizing))
+	for _, e := range list.entries {
+		if _, ok := list.sizing[e.Name()]; ok {
+			sizing[e.Name()] = struct{}{}
+		}
+	}
+	list.sizing = sizing
+	list.path = newDir.Path()
+	list.sort = newDir.Sort()
 	selected := newDir.Selected()
 	matched := false
 	for i, entry := range list.entries {
@@ -51,11 +67,14 @@ func (list *List) handlePathChange(newDir message.DirChangedMsg) tea.Cmd {
 }
 
 func (list *List) handleMouseClick(msg tea.MouseMsg) tea.Cmd {
-	if msg.Type != tea.MouseLeft || !zone.Get("list").InBounds(msg) {
+	if msg.Type != tea.MouseLeft || !list.zones.Get(list.zoneID).InBounds(msg) {
 		return nil
 	}
-	x, y := zone.Get("list").Pos(msg)
+	x, y := list.zones.Get(list.zoneID).Pos(msg)
 	offset := 2
+	if y == offset-1 && x <= list.width {
+		return list.handleHeaderClick(x)
+	}
 	if (y < offset || y > len(list.entries)+offset-1) || x > list.width {
 		return nil
 	}
@@ -81,16 +100,89 @@ func (list *List) handleMouseClick(msg tea.MouseMsg) tea.Cmd {
 	return message.NewEntryCmd(list.SelectedEntry())
 }
 
+// handleHeaderClick sorts by the clicked column. Clicking the column
+// that is already sorted on reverses the sort.
+func (list *List) handleHeaderClick(x int) tea.Cmd {
+	right := 0
+	for i, column := range list.columns {
+		right += list.flexBox.Row(0).Cell(i).GetWidth()
+		if x < right {
+			return message.SetSortCmd(column.SortBy(list.sort))
+		}
+	}
+	return nil
+}
+
 func (list *List) resizeList() {
 	list.flexBox.SetWidth(list.width)
 	list.flexBox.SetHeight(list.height)
 	list.flexBox.ForceRecalculate()
-	list.truncateLimit = list.flexBox.Row(0).Cell(0).GetWidth() - 1
 	list.maxEntryToShow = list.height - 1 // 1 for the header
 	list.table.SetHeight(list.maxEntryToShow)
 }
 
+// handleDirSizesPending shows a spinner in the size column of the directories being sized
+func (list *List) handleDirSizesPending(msg message.DirSizesPendingMsg) tea.Cmd {
+	if msg.Path != list.path || len(msg.Names) == 0 {
+		return nil
+	}
+	spinning := len(list.sizing) > 0
+	for _, name := range msg.Names {
+		list.sizing[name] = struct{}{}
+	}
+	if spinning {
+		return nil
+	}
+	return list.spinner.Tick
+}
+
+// handleDirSize replaces the entry count of a directory with its total size
+func (list *List) handleDirSize(msg message.DirSizeMsg) {
+	if msg.Path != list.path || errors.Is(msg.Err, context.Canceled) {
+		return
+	}
+	delete(list.sizing, msg.Name)
+	if msg.Err != nil {
+		return
+	}
+	for i := range list.entries {
+		if list.entries[i].Name() == msg.Name {
+			list.entries[i].SizeStr = humanize.Bytes(uint64(msg.Size))
+			list.entries[i].SizeInt = msg.Size
+			return
+		}
+	}
+}
+
+// handleGitStatus sets the git status of the entries. The status of another directory is
+// ignored, it arrives late if the directory was left before git finished.
+func (list *List) handleGitStatus(msg message.GitStatusMsg) {
+	if msg.Path != list.path {
+		return
+	}
+	list.git = msg.Repo
+}
+
 func (list *List) Update(msg tea.Msg) (List, tea.Cmd) {
+	// directory sizes and git status are read in the background so they are handled even if the list is not focused
+	switch msg := msg.(type) {
+	case message.DirSizesPendingMsg:
+		return *list, list.handleDirSizesPending(msg)
+	case message.DirSizeMsg:
+		list.handleDirSize(msg)
+		return *list, nil
+	case message.GitStatusMsg:
+		list.handleGitStatus(msg)
+		return *list, nil
+	case spinner.TickMsg:
+		if len(list.sizing) == 0 {
+			return *list, nil
+		}
+		var cmd tea.Cmd
+		list.spinner, cmd = list.spinner.Update(msg)
+		return *list, cmd
+	}
+
 	if !list.focused {
 		return *list, nil
 	}
@@ -109,42 +201,91 @@ func (list *List) Update(msg tea.Msg) (List, tea.Cmd) {
 	case tea.KeyMsg:
 		switch {
 
-		// Move this elsewhere TODO!!!
-		// case key.Matches(msg, keys.Map.OpenFile): // Open file with default application
-		// 	path := getFullPath(list.SelectedEntry(), list.path)
-		// 	// If the file can be readable open the default editor for editing
-		// 	if !list.SelectedEntry().IsDir() && isFileReadable(path) {
-		// 		return *list, list.openEditor(path)
-		// 	}
-		// 	cmd := exec.Command(detectOpenCommand(), path)
-		// 	cmd.Start()
-		// 	return *list, nil
+		case key.Matches(msg, list.keys.OpenFile): // Open the selected files or enter the selected directory
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			if list.SelectedEntry().IsDir() {
+				if list.SelectedEntry().SizeStr == "Access Denied" {
+					return *list, message.NewNotificationCmd("Access Denied")
+				}
+				return *list, message.NavDownCmd(list.SelectedEntry().Name())
+			}
+			return *list, message.OpenFileCmd()
+		case key.Matches(msg, list.keys.OpenWith): // Choose a program to open the selected files
+			if len(list.entries) == 0 || list.SelectedEntry().IsDir() {
+				return *list, nil
+			}
+			return *list, message.OpenWithCmd()
 
 		// Move this elsewhere TODO!!!
-		// case key.Matches(msg, keys.Map.CopyToClipboard): // Copy path to the clipboard
+		// case key.Matches(msg, list.keys.CopyToClipboard): // Copy path to the clipboard
 		// 	path := getFullPath(list.SelectedEntry(), list.path)
 		// 	clipboard.WriteAll(path)
 		// 	return *list, message.SendMessage("Copied!")
 
-		case key.Matches(msg, keys.Map.MoveCursorToTop): // Move to the beginning of the list
+		case key.Matches(msg, list.keys.MoveCursorToTop): // Move to the beginning of the list
 			list.table.GoToTop()
-		case key.Matches(msg, keys.Map.MoveCursorToBottom): // Move to the end of the list
+		case key.Matches(msg, list.keys.MoveCursorToBottom): // Move to the end of the list
 			list.table.GoToBottom()
-		case key.Matches(msg, keys.Map.MoveCursorUp): // Select entry above
+		case key.Matches(msg, list.keys.MultiSelectUp): // Extend the selection up
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			list.table.MoveUp(1, true)
+			return *list, message.NewEntryCmd(list.SelectedEntry())
+		case key.Matches(msg, list.keys.MultiSelectDown): // Extend the selection down
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			list.table.MoveDown(1, true)
+			return *list, message.NewEntryCmd(list.SelectedEntry())
+		case key.Matches(msg, list.keys.MultiSelectToTop): // Extend the selection to the beginning of the list
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			list.table.MultiSelectToTop()
+			return *list, message.NewEntryCmd(list.SelectedEntry())
+		case key.Matches(msg, list.keys.MultiSelectToBottom): // Extend the selection to the end of the list
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			list.table.MultiSelectToBottom()
+			return *list, message.NewEntryCmd(list.SelectedEntry())
+		case key.Matches(msg, list.keys.MultiSelectAll): // Select every entry
+			list.table.SelectAll()
+		case key.Matches(msg, list.keys.Compare): // Compare the two selected entries
+			return *list, message.CompareCmd()
+		case key.Matches(msg, list.keys.OpenPager): // View the selected file in the pager
+			if len(list.entries) == 0 || list.SelectedEntry().IsDir() {
+				return *list, nil
+			}
+			return *list, message.OpenPagerCmd()
+		case key.Matches(msg, list.keys.FollowFile): // Follow the end of the selected file
+			if len(list.entries) == 0 || list.SelectedEntry().IsDir() {
+				return *list, nil
+			}
+			return *list, message.ToggleFollowCmd()
+		case key.Matches(msg, list.keys.GitPreview): // Show the git log or diff in the preview
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			return *list, message.ToggleGitPreviewCmd()
+		case key.Matches(msg, list.keys.MoveCursorUp): // Select entry above
 			if len(list.entries) == 0 {
 				return *list, nil
 			}
 			list.table.MoveUp(1, false)
 			return *list, message.NewEntryCmd(list.SelectedEntry())
-		case key.Matches(msg, keys.Map.MoveCursorDown): // Select entry below
+		case key.Matches(msg, list.keys.MoveCursorDown): // Select entry below
 			if len(list.entries) == 0 {
 				return *list, nil
 			}
 			list.table.MoveDown(1, false)
 			return *list, message.NewEntryCmd(list.SelectedEntry())
-		case key.Matches(msg, keys.Map.GoToParentDirectory): // Get entries from parent directory
+		case key.Matches(msg, list.keys.GoToParentDirectory): // Get entries from parent directory
 			return *list, message.NavUpCmd()
-		case key.Matches(msg, keys.Map.GoToSelectedDirectory): // If the selected entry is a directory. Get entries under that directory
+		case key.Matches(msg, list.keys.GoToSelectedDirectory): // If the selected entry is a directory. Get entries under that directory
 			if len(list.entries) == 0 {
 				return *list, nil
 			}
@@ -157,14 +298,91 @@ func (list *List) Update(msg tea.Msg) (List, tea.Cmd) {
 			return *list, message.NavDownCmd(list.SelectedEntry().Name())
 
 		// TODO: Move this elsewhere
-		case key.Matches(msg, keys.Map.GoBack):
+		case key.Matches(msg, list.keys.GoBack):
 			return *list, message.NavBackCmd()
-		case key.Matches(msg, keys.Map.GoForward):
+		case key.Matches(msg, list.keys.GoForward):
 			return *list, message.NavFwdCmd()
-		case key.Matches(msg, keys.Map.GoToHomeDirectory): // Move to the home directory
+		case key.Matches(msg, list.keys.GoToHomeDirectory): // Move to the home directory
 			return *list, message.NavHomeCmd()
-		case key.Matches(msg, keys.Map.ShowHiddenEntries): // Show hidden files
+		case key.Matches(msg, list.keys.ShowHiddenEntries): // Show hidden files
 			return *list, message.ToggleShowHiddenCmd()
+		case key.Matches(msg, list.keys.BulkRename): // Rename the selected entries in $EDITOR
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			return *list, message.BulkRenameCmd()
+		case key.Matches(msg, list.keys.BatchRename): // Rename the selected entries with a pattern
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			return *list, message.BatchRenameCmd()
+		case key.Matches(msg, list.keys.UndoRename): // Undo the last bulk or batch rename
+			return *list, message.UndoRenameCmd()
+		case key.Matches(msg, list.keys.ChangePermissions): // Change the mode and owner of the selected entries
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			return *list, message.ChangePermissionsCmd()
+		case key.Matches(msg, list.keys.InternalCopy): // Copy the selected entries to the internal clipboard
+			if len(list.entries) == 0 {
+				return *list, nil
+			}
+			return *list, message.InternalCopyCmd()
+		case key.Matches(msg, list.keys.PasteLinks): // Link to the entries in the internal clipboard
+			return *list, message.PasteLinksCmd()
+		case key.Matches(msg, list.keys.GitStage): // Stage the changes to the selected entries
+			if len(list.entries) == 0 || list.git == nil {
+				return *list, nil
+			}
+			return *list, message.GitStageCmd()
+		case key.Matches(msg, list.keys.GitUnstage): // Unstage the changes to the selected entries
+			if len(list.entries) == 0 || list.git == nil {
+				return *list, nil
+			}
+			return *list, message.GitUnstageCmd()
+		case key.Matches(msg, list.keys.GitRestore): // Discard the unstaged changes to the selected entries
+			if len(list.entries) == 0 || list.git == nil {
+				return *list, nil
+			}
+			return *list, message.GitRestoreCmd()
+		case key.Matches(msg, list.keys.GitCommit): // Commit the staged changes
+			if list.git == nil {
+				return *list, message.NewNotificationCmd("Not inside a git work tree")
+			}
+			return *list, message.GitCommitCmd()
+		case key.Matches(msg, list.keys.CalcDirSizes): // Calculate the total size of the selected directories
+			return *list, message.CalcDirSizesCmd()
+		case key.Matches(msg, list.keys.OpenUsage): // Show the disk usage of the current directory
+			return *list, message.OpenUsageCmd()
+		case key.Matches(msg, list.keys.OpenDevices): // Show the mounted filesystems
+			return *list, message.OpenDevicesCmd()
+		case key.Matches(msg, list.keys.FindDupes): // Find duplicate files below the current directory
+			return *list, message.FindDupesCmd()
+		case key.Matches(msg, list.keys.ShellPrompt): // Ask for a shell command to run in the current directory
+			return *list, message.ShellPromptCmd()
+		case key.Matches(msg, list.keys.Subshell): // Open a shell in the current directory
+			return *list, message.SubshellCmd()
+		case key.Matches(msg, list.keys.CycleSort): // Sort by the next method
+			sort := list.sort
+			sort.Method = sort.Method.Next()
+			sort.Column = ""
+			return *list, message.SetSortCmd(sort)
+		case key.Matches(msg, list.keys.ReverseSort):
+			sort := list.sort
+			sort.Reverse = !sort.Reverse
+			return *list, message.SetSortCmd(sort)
+		case key.Matches(msg, list.keys.ToggleDirsFirst):
+			sort := list.sort
+			sort.DirsFirst = !sort.DirsFirst
+			return *list, message.SetSortCmd(sort)
+		case key.Matches(msg, list.keys.ToggleSortCase):
+			sort := list.sort
+			sort.IgnoreCase = !sort.IgnoreCase
+			return *list, message.SetSortCmd(sort)
+		case key.Matches(msg, list.keys.ToggleSortAccents):
+			sort := list.sort
+			sort.IgnoreDiacritics = !sort.IgnoreDiacritics
+			return *list, message.SetSortCmd(sort)
 		}
 	}
 	return *list, nil
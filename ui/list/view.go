@@ -4,13 +4,65 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/git"
 	"github.com/Philistino/fman/icons"
-	"github.com/Philistino/fman/ui/theme"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mattn/go-runewidth"
 	"github.com/muesli/termenv"
 )
 
+// sortIndicator returns the suffix of the header of the column,
+// showing whether the list is sorted on it
+func (list *List) sortIndicator(column entry.Column) string {
+	if !column.Sorted(list.sort) {
+		return ""
+	}
+	var suffix string
+	if list.sort.Column == "" {
+		switch list.sort.Method {
+		case entry.NameSort:
+			suffix = " (name)"
+		case entry.ExtSort:
+			suffix = " (ext)"
+		}
+	}
+	if list.sort.Reverse {
+		return suffix + " ▼"
+	}
+	return suffix + " ▲"
+}
+
+// headerIcon returns the icon shown before the title of the column, if it has one
+func (list *List) headerIcon(column entry.Column) (rune, bool) {
+	switch column.ID {
+	case "name":
+		return list.styles.Icons.NameIcon, true
+	case "size":
+		return list.styles.Icons.SizeIcon, true
+	case "mtime", "btime", "atime":
+		return list.styles.Icons.TimeIcon, true
+	}
+	return 0, false
+}
+
+// gitColor returns the color of the git status of the entry, if it has one
+func (list *List) gitColor(name string) (lipgloss.Color, bool) {
+	switch list.GitStatus(name).Primary() {
+	case git.Conflicted:
+		return list.theme.GitConflictColor, true
+	case git.Staged:
+		return list.theme.GitStagedColor, true
+	case git.Modified:
+		return list.theme.GitModifiedColor, true
+	case git.Untracked:
+		return list.theme.GitUntrackedColor, true
+	case git.Ignored:
+		return list.theme.GitIgnoredColor, true
+	}
+	return "", false
+}
+
 func (list *List) View() string {
 	list.flexBox.ForceRecalculate()
 
@@ -18,17 +70,13 @@ func (list *List) View() string {
 	contents := make([]strings.Builder, cellsLength)
 
 	// Write List headers
-	contents[0].WriteRune(theme.GetActiveIconTheme().NameIcon)
-	contents[0].WriteString(termenv.String(" Name").Italic().String())
-	contents[0].WriteByte('\n')
-
-	contents[1].WriteRune(theme.GetActiveIconTheme().SizeIcon)
-	contents[1].WriteString(termenv.String(" Size").Italic().String())
-	contents[1].WriteByte('\n')
-
-	contents[2].WriteRune(theme.GetActiveIconTheme().TimeIcon)
-	contents[2].WriteString(termenv.String(" Modify Time").Italic().String())
-	contents[2].WriteByte('\n')
+	for i, column := range list.columns {
+		if icon, ok := list.headerIcon(column); ok {
+			contents[i].WriteRune(icon)
+		}
+		contents[i].WriteString(termenv.String(" " + column.Title + list.sortIndicator(column)).Italic().String())
+		contents[i].WriteByte('\n')
+	}
 
 	if len(list.entries) == 0 {
 		for i := 0; i < cellsLength; i++ {
@@ -41,28 +89,45 @@ func (list *List) View() string {
 		entry := list.entries[index]
 		content := make([]strings.Builder, cellsLength)
 
-		if entry.SymlinkName != "" {
-			content[0].WriteRune(theme.GetActiveIconTheme().SymlinkIcon)
-		} else if entry.IsDir() {
-			i := icons.GetIconForReal(entry, entry.IsHidden)
-			content[0].WriteString(fmt.Sprintf("%s%s\033[39m", i.ColorTerm(), i.Glyph()))
+		for i, column := range list.columns {
+			if _, ok := list.sizing[entry.Name()]; ok && column.ID == "size" {
+				content[i].WriteString(list.spinner.View())
+				continue
+			}
+			if column.ID != "name" {
+				content[i].WriteString(runewidth.Truncate(column.Value(list.path, entry), list.flexBox.Row(0).Cell(i).GetWidth()-1, "..."))
+				continue
+			}
+			if entry.SymlinkName != "" {
+				content[i].WriteRune(list.styles.Icons.SymlinkIcon)
+			} else if entry.IsDir() {
+				icon := icons.GetIconForReal(entry, entry.IsHidden)
+				content[i].WriteString(fmt.Sprintf("%s%s\033[39m", icon.ColorTerm(), icon.Glyph()))
+
+			} else {
+				content[i].WriteString(icons.GetIconTerm(entry, entry.IsHidden))
+			}
 
-		} else {
-			content[0].WriteString(icons.GetIconTerm(entry, entry.IsHidden))
+			content[i].WriteRune(' ')
+			width := list.flexBox.Row(0).Cell(i).GetWidth() - 3
+			marker := list.GitStatus(entry.Name()).String()
+			if marker == "" || width < len(marker)+4 {
+				content[i].WriteString(runewidth.Truncate(entry.Name(), width, "..."))
+				continue
+			}
+			// the git status is aligned to the right of the name cell
+			name := runewidth.Truncate(entry.Name(), width-len(marker)-1, "...")
+			content[i].WriteString(name)
+			content[i].WriteString(strings.Repeat(" ", width-len(marker)-runewidth.StringWidth(name)))
+			content[i].WriteString(marker)
 		}
 
-		content[0].WriteRune(' ')
-		name := runewidth.Truncate(entry.Name(), list.truncateLimit-2, "...")
-		content[0].WriteString(name)
-		content[1].WriteString(entry.SizeStr)
-		content[2].WriteString(entry.ModifyTime)
-
 		var style lipgloss.Style
 		for i := 0; i < cellsLength; i++ {
-			if index == list.table.Cursor() {
-				style = theme.SelectedItemStyle
+			if index == list.table.Cursor() || list.table.IsSelected(index) {
+				style = list.styles.SelectedItemStyle
 			} else if index%2 == 0 {
-				style = theme.EvenItemStyle
+				style = list.styles.EvenItemStyle
 			}
 
 			// IDK
@@ -74,15 +139,22 @@ func (list *List) View() string {
 			// style = style.Width(list.flexBox.Row(0).Cell(i).GetWidth() - offset)
 			style = style.Width(list.flexBox.Row(0).Cell(i).GetWidth())
 
-			if i == 0 && entry.SymlinkName != "" {
+			isName := list.columns[i].ID == "name"
+			if isName && entry.SymlinkName != "" {
 				style = style.Bold(true).Underline(true)
 			} else {
 				style = style.UnsetBold().UnsetUnderline()
 			}
+			// broken links are struck through so they stand out even under the cursor
+			style = style.Strikethrough(isName && entry.BrokenLink)
 
 			// Colors
-			if index == list.table.Cursor() {
+			if index == list.table.Cursor() || list.table.IsSelected(index) {
 				style = style.Foreground(list.theme.SelectedItemFgColor)
+			} else if entry.BrokenLink {
+				style = style.Foreground(list.theme.BrokenLinkColor)
+			} else if color, ok := list.gitColor(entry.Name()); ok {
+				style = style.Foreground(color)
 			} else if entry.IsHidden {
 				style = style.Foreground(list.theme.HiddenFileColor)
 				if entry.IsDir() {
@@ -94,7 +166,7 @@ func (list *List) View() string {
 				style = style.Foreground(list.theme.TextColor)
 			}
 
-			if i != 0 && index != list.table.Cursor() {
+			if !isName && index != list.table.Cursor() && !list.table.IsSelected(index) {
 				style = style.Foreground(list.theme.TextColor)
 			}
 
@@ -35,3 +35,16 @@ func InternalPasteCmd() tea.Cmd {
 		return InternalPasteMsg{}
 	}
 }
+
+// PasteLinksMsg is used to communicate to the main program
+// that creating links to the entries in the clipboard is requested.
+type PasteLinksMsg struct{}
+
+// PasteLinksCmd is used to create a command that will
+// communicate to the main program that creating links to
+// the entries in the clipboard is requested.
+func PasteLinksCmd() tea.Cmd {
+	return func() tea.Msg {
+		return PasteLinksMsg{}
+	}
+}
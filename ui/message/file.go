@@ -15,6 +15,58 @@ func RenameCmd() tea.Cmd {
 	}
 }
 
+// BulkRenameMsg is used to communicate to the main program
+// that renaming the selected entries in $EDITOR is requested.
+type BulkRenameMsg struct{}
+
+// BulkRenameCmd is used to create a command that will
+// communicate to the main program that renaming the selected
+// entries in $EDITOR is requested.
+func BulkRenameCmd() tea.Cmd {
+	return func() tea.Msg {
+		return BulkRenameMsg{}
+	}
+}
+
+// BatchRenameMsg is used to communicate to the main program
+// that renaming the selected entries with a pattern is requested.
+type BatchRenameMsg struct{}
+
+// BatchRenameCmd is used to create a command that will
+// communicate to the main program that renaming the selected
+// entries with a pattern is requested.
+func BatchRenameCmd() tea.Cmd {
+	return func() tea.Msg {
+		return BatchRenameMsg{}
+	}
+}
+
+// UndoRenameMsg is used to communicate to the main program
+// that undoing the last bulk or batch rename is requested.
+type UndoRenameMsg struct{}
+
+// UndoRenameCmd is used to create a command that will
+// communicate to the main program that undoing the last
+// bulk or batch rename is requested.
+func UndoRenameCmd() tea.Cmd {
+	return func() tea.Msg {
+		return UndoRenameMsg{}
+	}
+}
+
+// ChangePermissionsMsg is used to communicate to the main program
+// that changing the mode and owner of the selected entries is requested.
+type ChangePermissionsMsg struct{}
+
+// ChangePermissionsCmd is used to create a command that will
+// communicate to the main program that changing the mode and
+// owner of the selected entries is requested.
+func ChangePermissionsCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ChangePermissionsMsg{}
+	}
+}
+
 // NewFileMsg is used to communicate to the main program
 // that a new file operation is requested.
 type NewFileMsg struct{}
@@ -53,3 +105,120 @@ func DeleteCmd() tea.Cmd {
 		return DeleteMsg{}
 	}
 }
+
+// CompareMsg is used to communicate to the main program
+// that a comparison of the two selected entries is requested.
+type CompareMsg struct{}
+
+// CompareCmd is used to create a command that will
+// communicate to the main program that a comparison
+// of the two selected entries is requested.
+func CompareCmd() tea.Cmd {
+	return func() tea.Msg {
+		return CompareMsg{}
+	}
+}
+
+// OpenPagerMsg is used to communicate to the main program
+// that viewing the selected file in the pager is requested.
+type OpenPagerMsg struct{}
+
+// OpenPagerCmd is used to create a command that will
+// communicate to the main program that viewing the
+// selected file in the pager is requested.
+func OpenPagerCmd() tea.Cmd {
+	return func() tea.Msg {
+		return OpenPagerMsg{}
+	}
+}
+
+// OpenFileMsg is used to communicate to the main program
+// that opening the selected files is requested.
+type OpenFileMsg struct{}
+
+// OpenFileCmd is used to create a command that will
+// communicate to the main program that opening the
+// selected files is requested.
+func OpenFileCmd() tea.Cmd {
+	return func() tea.Msg {
+		return OpenFileMsg{}
+	}
+}
+
+// OpenWithMsg is used to communicate to the main program
+// that choosing a program to open the selected files is requested.
+type OpenWithMsg struct{}
+
+// OpenWithCmd is used to create a command that will
+// communicate to the main program that choosing a program
+// to open the selected files is requested.
+func OpenWithCmd() tea.Cmd {
+	return func() tea.Msg {
+		return OpenWithMsg{}
+	}
+}
+
+// OpenUsageMsg is used to communicate to the main program that the
+// disk usage view of the current directory is requested.
+type OpenUsageMsg struct{}
+
+// OpenUsageCmd is used to create a command that will communicate to the main
+// program that the disk usage view of the current directory is requested.
+func OpenUsageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return OpenUsageMsg{}
+	}
+}
+
+// FindDupesMsg is used to communicate to the main program that a search for
+// duplicate files below the current directory is requested.
+type FindDupesMsg struct{}
+
+// FindDupesCmd is used to create a command that will communicate to the main
+// program that a search for duplicate files below the current directory is requested.
+func FindDupesCmd() tea.Cmd {
+	return func() tea.Msg {
+		return FindDupesMsg{}
+	}
+}
+
+// RunCommandMsg is used to communicate to the main program
+// that running the user command with the given index is requested.
+type RunCommandMsg struct {
+	Index int
+}
+
+// RunCommandCmd is used to create a command that will
+// communicate to the main program that running the user
+// command with the given index is requested.
+func RunCommandCmd(index int) tea.Cmd {
+	return func() tea.Msg {
+		return RunCommandMsg{index}
+	}
+}
+
+// ShellPromptMsg is used to communicate to the main program
+// that asking for a shell command to run is requested.
+type ShellPromptMsg struct{}
+
+// ShellPromptCmd is used to create a command that will
+// communicate to the main program that asking for a shell
+// command to run is requested.
+func ShellPromptCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ShellPromptMsg{}
+	}
+}
+
+// SubshellMsg is used to communicate to the main program
+// that opening a shell in the current directory is requested.
+type SubshellMsg struct{}
+
+// SubshellCmd is used to create a command that will
+// communicate to the main program that opening a shell
+// in the current directory is requested.
+func SubshellCmd() tea.Cmd {
+	return func() tea.Msg {
+		return SubshellMsg{}
+	}
+}
@@ -0,0 +1,122 @@
+package message
+
+import (
+	"context"
+
+	"github.com/Philistino/fman/entry/git"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// GitStatusMsg is used to communicate the git status of the entries of the directory
+// at Path. Repo is nil if the directory is not inside a git work tree.
+type GitStatusMsg struct {
+	Path string
+	Repo *git.Repo
+	Err  error
+}
+
+// GitStageMsg is used to communicate to the main program that staging the
+// changes to the selected entries is requested.
+type GitStageMsg struct{}
+
+// GitStageCmd is used to create a command that will communicate to the main
+// program that staging the changes to the selected entries is requested.
+func GitStageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return GitStageMsg{}
+	}
+}
+
+// GitUnstageMsg is used to communicate to the main program that unstaging the
+// changes to the selected entries is requested.
+type GitUnstageMsg struct{}
+
+// GitUnstageCmd is used to create a command that will communicate to the main
+// program that unstaging the changes to the selected entries is requested.
+func GitUnstageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return GitUnstageMsg{}
+	}
+}
+
+// GitRestoreMsg is used to communicate to the main program that discarding the
+// unstaged changes to the selected entries is requested.
+type GitRestoreMsg struct{}
+
+// GitRestoreCmd is used to create a command that will communicate to the main
+// program that discarding the unstaged changes to the selected entries is requested.
+func GitRestoreCmd() tea.Cmd {
+	return func() tea.Msg {
+		return GitRestoreMsg{}
+	}
+}
+
+// GitCommitMsg is used to communicate to the main program that committing the
+// staged changes is requested.
+type GitCommitMsg struct{}
+
+// GitCommitCmd is used to create a command that will communicate to the main
+// program that committing the staged changes is requested.
+func GitCommitCmd() tea.Cmd {
+	return func() tea.Msg {
+		return GitCommitMsg{}
+	}
+}
+
+// GetGitLogMsg is used to communicate to the main program that the commits
+// that changed the file at Path are requested for the preview.
+type GetGitLogMsg struct {
+	Ctx  context.Context
+	Path string
+}
+
+// GetGitLogCmd is used to create a command that will communicate to the main
+// program that the commits that changed the file at path are requested.
+func GetGitLogCmd(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		return GetGitLogMsg{ctx, path}
+	}
+}
+
+// GetRevisionPreviewMsg is used to communicate to the main program that a
+// preview of the file at Path as it was at Revision is requested.
+type GetRevisionPreviewMsg struct {
+	Ctx      context.Context
+	Path     string
+	Revision git.Revision
+}
+
+// GetRevisionPreviewCmd is used to create a command that will communicate to the
+// main program that a preview of the file at path as it was at rev is requested.
+func GetRevisionPreviewCmd(ctx context.Context, path string, rev git.Revision) tea.Cmd {
+	return func() tea.Msg {
+		return GetRevisionPreviewMsg{ctx, path, rev}
+	}
+}
+
+// GetGitDiffMsg is used to communicate to the main program that the changes
+// to the file at Path against HEAD are requested for the preview.
+type GetGitDiffMsg struct {
+	Ctx  context.Context
+	Path string
+}
+
+// GetGitDiffCmd is used to create a command that will communicate to the main
+// program that the changes to the file at path against HEAD are requested.
+func GetGitDiffCmd(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		return GetGitDiffMsg{ctx, path}
+	}
+}
+
+// ToggleGitPreviewMsg is used to communicate to the preview that switching to
+// the next git mode is requested.
+type ToggleGitPreviewMsg struct{}
+
+// ToggleGitPreviewCmd is used to create a command that will communicate to the
+// preview that switching to the next git mode is requested.
+func ToggleGitPreviewCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ToggleGitPreviewMsg{}
+	}
+}
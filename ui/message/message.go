@@ -3,6 +3,7 @@ package message
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
@@ -58,6 +59,33 @@ func GetPreviewCmd(ctx context.Context, path string) tea.Cmd {
 	}
 }
 
+// ToggleFollowMsg is used to communicate to the preview
+// that following the selected file is requested.
+type ToggleFollowMsg struct{}
+
+// ToggleFollowCmd is used to create a command that will
+// communicate to the preview that following the selected
+// file is requested.
+func ToggleFollowCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ToggleFollowMsg{}
+	}
+}
+
+// ReadTailMsg is used to communicate to the main program that
+// the data appended to a followed file should be read.
+// ID identifies the follow session the read belongs to.
+type ReadTailMsg struct {
+	ID    int
+	State entry.TailState
+}
+
+func ReadTailCmd(id int, state entry.TailState) tea.Cmd {
+	return func() tea.Msg {
+		return ReadTailMsg{id, state}
+	}
+}
+
 type NewNotificationMsg struct {
 	Message string
 }
@@ -83,6 +111,48 @@ func ToggleShowHiddenCmd() tea.Cmd {
 	}
 }
 
+// SetSortMsg is used to communicate to the main program
+// that the sort of the current directory should be changed.
+type SetSortMsg struct {
+	Sort entry.SortSpec
+}
+
+// SetSortCmd is used to create a command that will communicate to the
+// main program that the sort of the current directory should be changed.
+func SetSortCmd(sort entry.SortSpec) tea.Cmd {
+	return func() tea.Msg {
+		return SetSortMsg{Sort: sort}
+	}
+}
+
+// CalcDirSizesMsg is used to communicate to the main program that the
+// sizes of the selected directories should be calculated.
+type CalcDirSizesMsg struct{}
+
+// CalcDirSizesCmd is used to create a command that will communicate to the main
+// program that the sizes of the selected directories should be calculated.
+func CalcDirSizesCmd() tea.Cmd {
+	return func() tea.Msg {
+		return CalcDirSizesMsg{}
+	}
+}
+
+// DirSizesPendingMsg is used to communicate that the sizes of the
+// directories with the given names in Path are being calculated.
+type DirSizesPendingMsg struct {
+	Path  string
+	Names []string
+}
+
+// DirSizeMsg is used to communicate the total size of a directory
+type DirSizeMsg struct {
+	ID   int    // identifies the calculation run the size belongs to
+	Path string // path of the parent directory
+	Name string
+	Size int64
+	Err  error
+}
+
 // DirChangedMsg is used to communicate that the CWD has changed
 type DirChangedMsg struct {
 	nav.DirState
@@ -145,7 +215,8 @@ func HandleReloadCmd(navi *nav.Nav, currentSelected []string, cursor string) tea
 	}
 }
 
-func openEditor(path string) tea.Cmd {
+// EditorCommand returns the command that edits the file at path in $EDITOR, falling back to nano.
+func EditorCommand(path string) *exec.Cmd {
 	const fallBackEditor = "nano"
 
 	editor := os.Getenv("EDITOR")
@@ -153,23 +224,64 @@ func openEditor(path string) tea.Cmd {
 	if editor == "" {
 		editor = fallBackEditor
 	}
-	cmd := exec.Command(editor, path)
+	return exec.Command(editor, path)
+}
+
+// ExecProcess runs cmd like tea.ExecProcess. Restoring the terminal after the process exits
+// does not turn mouse support back on, so it is enabled again before fn's message is sent.
+func ExecProcess(cmd *exec.Cmd, fn tea.ExecCallback) tea.Cmd {
 	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		msg := fn(err)
+		return tea.BatchMsg{tea.EnableMouseCellMotion, func() tea.Msg { return msg }}
+	})
+}
+
+// OpenEditorCmd opens the file at path in $EDITOR, falling back to nano.
+// If the editor cannot be run, the file is opened with the default application.
+func OpenEditorCmd(path string) tea.Cmd {
+	cmd := EditorCommand(path)
+	return ExecProcess(cmd, func(err error) tea.Msg {
 		if err == nil {
-			return tea.EnableMouseCellMotion
+			return nil
 		}
 
 		// Failed to open editor, open with default app instead
 		cmd := exec.Command(detectOpenCommand(), path)
-		cmd.Start()
-
-		return tea.Batch(
-			NewNotificationCmd(err.Error()),
-			tea.EnableMouseCellMotion,
-		)
+		entry.Detach(cmd)
+		if startErr := cmd.Start(); startErr != nil {
+			return NewNotificationMsg{startErr.Error()}
+		}
+		go cmd.Wait()
+		return NewNotificationMsg{err.Error()}
 	})
 }
 
+// OpenCmd opens the paths with the opener. Foreground openers take over the
+// terminal until they exit while other openers are detached from fman.
+func OpenCmd(opener entry.Opener, paths []string) tea.Cmd {
+	cmd, err := opener.Cmd(paths)
+	if err != nil {
+		return NewNotificationCmd(err.Error())
+	}
+	if opener.Foreground {
+		return ExecProcess(cmd, func(err error) tea.Msg {
+			if err != nil {
+				return NewNotificationMsg{fmt.Sprintf("%s: %s", opener.Name, err)}
+			}
+			return nil
+		})
+	}
+	entry.Detach(cmd)
+	return func() tea.Msg {
+		if err := cmd.Start(); err != nil {
+			return NewNotificationMsg{fmt.Sprintf("%s: %s", opener.Name, err)}
+		}
+		// wait in the background so the process does not become a zombie
+		go cmd.Wait()
+		return nil
+	}
+}
+
 func detectOpenCommand() string {
 	switch runtime.GOOS {
 	case "linux":
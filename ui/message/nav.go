@@ -87,3 +87,15 @@ func NavOtherCmd(path string) tea.Cmd {
 		}
 	}
 }
+
+// OpenDevicesMsg is used to communicate to the main program that the
+// mounted devices panel should be shown
+type OpenDevicesMsg struct{}
+
+// OpenDevicesCmd is used to create a command that will communicate to the main
+// program that the mounted devices panel should be shown
+func OpenDevicesCmd() tea.Cmd {
+	return func() tea.Msg {
+		return OpenDevicesMsg{}
+	}
+}
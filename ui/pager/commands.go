@@ -0,0 +1,96 @@
+package pager
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/Philistino/fman/entry"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// countBottom is the target line used to jump to the end of the file
+const countBottom = -1
+
+// countMsg is sent once the file has been indexed up to the target line
+type countMsg struct {
+	target int
+	count  int
+	err    error
+}
+
+// countCmd indexes the file in the background so that the pager can jump to the
+// target line. The whole file is indexed if the target is countBottom. Nothing is sent
+// if ctx is done.
+func countCmd(ctx context.Context, file *entry.LineFile, target int) tea.Cmd {
+	return func() tea.Msg {
+		n := target
+		if target == countBottom {
+			n = -1
+		}
+		count, err := file.IndexLines(ctx, n)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return countMsg{target: target, count: count, err: err}
+	}
+}
+
+// indexedMsg is sent once the lines below the view have been indexed
+type indexedMsg struct {
+	err error
+}
+
+// indexCmd indexes the file in the background until line n is known, so that the pager
+// can tell whether it scrolled past the end of the file. Nothing is sent if ctx is done.
+func indexCmd(ctx context.Context, file *entry.LineFile, n int) tea.Cmd {
+	return func() tea.Msg {
+		_, err := file.IndexLines(ctx, n)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return indexedMsg{err: err}
+	}
+}
+
+// searchResultMsg is sent when a search is complete
+type searchResultMsg struct {
+	pattern *regexp.Regexp
+	line    int
+	found   bool
+	forward bool
+	wrapped bool // true if the match was found after wrapping around the end of the file
+	err     error
+}
+
+// searchCmd searches the file in the background. If nothing is found before
+// the end of the file the search wraps around to the other end. Nothing is sent
+// if ctx is done.
+func searchCmd(ctx context.Context, file *entry.LineFile, re *regexp.Regexp, from int, forward bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := search(ctx, file, re, from, forward)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return msg
+	}
+}
+
+func search(ctx context.Context, file *entry.LineFile, re *regexp.Regexp, from int, forward bool) searchResultMsg {
+	msg := searchResultMsg{pattern: re, forward: forward}
+	msg.line, msg.found, msg.err = file.Search(ctx, re, from, forward)
+	if msg.found || msg.err != nil {
+		return msg
+	}
+	msg.wrapped = true
+	if forward {
+		msg.line, msg.found, msg.err = file.Search(ctx, re, 0, true)
+		return msg
+	}
+	count, err := file.CountLines(ctx)
+	if err != nil {
+		msg.err = err
+		return msg
+	}
+	msg.line, msg.found, msg.err = file.Search(ctx, re, count-1, false)
+	return msg
+}
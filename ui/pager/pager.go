@@ -0,0 +1,466 @@
+// Package pager implements a full screen pager for reading files. Files are read
+// lazily so even very large files open instantly.
+package pager
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// chunkSize is the number of lines that are read and highlighted together
+const chunkSize = 200
+
+// maxChunks is the number of highlighted chunks kept in memory
+const maxChunks = 20
+
+// ClosedMsg is sent when the user closes the pager
+type ClosedMsg struct{}
+
+func closedCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ClosedMsg{}
+	}
+}
+
+type inputMode uint8
+
+const (
+	inputNone inputMode = iota
+	inputSearch
+	inputGoTo
+)
+
+// chunk is a range of lines of the file with their highlighted versions
+type chunk struct {
+	raw         []string
+	highlighted []string
+}
+
+// Pager shows a file in full screen and lets the user scroll and search through it.
+type Pager struct {
+	file *entry.LineFile
+	name string
+
+	// ctx stops the counts and searches running in the background once the pager is closed
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	width  int
+	height int
+
+	top    int // first visible line
+	chunks map[int]chunk
+
+	lineNumbers bool
+	wrap        bool
+
+	pattern *regexp.Regexp
+	match   int // line of the current match or -1
+
+	mode   inputMode
+	input  textinput.Model
+	status string
+
+	theme colors.Theme
+}
+
+// New creates a pager for the file. The name is used to choose the syntax
+// highlighting. The pager takes ownership of the file and closes it with Close.
+func New(file *entry.LineFile, name string, theme colors.Theme, width, height int) *Pager {
+	ti := textinput.New()
+	ti.CharLimit = 256
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pager{
+		file:        file,
+		name:        name,
+		ctx:         ctx,
+		cancel:      cancel,
+		width:       width,
+		height:      height,
+		chunks:      make(map[int]chunk),
+		lineNumbers: true,
+		match:       -1,
+		input:       ti,
+		theme:       theme,
+	}
+}
+
+// Close stops the counts and searches running in the background and closes the file
+// shown in the pager
+func (p *Pager) Close() error {
+	p.cancel()
+	return p.file.Close()
+}
+
+// SetSize sets the size of the pager
+func (p *Pager) SetSize(width, height int) tea.Cmd {
+	p.width = width
+	p.height = height
+	return p.clampTop()
+}
+
+// bodyHeight returns the number of rows available for the file content
+func (p *Pager) bodyHeight() int {
+	h := p.height - 2 // header and footer
+	if h < 1 {
+		return 1
+	}
+	return h
+}
+
+func (p *Pager) Update(msg tea.Msg) (*Pager, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return p, p.SetSize(msg.Width, msg.Height)
+	case countMsg:
+		return p, p.handleCount(msg)
+	case indexedMsg:
+		if msg.err != nil {
+			p.status = msg.err.Error()
+			return p, nil
+		}
+		return p, p.clampTop()
+	case searchResultMsg:
+		return p, p.handleSearchResult(msg)
+	case tea.KeyMsg:
+		if p.mode != inputNone {
+			return p, p.handleInputKey(msg)
+		}
+		return p, p.handleKey(msg)
+	}
+	if p.mode != inputNone {
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return p, cmd
+	}
+	return p, nil
+}
+
+func (p *Pager) handleKey(msg tea.KeyMsg) tea.Cmd {
+	p.status = ""
+	switch {
+	case key.Matches(msg, keys.Map.ClosePager):
+		return closedCmd()
+	case key.Matches(msg, keys.Map.MoveCursorUp):
+		return p.scroll(-1)
+	case key.Matches(msg, keys.Map.MoveCursorDown):
+		return p.scroll(1)
+	case key.Matches(msg, keys.Map.PageUp):
+		return p.scroll(-p.bodyHeight())
+	case key.Matches(msg, keys.Map.PageDown):
+		return p.scroll(p.bodyHeight())
+	case key.Matches(msg, keys.Map.MoveCursorToTop):
+		p.top = 0
+	case key.Matches(msg, keys.Map.MoveCursorToBottom):
+		p.status = "Counting lines..."
+		return countCmd(p.ctx, p.file, countBottom)
+	case key.Matches(msg, keys.Map.ToggleLineNumbers):
+		p.lineNumbers = !p.lineNumbers
+	case key.Matches(msg, keys.Map.ToggleWrap):
+		p.wrap = !p.wrap
+	case key.Matches(msg, keys.Map.Search):
+		return p.startInput(inputSearch, "/")
+	case key.Matches(msg, keys.Map.GoToLine):
+		return p.startInput(inputGoTo, ":")
+	case key.Matches(msg, keys.Map.NextMatch):
+		if p.match < 0 {
+			return p.searchFrom(p.top, true)
+		}
+		return p.searchFrom(p.match+1, true)
+	case key.Matches(msg, keys.Map.PrevMatch):
+		if p.match < 0 {
+			return p.searchFrom(p.top, false)
+		}
+		return p.searchFrom(p.match-1, false)
+	}
+	return nil
+}
+
+func (p *Pager) startInput(mode inputMode, prompt string) tea.Cmd {
+	p.mode = mode
+	p.input.Prompt = prompt
+	p.input.Reset()
+	return p.input.Focus()
+}
+
+func (p *Pager) handleInputKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		p.mode = inputNone
+		p.input.Blur()
+		return nil
+	case tea.KeyEnter:
+		mode := p.mode
+		value := p.input.Value()
+		p.mode = inputNone
+		p.input.Blur()
+		if mode == inputGoTo {
+			return p.goToLine(value)
+		}
+		return p.search(value)
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+// scroll moves the view by n lines
+func (p *Pager) scroll(n int) tea.Cmd {
+	p.top += n
+	return p.clampTop()
+}
+
+// clampTop keeps the top line within the file. Where the file ends is only known once
+// the lines past the visible ones are indexed, so if they are not yet the returned
+// command indexes them in the background and the top line is clamped again after.
+func (p *Pager) clampTop() tea.Cmd {
+	if p.top < 0 {
+		p.top = 0
+	}
+	count, complete := p.file.LineCount()
+	if !complete {
+		if count > p.top+p.bodyHeight() {
+			return nil
+		}
+		return indexCmd(p.ctx, p.file, p.top+p.bodyHeight())
+	}
+	last := count - p.bodyHeight()
+	if last < 0 {
+		last = 0
+	}
+	if p.top > last {
+		p.top = last
+	}
+	return nil
+}
+
+func (p *Pager) goToLine(value string) tea.Cmd {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 1 {
+		p.status = fmt.Sprintf("Invalid line number: %s", value)
+		return nil
+	}
+	p.status = "Going to line..."
+	return countCmd(p.ctx, p.file, n-1)
+}
+
+func (p *Pager) handleCount(msg countMsg) tea.Cmd {
+	p.status = ""
+	if msg.err != nil {
+		p.status = msg.err.Error()
+		return nil
+	}
+	if msg.target == countBottom || msg.target >= msg.count {
+		p.top = msg.count - p.bodyHeight()
+	} else {
+		p.top = msg.target
+	}
+	return p.clampTop()
+}
+
+func (p *Pager) search(value string) tea.Cmd {
+	if value == "" {
+		return nil
+	}
+	re, err := regexp.Compile(value)
+	if err != nil {
+		p.status = fmt.Sprintf("Invalid pattern: %s", err)
+		return nil
+	}
+	p.pattern = re
+	return p.searchFrom(p.top, true)
+}
+
+func (p *Pager) searchFrom(from int, forward bool) tea.Cmd {
+	if p.pattern == nil {
+		p.status = "No previous search"
+		return nil
+	}
+	if from < 0 && forward {
+		from = 0
+	}
+	p.status = "Searching..."
+	return searchCmd(p.ctx, p.file, p.pattern, from, forward)
+}
+
+func (p *Pager) handleSearchResult(msg searchResultMsg) tea.Cmd {
+	if msg.pattern != p.pattern {
+		return nil
+	}
+	switch {
+	case msg.err != nil:
+		p.status = msg.err.Error()
+		return nil
+	case !msg.found:
+		p.status = fmt.Sprintf("Pattern not found: %s", p.pattern)
+		return nil
+	case msg.wrapped && msg.forward:
+		p.status = "Search hit bottom, continuing at top"
+	case msg.wrapped:
+		p.status = "Search hit top, continuing at bottom"
+	default:
+		p.status = ""
+	}
+	p.match = msg.line
+	// keep a few lines of context above the match
+	p.top = msg.line - p.bodyHeight()/4
+	return p.clampTop()
+}
+
+// line returns the raw and highlighted versions of line i
+func (p *Pager) line(i int) (raw string, highlighted string, ok bool) {
+	c, err := p.chunk(i / chunkSize)
+	if err != nil {
+		return "", "", false
+	}
+	idx := i % chunkSize
+	if idx >= len(c.raw) {
+		return "", "", false
+	}
+	return c.raw[idx], c.highlighted[idx], true
+}
+
+// chunk returns the chunk with the given index, reading and highlighting it if needed
+func (p *Pager) chunk(idx int) (chunk, error) {
+	if c, ok := p.chunks[idx]; ok {
+		return c, nil
+	}
+	raw, err := p.file.Lines(idx*chunkSize, chunkSize)
+	if err != nil {
+		return chunk{}, err
+	}
+	for i := range raw {
+		raw[i] = entry.ExpandTabs(raw[i])
+	}
+	c := chunk{raw: raw, highlighted: entry.HighlightLines(p.name, raw)}
+	if len(p.chunks) >= maxChunks {
+		// forget the chunks furthest from the one being read
+		for k := range p.chunks {
+			if k < idx-maxChunks/2 || k > idx+maxChunks/2 {
+				delete(p.chunks, k)
+			}
+		}
+	}
+	p.chunks[idx] = c
+	return c, nil
+}
+
+func (p *Pager) View() string {
+	body := p.bodyHeight()
+	count, complete := p.file.LineCount()
+	numWidth := len(strconv.Itoa(p.top + body))
+	if complete && len(strconv.Itoa(count)) > numWidth {
+		numWidth = len(strconv.Itoa(count))
+	}
+	gutterWidth := 0
+	if p.lineNumbers {
+		gutterWidth = numWidth + 3
+	}
+	textWidth := p.width - gutterWidth
+	if textWidth < 1 {
+		textWidth = 1
+	}
+
+	rows := make([]string, 0, body)
+	for i := p.top; len(rows) < body; i++ {
+		raw, highlighted, ok := p.line(i)
+		if !ok {
+			break
+		}
+		text := highlighted
+		if p.pattern != nil && p.pattern.MatchString(raw) {
+			text = p.highlightMatches(raw)
+		}
+		segments := []string{layout.FitWidth(text, textWidth)}
+		if p.wrap {
+			segments = wrapWidth(text, textWidth)
+		}
+		for j, segment := range segments {
+			if len(rows) == body {
+				break
+			}
+			gutter := ""
+			if p.lineNumbers {
+				number := strings.Repeat(" ", numWidth)
+				if j == 0 {
+					number = fmt.Sprintf("%*d", numWidth, i+1)
+				}
+				gutter = termenv.String(number + " │ ").Faint().String()
+				if i == p.match && j == 0 {
+					gutter = termenv.String(number + " │ ").Foreground(termenv.ANSIYellow).String()
+				}
+			}
+			rows = append(rows, gutter+segment)
+		}
+	}
+	for len(rows) < body {
+		rows = append(rows, termenv.String("~").Faint().String())
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, p.headerView(count, complete), strings.Join(rows, "\n"), p.footerView())
+}
+
+// highlightMatches renders the raw line with the matches of the pattern in reverse video
+func (p *Pager) highlightMatches(raw string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range p.pattern.FindAllStringIndex(raw, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		sb.WriteString(raw[last:m[0]])
+		sb.WriteString(termenv.String(raw[m[0]:m[1]]).Reverse().String())
+		last = m[1]
+	}
+	sb.WriteString(raw[last:])
+	return sb.String()
+}
+
+func (p *Pager) headerView(count int, complete bool) string {
+	total := "?"
+	if complete {
+		total = strconv.Itoa(count)
+	}
+	position := fmt.Sprintf("line %d/%s", p.top+1, total)
+	name := lipgloss.NewStyle().Bold(true).Render(p.name)
+	gap := p.width - lipgloss.Width(name) - lipgloss.Width(position) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return lipgloss.NewStyle().
+		Background(p.theme.InfobarBgColor).
+		Foreground(p.theme.InfobarFgColor).
+		Inline(true).
+		Render(layout.FitWidth(" "+name+strings.Repeat(" ", gap)+position+" ", p.width))
+}
+
+func (p *Pager) footerView() string {
+	if p.mode != inputNone {
+		return layout.FitWidth(p.input.View(), p.width)
+	}
+	if p.status != "" {
+		return layout.FitWidth(p.status, p.width)
+	}
+	return layout.FitWidth(termenv.String("/ search  : go to line  n/N next/previous match  # line numbers  w wrap  q close").Faint().String(), p.width)
+}
+
+// wrapWidth wraps the possibly styled string s into lines of at most width cells
+func wrapWidth(s string, width int) []string {
+	if lipgloss.Width(s) <= width {
+		return []string{s}
+	}
+	return strings.Split(lipgloss.NewStyle().Width(width).Render(s), "\n")
+}
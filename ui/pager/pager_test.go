@@ -0,0 +1,172 @@
+package pager
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/theme/colors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+)
+
+// numbered returns a pager 12 rows high over a file of n lines, "line 0" to "line n-1"
+func numbered(t *testing.T, n int) *Pager {
+	t.Helper()
+	fsys := afero.NewMemMapFs()
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	afero.WriteFile(fsys, "/file.txt", []byte(sb.String()), 0644)
+	file, err := entry.OpenLineFile(fsys, "/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return New(file, "file.txt", colors.Theme{}, 40, 12)
+}
+
+// run executes the command and sends the resulting message to the pager
+func run(p *Pager, cmd tea.Cmd) {
+	if cmd != nil {
+		p.Update(cmd())
+	}
+}
+
+func TestPagerScroll(t *testing.T) {
+	p := numbered(t, 100)
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	run(p, cmd)
+	if p.top != 10 {
+		t.Errorf("top = %d after page down; want 10", p.top)
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if p.top != 9 {
+		t.Errorf("top = %d after up; want 9", p.top)
+	}
+
+	_, cmd = p.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	run(p, cmd)
+	if p.top != 90 {
+		t.Errorf("top = %d after end; want 90", p.top)
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if p.top != 90 {
+		t.Errorf("top = %d after scrolling past the end; want 90", p.top)
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeyHome})
+	if p.top != 0 {
+		t.Errorf("top = %d after home; want 0", p.top)
+	}
+}
+
+func TestPagerScrollIndexesInBackground(t *testing.T) {
+	p := numbered(t, 15)
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if count, _ := p.file.LineCount(); count != 1 || cmd == nil {
+		t.Fatalf("%d lines indexed while scrolling; want the lines to be indexed by a command", count)
+	}
+	if p.top != 10 {
+		t.Errorf("top = %d before the lines are indexed; want 10", p.top)
+	}
+	run(p, cmd)
+	if p.top != 5 {
+		t.Errorf("top = %d after the end of the file was found; want 5", p.top)
+	}
+	if _, cmd := p.Update(tea.KeyMsg{Type: tea.KeyPgDown}); cmd != nil || p.top != 5 {
+		t.Errorf("top = %d after scrolling past the indexed end; want 5 without indexing again", p.top)
+	}
+}
+
+func TestPagerGoToLine(t *testing.T) {
+	p := numbered(t, 100)
+	run(p, p.goToLine("42"))
+	if p.top != 41 {
+		t.Errorf("top = %d; want 41", p.top)
+	}
+	if cmd := p.goToLine("nope"); cmd != nil || p.status == "" {
+		t.Errorf("invalid line number was accepted")
+	}
+}
+
+func TestPagerSearch(t *testing.T) {
+	p := numbered(t, 100)
+	run(p, p.search(`^line 5\d$`))
+	if p.match != 50 {
+		t.Fatalf("match = %d; want 50", p.match)
+	}
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	run(p, cmd)
+	if p.match != 51 {
+		t.Errorf("match = %d after next; want 51", p.match)
+	}
+	_, cmd = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	run(p, cmd)
+	if p.match != 50 {
+		t.Errorf("match = %d after previous; want 50", p.match)
+	}
+	_, cmd = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	run(p, cmd)
+	if p.match != 59 || !strings.Contains(p.status, "continuing at bottom") {
+		t.Errorf("match = %d, status %q after wrapping; want 59", p.match, p.status)
+	}
+	if !strings.Contains(p.View(), p.highlightMatches("line 59")) {
+		t.Errorf("match is not highlighted")
+	}
+}
+
+func TestPagerPrevMatchWraps(t *testing.T) {
+	p := numbered(t, 100)
+	p.pattern = regexp.MustCompile(`^line [05]$`)
+	// without a current match the search starts from the top line and wraps to the bottom
+	p.top = 3
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	run(p, cmd)
+	if p.match != 0 {
+		t.Fatalf("match = %d; want 0", p.match)
+	}
+	_, cmd = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	run(p, cmd)
+	if p.match != 5 || !strings.Contains(p.status, "continuing at bottom") {
+		t.Errorf("match = %d, status %q after a match on the first line; want 5", p.match, p.status)
+	}
+
+	p = numbered(t, 100)
+	p.pattern = regexp.MustCompile(`^line 9\d$`)
+	_, cmd = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	run(p, cmd)
+	if p.match != 99 {
+		t.Errorf("match = %d with no match above the top; want 99", p.match)
+	}
+}
+
+func TestPagerView(t *testing.T) {
+	p := numbered(t, 3)
+	p.pattern = regexp.MustCompile("zzz")
+	view := p.View()
+	if h := lipgloss.Height(view); h != 12 {
+		t.Errorf("view is %d rows high; want 12", h)
+	}
+	if !strings.Contains(view, "line 2") {
+		t.Errorf("view does not contain the last line")
+	}
+	p.wrap = true
+	p.lineNumbers = false
+	p.width = 3
+	if h := lipgloss.Height(p.View()); h != 12 {
+		t.Errorf("wrapped view is %d rows high; want 12", h)
+	}
+}
+
+func TestPagerClose(t *testing.T) {
+	p := numbered(t, 100)
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	p.Close()
+	if msg := cmd(); msg != nil {
+		t.Errorf("got %#v from a count after the pager was closed; want nil", msg)
+	}
+}
@@ -0,0 +1,451 @@
+// Package permissions implements a full screen dialog that changes the mode and owner of
+// the selected entries. The mode is edited in a grid of checkboxes or as an octal number.
+// In recursive mode, files and directories below the selected entries get separate modes.
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Philistino/fman/entry/perms"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+const (
+	labelWidth = 14 // width of the labels of the rows
+	cellWidth  = 12 // width of each checkbox
+)
+
+// Backend changes the entries
+type Backend interface {
+	ChangePermissions(ctx context.Context, names []string, change perms.Change) []error
+}
+
+// Target is an entry whose permissions are changed
+type Target struct {
+	Name  string
+	Mode  perms.Mode
+	IsDir bool
+}
+
+// ClosedMsg is sent when the dialog is closed. Changed reports whether the entries were
+// changed and Errs holds the errors of the entries that could not be changed.
+type ClosedMsg struct {
+	Changed bool
+	Errs    []error
+}
+
+func closedCmd(changed bool, errs []error) tea.Cmd {
+	return func() tea.Msg {
+		return ClosedMsg{Changed: changed, Errs: errs}
+	}
+}
+
+func changeCmd(backend Backend, names []string, change perms.Change) tea.Cmd {
+	return func() tea.Msg {
+		errs := backend.ChangePermissions(context.Background(), names, change)
+		return ClosedMsg{Changed: true, Errs: errs}
+	}
+}
+
+// grid is a mode edited in the dialog
+type grid struct {
+	mode    perms.Mode
+	changed bool // whether the user edited the mode
+	octal   textinput.Model
+}
+
+const (
+	gridFiles = 0 // mode of every entry, or of files in recursive mode
+	gridDirs  = 1 // mode of directories in recursive mode
+)
+
+// rowKind is the kind of a row of the dialog
+type rowKind uint8
+
+const (
+	rowPerm rowKind = iota
+	rowSpecial
+	rowOctal
+	rowOwner
+	rowGroup
+	rowRecursive
+)
+
+// row is a line of the dialog that the cursor can be on
+type row struct {
+	kind  rowKind
+	grid  int
+	class perms.Class // class of a rowPerm
+}
+
+// columns is the number of checkboxes in the rows of the grids
+const columns = 3
+
+var (
+	classNames   = [...]string{"user", "group", "other"}
+	permNames    = [...]string{"read", "write", "exec"}
+	specialNames = [...]string{"setuid", "setgid", "sticky"}
+	specialBits  = [...]perms.Mode{perms.Setuid, perms.Setgid, perms.Sticky}
+)
+
+// Permissions edits the mode and owner of entries
+type Permissions struct {
+	backend Backend
+	targets []Target
+	hasDirs bool
+
+	grids     [2]grid
+	owner     textinput.Model
+	group     textinput.Model
+	recursive bool
+
+	cursor   int // index in rows()
+	col      int // checkbox under the cursor
+	applying bool
+	status   string
+
+	theme  colors.Theme
+	width  int
+	height int
+}
+
+// New creates the dialog for the targets. The grids start with the mode of the first
+// target and of the first directory.
+func New(backend Backend, targets []Target, theme colors.Theme, width, height int) (*Permissions, tea.Cmd) {
+	p := &Permissions{
+		backend: backend,
+		targets: targets,
+		theme:   theme,
+		width:   width,
+		height:  height,
+	}
+	p.grids[gridFiles].mode = targets[0].Mode
+	p.grids[gridDirs].mode = 0o755
+	for i := len(targets) - 1; i >= 0; i-- {
+		if targets[i].IsDir {
+			p.hasDirs = true
+			p.grids[gridDirs].mode = targets[i].Mode
+		}
+	}
+	for i := range p.grids {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 4
+		ti.Width = 5
+		ti.SetValue(p.grids[i].mode.String())
+		p.grids[i].octal = ti
+	}
+	p.owner = newNameInput("user name or id, empty to keep")
+	p.group = newNameInput("group name or id, empty to keep")
+	return p, textinput.Blink
+}
+
+func newNameInput(placeholder string) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.CharLimit = 256
+	ti.Placeholder = placeholder
+	return ti
+}
+
+// SetSize sets the size of the dialog
+func (p *Permissions) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// rows returns the rows of the dialog
+func (p *Permissions) rows() []row {
+	grids := 1
+	if p.recursive {
+		grids = 2
+	}
+	rows := make([]row, 0, 5*grids+3)
+	for g := 0; g < grids; g++ {
+		for c := perms.User; c <= perms.Other; c++ {
+			rows = append(rows, row{kind: rowPerm, grid: g, class: c})
+		}
+		rows = append(rows, row{kind: rowSpecial, grid: g}, row{kind: rowOctal, grid: g})
+	}
+	rows = append(rows, row{kind: rowOwner}, row{kind: rowGroup})
+	if p.hasDirs {
+		rows = append(rows, row{kind: rowRecursive})
+	}
+	return rows
+}
+
+// current returns the row under the cursor
+func (p *Permissions) current() row {
+	rows := p.rows()
+	if p.cursor >= len(rows) {
+		p.cursor = len(rows) - 1
+	}
+	return rows[p.cursor]
+}
+
+// input returns the text input of the row, or nil if it has none
+func (p *Permissions) input(r row) *textinput.Model {
+	switch r.kind {
+	case rowOctal:
+		return &p.grids[r.grid].octal
+	case rowOwner:
+		return &p.owner
+	case rowGroup:
+		return &p.group
+	}
+	return nil
+}
+
+func (p *Permissions) Update(msg tea.Msg) (*Permissions, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.SetSize(msg.Width, msg.Height)
+	case tea.KeyMsg:
+		if p.applying {
+			return p, nil
+		}
+		return p, p.handleKey(msg)
+	default:
+		var cmd tea.Cmd
+		if input := p.input(p.current()); input != nil {
+			*input, cmd = input.Update(msg)
+		}
+		return p, cmd
+	}
+	return p, nil
+}
+
+func (p *Permissions) handleKey(msg tea.KeyMsg) tea.Cmd {
+	p.status = ""
+	switch msg.Type {
+	case tea.KeyEsc:
+		return closedCmd(false, nil)
+	case tea.KeyEnter:
+		return p.apply()
+	case tea.KeyDown, tea.KeyTab:
+		p.moveCursor(1)
+		return nil
+	case tea.KeyUp, tea.KeyShiftTab:
+		p.moveCursor(-1)
+		return nil
+	}
+	r := p.current()
+	if input := p.input(r); input != nil {
+		before := input.Value()
+		var cmd tea.Cmd
+		*input, cmd = input.Update(msg)
+		if r.kind == rowOctal && input.Value() != before {
+			p.parseOctal(r.grid)
+		}
+		return cmd
+	}
+	switch {
+	case msg.Type == tea.KeyLeft:
+		p.col = (p.col + columns - 1) % columns
+	case msg.Type == tea.KeyRight:
+		p.col = (p.col + 1) % columns
+	case msg.Type == tea.KeySpace || msg.String() == "x":
+		p.toggle(r)
+	}
+	return nil
+}
+
+func (p *Permissions) moveCursor(n int) {
+	rows := p.rows()
+	p.cursor = (p.cursor + n + len(rows)) % len(rows)
+	for i := range rows {
+		if input := p.input(rows[i]); input != nil {
+			if i == p.cursor {
+				input.Focus()
+			} else {
+				input.Blur()
+			}
+		}
+	}
+}
+
+// toggle flips the checkbox under the cursor
+func (p *Permissions) toggle(r row) {
+	g := &p.grids[r.grid]
+	switch r.kind {
+	case rowPerm:
+		g.mode = g.mode.Toggle(r.class, perms.Perm(p.col))
+	case rowSpecial:
+		g.mode ^= specialBits[p.col]
+	case rowRecursive:
+		p.recursive = !p.recursive
+		// keep the cursor on the recursive row, which moves when the rows of a grid are added
+		p.cursor = len(p.rows()) - 1
+		return
+	default:
+		return
+	}
+	g.changed = true
+	g.octal.SetValue(g.mode.String())
+}
+
+// parseOctal marks the grid as edited and sets its mode from its octal input if the input
+// is valid
+func (p *Permissions) parseOctal(i int) {
+	g := &p.grids[i]
+	g.changed = true
+	if mode, err := perms.ParseOctal(g.octal.Value()); err == nil {
+		g.mode = mode
+	}
+}
+
+// change returns the change made in the dialog
+func (p *Permissions) change() (perms.Change, error) {
+	if _, err := perms.ParseOctal(p.grids[gridFiles].octal.Value()); err != nil {
+		return perms.Change{}, err
+	}
+	if _, err := perms.ParseOctal(p.grids[gridDirs].octal.Value()); err != nil && p.recursive {
+		return perms.Change{}, err
+	}
+	uid, gid, err := perms.LookupIDs(p.owner.Value(), p.group.Value())
+	if err != nil {
+		return perms.Change{}, err
+	}
+	c := perms.Change{Recursive: p.recursive, UID: uid, GID: gid}
+	if g := p.grids[gridFiles]; g.changed {
+		c.Mode = &g.mode
+	}
+	if g := p.grids[gridDirs]; g.changed && p.recursive {
+		c.DirMode = &g.mode
+	}
+	return c, nil
+}
+
+// apply starts changing the entries
+func (p *Permissions) apply() tea.Cmd {
+	c, err := p.change()
+	if err != nil {
+		p.status = err.Error()
+		return nil
+	}
+	if c.Mode == nil && c.DirMode == nil && c.UID < 0 && c.GID < 0 {
+		p.status = "Nothing was changed"
+		return nil
+	}
+	names := make([]string, len(p.targets))
+	for i, t := range p.targets {
+		names[i] = t.Name
+	}
+	p.applying = true
+	p.status = "Changing..."
+	return changeCmd(p.backend, names, c)
+}
+
+func (p *Permissions) View() string {
+	rows := p.rows()
+	lines := make([]string, 0, len(rows)+6)
+	lines = append(lines, p.headerView())
+	for i, r := range rows {
+		if r.kind == rowPerm && r.class == perms.User {
+			lines = append(lines, p.gridTitleView(r.grid))
+		}
+		lines = append(lines, p.rowView(r, i == p.cursor))
+	}
+	for len(lines) < p.height-1 {
+		lines = append(lines, strings.Repeat(" ", p.width))
+	}
+	lines = append(lines, p.footerView())
+	return strings.Join(lines, "\n")
+}
+
+func (p *Permissions) gridTitleView(i int) string {
+	title := "Mode"
+	if p.recursive {
+		title = [...]string{"Files", "Directories"}[i]
+	}
+	line := layout.FitWidth(" "+title, labelWidth)
+	for _, name := range permNames {
+		line += layout.FitWidth(name, cellWidth)
+	}
+	return layout.FitWidth(lipgloss.NewStyle().Bold(true).Render(line), p.width)
+}
+
+func (p *Permissions) rowView(r row, focused bool) string {
+	style := lipgloss.NewStyle().Foreground(p.theme.TextColor)
+	labelStyle := style
+	if focused {
+		labelStyle = style.Copy().Bold(true).Foreground(p.theme.FolderColor)
+	}
+	g := p.grids[r.grid]
+	var label, value string
+	switch r.kind {
+	case rowPerm:
+		label = "  " + classNames[r.class]
+		for col := 0; col < columns; col++ {
+			value += p.cellView(g.mode.Has(r.class, perms.Perm(col)), "", focused && col == p.col)
+		}
+	case rowSpecial:
+		label = "  special"
+		for col := 0; col < columns; col++ {
+			value += p.cellView(g.mode&specialBits[col] != 0, specialNames[col], focused && col == p.col)
+		}
+	case rowOctal:
+		label = "  octal"
+		value = g.octal.View() + "  " + g.mode.Symbolic()
+		if _, err := perms.ParseOctal(g.octal.Value()); err != nil {
+			value += termenv.String("  not a valid mode").Foreground(termenv.ANSIRed).String()
+		}
+	case rowOwner:
+		label = " Owner"
+		p.owner.Width = p.width - labelWidth - 1
+		value = p.owner.View()
+	case rowGroup:
+		label = " Group"
+		p.group.Width = p.width - labelWidth - 1
+		value = p.group.View()
+	case rowRecursive:
+		label = " Recursive"
+		value = p.cellView(p.recursive, "", focused) + "separate modes for files and directories below"
+	}
+	return layout.FitWidth(labelStyle.Render(layout.FitWidth(label, labelWidth))+value, p.width)
+}
+
+func (p *Permissions) cellView(checked bool, name string, focused bool) string {
+	box := "[ ]"
+	if checked {
+		box = "[x]"
+	}
+	if focused {
+		box = lipgloss.NewStyle().Background(p.theme.SelectedItemBgColor).Foreground(p.theme.SelectedItemFgColor).Render(box)
+	}
+	if name != "" {
+		box += " " + name
+	}
+	return layout.FitWidth(box, cellWidth)
+}
+
+func (p *Permissions) headerView() string {
+	title := lipgloss.NewStyle().Bold(true).Render("Permissions")
+	summary := p.targets[0].Name
+	if len(p.targets) > 1 {
+		summary = fmt.Sprintf("%d entries", len(p.targets))
+	}
+	gap := p.width - lipgloss.Width(title) - lipgloss.Width(summary) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return lipgloss.NewStyle().
+		Background(p.theme.InfobarBgColor).
+		Foreground(p.theme.InfobarFgColor).
+		Inline(true).
+		Render(layout.FitWidth(" "+title+strings.Repeat(" ", gap)+summary+" ", p.width))
+}
+
+func (p *Permissions) footerView() string {
+	if p.status != "" {
+		return layout.FitWidth(p.status, p.width)
+	}
+	return layout.FitWidth(termenv.String("↑/↓ move  ←/→ column  space toggle  enter apply  esc close").Faint().String(), p.width)
+}
@@ -0,0 +1,178 @@
+package permissions
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry/perms"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/theme/colors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+)
+
+func permsFs() afero.Fs {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", nil, 0o644)
+	fsys.MkdirAll("/root/dir/sub", 0o755)
+	fsys.Chmod("/root/dir", 0o700)
+	afero.WriteFile(fsys, "/root/dir/f", nil, 0o644)
+	afero.WriteFile(fsys, "/root/dir/sub/g", nil, 0o640)
+	return fsys
+}
+
+// opened returns the dialog for a and dir in /root on fsys
+func opened(t *testing.T, fsys afero.Fs, dryRun bool) *Permissions {
+	t.Helper()
+	var targets []Target
+	for _, name := range []string{"a", "dir"} {
+		info, err := fsys.Stat("/root/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, Target{Name: name, Mode: perms.FromFileMode(info.Mode()), IsDir: info.IsDir()})
+	}
+	p, _ := New(nav.NewNav(true, false, "/root", fsys, 0, dryRun), targets, colors.Theme{}, 80, 24)
+	return p
+}
+
+// apply presses enter and runs the resulting command
+func apply(t *testing.T, p *Permissions) ClosedMsg {
+	t.Helper()
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("expected a command, status is %q", p.status)
+	}
+	msg, ok := cmd().(ClosedMsg)
+	if !ok || !msg.Changed {
+		t.Fatalf("expected the entries to be changed, got %#v", msg)
+	}
+	return msg
+}
+
+// setOctal replaces the octal input under the cursor with s
+func setOctal(p *Permissions, s string) {
+	for i := 0; i < 4; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+}
+
+// checkModes checks the permission bits of the paths on fsys
+func checkModes(t *testing.T, fsys afero.Fs, modes map[string]fs.FileMode) {
+	t.Helper()
+	for path, exp := range modes {
+		info, err := fsys.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != exp {
+			t.Errorf("%s: expected mode %o, got %o", path, exp, got)
+		}
+	}
+}
+
+func TestPermissionsGrid(t *testing.T) {
+	fsys := permsFs()
+	p := opened(t, fsys, false)
+	// toggle group write and other read
+	p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	p.Update(tea.KeyMsg{Type: tea.KeyRight})
+	p.Update(tea.KeyMsg{Type: tea.KeySpace})
+	p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	p.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	p.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if !strings.Contains(p.View(), "0660") {
+		t.Errorf("expected the octal mode to follow the grid, got\n%s", p.View())
+	}
+	if msg := apply(t, p); len(msg.Errs) != 0 {
+		t.Fatalf("unexpected errors %v", msg.Errs)
+	}
+	// without recursion the mode is given to the selected entries only
+	checkModes(t, fsys, map[string]fs.FileMode{
+		"/root/a":       0o660,
+		"/root/dir":     0o660,
+		"/root/dir/f":   0o644,
+		"/root/dir/sub": 0o755,
+	})
+}
+
+func TestPermissionsRecursiveModes(t *testing.T) {
+	fsys := permsFs()
+	p := opened(t, fsys, false)
+	// the octal input of the files is the fifth row
+	for i := 0; i < 4; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	setOctal(p, "600")
+	// the recursive checkbox is below the owner and group
+	for i := 0; i < 3; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if !strings.Contains(p.View(), "Directories") {
+		t.Fatalf("expected a grid for directories in recursive mode, got\n%s", p.View())
+	}
+	// the octal input of the directories is above the owner and group
+	for i := 0; i < 3; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyUp})
+	}
+	setOctal(p, "750")
+	if msg := apply(t, p); len(msg.Errs) != 0 {
+		t.Fatalf("unexpected errors %v", msg.Errs)
+	}
+	checkModes(t, fsys, map[string]fs.FileMode{
+		"/root/a":         0o600,
+		"/root/dir":       0o750,
+		"/root/dir/f":     0o600,
+		"/root/dir/sub":   0o750,
+		"/root/dir/sub/g": 0o600,
+	})
+}
+
+func TestPermissionsRecursiveKeepsDirModes(t *testing.T) {
+	fsys := permsFs()
+	p := opened(t, fsys, false)
+	for i := 0; i < 4; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	setOctal(p, "600")
+	for i := 0; i < 3; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if msg := apply(t, p); len(msg.Errs) != 0 {
+		t.Fatalf("unexpected errors %v", msg.Errs)
+	}
+	// the mode of the directories was not edited, so they keep their own
+	checkModes(t, fsys, map[string]fs.FileMode{
+		"/root/dir":       0o700,
+		"/root/dir/f":     0o600,
+		"/root/dir/sub":   0o755,
+		"/root/dir/sub/g": 0o600,
+	})
+}
+
+func TestPermissionsInvalid(t *testing.T) {
+	fsys := permsFs()
+	p := opened(t, fsys, false)
+	for i := 0; i < 4; i++ {
+		p.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9")})
+	if _, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("expected an invalid mode to be refused")
+	}
+	p = opened(t, fsys, false)
+	if _, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("expected nothing to be done when nothing was changed")
+	}
+
+	p = opened(t, fsys, true)
+	p.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if msg := apply(t, p); len(msg.Errs) != 1 || msg.Errs[0].Error() != "dry run" {
+		t.Errorf("expected the change to fail in dry run mode, got %v", msg.Errs)
+	}
+	checkModes(t, fsys, map[string]fs.FileMode{"/root/a": 0o644, "/root/dir": 0o700})
+}
@@ -0,0 +1,240 @@
+package preview
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/diff"
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// DiffReadyMsg is sent when the comparison of two entries is complete
+type DiffReadyMsg struct {
+	Comparison   nav.Comparison
+	HighlightedA []string // syntax highlighted lines of the first file
+	HighlightedB []string // syntax highlighted lines of the second file
+	Err          error
+}
+
+// NewDiffReadyMsg highlights the compared files and returns the message
+// to show the comparison in the preview. It can be slow for large files
+// so it should be called from a command.
+func NewDiffReadyMsg(cmp nav.Comparison, err error) DiffReadyMsg {
+	msg := DiffReadyMsg{Comparison: cmp, Err: err}
+	if err != nil || cmp.IsDir {
+		return msg
+	}
+	msg.HighlightedA = entry.HighlightLines(cmp.NameA, cmp.File.LinesA)
+	msg.HighlightedB = entry.HighlightLines(cmp.NameB, cmp.File.LinesB)
+	return msg
+}
+
+var (
+	diffDelete = termenv.ANSIRed
+	diffInsert = termenv.ANSIGreen
+	diffChange = termenv.ANSIYellow
+	diffHunk   = termenv.ANSICyan
+)
+
+// diffView renders a comparison as a unified or side-by-side diff
+type diffView struct {
+	msg        DiffReadyMsg
+	sideBySide bool
+	hunkLines  []int // line offsets of the hunks in the rendered content
+}
+
+func newDiffView(msg DiffReadyMsg) *diffView {
+	return &diffView{msg: msg}
+}
+
+// title describes the comparison for the file info section
+func (d *diffView) title() string {
+	cmp := d.msg.Comparison
+	var summary string
+	if cmp.IsDir {
+		summary = fmt.Sprintf("%d differences", len(cmp.Dirs))
+	} else {
+		summary = fmt.Sprintf("%d hunks", len(cmp.File.Hunks))
+	}
+	return fmt.Sprintf("%s ↔ %s (%s)", cmp.NameA, cmp.NameB, summary)
+}
+
+// render renders the diff to fit the width and records where each hunk starts
+func (d *diffView) render(width int) string {
+	d.hunkLines = d.hunkLines[:0]
+	cmp := d.msg.Comparison
+	switch {
+	case cmp.IsDir && len(cmp.Dirs) == 0:
+		return "Directories are identical"
+	case cmp.IsDir:
+		return d.renderDirs()
+	case len(cmp.File.Hunks) == 0:
+		return "Files are identical"
+	case d.sideBySide:
+		return d.renderSideBySide(width)
+	default:
+		return d.renderUnified()
+	}
+}
+
+func (d *diffView) renderDirs() string {
+	cmp := d.msg.Comparison
+	lines := make([]string, 0, len(cmp.Dirs)+1)
+	lines = append(lines, fmt.Sprintf("A: %s   B: %s", cmp.NameA, cmp.NameB))
+	for _, e := range cmp.Dirs {
+		d.hunkLines = append(d.hunkLines, len(lines))
+		name := e.Path
+		if e.IsDir {
+			name += "/"
+		}
+		var marker termenv.Style
+		switch e.Status {
+		case diff.OnlyInA:
+			marker = termenv.String("- ").Foreground(diffDelete)
+		case diff.OnlyInB:
+			marker = termenv.String("+ ").Foreground(diffInsert)
+		default:
+			marker = termenv.String("~ ").Foreground(diffChange)
+		}
+		status := termenv.String(fmt.Sprintf("%-10s", e.Status)).Italic()
+		lines = append(lines, marker.String()+status.String()+name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *diffView) renderUnified() string {
+	cmp := d.msg.Comparison
+	numWidth := d.numberWidth()
+	lines := make([]string, 0, 2*len(cmp.File.Hunks))
+	lines = append(lines,
+		termenv.String("--- "+cmp.NameA).Foreground(diffDelete).String(),
+		termenv.String("+++ "+cmp.NameB).Foreground(diffInsert).String(),
+	)
+	for _, h := range cmp.File.Hunks {
+		d.hunkLines = append(d.hunkLines, len(lines))
+		lines = append(lines, termenv.String(h.Header()).Foreground(diffHunk).String())
+		for _, e := range h.Edits {
+			gutter := lineNumber(e.A, numWidth) + " " + lineNumber(e.B, numWidth) + " "
+			switch e.Op {
+			case diff.Equal:
+				lines = append(lines, termenv.String(gutter+"  ").Faint().String()+d.msg.HighlightedB[e.B])
+			case diff.Delete:
+				lines = append(lines, termenv.String(gutter+"- ").Foreground(diffDelete).String()+d.msg.HighlightedA[e.A])
+			case diff.Insert:
+				lines = append(lines, termenv.String(gutter+"+ ").Foreground(diffInsert).String()+d.msg.HighlightedB[e.B])
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *diffView) renderSideBySide(width int) string {
+	cmp := d.msg.Comparison
+	numWidth := d.numberWidth()
+	sep := termenv.String(" │ ").Faint().String()
+	// the right side gets the extra cell if the width is odd
+	left := (width - lipgloss.Width(sep)) / 2
+	if left < numWidth+4 {
+		left = numWidth + 4
+	}
+	right := width - lipgloss.Width(sep) - left
+	if right < numWidth+4 {
+		right = numWidth + 4
+	}
+
+	side := func(idx int, op diff.Op, highlighted []string, width int) string {
+		if idx < 0 {
+			return strings.Repeat(" ", width)
+		}
+		gutter := lineNumber(idx, numWidth) + " "
+		var marker string
+		switch op {
+		case diff.Delete:
+			marker = termenv.String(gutter + "- ").Foreground(diffDelete).String()
+		case diff.Insert:
+			marker = termenv.String(gutter + "+ ").Foreground(diffInsert).String()
+		default:
+			marker = termenv.String(gutter + "  ").Faint().String()
+		}
+		return marker + layout.FitWidth(highlighted[idx], width-numWidth-3)
+	}
+
+	lines := []string{
+		layout.FitWidth(termenv.String(cmp.NameA).Foreground(diffDelete).String(), left) + sep +
+			termenv.String(cmp.NameB).Foreground(diffInsert).String(),
+	}
+	for _, h := range cmp.File.Hunks {
+		d.hunkLines = append(d.hunkLines, len(lines))
+		lines = append(lines, termenv.String(h.Header()).Foreground(diffHunk).String())
+		for i := 0; i < len(h.Edits); {
+			if h.Edits[i].Op == diff.Equal {
+				e := h.Edits[i]
+				lines = append(lines, side(e.A, diff.Equal, d.msg.HighlightedA, left)+sep+side(e.B, diff.Equal, d.msg.HighlightedB, right))
+				i++
+				continue
+			}
+			// pair up a run of deletions with the insertions that follow it
+			var deleted, inserted []int
+			for ; i < len(h.Edits) && h.Edits[i].Op == diff.Delete; i++ {
+				deleted = append(deleted, h.Edits[i].A)
+			}
+			for ; i < len(h.Edits) && h.Edits[i].Op == diff.Insert; i++ {
+				inserted = append(inserted, h.Edits[i].B)
+			}
+			for j := 0; j < len(deleted) || j < len(inserted); j++ {
+				a, b := -1, -1
+				if j < len(deleted) {
+					a = deleted[j]
+				}
+				if j < len(inserted) {
+					b = inserted[j]
+				}
+				lines = append(lines, side(a, diff.Delete, d.msg.HighlightedA, left)+sep+side(b, diff.Insert, d.msg.HighlightedB, right))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// numberWidth returns the number of digits needed for the line numbers
+func (d *diffView) numberWidth() int {
+	n := len(d.msg.Comparison.File.LinesA)
+	if len(d.msg.Comparison.File.LinesB) > n {
+		n = len(d.msg.Comparison.File.LinesB)
+	}
+	return len(strconv.Itoa(n))
+}
+
+// nextHunk returns the offset of the first hunk after the offset
+func (d *diffView) nextHunk(offset int) (int, bool) {
+	for _, line := range d.hunkLines {
+		if line > offset {
+			return line, true
+		}
+	}
+	return 0, false
+}
+
+// prevHunk returns the offset of the last hunk before the offset
+func (d *diffView) prevHunk(offset int) (int, bool) {
+	for i := len(d.hunkLines) - 1; i >= 0; i-- {
+		if d.hunkLines[i] < offset {
+			return d.hunkLines[i], true
+		}
+	}
+	return 0, false
+}
+
+// lineNumber formats a zero based line index as a one based line number
+// right aligned to width. Missing lines (-1) are rendered as blanks.
+func lineNumber(idx int, width int) string {
+	if idx < 0 {
+		return strings.Repeat(" ", width)
+	}
+	return fmt.Sprintf("%*d", width, idx+1)
+}
@@ -0,0 +1,90 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry/diff"
+	"github.com/Philistino/fman/nav"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func testComparison() nav.Comparison {
+	a := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+	b := []string{"a", "B", "c", "d", "e", "f", "g", "h", "i", "j", "k", "L", "m"}
+	return nav.Comparison{
+		NameA: "a.txt",
+		NameB: "b.txt",
+		File: diff.FileDiff{
+			LinesA: a,
+			LinesB: b,
+			Hunks:  diff.Hunks(diff.Lines(a, b), diff.DefaultContext),
+		},
+	}
+}
+
+func TestDiffViewHunks(t *testing.T) {
+	d := newDiffView(NewDiffReadyMsg(testComparison(), nil))
+	for _, sideBySide := range []bool{false, true} {
+		d.sideBySide = sideBySide
+		content := d.render(60)
+		lines := strings.Split(content, "\n")
+		if len(d.hunkLines) != 2 {
+			t.Fatalf("sideBySide=%v: got %d hunks; want 2", sideBySide, len(d.hunkLines))
+		}
+		for _, offset := range d.hunkLines {
+			if !strings.Contains(lines[offset], "@@") {
+				t.Errorf("sideBySide=%v: line %d is not a hunk header: %q", sideBySide, offset, lines[offset])
+			}
+		}
+		next, ok := d.nextHunk(d.hunkLines[0])
+		if !ok || next != d.hunkLines[1] {
+			t.Errorf("sideBySide=%v: nextHunk = %d, %v; want %d", sideBySide, next, ok, d.hunkLines[1])
+		}
+		if _, ok := d.nextHunk(d.hunkLines[1]); ok {
+			t.Errorf("sideBySide=%v: nextHunk after the last hunk should fail", sideBySide)
+		}
+		prev, ok := d.prevHunk(d.hunkLines[1])
+		if !ok || prev != d.hunkLines[0] {
+			t.Errorf("sideBySide=%v: prevHunk = %d, %v; want %d", sideBySide, prev, ok, d.hunkLines[0])
+		}
+	}
+}
+
+func TestDiffViewSideBySideWidth(t *testing.T) {
+	d := newDiffView(NewDiffReadyMsg(testComparison(), nil))
+	d.sideBySide = true
+	for i, line := range strings.Split(d.render(60), "\n") {
+		if strings.Contains(line, "@@") || i == 0 {
+			continue
+		}
+		if w := lipgloss.Width(line); w != 60 {
+			t.Errorf("line %d is %d cells wide; want 60: %q", i, w, line)
+		}
+	}
+}
+
+func TestDiffViewDirs(t *testing.T) {
+	cmp := nav.Comparison{
+		NameA: "a",
+		NameB: "b",
+		IsDir: true,
+		Dirs: []diff.EntryDiff{
+			{Path: "only", Status: diff.OnlyInA},
+			{Path: "sub", IsDir: true, Status: diff.OnlyInB},
+		},
+	}
+	d := newDiffView(NewDiffReadyMsg(cmp, nil))
+	content := d.render(60)
+	if !strings.Contains(content, "only") || !strings.Contains(content, "sub/") {
+		t.Errorf("missing entries in %q", content)
+	}
+	if len(d.hunkLines) != 2 {
+		t.Errorf("got %d entries to jump to; want 2", len(d.hunkLines))
+	}
+
+	cmp.Dirs = nil
+	if got := newDiffView(NewDiffReadyMsg(cmp, nil)).render(60); got != "Directories are identical" {
+		t.Errorf("got %q for identical directories", got)
+	}
+}
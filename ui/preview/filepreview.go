@@ -43,6 +43,8 @@ const (
 
 type FilePreview struct {
 	theme    colors.Theme
+	styles   theme.StyleSet
+	keys     keys.KeyMap
 	viewPort viewport.Model // viewport for scrolling preview
 
 	width         int
@@ -57,13 +59,23 @@ type FilePreview struct {
 	spinner spinner.Model
 
 	state previewState
+
+	diff *diffView // set while a comparison is shown instead of the preview
+
+	follow   *follower // set while the end of the file is followed
+	followID int       // incremented for every follow session to ignore stale reads
+
+	gitMode gitMode  // shows the git log or diff of the file instead of its contents
+	history *history // set while the commits of the file are shown
+
+	output *CommandOutputMsg // set while the output of a user command is shown
 }
 
-func NewFilePreviewer(theme colors.Theme, previewDelay int) *FilePreview {
+func NewFilePreviewer(scheme colors.Theme, styles theme.StyleSet, keyMap keys.KeyMap, previewDelay int) *FilePreview {
 
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(theme.SelectedItemBgColor)
+	s.Style = lipgloss.NewStyle().Foreground(scheme.SelectedItemBgColor)
 
 	// set loading delay to 1.5x the preview delay or 500ms, whichever is less
 	previewDelay = previewDelay * 3 / 2
@@ -73,7 +85,9 @@ func NewFilePreviewer(theme colors.Theme, previewDelay int) *FilePreview {
 	f := &FilePreview{
 		height:       10,
 		width:        10,
-		theme:        theme,
+		theme:        scheme,
+		styles:       styles,
+		keys:         keyMap,
 		viewPort:     viewport.New(1_000_000, 10), // set width super wide to avoid text wrapping
 		state:        previewStateLoadingDirPre,
 		loadingDelay: time.Duration(time.Millisecond * time.Duration(previewDelay)),
@@ -88,6 +102,11 @@ func (fp *FilePreview) Init() tea.Cmd {
 
 func (fp *FilePreview) setNewEntry(entry entry.Entry) tea.Cmd {
 	fp.entry = entry
+	fp.diff = nil
+	fp.follow = nil
+	fp.history = nil
+	fp.output = nil
+	fp.resizeViewPort()
 	// handle preview context cancellation for previous file
 	if fp.previewCancel != nil {
 		fp.previewCancel()
@@ -101,23 +120,25 @@ func (fp *FilePreview) setNewEntry(entry entry.Entry) tea.Cmd {
 		fp.state = previewStatePreviewing
 		return nil
 	}
-	if entry.Size() == 0 {
+	if entry.Size() == 0 && fp.gitMode == gitModeOff {
 		fp.viewPort.SetContent(fp.renderNoPreview("Empty file"))
 		fp.state = previewStatePreviewing
 		return nil
 	}
 
-	// if file is not immediately identifiable as text and is larger than 1MB, don't preview
-	if !fp.isText() && entry.Size() > 1_000_000 {
-		fp.viewPort.SetContent(fp.renderNoPreview("No preview available"))
-		fp.state = previewStatePreviewing
-		return nil
-	}
-
 	ctx, cancel := context.WithCancel(context.Background())
 	fp.previewCancel = cancel
 
-	cmd := message.GetPreviewCmd(ctx, fp.getFullPath())
+	var cmd tea.Cmd
+	switch fp.gitMode {
+	case gitModeLog:
+		fp.history = &history{path: fp.getFullPath()}
+		cmd = message.GetGitLogCmd(ctx, fp.getFullPath())
+	case gitModeDiff:
+		cmd = message.GetGitDiffCmd(ctx, fp.getFullPath())
+	default:
+		cmd = message.GetPreviewCmd(ctx, fp.getFullPath())
+	}
 
 	fp.state = previewStateLoadingFilePre
 
@@ -180,6 +201,11 @@ func (fp *FilePreview) handleDirChangedMsg(msg message.DirChangedMsg) tea.Cmd {
 	}
 
 	fp.dirPath = msg.Path()
+	fp.diff = nil
+	fp.follow = nil
+	fp.history = nil
+	fp.output = nil
+	fp.resizeViewPort()
 
 	if len(msg.Entries()) == 0 {
 		fp.state = previewStatePreviewing
@@ -194,7 +220,7 @@ func (fp *FilePreview) handleDirChangedMsg(msg message.DirChangedMsg) tea.Cmd {
 
 func (fp *FilePreview) handlePreviewReadyMsg(msg PreviewReadyMsg) {
 	// check that the path matches so we don't set the current preview based on the previous file
-	if msg.Path != fp.getFullPath() {
+	if msg.Path != fp.getFullPath() || fp.diff != nil || fp.follow != nil || fp.output != nil || fp.gitMode != gitModeOff {
 		return
 	}
 	if msg.Err != nil {
@@ -206,6 +232,43 @@ func (fp *FilePreview) handlePreviewReadyMsg(msg PreviewReadyMsg) {
 	fp.state = previewStatePreviewing
 }
 
+// handleDiffReadyMsg replaces the preview with the comparison
+func (fp *FilePreview) handleDiffReadyMsg(msg DiffReadyMsg) {
+	if msg.Err != nil {
+		return
+	}
+	if fp.previewCancel != nil {
+		fp.previewCancel()
+		fp.previewCancel = nil
+	}
+	fp.follow = nil
+	sideBySide := fp.diff != nil && fp.diff.sideBySide
+	fp.diff = newDiffView(msg)
+	fp.resizeViewPort()
+	fp.diff.sideBySide = sideBySide
+	fp.viewPort.SetContent(fp.diff.render(fp.width - margin))
+	fp.viewPort.SetYOffset(0)
+	fp.state = previewStatePreviewing
+}
+
+// handleDiffKeys handles hunk navigation and switching the diff layout
+func (fp *FilePreview) handleDiffKeys(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, fp.keys.NextHunk):
+		if offset, ok := fp.diff.nextHunk(fp.viewPort.YOffset); ok {
+			fp.viewPort.SetYOffset(offset)
+		}
+	case key.Matches(msg, fp.keys.PrevHunk):
+		if offset, ok := fp.diff.prevHunk(fp.viewPort.YOffset); ok {
+			fp.viewPort.SetYOffset(offset)
+		}
+	case key.Matches(msg, fp.keys.ToggleDiffLayout):
+		fp.diff.sideBySide = !fp.diff.sideBySide
+		fp.viewPort.SetContent(fp.diff.render(fp.width - margin))
+		fp.viewPort.SetYOffset(0)
+	}
+}
+
 func (fp *FilePreview) Update(msg tea.Msg) (*FilePreview, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
@@ -222,13 +285,44 @@ func (fp *FilePreview) Update(msg tea.Msg) (*FilePreview, tea.Cmd) {
 		fp.handleShowSpinDirMsg(msg)
 	case PreviewReadyMsg:
 		fp.handlePreviewReadyMsg(msg)
+	case DiffReadyMsg:
+		fp.handleDiffReadyMsg(msg)
+	case CommandOutputMsg:
+		fp.handleCommandOutputMsg(msg)
+	case message.ToggleFollowMsg:
+		cmd = fp.toggleFollow()
+		cmds = append(cmds, cmd)
+	case TailReadyMsg:
+		cmd = fp.handleTailReadyMsg(msg)
+		cmds = append(cmds, cmd)
+	case followTickMsg:
+		cmd = fp.handleFollowTickMsg(msg)
+		cmds = append(cmds, cmd)
+	case message.ToggleGitPreviewMsg:
+		cmd = fp.toggleGitMode()
+		cmds = append(cmds, cmd)
+	case GitLogReadyMsg:
+		cmd = fp.handleGitLogReadyMsg(msg)
+		cmds = append(cmds, cmd)
+	case RevisionReadyMsg:
+		fp.handleRevisionReadyMsg(msg)
+	case GitDiffReadyMsg:
+		cmd = fp.handleGitDiffReadyMsg(msg)
+		cmds = append(cmds, cmd)
 	case tea.KeyMsg:
-		if key.Matches(msg, keys.Map.ScrollPreviewDown) {
+		if key.Matches(msg, fp.keys.ScrollPreviewDown) {
 			fp.viewPort.LineDown(1)
 		}
-		if key.Matches(msg, keys.Map.ScrollPreviewUp) {
+		if key.Matches(msg, fp.keys.ScrollPreviewUp) {
 			fp.viewPort.LineUp(1)
 		}
+		if fp.diff != nil {
+			fp.handleDiffKeys(msg)
+		}
+		if fp.showsHistory() {
+			cmd = fp.handleHistoryKeys(msg)
+			cmds = append(cmds, cmd)
+		}
 	case spinner.TickMsg:
 		fp.spinner, cmd = fp.spinner.Update(msg)
 		cmds = append(cmds, cmd)
@@ -240,6 +334,25 @@ func (fp *FilePreview) fileInfoView() string {
 	str := strings.Builder{}
 	str.WriteString(termenv.String(strings.Repeat("-", fp.width-margin)).Foreground(termenv.RGBColor(fp.theme.InfobarBgColor)).String())
 	str.WriteByte('\n')
+	if fp.follow != nil {
+		str.WriteString(termenv.String("Following ").Italic().String())
+		str.WriteString(fp.entry.Name())
+		return str.String()
+	}
+	if fp.diff != nil {
+		str.WriteString(termenv.String("Comparing ").Italic().String())
+		str.WriteString(fp.diff.title())
+		return str.String()
+	}
+	if fp.output != nil {
+		str.WriteString(termenv.String("Output of ").Italic().String())
+		str.WriteString(fp.output.Name)
+		return str.String()
+	}
+	if fp.gitMode != gitModeOff {
+		str.WriteString(fp.gitInfoView())
+		return str.String()
+	}
 	str.WriteString(termenv.String("Modified ").Italic().String())
 	str.WriteString(fp.entry.ModifyTime)
 	return str.String()
@@ -255,8 +368,11 @@ func (fp *FilePreview) View() string {
 	} else {
 		mainView = fp.viewPort.View()
 	}
+	if fp.showsHistory() {
+		mainView = fp.historyView() + "\n" + mainView
+	}
 
-	return theme.EntryInfoStyle.Render(
+	return fp.styles.EntryInfoStyle.Render(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
 			previewStyle.
@@ -289,7 +405,7 @@ func (fp *FilePreview) renderText(text string) string {
 		lipgloss.Center,
 		text,
 		lipgloss.WithWhitespaceChars("."),
-		lipgloss.WithWhitespaceForeground(theme.EvenItemStyle.GetBackground()),
+		lipgloss.WithWhitespaceForeground(fp.styles.EvenItemStyle.GetBackground()),
 	)
 }
 
@@ -300,20 +416,12 @@ func (fp *FilePreview) getFullPath() string {
 	return filepath.Join(fp.dirPath, fp.entry.Name())
 }
 
-// isText returns true if the file is likely a text file
-func (fp *FilePreview) isText() bool {
-	types := []string{"application/xml", "application/json", "application/javascript", "application/x-sh", "text/"}
-	for _, t := range types {
-		if strings.Contains(fp.entry.MimeType, t) {
-			return true
-		}
-	}
-	return false
-}
-
 // SetWidth sets the width of the preview
 func (fp *FilePreview) SetWidth(width int) {
 	fp.width = width
+	if fp.diff != nil && fp.diff.sideBySide {
+		fp.viewPort.SetContent(fp.diff.render(fp.width - margin))
+	}
 }
 
 // Height returns the height of the preview
@@ -323,6 +431,11 @@ func (fp *FilePreview) Height() int {
 
 // SetHeight sets the height of the preview
 func (fp *FilePreview) SetHeight(height int) {
-	fp.viewPort.Height = height - lipgloss.Height(fp.fileInfoView()) - margin
 	fp.height = height
+	fp.resizeViewPort()
+}
+
+// resizeViewPort fits the viewport below the list of commits, if it is shown
+func (fp *FilePreview) resizeViewPort() {
+	fp.viewPort.Height = fp.height - lipgloss.Height(fp.fileInfoView()) - margin - fp.historyHeight()
 }
@@ -0,0 +1,120 @@
+package preview
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/message"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// followInterval is how often a followed file is checked for new data
+const followInterval = 500 * time.Millisecond
+
+// maxFollowLines is the number of lines of a followed file kept in the preview
+const maxFollowLines = 5000
+
+// TailReadyMsg is sent when the data appended to a followed file has been read
+type TailReadyMsg struct {
+	ID    int // identifies the follow session
+	State entry.TailState
+	Data  string
+	Reset bool // if true, Data replaces the content instead of being appended to it
+	Err   error
+}
+
+// followTickMsg is sent when it is time to check a followed file again
+type followTickMsg struct {
+	ID int
+}
+
+// follower holds the state of a file that is being followed like tail -f
+type follower struct {
+	id      int
+	state   entry.TailState
+	content strings.Builder
+	lines   int
+}
+
+// append adds data to the content, dropping the oldest lines once there are too many
+func (f *follower) append(data string, reset bool) {
+	if reset {
+		f.content.Reset()
+		f.lines = 0
+	}
+	f.content.WriteString(data)
+	f.lines += strings.Count(data, "\n")
+	if f.lines <= maxFollowLines {
+		return
+	}
+	content := f.content.String()
+	for ; f.lines > maxFollowLines; f.lines-- {
+		content = content[strings.IndexByte(content, '\n')+1:]
+	}
+	f.content.Reset()
+	f.content.WriteString(content)
+}
+
+func followTickCmd(id int) tea.Cmd {
+	return tea.Tick(followInterval, func(time.Time) tea.Msg {
+		return followTickMsg{ID: id}
+	})
+}
+
+// toggleFollow starts or stops following the current file
+func (fp *FilePreview) toggleFollow() tea.Cmd {
+	if fp.follow != nil {
+		fp.follow = nil
+		return fp.setNewEntry(fp.entry)
+	}
+	if fp.entry.IsDir() {
+		return nil
+	}
+	if fp.previewCancel != nil {
+		fp.previewCancel()
+		fp.previewCancel = nil
+	}
+	fp.diff = nil
+	fp.followID++
+	fp.follow = &follower{id: fp.followID, state: entry.TailState{Path: fp.getFullPath()}}
+	fp.resizeViewPort()
+	fp.viewPort.SetContent("")
+	fp.state = previewStatePreviewing
+	return message.ReadTailCmd(fp.follow.id, fp.follow.state)
+}
+
+func (fp *FilePreview) handleTailReadyMsg(msg TailReadyMsg) tea.Cmd {
+	if fp.follow == nil || msg.ID != fp.follow.id {
+		return nil
+	}
+	if errors.Is(msg.Err, fs.ErrNotExist) {
+		// the file may be missing for a moment while it is rotated
+		return followTickCmd(msg.ID)
+	}
+	if msg.Err != nil {
+		fp.follow = nil
+		fp.viewPort.SetContent(fp.renderNoPreview("File can no longer be followed"))
+		return message.NewNotificationCmd(msg.Err.Error())
+	}
+	fp.follow.state = msg.State
+	if msg.Data != "" || msg.Reset {
+		// keep scrolling with the new data unless the user has scrolled up
+		atBottom := fp.viewPort.AtBottom()
+		fp.follow.append(msg.Data, msg.Reset)
+		fp.viewPort.SetContent(fp.follow.content.String())
+		if atBottom {
+			fp.viewPort.GotoBottom()
+		}
+	}
+	return followTickCmd(msg.ID)
+}
+
+func (fp *FilePreview) handleFollowTickMsg(msg followTickMsg) tea.Cmd {
+	if fp.follow == nil || msg.ID != fp.follow.id {
+		return nil
+	}
+	return message.ReadTailCmd(fp.follow.id, fp.follow.state)
+}
@@ -0,0 +1,24 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFollowerAppend(t *testing.T) {
+	f := &follower{}
+	f.append("a\nb\n", false)
+	f.append("c\n", false)
+	if got := f.content.String(); got != "a\nb\nc\n" {
+		t.Errorf("got %q after appending", got)
+	}
+	f.append("x\n", true)
+	if got := f.content.String(); got != "x\n" {
+		t.Errorf("got %q after reset", got)
+	}
+
+	f.append(strings.Repeat("line\n", maxFollowLines+10), true)
+	if f.lines != maxFollowLines || strings.Count(f.content.String(), "\n") != maxFollowLines {
+		t.Errorf("got %d lines; want %d", f.lines, maxFollowLines)
+	}
+}
@@ -0,0 +1,215 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Philistino/fman/entry/git"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// gitMode is what the preview shows of the selected file. The mode is kept when
+// another file is selected.
+type gitMode uint8
+
+const (
+	gitModeOff  gitMode = iota // the contents of the file
+	gitModeLog                 // the commits that changed the file and the file at the selected commit
+	gitModeDiff                // the changes to the file against HEAD
+	gitModeCount
+)
+
+// GitLogReadyMsg is sent when the commits that changed a file have been read
+type GitLogReadyMsg struct {
+	Path      string
+	Revisions []git.Revision
+	Err       error
+}
+
+// RevisionReadyMsg is sent when the preview of a file at a commit has been read
+type RevisionReadyMsg struct {
+	Path    string
+	Hash    string
+	Preview string
+	Err     error
+}
+
+// GitDiffReadyMsg is sent when the changes to a file against HEAD have been read
+type GitDiffReadyMsg struct {
+	Path    string
+	Preview string
+	Err     error
+}
+
+// history holds the commits that changed the previewed file
+type history struct {
+	path      string
+	revisions []git.Revision
+	cursor    int
+}
+
+func (h *history) selected() git.Revision {
+	return h.revisions[h.cursor]
+}
+
+// toggleGitMode switches to the next git mode and reloads the preview
+func (fp *FilePreview) toggleGitMode() tea.Cmd {
+	fp.gitMode = (fp.gitMode + 1) % gitModeCount
+	return fp.setNewEntry(fp.entry)
+}
+
+// showsHistory reports whether the commits of the file are listed above the preview
+func (fp *FilePreview) showsHistory() bool {
+	return fp.history != nil && len(fp.history.revisions) > 0 && fp.diff == nil && fp.follow == nil
+}
+
+// historyHeight returns the number of rows of the list of commits, including the
+// separator below it. The list takes up to a third of the preview.
+func (fp *FilePreview) historyHeight() int {
+	if !fp.showsHistory() {
+		return 0
+	}
+	rows := (fp.height - margin) / 3
+	if rows < 1 {
+		rows = 1
+	}
+	if n := len(fp.history.revisions); n < rows {
+		rows = n
+	}
+	return rows + 1
+}
+
+func (fp *FilePreview) handleGitLogReadyMsg(msg GitLogReadyMsg) tea.Cmd {
+	if fp.history == nil || msg.Path != fp.history.path || fp.diff != nil || fp.follow != nil {
+		return nil
+	}
+	fp.state = previewStatePreviewing
+	switch {
+	case errors.Is(msg.Err, git.ErrNotRepository):
+		fp.history = nil
+		fp.viewPort.SetContent(fp.renderNoPreview("Not inside a git work tree"))
+		return nil
+	case msg.Err != nil:
+		fp.history = nil
+		fp.viewPort.SetContent(fp.renderNoPreview("No git history available"))
+		return message.NewNotificationCmd("git log: " + msg.Err.Error())
+	case len(msg.Revisions) == 0:
+		fp.history = nil
+		fp.viewPort.SetContent(fp.renderNoPreview("No commits"))
+		return nil
+	}
+	fp.history.revisions = msg.Revisions
+	fp.resizeViewPort()
+	return fp.selectRevision(0)
+}
+
+// selectRevision moves the cursor of the list of commits and requests the preview of
+// the file at the selected commit
+func (fp *FilePreview) selectRevision(i int) tea.Cmd {
+	if fp.previewCancel != nil {
+		fp.previewCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	fp.previewCancel = cancel
+	fp.history.cursor = i
+	fp.viewPort.SetYOffset(0)
+	fp.state = previewStateLoadingFilePre
+	return tea.Batch(
+		message.GetRevisionPreviewCmd(ctx, fp.history.path, fp.history.selected()),
+		fileLoadingCmd(fp.history.path, fp.loadingDelay),
+	)
+}
+
+func (fp *FilePreview) handleRevisionReadyMsg(msg RevisionReadyMsg) {
+	if !fp.showsHistory() || msg.Path != fp.history.path || msg.Hash != fp.history.selected().Hash {
+		return
+	}
+	switch {
+	case msg.Err != nil:
+		fp.viewPort.SetContent(fp.renderNoPreview("No preview available"))
+	case msg.Preview == "":
+		fp.viewPort.SetContent(fp.renderNoPreview("Empty file"))
+	default:
+		fp.viewPort.SetContent(msg.Preview)
+	}
+	fp.state = previewStatePreviewing
+}
+
+func (fp *FilePreview) handleGitDiffReadyMsg(msg GitDiffReadyMsg) tea.Cmd {
+	if fp.gitMode != gitModeDiff || msg.Path != fp.getFullPath() || fp.diff != nil || fp.follow != nil {
+		return nil
+	}
+	fp.state = previewStatePreviewing
+	switch {
+	case errors.Is(msg.Err, git.ErrNotRepository):
+		fp.viewPort.SetContent(fp.renderNoPreview("Not inside a git work tree"))
+	case msg.Err != nil:
+		fp.viewPort.SetContent(fp.renderNoPreview("No diff available"))
+		return message.NewNotificationCmd("git diff: " + msg.Err.Error())
+	case msg.Preview == "":
+		fp.viewPort.SetContent(fp.renderNoPreview("No changes against HEAD"))
+	default:
+		fp.viewPort.SetContent(msg.Preview)
+	}
+	return nil
+}
+
+// handleHistoryKeys moves between the commits of the file
+func (fp *FilePreview) handleHistoryKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, fp.keys.NextRevision):
+		if fp.history.cursor < len(fp.history.revisions)-1 {
+			return fp.selectRevision(fp.history.cursor + 1)
+		}
+	case key.Matches(msg, fp.keys.PrevRevision):
+		if fp.history.cursor > 0 {
+			return fp.selectRevision(fp.history.cursor - 1)
+		}
+	}
+	return nil
+}
+
+// historyView renders the commits of the file around the cursor, with the selected
+// commit highlighted, and a separator below them
+func (fp *FilePreview) historyView() string {
+	width := fp.width - margin
+	rows := fp.historyHeight() - 1
+	start := 0
+	if fp.history.cursor >= rows {
+		start = fp.history.cursor - rows + 1
+	}
+	lines := make([]string, 0, rows+1)
+	for i := start; i < len(fp.history.revisions) && i < start+rows; i++ {
+		r := fp.history.revisions[i]
+		line := strings.Join([]string{r.ShortHash(), r.Time.Format("2006-01-02"), r.Author, r.Subject}, " ")
+		if i == fp.history.cursor {
+			line = fp.styles.SelectedItemStyle.Render(layout.FitWidth(line, width))
+		} else {
+			line = layout.FitWidth(line, width)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, termenv.String(strings.Repeat("-", width)).Foreground(termenv.RGBColor(fp.theme.InfobarBgColor)).String())
+	return strings.Join(lines, "\n")
+}
+
+// gitInfoView returns the description of the git mode shown below the preview
+func (fp *FilePreview) gitInfoView() string {
+	if fp.gitMode == gitModeDiff {
+		return termenv.String("Changes to ").Italic().String() + fp.entry.Name() + " against HEAD"
+	}
+	title := termenv.String("History of ").Italic().String() + fp.entry.Name()
+	if fp.showsHistory() {
+		r := fp.history.selected()
+		title += " at " + r.ShortHash()
+		if base := r.Path[strings.LastIndexByte(r.Path, '/')+1:]; base != fp.entry.Name() {
+			title += " (" + base + ")"
+		}
+	}
+	return title
+}
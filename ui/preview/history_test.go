@@ -0,0 +1,83 @@
+package preview
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/entry/git"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/message"
+	"github.com/Philistino/fman/ui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+)
+
+func TestGitModes(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/dir/file.txt", []byte("content"), 0o644)
+	info, err := fsys.Stat("/dir/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := NewFilePreviewer(theme.GetActiveTheme("dracula"), theme.GlobalStyleSet(), keys.Map, 0)
+	fp.SetWidth(60)
+	fp.SetHeight(30)
+	fp.dirPath = "/dir"
+	fp.setNewEntry(entry.Entry{FileInfo: info})
+	path := filepath.Join("/dir", "file.txt")
+	fullHeight := fp.viewPort.Height
+
+	fp.Update(message.ToggleGitPreviewMsg{})
+	if fp.gitMode != gitModeLog || fp.history == nil || fp.history.path != path {
+		t.Fatalf("expected the log mode for %s, got mode %d", path, fp.gitMode)
+	}
+	fp.Update(PreviewReadyMsg{Path: path, Preview: "contents"})
+	if strings.Contains(fp.viewPort.View(), "contents") {
+		t.Error("the contents of the file should not replace the history")
+	}
+
+	revisions := []git.Revision{
+		{Hash: "aaaaaaaaaa", Subject: "second", Path: "dir/file.txt"},
+		{Hash: "bbbbbbbbbb", Subject: "first", Path: "dir/old.txt"},
+	}
+	fp.Update(GitLogReadyMsg{Path: path, Revisions: revisions})
+	if !fp.showsHistory() || fp.viewPort.Height != fullHeight-3 {
+		t.Fatalf("expected 2 commits and a separator above a viewport of %d rows, got %d rows", fullHeight-3, fp.viewPort.Height)
+	}
+	fp.Update(RevisionReadyMsg{Path: path, Hash: "aaaaaaaaaa", Preview: "at second"})
+	if !strings.Contains(fp.viewPort.View(), "at second") {
+		t.Errorf("expected the file at the selected commit, got %q", fp.viewPort.View())
+	}
+
+	fp.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("}")})
+	if fp.history.cursor != 1 {
+		t.Fatalf("expected the cursor on the second commit, got %d", fp.history.cursor)
+	}
+	fp.Update(RevisionReadyMsg{Path: path, Hash: "aaaaaaaaaa", Preview: "stale"})
+	if strings.Contains(fp.viewPort.View(), "stale") {
+		t.Error("a preview of a commit that is no longer selected should be ignored")
+	}
+	fp.Update(RevisionReadyMsg{Path: path, Hash: "bbbbbbbbbb", Preview: "at first"})
+	if !strings.Contains(fp.viewPort.View(), "at first") {
+		t.Errorf("expected the file at the first commit, got %q", fp.viewPort.View())
+	}
+	if view := fp.View(); !strings.Contains(view, "bbbbbbb") || !strings.Contains(view, "(old.txt)") {
+		t.Errorf("expected the commits and the old name in the view, got %q", view)
+	}
+
+	fp.Update(message.ToggleGitPreviewMsg{})
+	if fp.gitMode != gitModeDiff || fp.history != nil || fp.viewPort.Height != fullHeight {
+		t.Fatalf("expected the diff mode without the commits, got mode %d", fp.gitMode)
+	}
+	fp.Update(GitDiffReadyMsg{Path: path})
+	if !strings.Contains(fp.viewPort.View(), "No changes against HEAD") {
+		t.Errorf("expected no changes, got %q", fp.viewPort.View())
+	}
+
+	fp.Update(message.ToggleGitPreviewMsg{})
+	if fp.gitMode != gitModeOff {
+		t.Errorf("expected the contents after the diff, got mode %d", fp.gitMode)
+	}
+}
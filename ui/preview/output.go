@@ -0,0 +1,39 @@
+package preview
+
+import (
+	"strings"
+
+	"github.com/Philistino/fman/entry"
+)
+
+// CommandOutputMsg holds the output of a user command to show in the preview
+type CommandOutputMsg struct {
+	Name   string
+	Output string
+	Err    error
+}
+
+// handleCommandOutputMsg replaces the preview with the output of the command until
+// another entry is previewed
+func (fp *FilePreview) handleCommandOutputMsg(msg CommandOutputMsg) {
+	if fp.previewCancel != nil {
+		fp.previewCancel()
+		fp.previewCancel = nil
+	}
+	fp.diff = nil
+	fp.follow = nil
+	fp.history = nil
+	fp.output = &msg
+	fp.resizeViewPort()
+
+	content := strings.ReplaceAll(entry.ExpandTabs(msg.Output), "\r\n", "\n")
+	if msg.Err != nil {
+		content = strings.TrimRight(content, "\n") + "\n\n" + msg.Err.Error()
+	}
+	if strings.TrimSpace(content) == "" {
+		content = fp.renderNoPreview("No output")
+	}
+	fp.viewPort.SetContent(content)
+	fp.viewPort.SetYOffset(0)
+	fp.state = previewStatePreviewing
+}
@@ -0,0 +1,41 @@
+package preview
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/entry"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/theme"
+	"github.com/spf13/afero"
+)
+
+func TestCommandOutput(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/dir/file.txt", []byte("content"), 0o644)
+	info, err := fsys.Stat("/dir/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := NewFilePreviewer(theme.GetActiveTheme("dracula"), theme.GlobalStyleSet(), keys.Map, 0)
+	fp.SetWidth(60)
+	fp.SetHeight(30)
+	fp.dirPath = "/dir"
+	fp.setNewEntry(entry.Entry{FileInfo: info})
+
+	fp.Update(CommandOutputMsg{Name: "count", Output: "a\tb\n", Err: errors.New("exit status 1")})
+	view := fp.View()
+	if !strings.Contains(view, "a    b") || !strings.Contains(view, "exit status 1") || !strings.Contains(view, "Output of") || !strings.Contains(view, "count") {
+		t.Errorf("expected the output, the error and the name of the command, got %q", view)
+	}
+	fp.Update(PreviewReadyMsg{Path: "/dir/file.txt", Preview: "contents"})
+	if strings.Contains(fp.viewPort.View(), "contents") {
+		t.Error("the contents of the file should not replace the output")
+	}
+
+	fp.setNewEntry(entry.Entry{FileInfo: info})
+	if fp.output != nil {
+		t.Error("the output should be closed when another entry is previewed")
+	}
+}
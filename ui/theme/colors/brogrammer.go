@@ -21,6 +21,13 @@ var BrogrammerTheme = Theme{
 	HiddenFolderColor:        lipgloss.Color("#2ecc71"),
 	FolderColor:              lipgloss.Color("#f1c40f"),
 	TextColor:                lipgloss.Color("#ddd"),
+	BrokenLinkColor:          lipgloss.Color("#e74c3c"),
+	GitBranchColor:           lipgloss.Color("#3498db"),
+	GitModifiedColor:         lipgloss.Color("#e67e22"),
+	GitStagedColor:           lipgloss.Color("#2ecc71"),
+	GitUntrackedColor:        lipgloss.Color("#1abc9c"),
+	GitIgnoredColor:          lipgloss.Color("#7f8c8d"),
+	GitConflictColor:         lipgloss.Color("#e74c3c"),
 	InfobarBgColor:           lipgloss.Color("#555555"),
 	InfobarFgColor:           lipgloss.Color("#f5e0dc"),
 	BackgroundColor:          lipgloss.Color("#1a1a1a"),
@@ -21,6 +21,13 @@ var CatppuccinThemeFrappe = Theme{
 	HiddenFolderColor:        lipgloss.Color("#99d1db"),
 	FolderColor:              lipgloss.Color("#e5c890"),
 	TextColor:                lipgloss.Color("#99d1db"),
+	BrokenLinkColor:          lipgloss.Color("#e78284"),
+	GitBranchColor:           lipgloss.Color("#ca9ee6"),
+	GitModifiedColor:         lipgloss.Color("#ef9f76"),
+	GitStagedColor:           lipgloss.Color("#a6d189"),
+	GitUntrackedColor:        lipgloss.Color("#81c8be"),
+	GitIgnoredColor:          lipgloss.Color("#737994"),
+	GitConflictColor:         lipgloss.Color("#e78284"),
 	InfobarBgColor:           lipgloss.Color("#c6d0f5"),
 	InfobarFgColor:           lipgloss.Color("#f2d5cf"),
 	BackgroundColor:          lipgloss.Color("#232634"),
@@ -21,6 +21,13 @@ var CatppuccinThemeLatte = Theme{
 	HiddenFolderColor:        lipgloss.Color("#04a5e5"),
 	FolderColor:              lipgloss.Color("#df8e1d"),
 	TextColor:                lipgloss.Color("#04a5e5"),
+	BrokenLinkColor:          lipgloss.Color("#d20f39"),
+	GitBranchColor:           lipgloss.Color("#8839ef"),
+	GitModifiedColor:         lipgloss.Color("#fe640b"),
+	GitStagedColor:           lipgloss.Color("#40a02b"),
+	GitUntrackedColor:        lipgloss.Color("#179299"),
+	GitIgnoredColor:          lipgloss.Color("#9ca0b0"),
+	GitConflictColor:         lipgloss.Color("#d20f39"),
 	InfobarBgColor:           lipgloss.Color("#4c4f69"),
 	InfobarFgColor:           lipgloss.Color("#dc8a78"),
 	BackgroundColor:          lipgloss.Color("#dce0e8"),
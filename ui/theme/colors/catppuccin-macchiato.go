@@ -21,6 +21,13 @@ var CatppuccinThemeMacchiato = Theme{
 	HiddenFolderColor:        lipgloss.Color("#91d7e3"),
 	FolderColor:              lipgloss.Color("#eed49f"),
 	TextColor:                lipgloss.Color("#91d7e3"),
+	BrokenLinkColor:          lipgloss.Color("#ed8796"),
+	GitBranchColor:           lipgloss.Color("#c6a0f6"),
+	GitModifiedColor:         lipgloss.Color("#f5a97f"),
+	GitStagedColor:           lipgloss.Color("#a6da95"),
+	GitUntrackedColor:        lipgloss.Color("#8bd5ca"),
+	GitIgnoredColor:          lipgloss.Color("#6e738d"),
+	GitConflictColor:         lipgloss.Color("#ed8796"),
 	InfobarBgColor:           lipgloss.Color("#cad3f5"),
 	InfobarFgColor:           lipgloss.Color("#f4dbd6"),
 	BackgroundColor:          lipgloss.Color("#181926"),
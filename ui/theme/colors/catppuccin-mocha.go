@@ -21,6 +21,13 @@ var CatppuccinThemeMocha = Theme{
 	HiddenFolderColor:        lipgloss.Color("#89dceb"),
 	FolderColor:              lipgloss.Color("#f9e2af"),
 	TextColor:                lipgloss.Color("#89dceb"),
+	BrokenLinkColor:          lipgloss.Color("#f38ba8"),
+	GitBranchColor:           lipgloss.Color("#cba6f7"),
+	GitModifiedColor:         lipgloss.Color("#fab387"),
+	GitStagedColor:           lipgloss.Color("#a6e3a1"),
+	GitUntrackedColor:        lipgloss.Color("#94e2d5"),
+	GitIgnoredColor:          lipgloss.Color("#6c7086"),
+	GitConflictColor:         lipgloss.Color("#f38ba8"),
 	InfobarBgColor:           lipgloss.Color("#cdd6f4"),
 	InfobarFgColor:           lipgloss.Color("#f5e0dc"),
 	BackgroundColor:          lipgloss.Color("#11111b"),
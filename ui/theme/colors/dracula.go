@@ -21,6 +21,13 @@ var DraculaTheme = Theme{
 	HiddenFolderColor:        lipgloss.Color("#bd93f9"),
 	FolderColor:              lipgloss.Color("#ffb86c"),
 	TextColor:                lipgloss.Color("#ddd"),
+	BrokenLinkColor:          lipgloss.Color("#ff5555"),
+	GitBranchColor:           lipgloss.Color("#bd93f9"),
+	GitModifiedColor:         lipgloss.Color("#ffb86c"),
+	GitStagedColor:           lipgloss.Color("#50fa7b"),
+	GitUntrackedColor:        lipgloss.Color("#8be9fd"),
+	GitIgnoredColor:          lipgloss.Color("#6272a4"),
+	GitConflictColor:         lipgloss.Color("#ff5555"),
 	InfobarBgColor:           lipgloss.Color("#646a7a"),
 	InfobarFgColor:           lipgloss.Color("#f5e0dc"),
 	BackgroundColor:          lipgloss.Color("#282a36"),
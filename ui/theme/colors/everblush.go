@@ -21,6 +21,13 @@ var EverblushTheme = Theme{
 	HiddenFolderColor:        lipgloss.Color("#67b0e8"),
 	FolderColor:              lipgloss.Color("#e5c76b"),
 	TextColor:                lipgloss.Color("#9bdead"),
+	BrokenLinkColor:          lipgloss.Color("#e57474"),
+	GitBranchColor:           lipgloss.Color("#c47fd5"),
+	GitModifiedColor:         lipgloss.Color("#e5c76b"),
+	GitStagedColor:           lipgloss.Color("#8ccf7e"),
+	GitUntrackedColor:        lipgloss.Color("#6cbfbf"),
+	GitIgnoredColor:          lipgloss.Color("#5c6466"),
+	GitConflictColor:         lipgloss.Color("#e57474"),
 	InfobarBgColor:           lipgloss.Color("#67b0e8"),
 	InfobarFgColor:           lipgloss.Color("#232a2d"),
 	BackgroundColor:          lipgloss.Color("#141b1e"),
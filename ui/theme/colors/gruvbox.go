@@ -21,6 +21,13 @@ var GruvboxTheme = Theme{
 	HiddenFolderColor:        lipgloss.Color("#458588"),
 	FolderColor:              lipgloss.Color("#FABD2F"),
 	TextColor:                lipgloss.Color("#EBDBB2"),
+	BrokenLinkColor:          lipgloss.Color("#fb4934"),
+	GitBranchColor:           lipgloss.Color("#d3869b"),
+	GitModifiedColor:         lipgloss.Color("#fe8019"),
+	GitStagedColor:           lipgloss.Color("#b8bb26"),
+	GitUntrackedColor:        lipgloss.Color("#8ec07c"),
+	GitIgnoredColor:          lipgloss.Color("#928374"),
+	GitConflictColor:         lipgloss.Color("#fb4934"),
 	InfobarBgColor:           lipgloss.Color("#7C6F64"),
 	InfobarFgColor:           lipgloss.Color("#FBF1C7"),
 	BackgroundColor:          lipgloss.Color("#1D2021"),
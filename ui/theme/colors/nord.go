@@ -21,6 +21,13 @@ var NordTheme = Theme{
 	HiddenFolderColor:        lipgloss.Color("#81a1c1"),
 	FolderColor:              lipgloss.Color("#ebcb8b"),
 	TextColor:                lipgloss.Color("#d8dee9"),
+	BrokenLinkColor:          lipgloss.Color("#bf616a"),
+	GitBranchColor:           lipgloss.Color("#b48ead"),
+	GitModifiedColor:         lipgloss.Color("#d08770"),
+	GitStagedColor:           lipgloss.Color("#a3be8c"),
+	GitUntrackedColor:        lipgloss.Color("#88c0d0"),
+	GitIgnoredColor:          lipgloss.Color("#4c566a"),
+	GitConflictColor:         lipgloss.Color("#bf616a"),
 	InfobarBgColor:           lipgloss.Color("#4c566a"),
 	InfobarFgColor:           lipgloss.Color("#eceff4"),
 	BackgroundColor:          lipgloss.Color("#2e3440"),
@@ -21,6 +21,13 @@ type Theme struct {
 	HiddenFolderColor        lipgloss.Color
 	FolderColor              lipgloss.Color
 	TextColor                lipgloss.Color
+	BrokenLinkColor          lipgloss.Color
+	GitBranchColor           lipgloss.Color
+	GitModifiedColor         lipgloss.Color
+	GitStagedColor           lipgloss.Color
+	GitUntrackedColor        lipgloss.Color
+	GitIgnoredColor          lipgloss.Color
+	GitConflictColor         lipgloss.Color
 	InfobarBgColor           lipgloss.Color
 	InfobarFgColor           lipgloss.Color
 	BackgroundColor          lipgloss.Color
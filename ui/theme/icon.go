@@ -1,6 +1,7 @@
 package theme
 
-type iconSet struct {
+// IconSet holds the icons drawn by the components
+type IconSet struct {
 	LeftArrowIcon  rune
 	RightArrowIcon rune
 	UpArrowIcon    rune
@@ -22,13 +23,14 @@ type iconSet struct {
 	CopyIcon    rune
 	PasteIcon   rune
 
-	PinIcon  rune
-	Selected string
+	PinIcon    rune
+	BranchIcon rune
+	Selected   string
 }
 
-type iconSets map[string]iconSet
+type iconSets map[string]IconSet
 
-var nerdFont = iconSet{
+var nerdFont = IconSet{
 	LeftArrowIcon:       '\uf060',
 	RightArrowIcon:      '\uf061',
 	UpArrowIcon:         '\uf062',
@@ -45,9 +47,10 @@ var nerdFont = iconSet{
 	CopyIcon:            '\uebcc',
 	PasteIcon:           '\uf0ea',
 	PinIcon:             '\ueba0',
+	BranchIcon:          '\ue0a0',
 }
 
-var emoji = iconSet{
+var emoji = IconSet{
 	LeftArrowIcon:       '◀',
 	RightArrowIcon:      '▶',
 	UpArrowIcon:         '▲',
@@ -60,10 +63,11 @@ var emoji = iconSet{
 	TrashIcon:           '🗑',
 	PasteIcon:           '📋',
 	PinIcon:             '📌',
+	BranchIcon:          '🌿',
 	Selected:            "✔️",
 }
 
-var noIcons = iconSet{
+var noIcons = IconSet{
 	LeftArrowIcon:       '<',
 	RightArrowIcon:      '>',
 	UpArrowIcon:         '^',
@@ -83,8 +87,14 @@ func SetIcons(icons string) {
 	iconsG = icons
 }
 
-func GetActiveIconTheme() iconSet {
-	set, ok := iconProviders[iconsG]
+func GetActiveIconTheme() IconSet {
+	return Icons(iconsG)
+}
+
+// Icons returns the icon set with the given name, or the emoji icons if there is
+// no such set
+func Icons(name string) IconSet {
+	set, ok := iconProviders[name]
 	if !ok {
 		return iconProviders["emoji"]
 	}
@@ -5,24 +5,27 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// The global styles are used by the components of the app. Components that can be
+// embedded more than once hold their own StyleSet instead.
 var (
-	EntryInfoStyle      = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
-	ListStyle           = lipgloss.NewStyle().Padding(1)
-	AppStyle            = lipgloss.NewStyle().Align(lipgloss.Center)
-	EvenItemStyle       = lipgloss.NewStyle().Height(1)
-	PathStyle           = lipgloss.NewStyle().Padding(0, 1)
-	SelectedItemStyle   = lipgloss.NewStyle().Height(1)
-	LogoStyle           = lipgloss.NewStyle().Padding(0, 1)
-	ProgressStyle       = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, true)
-	InfobarStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#000"))
-	ArrowStyle          = lipgloss.NewStyle().Align(lipgloss.Center)
-	EmptyFolderStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1)
-	ButtonStyle         = lipgloss.NewStyle().Padding(0, 1).Border(lipgloss.NormalBorder(), false, true)
-	InactiveButtonStyle = lipgloss.NewStyle().Padding(0, 1).
-				Border(lipgloss.NormalBorder(), false, true).
-				Foreground(lipgloss.Color("#707070")) // TODO: make this a theme color
+	EntryInfoStyle      = globalStyles.EntryInfoStyle
+	ListStyle           = globalStyles.ListStyle
+	AppStyle            = globalStyles.AppStyle
+	EvenItemStyle       = globalStyles.EvenItemStyle
+	PathStyle           = globalStyles.PathStyle
+	SelectedItemStyle   = globalStyles.SelectedItemStyle
+	LogoStyle           = globalStyles.LogoStyle
+	ProgressStyle       = globalStyles.ProgressStyle
+	InfobarStyle        = globalStyles.InfobarStyle
+	ArrowStyle          = globalStyles.ArrowStyle
+	BranchStyle         = globalStyles.BranchStyle
+	EmptyFolderStyle    = globalStyles.EmptyFolderStyle
+	ButtonStyle         = globalStyles.ButtonStyle
+	InactiveButtonStyle = globalStyles.InactiveButtonStyle
 )
 
+var globalStyles = newStyleSet()
+
 type StyleSet struct {
 	EntryInfoStyle      lipgloss.Style
 	ListStyle           lipgloss.Style
@@ -34,42 +37,106 @@ type StyleSet struct {
 	ProgressStyle       lipgloss.Style
 	InfobarStyle        lipgloss.Style
 	ArrowStyle          lipgloss.Style
+	BranchStyle         lipgloss.Style
 	EmptyFolderStyle    lipgloss.Style
 	ButtonStyle         lipgloss.Style
 	InactiveButtonStyle lipgloss.Style
+
+	Icons IconSet
+}
+
+// newStyleSet returns the styles without the colors of a theme
+func newStyleSet() StyleSet {
+	return StyleSet{
+		EntryInfoStyle:    lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		ListStyle:         lipgloss.NewStyle().Padding(1),
+		AppStyle:          lipgloss.NewStyle().Align(lipgloss.Center),
+		EvenItemStyle:     lipgloss.NewStyle().Height(1),
+		PathStyle:         lipgloss.NewStyle().Padding(0, 1),
+		SelectedItemStyle: lipgloss.NewStyle().Height(1),
+		LogoStyle:         lipgloss.NewStyle().Padding(0, 1),
+		ProgressStyle:     lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, true),
+		InfobarStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color("#000")),
+		ArrowStyle:        lipgloss.NewStyle().Align(lipgloss.Center),
+		BranchStyle:       lipgloss.NewStyle().Padding(0, 1),
+		EmptyFolderStyle:  lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1),
+		ButtonStyle:       lipgloss.NewStyle().Padding(0, 1).Border(lipgloss.NormalBorder(), false, true),
+		InactiveButtonStyle: lipgloss.NewStyle().Padding(0, 1).
+			Border(lipgloss.NormalBorder(), false, true).
+			Foreground(lipgloss.Color("#707070")), // TODO: make this a theme color
+	}
+}
+
+// NewStyleSet returns styles with the colors of the theme and the icon set with the
+// given name. Unlike SetTheme, it does not change the global styles.
+func NewStyleSet(theme colors.Theme, icons string) StyleSet {
+	styles := newStyleSet()
+	styles.setTheme(theme)
+	styles.Icons = Icons(icons)
+	return styles
 }
 
-// func NewStyleSet() StyleSet {
+// GlobalStyleSet returns the global styles and icons. The styles share their rules
+// with the global styles, so they change when SetTheme is called.
+func GlobalStyleSet() StyleSet {
+	return StyleSet{
+		EntryInfoStyle:      EntryInfoStyle,
+		ListStyle:           ListStyle,
+		AppStyle:            AppStyle,
+		EvenItemStyle:       EvenItemStyle,
+		PathStyle:           PathStyle,
+		SelectedItemStyle:   SelectedItemStyle,
+		LogoStyle:           LogoStyle,
+		ProgressStyle:       ProgressStyle,
+		InfobarStyle:        InfobarStyle,
+		ArrowStyle:          ArrowStyle,
+		BranchStyle:         BranchStyle,
+		EmptyFolderStyle:    EmptyFolderStyle,
+		ButtonStyle:         ButtonStyle,
+		InactiveButtonStyle: InactiveButtonStyle,
+		Icons:               GetActiveIconTheme(),
+	}
+}
 
+// SetTheme colors the global styles with the theme
 func SetTheme(theme colors.Theme) {
-	EvenItemStyle.Background(theme.EvenItemBgColor)
+	styles := GlobalStyleSet()
+	styles.setTheme(theme)
+}
+
+// setTheme colors the styles. The setters of lipgloss change the rules the copies of a
+// style share, so the styles of s are changed in place.
+func (s StyleSet) setTheme(theme colors.Theme) {
+	s.EvenItemStyle.Background(theme.EvenItemBgColor)
+
+	s.SelectedItemStyle.Background(theme.SelectedItemBgColor)
+	s.SelectedItemStyle.Foreground(theme.SelectedItemFgColor)
 
-	SelectedItemStyle.Background(theme.SelectedItemBgColor)
-	SelectedItemStyle.Foreground(theme.SelectedItemFgColor)
+	s.ButtonStyle.BorderForeground(theme.ButtonBorderFgColor)
+	s.ButtonStyle.Background(theme.ButtonBgColor)
 
-	ButtonStyle.BorderForeground(theme.ButtonBorderFgColor)
-	ButtonStyle.Background(theme.ButtonBgColor)
+	s.InactiveButtonStyle.BorderForeground(theme.ButtonBorderFgColor)
+	s.InactiveButtonStyle.Background(theme.ButtonBgColor)
 
-	InactiveButtonStyle.BorderForeground(theme.ButtonBorderFgColor)
-	InactiveButtonStyle.Background(theme.ButtonBgColor)
+	s.PathStyle.Background(theme.PathElementBgColor)
+	s.PathStyle.BorderForeground(theme.PathElementBorderFgColor)
 
-	PathStyle.Background(theme.PathElementBgColor)
-	PathStyle.BorderForeground(theme.PathElementBorderFgColor)
+	s.AppStyle.Background(theme.ListBgColor)
+	s.AppStyle.Foreground(theme.ListFgColor)
 
-	AppStyle.Background(theme.ListBgColor)
-	AppStyle.Foreground(theme.ListFgColor)
+	s.LogoStyle.Background(theme.LogoBgColor)
+	s.LogoStyle.Foreground(theme.LogoFgColor)
 
-	LogoStyle.Background(theme.LogoBgColor)
-	LogoStyle.Foreground(theme.LogoFgColor)
+	s.ProgressStyle.Background(theme.ProgressBarBgColor)
+	s.ProgressStyle.Foreground(theme.ProgressBarFgColor)
+	s.ProgressStyle.BorderForeground(theme.ProgressBarFgColor)
 
-	ProgressStyle.Background(theme.ProgressBarBgColor)
-	ProgressStyle.Foreground(theme.ProgressBarFgColor)
-	ProgressStyle.BorderForeground(theme.ProgressBarFgColor)
+	s.InfobarStyle.Background(theme.InfobarBgColor)
+	s.InfobarStyle.Foreground(theme.InfobarFgColor)
 
-	InfobarStyle.Background(theme.InfobarBgColor)
-	InfobarStyle.Foreground(theme.InfobarFgColor)
+	s.EntryInfoStyle.BorderForeground(theme.SeparatorColor)
 
-	EntryInfoStyle.BorderForeground(theme.SeparatorColor)
+	s.ArrowStyle.Foreground(theme.ArrowColor)
 
-	ArrowStyle.Foreground(theme.ArrowColor)
+	s.BranchStyle.Foreground(theme.GitBranchColor)
 }
@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Philistino/fman/entry/usage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scanDoneMsg is sent when a scan finishes
+type scanDoneMsg struct {
+	id   int
+	root *usage.Node
+	err  error
+}
+
+func scanCmd(ctx context.Context, backend Backend, id int, path string, progress *usage.Progress) tea.Cmd {
+	return func() tea.Msg {
+		root, err := backend.ScanUsage(ctx, path, progress)
+		return scanDoneMsg{id: id, root: root, err: err}
+	}
+}
+
+// progressTickMsg redraws the progress of a scan
+type progressTickMsg struct {
+	id int
+}
+
+func progressTick(id int) tea.Cmd {
+	return tea.Tick(progressInterval, func(time.Time) tea.Msg {
+		return progressTickMsg{id: id}
+	})
+}
+
+// deletedMsg is sent once a node has been deleted from the filesystem
+type deletedMsg struct {
+	node *usage.Node
+	err  error
+}
+
+func deleteCmd(backend Backend, node *usage.Node) tea.Cmd {
+	path := node.Path()
+	return func() tea.Msg {
+		err := backend.DeletePath(context.Background(), path)
+		return deletedMsg{node: node, err: err}
+	}
+}
+
+type exportedMsg struct {
+	path string
+	err  error
+}
+
+// exportCmd writes a copy of the tree, so that entries can be deleted from it while it is
+// exported
+func exportCmd(backend Backend, path string, root *usage.Node) tea.Cmd {
+	root = root.Copy()
+	return func() tea.Msg {
+		return exportedMsg{path: path, err: backend.ExportUsage(path, root)}
+	}
+}
+
+type importedMsg struct {
+	path string
+	root *usage.Node
+	err  error
+}
+
+func importCmd(backend Backend, path string) tea.Cmd {
+	return func() tea.Msg {
+		root, err := backend.ImportUsage(path)
+		return importedMsg{path: path, root: root, err: err}
+	}
+}
@@ -0,0 +1,462 @@
+// Package usage implements a full screen view of the disk usage of a directory tree,
+// similar to ncdu. The tree is scanned once and can then be browsed, pruned and exported.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Philistino/fman/entry/usage"
+	"github.com/Philistino/fman/ui/keys"
+	"github.com/Philistino/fman/ui/layout"
+	"github.com/Philistino/fman/ui/theme/colors"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/muesli/termenv"
+)
+
+// progressInterval is how often the progress of a scan is shown
+const progressInterval = 100 * time.Millisecond
+
+// barWidth is the width of the usage bars
+const barWidth = 20
+
+// Backend performs the filesystem operations of the view
+type Backend interface {
+	ScanUsage(ctx context.Context, path string, progress *usage.Progress) (*usage.Node, error)
+	DeletePath(ctx context.Context, path string) error
+	ExportUsage(path string, root *usage.Node) error
+	ImportUsage(path string) (*usage.Node, error)
+}
+
+// ClosedMsg is sent when the user closes the view
+type ClosedMsg struct{}
+
+func closedCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ClosedMsg{}
+	}
+}
+
+type inputMode uint8
+
+const (
+	inputNone inputMode = iota
+	inputExport
+	inputImport
+	inputConfirmDelete
+)
+
+// Usage shows the entries of a scanned directory sorted by their total size
+type Usage struct {
+	backend Backend
+	path    string // path that is scanned
+
+	root   *usage.Node
+	dir    *usage.Node // directory being shown
+	cursor int
+	top    int
+
+	// imported is set when the tree was read from a file. It may come from another
+	// machine or be out of date, so its paths are not deleted or scanned again.
+	imported bool
+
+	scanID   int // identifies the current scan so that results of cancelled scans are ignored
+	scanning bool
+	progress *usage.Progress
+	cancel   context.CancelFunc
+	spinner  spinner.Model
+
+	mode   inputMode
+	input  textinput.Model
+	status string
+
+	theme  colors.Theme
+	width  int
+	height int
+}
+
+// New creates the view and returns the command that starts scanning path
+func New(backend Backend, path string, theme colors.Theme, width, height int) (*Usage, tea.Cmd) {
+	ti := textinput.New()
+	ti.CharLimit = 4096
+	u := &Usage{
+		backend: backend,
+		path:    path,
+		spinner: spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+		input:   ti,
+		theme:   theme,
+		width:   width,
+		height:  height,
+	}
+	return u, u.scan()
+}
+
+// Close cancels a running scan
+func (u *Usage) Close() {
+	if u.cancel != nil {
+		u.cancel()
+	}
+}
+
+// SetSize sets the size of the view
+func (u *Usage) SetSize(width, height int) {
+	u.width = width
+	u.height = height
+	u.clampCursor()
+}
+
+// bodyHeight returns the number of rows available for entries
+func (u *Usage) bodyHeight() int {
+	h := u.height - 2 // header and footer
+	if h < 1 {
+		return 1
+	}
+	return h
+}
+
+func (u *Usage) Update(msg tea.Msg) (*Usage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		u.SetSize(msg.Width, msg.Height)
+	case scanDoneMsg:
+		u.handleScanDone(msg)
+	case progressTickMsg:
+		if msg.id == u.scanID && u.scanning {
+			return u, progressTick(u.scanID)
+		}
+	case spinner.TickMsg:
+		if u.scanning {
+			var cmd tea.Cmd
+			u.spinner, cmd = u.spinner.Update(msg)
+			return u, cmd
+		}
+	case deletedMsg:
+		u.handleDeleted(msg)
+	case exportedMsg:
+		u.status = "Exported to " + msg.path
+		if msg.err != nil {
+			u.status = msg.err.Error()
+		}
+	case importedMsg:
+		u.handleImported(msg)
+	case tea.KeyMsg:
+		if u.mode != inputNone {
+			return u, u.handleInputKey(msg)
+		}
+		return u, u.handleKey(msg)
+	}
+	if u.mode == inputExport || u.mode == inputImport {
+		var cmd tea.Cmd
+		u.input, cmd = u.input.Update(msg)
+		return u, cmd
+	}
+	return u, nil
+}
+
+func (u *Usage) handleKey(msg tea.KeyMsg) tea.Cmd {
+	u.status = ""
+	switch {
+	case key.Matches(msg, keys.Map.ClosePager):
+		return closedCmd()
+	case key.Matches(msg, keys.Map.UsageRescan):
+		if u.imported {
+			u.status = "An imported tree cannot be rescanned"
+			return nil
+		}
+		return u.scan()
+	case key.Matches(msg, keys.Map.UsageImport):
+		return u.startInput(inputImport, "Import from: ", "")
+	}
+	if u.dir == nil {
+		return nil
+	}
+	switch {
+	case key.Matches(msg, keys.Map.MoveCursorUp):
+		u.moveCursor(-1)
+	case key.Matches(msg, keys.Map.MoveCursorDown):
+		u.moveCursor(1)
+	case key.Matches(msg, keys.Map.PageUp):
+		u.moveCursor(-u.bodyHeight())
+	case key.Matches(msg, keys.Map.PageDown):
+		u.moveCursor(u.bodyHeight())
+	case key.Matches(msg, keys.Map.MoveCursorToTop):
+		u.moveCursor(-len(u.dir.Children))
+	case key.Matches(msg, keys.Map.MoveCursorToBottom):
+		u.moveCursor(len(u.dir.Children))
+	case key.Matches(msg, keys.Map.OpenFile), key.Matches(msg, keys.Map.GoToSelectedDirectory):
+		u.enter()
+	case key.Matches(msg, keys.Map.GoToParentDirectory), msg.Type == tea.KeyBackspace:
+		u.leave()
+	case key.Matches(msg, keys.Map.UsageDelete):
+		if u.imported {
+			u.status = "Entries of an imported tree cannot be deleted"
+			return nil
+		}
+		if selected := u.selected(); selected != nil {
+			u.mode = inputConfirmDelete
+		}
+	case key.Matches(msg, keys.Map.UsageExport):
+		return u.startInput(inputExport, "Export to: ", filepath.Join(u.path, "fman-usage.json"))
+	}
+	return nil
+}
+
+func (u *Usage) startInput(mode inputMode, prompt, value string) tea.Cmd {
+	u.mode = mode
+	u.input.Prompt = prompt
+	u.input.SetValue(value)
+	u.input.CursorEnd()
+	return u.input.Focus()
+}
+
+func (u *Usage) handleInputKey(msg tea.KeyMsg) tea.Cmd {
+	mode := u.mode
+	if mode == inputConfirmDelete {
+		u.mode = inputNone
+		if msg.String() != "y" && msg.String() != "Y" {
+			return nil
+		}
+		return u.delete()
+	}
+	switch msg.Type {
+	case tea.KeyEsc:
+		u.mode = inputNone
+		u.input.Blur()
+		return nil
+	case tea.KeyEnter:
+		value := strings.TrimSpace(u.input.Value())
+		u.mode = inputNone
+		u.input.Blur()
+		if value == "" {
+			return nil
+		}
+		if mode == inputExport {
+			return exportCmd(u.backend, value, u.root)
+		}
+		u.status = "Importing..."
+		return importCmd(u.backend, value)
+	}
+	var cmd tea.Cmd
+	u.input, cmd = u.input.Update(msg)
+	return cmd
+}
+
+// scan starts scanning the path, cancelling a running scan
+func (u *Usage) scan() tea.Cmd {
+	u.Close()
+	var ctx context.Context
+	ctx, u.cancel = context.WithCancel(context.Background())
+	u.scanID++
+	u.scanning = true
+	u.progress = &usage.Progress{}
+	return tea.Batch(
+		scanCmd(ctx, u.backend, u.scanID, u.path, u.progress),
+		progressTick(u.scanID),
+		u.spinner.Tick,
+	)
+}
+
+func (u *Usage) handleScanDone(msg scanDoneMsg) {
+	if msg.id != u.scanID {
+		return
+	}
+	u.scanning = false
+	u.cancel = nil
+	if msg.err != nil {
+		u.status = msg.err.Error()
+		return
+	}
+	u.setRoot(msg.root)
+}
+
+func (u *Usage) handleImported(msg importedMsg) {
+	if msg.err != nil {
+		u.status = msg.err.Error()
+		return
+	}
+	// stop a running scan so it does not replace the imported tree
+	u.Close()
+	u.scanID++
+	u.scanning = false
+	u.path = msg.root.Name
+	u.status = "Imported " + msg.path
+	u.imported = true
+	u.setRoot(msg.root)
+}
+
+func (u *Usage) setRoot(root *usage.Node) {
+	u.root = root
+	u.dir = root
+	u.cursor = 0
+	u.top = 0
+}
+
+// selected returns the entry under the cursor
+func (u *Usage) selected() *usage.Node {
+	if u.dir == nil || u.cursor >= len(u.dir.Children) {
+		return nil
+	}
+	return u.dir.Children[u.cursor]
+}
+
+func (u *Usage) moveCursor(n int) {
+	u.cursor += n
+	u.clampCursor()
+}
+
+// clampCursor keeps the cursor on an entry and the cursor in view
+func (u *Usage) clampCursor() {
+	if u.dir == nil {
+		return
+	}
+	if u.cursor >= len(u.dir.Children) {
+		u.cursor = len(u.dir.Children) - 1
+	}
+	if u.cursor < 0 {
+		u.cursor = 0
+	}
+	if u.cursor < u.top {
+		u.top = u.cursor
+	}
+	if u.cursor >= u.top+u.bodyHeight() {
+		u.top = u.cursor - u.bodyHeight() + 1
+	}
+}
+
+// enter shows the directory under the cursor
+func (u *Usage) enter() {
+	selected := u.selected()
+	if selected == nil || !selected.IsDir || selected.Excluded {
+		return
+	}
+	u.dir = selected
+	u.cursor = 0
+	u.top = 0
+}
+
+// leave goes back up to the parent directory and puts the cursor on the directory that was left
+func (u *Usage) leave() {
+	if u.dir.Parent == nil {
+		return
+	}
+	left := u.dir
+	u.dir = u.dir.Parent
+	u.cursor = 0
+	u.top = 0
+	for i, c := range u.dir.Children {
+		if c == left {
+			u.cursor = i
+			break
+		}
+	}
+	u.clampCursor()
+}
+
+func (u *Usage) delete() tea.Cmd {
+	selected := u.selected()
+	if selected == nil || u.imported {
+		return nil
+	}
+	u.status = "Deleting " + selected.Name + "..."
+	return deleteCmd(u.backend, selected)
+}
+
+func (u *Usage) handleDeleted(msg deletedMsg) {
+	if msg.err != nil {
+		u.status = msg.err.Error()
+		return
+	}
+	u.status = "Deleted " + msg.node.Name
+	if parent := msg.node.Parent; parent != nil {
+		parent.Remove(msg.node)
+	}
+	u.clampCursor()
+}
+
+func (u *Usage) View() string {
+	rows := make([]string, 0, u.bodyHeight())
+	if u.dir != nil {
+		for i := u.top; i < len(u.dir.Children) && len(rows) < u.bodyHeight(); i++ {
+			rows = append(rows, u.rowView(u.dir.Children[i], i == u.cursor))
+		}
+	}
+	for len(rows) < u.bodyHeight() {
+		rows = append(rows, strings.Repeat(" ", u.width))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, u.headerView(), strings.Join(rows, "\n"), u.footerView())
+}
+
+func (u *Usage) rowView(n *usage.Node, selected bool) string {
+	var share float64
+	if u.dir.Size > 0 {
+		share = float64(n.Size) / float64(u.dir.Size)
+	}
+	filled := int(share*barWidth + 0.5)
+	bar := lipgloss.NewStyle().Background(u.theme.ProgressBarFgColor).Render(strings.Repeat(" ", filled)) +
+		lipgloss.NewStyle().Background(u.theme.ProgressBarBgColor).Render(strings.Repeat(" ", barWidth-filled))
+
+	name := n.Name
+	switch {
+	case n.Excluded:
+		name += "/ (other filesystem)"
+	case n.ReadError:
+		name += "/ (could not be read)"
+	case n.IsDir:
+		name += "/"
+	}
+	size := fmt.Sprintf(" %10s %5.1f%% ", humanize.Bytes(uint64(n.Size)), share*100)
+	nameStyle := lipgloss.NewStyle().Foreground(u.theme.TextColor)
+	if n.IsDir {
+		nameStyle = nameStyle.Foreground(u.theme.FolderColor)
+	}
+	if selected {
+		style := lipgloss.NewStyle().Background(u.theme.SelectedItemBgColor).Foreground(u.theme.SelectedItemFgColor)
+		return style.Render(size) + bar + style.Render(layout.FitWidth(" "+name, u.width-lipgloss.Width(size)-barWidth))
+	}
+	return size + bar + nameStyle.Render(layout.FitWidth(" "+name, u.width-lipgloss.Width(size)-barWidth))
+}
+
+func (u *Usage) headerView() string {
+	title := "Disk usage of " + u.path
+	var summary string
+	if u.dir != nil {
+		title = u.dir.Path()
+		if u.imported {
+			title += " (imported, read only)"
+		}
+		summary = fmt.Sprintf("%s in %d items", humanize.Bytes(uint64(u.dir.Size)), u.dir.Items)
+	}
+	name := lipgloss.NewStyle().Bold(true).Render(title)
+	gap := u.width - lipgloss.Width(name) - lipgloss.Width(summary) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return lipgloss.NewStyle().
+		Background(u.theme.InfobarBgColor).
+		Foreground(u.theme.InfobarFgColor).
+		Inline(true).
+		Render(layout.FitWidth(" "+name+strings.Repeat(" ", gap)+summary+" ", u.width))
+}
+
+func (u *Usage) footerView() string {
+	switch {
+	case u.mode == inputConfirmDelete:
+		return layout.FitWidth(fmt.Sprintf("Delete %s? y/N", u.selected().Path()), u.width)
+	case u.mode != inputNone:
+		return layout.FitWidth(u.input.View(), u.width)
+	case u.scanning:
+		items, bytes := u.progress.Counts()
+		return layout.FitWidth(fmt.Sprintf("%s Scanning... %d items, %s", u.spinner.View(), items, humanize.Bytes(uint64(bytes))), u.width)
+	case u.status != "":
+		return layout.FitWidth(u.status, u.width)
+	}
+	return layout.FitWidth(termenv.String("enter/right open  left up  d delete  e export  i import  r rescan  q close").Faint().String(), u.width)
+}
@@ -0,0 +1,198 @@
+package usage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Philistino/fman/nav"
+	"github.com/Philistino/fman/ui/theme/colors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+)
+
+// scanned returns a view that has finished scanning /root on fsys
+func scanned(t *testing.T, fsys afero.Fs, dryRun bool) *Usage {
+	t.Helper()
+	n := nav.NewNav(true, false, "/root", fsys, 0, dryRun)
+	u, cmd := New(n, "/root", colors.Theme{}, 60, 10)
+	// the scan is the first command of the batch, the others redraw the progress
+	u.Update(cmd().(tea.BatchMsg)[0]())
+	if u.scanning || u.root == nil {
+		t.Fatalf("expected the scan to finish, status %q", u.status)
+	}
+	return u
+}
+
+func usageFs() afero.Fs {
+	fsys := afero.NewMemMapFs()
+	afero.WriteFile(fsys, "/root/a", make([]byte, 10), 0644)
+	afero.WriteFile(fsys, "/root/big/b", make([]byte, 1000), 0644)
+	afero.WriteFile(fsys, "/root/big/c", make([]byte, 500), 0644)
+	afero.WriteFile(fsys, "/root/big/deep/d", make([]byte, 200), 0644)
+	return fsys
+}
+
+func press(u *Usage, s string) tea.Cmd {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	switch s {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "left":
+		msg = tea.KeyMsg{Type: tea.KeyLeft}
+	}
+	_, cmd := u.Update(msg)
+	return cmd
+}
+
+func TestUsageScanTotals(t *testing.T) {
+	u := scanned(t, usageFs(), false)
+	if u.root.Size != 1710 || u.root.Items != 6 {
+		t.Errorf("expected 1710 bytes in 6 entries, got %d bytes in %d entries", u.root.Size, u.root.Items)
+	}
+	if u.selected().Name != "big" {
+		t.Fatalf("expected the largest entry to be selected after the scan, got %s", u.selected().Name)
+	}
+
+	press(u, "enter")
+	names := []string{}
+	for _, c := range u.dir.Children {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, " ") != "b c deep" {
+		t.Errorf("expected the entries of big sorted by size, got %v", names)
+	}
+	press(u, "down")
+	press(u, "down")
+	press(u, "down")
+	if u.selected().Name != "deep" {
+		t.Errorf("expected the cursor to stop at the last entry, got %s", u.selected().Name)
+	}
+	press(u, "left")
+	if u.dir != u.root || u.selected().Name != "big" {
+		t.Errorf("expected to go back to /root with big selected")
+	}
+
+	view := u.View()
+	if lipgloss.Height(view) != 10 {
+		t.Errorf("expected the view to be 10 rows high, got %d", lipgloss.Height(view))
+	}
+	if !strings.Contains(view, "big/") || !strings.Contains(view, "99.4%") {
+		t.Errorf("expected the view to show big with its share of the total:\n%s", view)
+	}
+}
+
+func TestUsageDeleteUpdatesTotals(t *testing.T) {
+	fsys := usageFs()
+	u := scanned(t, fsys, false)
+	press(u, "enter")
+	press(u, "down")
+	press(u, "down")
+	press(u, "d")
+	if u.mode != inputConfirmDelete {
+		t.Fatal("expected a confirmation before deleting")
+	}
+	cmd := press(u, "y")
+	if cmd == nil {
+		t.Fatal("expected a command deleting the entry")
+	}
+	u.Update(cmd())
+
+	if exists, _ := afero.DirExists(fsys, "/root/big/deep"); exists {
+		t.Error("expected /root/big/deep to be removed from the filesystem")
+	}
+	big := u.dir
+	if big.Size != 1500 || big.Items != 2 || len(big.Children) != 2 {
+		t.Errorf("expected big to hold 1500 bytes in 2 entries, got %d bytes in %d entries", big.Size, big.Items)
+	}
+	if u.root.Size != 1510 || u.root.Items != 4 {
+		t.Errorf("expected the root to hold 1510 bytes in 4 entries, got %d bytes in %d entries", u.root.Size, u.root.Items)
+	}
+	if u.selected().Name != "c" {
+		t.Errorf("expected the cursor to move to the last remaining entry, got %s", u.selected().Name)
+	}
+
+	press(u, "d")
+	if cmd := press(u, "n"); cmd != nil || u.mode != inputNone {
+		t.Error("expected nothing to be deleted when the deletion is not confirmed")
+	}
+	if exists, _ := afero.Exists(fsys, "/root/big/c"); !exists {
+		t.Error("expected /root/big/c to be kept")
+	}
+}
+
+func TestUsageDeleteFailureKeepsTotals(t *testing.T) {
+	fsys := usageFs()
+	u := scanned(t, fsys, true)
+	press(u, "d")
+	u.Update(press(u, "y")())
+	if exists, _ := afero.DirExists(fsys, "/root/big"); !exists {
+		t.Error("expected nothing to be removed in dry run mode")
+	}
+	if u.root.Size != 1710 || len(u.root.Children) != 2 {
+		t.Errorf("expected the tree to be unchanged, got %d bytes", u.root.Size)
+	}
+	if u.status != "dry run" {
+		t.Errorf("expected the error to be shown, got %q", u.status)
+	}
+}
+
+func TestUsageExportWhileDeleting(t *testing.T) {
+	fsys := usageFs()
+	u := scanned(t, fsys, false)
+	press(u, "e")
+	export := press(u, "enter")
+	press(u, "d")
+	u.Update(press(u, "y")())
+	if u.root.Size != 10 {
+		t.Fatalf("expected big to be deleted, got %d bytes, status %q", u.root.Size, u.status)
+	}
+
+	// the tree is exported as it was when the export was started
+	u.Update(export())
+	imported, err := u.backend.ImportUsage("/root/fman-usage.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Size != 1710 || len(imported.Children) != 2 {
+		t.Errorf("expected the exported tree to hold 1710 bytes in 2 entries, got %d bytes", imported.Size)
+	}
+}
+
+func TestUsageExportImport(t *testing.T) {
+	fsys := usageFs()
+	u := scanned(t, fsys, false)
+	press(u, "e")
+	if u.mode != inputExport || u.input.Value() != "/root/fman-usage.json" {
+		t.Fatalf("expected to be asked where to export, got %q", u.input.Value())
+	}
+	u.Update(press(u, "enter")())
+	if exists, _ := afero.Exists(fsys, "/root/fman-usage.json"); !exists {
+		t.Fatalf("expected the tree to be exported, status %q", u.status)
+	}
+
+	// a fresh view reads the exported tree back
+	u = scanned(t, fsys, false)
+	press(u, "i")
+	u.input.SetValue("/root/fman-usage.json")
+	u.Update(press(u, "enter")())
+	if !u.imported || u.root.Size != 1710 || u.selected().Name != "big" {
+		t.Fatalf("expected the exported tree to be imported, status %q", u.status)
+	}
+
+	press(u, "d")
+	if u.mode == inputConfirmDelete {
+		t.Error("expected deleting from an imported tree to be refused")
+	}
+	if cmd := press(u, "y"); cmd != nil {
+		u.Update(cmd())
+	}
+	if exists, _ := afero.DirExists(fsys, "/root/big"); !exists {
+		t.Error("expected nothing to be deleted from an imported tree")
+	}
+	if cmd := press(u, "r"); cmd != nil || u.scanning {
+		t.Error("expected rescanning an imported tree to be refused")
+	}
+}